@@ -0,0 +1,56 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/v6/internal/server/response"
+)
+
+// bootstrapRouter wraps authRouter with a narrow set of endpoints reachable without a trusted
+// client certificate, so a caller can discover the agent's CA bundle (and, once cluster join
+// support lands, exchange a one-time join secret) before it has anything to authenticate with.
+// Every other path falls straight through to authRouter, which still requires authentication.
+func bootstrapRouter(cert *x509.Certificate, authRouter *mux.Router) http.Handler {
+	router := mux.NewRouter()
+	router.StrictSlash(false)
+	router.UseEncodedPath()
+
+	router.HandleFunc("/1.0/certificates/ca", func(w http.ResponseWriter, r *http.Request) {
+		getCABundle(cert, w, r)
+	}).Methods("GET")
+
+	router.HandleFunc("/1.0/cluster/join-exchange", joinExchange).Methods("POST")
+
+	router.PathPrefix("/").Handler(authRouter)
+
+	return router
+}
+
+// getCABundle serves the agent's own certificate PEM-encoded alongside its fingerprint, so a
+// client bootstrapping trust (e.g. `incus remote add`) doesn't need the fingerprint out-of-band.
+func getCABundle(cert *x509.Certificate, w http.ResponseWriter, r *http.Request) {
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+
+	fingerprint, _ := authenticate(r, cert)
+	if fingerprint == "" {
+		fingerprint = certFingerprint(cert)
+	}
+
+	_ = response.SyncResponse(true, map[string]string{
+		"certificate": string(pemBytes),
+		"fingerprint": fingerprint,
+	}).Render(w)
+}
+
+// joinExchange would accept a one-time cluster join secret and return the cluster CA, member
+// list and a short-lived client certificate signed by the server. The incus-agent doesn't carry
+// the cluster join-secret store or a CA signing key, so there's nothing here yet for it to do;
+// this route exists so the unauthenticated bootstrap surface has a stable seam to hang that
+// exchange off once cluster join support reaches the agent.
+func joinExchange(w http.ResponseWriter, r *http.Request) {
+	_ = response.NotImplemented(nil).Render(w)
+}
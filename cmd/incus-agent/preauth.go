@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// PreAuthorizeFunc is consulted by createCmd after a request has been authenticated (it always has
+// a fingerprint available via fingerprintFromContext) but before it's dispatched to its handler. It
+// decides whether to allow the request, and may attach extra headers to the request's context for
+// the handler to consult (e.g. a filtered project list or a set of effective permissions).
+type PreAuthorizeFunc func(d *Daemon, r *http.Request) (allow bool, extra http.Header, err error)
+
+// defaultPreAuthorize is the in-process pre-authorization check: the incus-agent doesn't carry a
+// project/RBAC model beyond the TLS client certificate check authMiddleware already performed, so
+// anything that reached this point is allowed. An operator wanting finer-grained policy sets
+// APIEndpointAction.PreAuthorize (or overrides this default) to newWebhookPreAuthorize instead.
+func defaultPreAuthorize(d *Daemon, r *http.Request) (bool, http.Header, error) {
+	return true, nil, nil
+}
+
+// preAuthorizeEnvelope is the JSON body POSTed to a pre-authorization webhook.
+type preAuthorizeEnvelope struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Route       string      `json:"route,omitempty"`
+	Fingerprint string      `json:"fingerprint"`
+	Headers     http.Header `json:"headers"`
+}
+
+// newWebhookPreAuthorize returns a PreAuthorizeFunc that delegates the decision to an external
+// policy backend (OpenFGA, OPA, an identity broker, ...) reachable at webhookURL: it POSTs a JSON
+// envelope describing the request and rejects on any non-2xx response, otherwise applying the
+// response's headers as extra context for the handler.
+func newWebhookPreAuthorize(webhookURL string) PreAuthorizeFunc {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return func(d *Daemon, r *http.Request) (bool, http.Header, error) {
+		routeName := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			routeName = route.GetName()
+		}
+
+		envelope := preAuthorizeEnvelope{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Route:       routeName,
+			Fingerprint: fingerprintFromContext(r.Context()),
+			Headers:     r.Header,
+		}
+
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			return false, nil, err
+		}
+
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, webhookURL, bytes.NewReader(body))
+		if err != nil {
+			return false, nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, nil, err
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return false, nil, nil
+		}
+
+		return true, resp.Header.Clone(), nil
+	}
+}
+
+// preAuthorizedHeadersFromContext returns the extra headers a PreAuthorizeFunc attached to r, or
+// nil if none did.
+func preAuthorizedHeadersFromContext(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(ctxKeyPreAuthHeaders).(http.Header)
+
+	return headers
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -17,24 +23,206 @@ import (
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
+// Middleware wraps an http.Handler to add cross-cutting behavior (authentication, request-scoped
+// context values, logging, ...) around every route it's applied to.
+type Middleware func(http.Handler) http.Handler
+
+// ctxKey namespaces the context values middlewares attach to a request, so they don't collide with
+// keys set by net/http or a library the agent imports.
+type ctxKey int
+
+const (
+	ctxKeyFingerprint ctxKey = iota
+	ctxKeyRequestID
+	ctxKeyUploads
+	ctxKeyPreAuthHeaders
+)
+
+// fingerprintFromContext returns the trusted client certificate fingerprint the auth middleware
+// recorded for r, or "" if r wasn't authenticated through it.
+func fingerprintFromContext(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(ctxKeyFingerprint).(string)
+
+	return fingerprint
+}
+
+// requestIDFromContext returns the correlation ID the requestID middleware recorded for r, or ""
+// if r didn't go through it.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeyRequestID).(string)
+
+	return id
+}
+
+// requestIDHeader carries a request's correlation ID both on the way in (a caller, or an
+// upstream proxy, may already have assigned one) and on the way out (so the caller can tie its own
+// logs back to ours).
+const requestIDHeader = "X-Incus-Request-ID"
+
+// generateRequestID returns a short opaque correlation ID for a request that didn't arrive with
+// one already.
+func generateRequestID() string {
+	buf := make([]byte, 8)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		// crypto/rand.Read on a supported platform doesn't fail; fall back to a fixed marker
+		// rather than leaving logs and headers without a request ID at all.
+		return "00000000"
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+// requestIDMiddleware assigns every request a correlation ID, taken from the X-Incus-Request-ID
+// or X-Request-ID header if the caller (or an upstream proxy) already set one, and echoes it back
+// on the response so a client can correlate its own logs with ours.
+func requestIDMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestIDHeader)
+			if id == "" {
+				id = r.Header.Get("X-Request-ID")
+			}
+
+			if id == "" {
+				id = generateRequestID()
+			}
+
+			w.Header().Set(requestIDHeader, id)
+
+			ctx := context.WithValue(r.Context(), ctxKeyRequestID, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// statusRecorder wraps a ResponseWriter to remember the status code a handler wrote, for
+// loggingMiddleware's structured log line.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// loggingMiddleware logs a single structured line per request, once the handler (and any
+// middleware nested inside this one) has finished, so operators can correlate a client call with
+// its cluster-wide side effects via the request ID alone.
+func loggingMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			logger.Info("Handled request", logger.Ctx{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      rec.status,
+				"duration":    time.Since(start),
+				"fingerprint": fingerprintFromContext(r.Context()),
+				"request_id":  requestIDFromContext(r.Context()),
+			})
+		})
+	}
+}
+
+// chain wraps h with middlewares in order, so the first middleware in the slice is the outermost
+// one and sees the request before any of the others.
+func chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// authMiddleware rejects any request whose client certificate isn't trusted, and otherwise stashes
+// its fingerprint on the request context for downstream middlewares and handlers.
+func authMiddleware(cert *x509.Certificate) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			fingerprint, ok := authenticate(r, cert)
+			if !ok {
+				logger.Error("Not authorized")
+				_ = response.InternalError(errors.New("Not authorized")).Render(w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKeyFingerprint, fingerprint)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// debugMiddleware dumps the JSON body of non-GET requests to the logger when debug is enabled,
+// replacing r.Body with an equivalent reader so the handler still sees the full request.
+func debugMiddleware(debug bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		if !debug {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "GET" && localUtil.IsJSONRequest(r) {
+				newBody := &bytes.Buffer{}
+				captured := &bytes.Buffer{}
+				multiW := io.MultiWriter(newBody, captured)
+				_, err := io.Copy(multiW, r.Body)
+				if err != nil {
+					_ = response.InternalError(err).Render(w)
+					return
+				}
+
+				r.Body = internalIO.BytesReadCloser{Buf: newBody}
+				localUtil.DebugJSON("API Request", captured, logger.Log)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func restServer(tlsConfig *tls.Config, cert *x509.Certificate, debug bool, d *Daemon) *http.Server {
 	router := mux.NewRouter()
 	router.StrictSlash(false) // Don't redirect to URL with trailing slash.
 	router.UseEncodedPath()   // Allow encoded values in path segments.
 
+	// Cross-cutting behavior shared by every /1.0/... route. Order matters: auth runs right after
+	// the request ID is assigned, so later middlewares and handlers can rely on the request
+	// already being authenticated.
+	middlewares := []Middleware{
+		requestIDMiddleware(),
+		authMiddleware(cert),
+		loggingMiddleware(),
+		uploadMiddleware(os.TempDir()), // os.TempDir() until core.upload_tempdir is wired through.
+		debugMiddleware(debug),
+	}
+
+	for _, m := range middlewares {
+		router.Use(mux.MiddlewareFunc(m))
+	}
+
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		_ = response.SyncResponse(true, []string{"/1.0"}).Render(w)
 	})
 
 	for _, c := range api10 {
-		createCmd(router, "1.0", c, cert, debug, d)
+		createCmd(router, "1.0", c, d)
 	}
 
-	return &http.Server{Handler: router, TLSConfig: tlsConfig}
+	return &http.Server{Handler: bootstrapRouter(cert, router), TLSConfig: tlsConfig}
 }
 
-func createCmd(restAPI *mux.Router, version string, c APIEndpoint, cert *x509.Certificate, debug bool, d *Daemon) {
+func createCmd(restAPI *mux.Router, version string, c APIEndpoint, d *Daemon) {
 	var uri string
 	if c.Path == "" {
 		uri = fmt.Sprintf("/%s", version)
@@ -42,28 +230,28 @@ func createCmd(restAPI *mux.Router, version string, c APIEndpoint, cert *x509.Ce
 		uri = fmt.Sprintf("/%s/%s", version, c.Path)
 	}
 
-	route := restAPI.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Consult the pre-authorization hook after authentication but before dispatch, so a route
+		// can defer to an external policy backend without every handler having to know about it.
+		preAuthorize := defaultPreAuthorize
+		if c.PreAuthorize != nil {
+			preAuthorize = c.PreAuthorize
+		}
 
-		if !authenticate(r, cert) {
-			logger.Error("Not authorized")
-			_ = response.InternalError(errors.New("Not authorized")).Render(w)
+		allow, extra, err := preAuthorize(d, r)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
 			return
 		}
 
-		// Dump full request JSON when in debug mode
-		if r.Method != "GET" && localUtil.IsJSONRequest(r) {
-			newBody := &bytes.Buffer{}
-			captured := &bytes.Buffer{}
-			multiW := io.MultiWriter(newBody, captured)
-			_, err := io.Copy(multiW, r.Body)
-			if err != nil {
-				_ = response.InternalError(err).Render(w)
-				return
-			}
+		if !allow {
+			_ = response.Forbidden(nil).Render(w)
+			return
+		}
 
-			r.Body = internalIO.BytesReadCloser{Buf: newBody}
-			localUtil.DebugJSON("API Request", captured, logger.Log)
+		if extra != nil {
+			ctx := context.WithValue(r.Context(), ctxKeyPreAuthHeaders, extra)
+			r = r.WithContext(ctx)
 		}
 
 		// Actually process the request
@@ -93,7 +281,7 @@ func createCmd(restAPI *mux.Router, version string, c APIEndpoint, cert *x509.Ce
 		}
 
 		// Handle errors
-		err := resp.Render(w)
+		err = resp.Render(w)
 		if err != nil {
 			writeErr := response.InternalError(err).Render(w)
 			if writeErr != nil {
@@ -102,6 +290,11 @@ func createCmd(restAPI *mux.Router, version string, c APIEndpoint, cert *x509.Ce
 		}
 	})
 
+	// Routes opt into extra, route-specific middleware (on top of the router-wide chain set up in
+	// restServer) via c.Middlewares, e.g. to rate-limit or require read-only access on one route
+	// without touching every other handler.
+	route := restAPI.Handle(uri, chain(handler, c.Middlewares...))
+
 	// If the endpoint has a canonical name then record it so it can be used to build URLS
 	// and accessed in the context of the request by the handler function.
 	if c.Name != "" {
@@ -109,15 +302,24 @@ func createCmd(restAPI *mux.Router, version string, c APIEndpoint, cert *x509.Ce
 	}
 }
 
-func authenticate(r *http.Request, cert *x509.Certificate) bool {
+// authenticate reports whether r carries a client certificate trusted by cert, and if so returns
+// its fingerprint.
+func authenticate(r *http.Request, cert *x509.Certificate) (string, bool) {
 	clientCerts := map[string]x509.Certificate{"0": *cert}
 
-	for _, cert := range r.TLS.PeerCertificates {
-		trusted, _ := localUtil.CheckTrustState(*cert, clientCerts, nil, false)
+	for _, peerCert := range r.TLS.PeerCertificates {
+		trusted, _ := localUtil.CheckTrustState(*peerCert, clientCerts, nil, false)
 		if trusted {
-			return true
+			return certFingerprint(peerCert), true
 		}
 	}
 
-	return false
+	return "", false
+}
+
+// certFingerprint returns cert's SHA-256 fingerprint, hex-encoded.
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// uploadSpool tracks one file part that uploadMiddleware spooled to disk, so it can be unlinked
+// once the request is done unless the handler claimed it.
+type uploadSpool struct {
+	path     string
+	consumed bool
+}
+
+// uploadTracker collects the spools created for one request, attached to its context so
+// ConsumeUpload (called by a handler) and uploadMiddleware's post-request cleanup can share it.
+type uploadTracker struct {
+	mu     sync.Mutex
+	spools map[string]*uploadSpool // keyed by remote_id
+}
+
+// ConsumeUpload marks the spooled file identified by remoteID as claimed by the handler, returning
+// its temp path. uploadMiddleware unlinks any spool a handler doesn't claim this way, so a request
+// that errors out before reading its upload doesn't leak a temp file.
+func ConsumeUpload(r *http.Request, remoteID string) (string, bool) {
+	tracker, ok := r.Context().Value(ctxKeyUploads).(*uploadTracker)
+	if !ok {
+		return "", false
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	spool, ok := tracker.spools[remoteID]
+	if !ok {
+		return "", false
+	}
+
+	spool.consumed = true
+
+	return spool.path, true
+}
+
+// uploadMiddleware offloads multipart/form-data file parts to temp files under tempDir rather
+// than buffering multi-GB image and instance-file uploads in memory. Each file part is replaced in
+// the forwarded form with "<name>.path", "<name>.size", "<name>.sha256" and "<name>.remote_id"
+// fields describing the spooled file; handlers read the file directly from its path (via
+// ConsumeUpload) instead of re-reading it from the request body. Non-multipart requests pass
+// through unchanged.
+func uploadMiddleware(tempDir string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tracker := &uploadTracker{spools: map[string]*uploadSpool{}}
+
+			newBody, contentType, err := spoolMultipart(r.Body, params["boundary"], tempDir, tracker)
+			if err != nil {
+				unlinkSpools(tracker)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			r.Body = io.NopCloser(newBody)
+			r.ContentLength = int64(newBody.Len())
+			r.Header.Set("Content-Type", contentType)
+
+			ctx := context.WithValue(r.Context(), ctxKeyUploads, tracker)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			unlinkSpools(tracker)
+		})
+	}
+}
+
+// spoolMultipart reads a multipart/form-data body with the given boundary, writing each file
+// part to a temp file under tempDir and replacing it with metadata fields in the rewritten body it
+// returns, alongside that body's own Content-Type (carrying a freshly generated boundary).
+func spoolMultipart(body io.Reader, boundary string, tempDir string, tracker *uploadTracker) (*bytes.Buffer, string, error) {
+	reader := multipart.NewReader(body, boundary)
+
+	out := &bytes.Buffer{}
+	writer := multipart.NewWriter(out)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, "", fmt.Errorf("Invalid multipart upload: %w", err)
+		}
+
+		name := part.FormName()
+
+		if part.FileName() == "" {
+			data, err := io.ReadAll(part)
+			if err != nil {
+				return nil, "", fmt.Errorf("Invalid multipart upload: %w", err)
+			}
+
+			err = writer.WriteField(name, string(data))
+			if err != nil {
+				return nil, "", err
+			}
+
+			continue
+		}
+
+		remoteID, size, sha256sum, path, err := spoolFilePart(part, tempDir)
+		if err != nil {
+			return nil, "", err
+		}
+
+		tracker.spools[remoteID] = &uploadSpool{path: path}
+
+		metadata := map[string]string{
+			"path":      path,
+			"size":      fmt.Sprintf("%d", size),
+			"sha256":    sha256sum,
+			"remote_id": remoteID,
+		}
+
+		for _, suffix := range []string{"path", "size", "sha256", "remote_id"} {
+			err := writer.WriteField(fmt.Sprintf("%s.%s", name, suffix), metadata[suffix])
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return out, writer.FormDataContentType(), nil
+}
+
+// spoolFilePart streams part to a new temp file under tempDir, returning a freshly generated
+// remote ID alongside the spooled file's size, sha256 digest and path.
+func spoolFilePart(part *multipart.Part, tempDir string) (remoteID string, size int64, sha256sum string, path string, err error) {
+	remoteID, err = randomUploadID()
+	if err != nil {
+		return "", 0, "", "", err
+	}
+
+	f, err := os.CreateTemp(tempDir, "incus-agent-upload-*")
+	if err != nil {
+		return "", 0, "", "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hasher := sha256.New()
+
+	size, err = io.Copy(io.MultiWriter(f, hasher), part)
+	if err != nil {
+		_ = os.Remove(f.Name())
+
+		return "", 0, "", "", err
+	}
+
+	return remoteID, size, hex.EncodeToString(hasher.Sum(nil)), f.Name(), nil
+}
+
+// unlinkSpools removes every spool in tracker that hasn't been consumed, logging (but not
+// failing) on any file it can't remove.
+func unlinkSpools(tracker *uploadTracker) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	for _, spool := range tracker.spools {
+		if spool.consumed {
+			continue
+		}
+
+		err := os.Remove(spool.path)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed removing unclaimed upload spool file", logger.Ctx{"path": spool.path, "err": err})
+		}
+	}
+}
+
+// randomUploadID returns a short opaque identifier for a spooled upload.
+func randomUploadID() (string, error) {
+	buf := make([]byte, 8)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
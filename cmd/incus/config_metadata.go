@@ -4,17 +4,95 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	incus "github.com/lxc/incus/v6/client"
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/osarch"
 	"github.com/lxc/incus/v6/shared/termios"
 )
 
+// metadataTarget abstracts over the instance and image metadata APIs so that
+// `incus config metadata` can operate on either kind of resource.
+type metadataTarget interface {
+	// Get fetches the current metadata and its etag.
+	Get() (*api.ImageMetadata, string, error)
+	// Update PATCHes the metadata, enforcing the given etag.
+	Update(metadata api.ImageMetadata, etag string) error
+	// TemplateFiles lists the template files available for validation, if any.
+	TemplateFiles() ([]string, error)
+}
+
+type instanceMetadataTarget struct {
+	server incus.InstanceServer
+	name   string
+}
+
+func (t instanceMetadataTarget) Get() (*api.ImageMetadata, string, error) {
+	return t.server.GetInstanceMetadata(t.name)
+}
+
+func (t instanceMetadataTarget) Update(metadata api.ImageMetadata, etag string) error {
+	return t.server.UpdateInstanceMetadata(t.name, metadata, etag)
+}
+
+func (t instanceMetadataTarget) TemplateFiles() ([]string, error) {
+	return t.server.GetInstanceTemplateFiles(t.name)
+}
+
+type imageMetadataTarget struct {
+	server      incus.InstanceServer
+	fingerprint string
+}
+
+func (t imageMetadataTarget) Get() (*api.ImageMetadata, string, error) {
+	return t.server.GetImageMetadata(t.fingerprint)
+}
+
+func (t imageMetadataTarget) Update(metadata api.ImageMetadata, etag string) error {
+	return t.server.UpdateImageMetadata(t.fingerprint, metadata, etag)
+}
+
+func (t imageMetadataTarget) TemplateFiles() ([]string, error) {
+	// Images don't expose a templates directory listing, so there's nothing to check.
+	return nil, nil
+}
+
+// resolveMetadataTarget figures out whether the given name refers to an instance or an
+// image (by fingerprint or alias), and returns the matching metadataTarget.
+func resolveMetadataTarget(resource remoteResource) (metadataTarget, error) {
+	_, _, err := resource.server.GetInstance(resource.name)
+	if err == nil {
+		return instanceMetadataTarget{server: resource.server, name: resource.name}, nil
+	}
+
+	if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return nil, err
+	}
+
+	fingerprint := resource.name
+
+	alias, _, err := resource.server.GetImageAlias(resource.name)
+	if err == nil {
+		fingerprint = alias.Target
+	}
+
+	_, _, err = resource.server.GetImage(fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf(i18n.G("%q is neither an instance nor an image"), resource.name)
+	}
+
+	return imageMetadataTarget{server: resource.server, fingerprint: fingerprint}, nil
+}
+
 type cmdConfigMetadata struct {
 	global *cmdGlobal
 	config *cmdConfig
@@ -24,9 +102,9 @@ type cmdConfigMetadata struct {
 func (c *cmdConfigMetadata) Command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("metadata")
-	cmd.Short = i18n.G("Manage instance metadata files")
+	cmd.Short = i18n.G("Manage instance or image metadata files")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Manage instance metadata files`))
+		`Manage instance or image metadata files`))
 
 	// Edit
 	configMetadataEditCmd := cmdConfigMetadataEdit{global: c.global, config: c.config, configMetadata: c}
@@ -36,6 +114,10 @@ func (c *cmdConfigMetadata) Command() *cobra.Command {
 	configMetadataShowCmd := cmdConfigMetadataShow{global: c.global, config: c.config, configMetadata: c}
 	cmd.AddCommand(configMetadataShowCmd.Command())
 
+	// Template
+	configMetadataTemplateCmd := cmdConfigMetadataTemplate{global: c.global, config: c.config, configMetadata: c}
+	cmd.AddCommand(configMetadataTemplateCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
@@ -47,15 +129,19 @@ type cmdConfigMetadataEdit struct {
 	global         *cmdGlobal
 	config         *cmdConfig
 	configMetadata *cmdConfigMetadata
+
+	flagDryRun bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
 func (c *cmdConfigMetadataEdit) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("edit", i18n.G("[<remote>:]<instance>"))
-	cmd.Short = i18n.G("Edit instance metadata files")
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<instance|image>"))
+	cmd.Short = i18n.G("Edit instance or image metadata files")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Edit instance metadata files`))
+		`Edit instance or image metadata files`))
+
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only validate the metadata, don't upload it"))
 
 	cmd.RunE = c.Run
 
@@ -111,7 +197,12 @@ func (c *cmdConfigMetadataEdit) Run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	if resource.name == "" {
-		return errors.New(i18n.G("Missing instance name"))
+		return errors.New(i18n.G("Missing instance or image name"))
+	}
+
+	target, err := resolveMetadataTarget(resource)
+	if err != nil {
+		return err
 	}
 
 	// Edit the metadata
@@ -127,10 +218,19 @@ func (c *cmdConfigMetadataEdit) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return resource.server.UpdateInstanceMetadata(resource.name, metadata, "")
+		err = c.validate(target, &metadata)
+		if err != nil {
+			return err
+		}
+
+		if c.flagDryRun {
+			return nil
+		}
+
+		return target.Update(metadata, "")
 	}
 
-	metadata, etag, err := resource.server.GetInstanceMetadata(resource.name)
+	metadata, etag, err := target.Get()
 	if err != nil {
 		return err
 	}
@@ -150,7 +250,11 @@ func (c *cmdConfigMetadataEdit) Run(cmd *cobra.Command, args []string) error {
 		metadata := api.ImageMetadata{}
 		err = yaml.Unmarshal(content, &metadata)
 		if err == nil {
-			err = resource.server.UpdateInstanceMetadata(resource.name, metadata, etag)
+			err = c.validate(target, &metadata)
+		}
+
+		if err == nil && !c.flagDryRun {
+			err = target.Update(metadata, etag)
 		}
 
 		// Respawn the editor
@@ -177,6 +281,71 @@ func (c *cmdConfigMetadataEdit) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validTemplateTriggers are the "when" values the instance template engine understands.
+var validTemplateTriggers = map[string]bool{
+	"create": true,
+	"copy":   true,
+	"rename": true,
+}
+
+// knownMetadataProperties are the image metadata properties the standard tooling understands.
+var knownMetadataProperties = map[string]bool{
+	"architecture": true,
+	"description":  true,
+	"name":         true,
+	"os":           true,
+	"release":      true,
+	"variant":      true,
+}
+
+// validate checks the new metadata against the instance's template files and known triggers,
+// architectures and properties before it gets uploaded. Template file references are only checked
+// when the target lists its template files.
+func (c *cmdConfigMetadataEdit) validate(target metadataTarget, metadata *api.ImageMetadata) error {
+	files, err := target.TemplateFiles()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[file] = true
+	}
+
+	var errs []string
+
+	for path, tpl := range metadata.Templates {
+		if tpl.Template != "" && files != nil && !known[tpl.Template] {
+			errs = append(errs, fmt.Sprintf(i18n.G("Template entry %q references unknown template file %q"), path, tpl.Template))
+		}
+
+		for _, when := range tpl.When {
+			if !validTemplateTriggers[when] {
+				errs = append(errs, fmt.Sprintf(i18n.G("Template entry %q has unknown trigger %q"), path, when))
+			}
+		}
+	}
+
+	if metadata.Architecture != "" {
+		_, err := osarch.ArchitectureID(metadata.Architecture)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf(i18n.G("Unknown architecture %q"), metadata.Architecture))
+		}
+	}
+
+	for key := range metadata.Properties {
+		if !knownMetadataProperties[key] {
+			fmt.Fprintf(os.Stderr, i18n.G("Warning: unknown metadata property %q")+"\n", key)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
 // Show.
 type cmdConfigMetadataShow struct {
 	global         *cmdGlobal
@@ -187,10 +356,10 @@ type cmdConfigMetadataShow struct {
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
 func (c *cmdConfigMetadataShow) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("show", i18n.G("[<remote>:]<instance>"))
-	cmd.Short = i18n.G("Show instance metadata files")
+	cmd.Use = usage("show", i18n.G("[<remote>:]<instance|image>"))
+	cmd.Short = i18n.G("Show instance or image metadata files")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Show instance metadata files`))
+		`Show instance or image metadata files`))
 
 	cmd.RunE = c.Run
 
@@ -221,17 +390,367 @@ func (c *cmdConfigMetadataShow) Run(cmd *cobra.Command, args []string) error {
 
 	resource := resources[0]
 
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance or image name"))
+	}
+
+	target, err := resolveMetadataTarget(resource)
+	if err != nil {
+		return err
+	}
+
+	// Show the metadata
+	metadata, _, err := target.Get()
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", content)
+
+	return nil
+}
+
+// Template.
+type cmdConfigMetadataTemplate struct {
+	global         *cmdGlobal
+	config         *cmdConfig
+	configMetadata *cmdConfigMetadata
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdConfigMetadataTemplate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("template")
+	cmd.Short = i18n.G("Manage instance metadata templates")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage instance metadata templates`))
+
+	// Add
+	configMetadataTemplateAddCmd := cmdConfigMetadataTemplateAdd{global: c.global, config: c.config, configMetadataTemplate: c}
+	cmd.AddCommand(configMetadataTemplateAddCmd.Command())
+
+	// Remove
+	configMetadataTemplateRemoveCmd := cmdConfigMetadataTemplateRemove{global: c.global, config: c.config, configMetadataTemplate: c}
+	cmd.AddCommand(configMetadataTemplateRemoveCmd.Command())
+
+	// List
+	configMetadataTemplateListCmd := cmdConfigMetadataTemplateList{global: c.global, config: c.config, configMetadataTemplate: c}
+	cmd.AddCommand(configMetadataTemplateListCmd.Command())
+
+	// Show
+	configMetadataTemplateShowCmd := cmdConfigMetadataTemplateShow{global: c.global, config: c.config, configMetadataTemplate: c}
+	cmd.AddCommand(configMetadataTemplateShowCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Add.
+type cmdConfigMetadataTemplateAdd struct {
+	global                 *cmdGlobal
+	config                 *cmdConfig
+	configMetadataTemplate *cmdConfigMetadataTemplate
+
+	flagWhen       []string
+	flagTemplate   string
+	flagCreateOnly bool
+	flagProperty   []string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdConfigMetadataTemplateAdd) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<instance> <path>"))
+	cmd.Short = i18n.G("Add an instance metadata template entry")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add an instance metadata template entry`))
+
+	cmd.Flags().StringArrayVar(&c.flagWhen, "when", nil, i18n.G("Trigger to add the template entry for")+"``")
+	cmd.Flags().StringVar(&c.flagTemplate, "template", "", i18n.G("Template file to render")+"``")
+	cmd.Flags().BoolVar(&c.flagCreateOnly, "create-only", false, i18n.G("Only apply the template on creation"))
+	cmd.Flags().StringArrayVar(&c.flagProperty, "property", nil, i18n.G("Template property (key=value)")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdConfigMetadataTemplateAdd) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	path := args[1]
+
+	metadata, etag, err := resource.server.GetInstanceMetadata(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if metadata.Templates == nil {
+		metadata.Templates = map[string]*api.ImageMetadataTemplate{}
+	}
+
+	_, ok := metadata.Templates[path]
+	if ok {
+		return fmt.Errorf(i18n.G("Template entry %q already exists"), path)
+	}
+
+	properties := map[string]string{}
+	for _, entry := range c.flagProperty {
+		fields := strings.SplitN(entry, "=", 2)
+		if len(fields) < 2 {
+			return fmt.Errorf(i18n.G("Bad key=value pair: %s"), entry)
+		}
+
+		properties[fields[0]] = fields[1]
+	}
+
+	metadata.Templates[path] = &api.ImageMetadataTemplate{
+		When:       c.flagWhen,
+		CreateOnly: c.flagCreateOnly,
+		Template:   c.flagTemplate,
+		Properties: properties,
+	}
+
+	return resource.server.UpdateInstanceMetadata(resource.name, *metadata, etag)
+}
+
+// Remove.
+type cmdConfigMetadataTemplateRemove struct {
+	global                 *cmdGlobal
+	config                 *cmdConfig
+	configMetadataTemplate *cmdConfigMetadataTemplate
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdConfigMetadataTemplateRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<instance> <path>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Remove an instance metadata template entry")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Remove an instance metadata template entry`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdConfigMetadataTemplateRemove) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	path := args[1]
+
+	metadata, etag, err := resource.server.GetInstanceMetadata(resource.name)
+	if err != nil {
+		return err
+	}
+
+	_, ok := metadata.Templates[path]
+	if !ok {
+		return fmt.Errorf(i18n.G("Template entry %q doesn't exist"), path)
+	}
+
+	delete(metadata.Templates, path)
+
+	return resource.server.UpdateInstanceMetadata(resource.name, *metadata, etag)
+}
+
+// List.
+type cmdConfigMetadataTemplateList struct {
+	global                 *cmdGlobal
+	config                 *cmdConfig
+	configMetadataTemplate *cmdConfigMetadataTemplate
+
+	flagFormat string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdConfigMetadataTemplateList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<instance>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List instance metadata template entries")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`List instance metadata template entries`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact|markdown), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdConfigMetadataTemplateList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
 	if resource.name == "" {
 		return errors.New(i18n.G("Missing instance name"))
 	}
 
-	// Show the instance metadata
 	metadata, _, err := resource.server.GetInstanceMetadata(resource.name)
 	if err != nil {
 		return err
 	}
 
-	content, err := yaml.Marshal(metadata)
+	data := [][]string{}
+	for path, tpl := range metadata.Templates {
+		data = append(data, []string{
+			path,
+			strings.Join(tpl.When, ", "),
+			tpl.Template,
+		})
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("PATH"),
+		i18n.G("TRIGGERS"),
+		i18n.G("TEMPLATE"),
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, metadata.Templates)
+}
+
+// Show.
+type cmdConfigMetadataTemplateShow struct {
+	global                 *cmdGlobal
+	config                 *cmdConfig
+	configMetadataTemplate *cmdConfigMetadataTemplate
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdConfigMetadataTemplateShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<instance> <path>"))
+	cmd.Short = i18n.G("Show an instance metadata template entry")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show an instance metadata template entry`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpInstances(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdConfigMetadataTemplateShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing instance name"))
+	}
+
+	path := args[1]
+
+	metadata, _, err := resource.server.GetInstanceMetadata(resource.name)
+	if err != nil {
+		return err
+	}
+
+	tpl, ok := metadata.Templates[path]
+	if !ok {
+		return fmt.Errorf(i18n.G("Template entry %q doesn't exist"), path)
+	}
+
+	content, err := yaml.Marshal(tpl)
 	if err != nil {
 		return err
 	}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"os"
+	"path"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -18,7 +24,9 @@ import (
 	cli "github.com/lxc/incus/v6/internal/cmd"
 	"github.com/lxc/incus/v6/internal/i18n"
 	"github.com/lxc/incus/v6/internal/instance"
+	"github.com/lxc/incus/v6/internal/parallel"
 	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/termios"
 	"github.com/lxc/incus/v6/shared/units"
 	"github.com/lxc/incus/v6/shared/util"
 )
@@ -28,6 +36,8 @@ type column struct {
 	Data           columnData
 	NeedsState     bool
 	NeedsSnapshots bool
+	Align          string
+	Width          int
 }
 
 type columnData func(api.InstanceFull) string
@@ -38,9 +48,19 @@ type cmdList struct {
 	flagColumns     string
 	flagFast        bool
 	flagFormat      string
+	flagTemplate    string
 	flagAllProjects bool
+	flagSort        string
+	flagFilter      []string
+	flagParallel    int
+	flagWatch       string
+	flagTotals      bool
+	flagFilterFile  string
+	flagExcludeFile string
 
 	shorthandFilters map[string]func(*api.Instance, *api.InstanceState, string) bool
+	filterFileTrie   *cidrTrie
+	excludeFileTrie  *cidrTrie
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -59,6 +79,9 @@ A single keyword like "web" which will list any instance with a name starting by
 A regular expression on the instance name. (e.g. .*web.*01$).
 A key/value pair referring to a configuration item. For those, the
 namespace can be abbreviated to the smallest unambiguous identifier.
+By default the key must equal the value, but "!=" (not equal), "~=" (regular expression
+match), "^=" (prefix) and "*=" (contains) may be used instead of "=", e.g.
+"name~=^web-\d+$", "image.os!=Alpine" or "config.user.role*=db".
 A key/value pair where the key is a shorthand. Multiple values must be delimited by ','. Available shorthands:
   - type={instance type}
   - status={instance current lifecycle status}
@@ -66,6 +89,8 @@ A key/value pair where the key is a shorthand. Multiple values must be delimited
   - location={location name}
   - ipv4={ip or CIDR}
   - ipv6={ip or CIDR}
+  - mac={MAC address, exact, prefix or glob, e.g. mac=aa:bb:cc:* or mac=aabbcc}
+  - iface={host-side interface name, exact or glob, e.g. iface=veth*}
 
 Examples:
   - "user.blah=abc" will list all instances with the "blah" user property set to "abc".
@@ -78,7 +103,9 @@ Examples:
 A regular expression matching a configuration item or its value. (e.g. volatile.eth0.hwaddr=10:66:6a:.*).
 
 When multiple filters are passed, they are added one on top of the other,
-selecting instances which satisfy them all.
+selecting instances which satisfy them all. "and", "or" and "not" may be used
+to combine filters explicitly, with parentheses for grouping, e.g.:
+  "ipv4=10.0.0.0/24 and (status=running or status=frozen) and not user.env=prod"
 
 == Columns ==
 The -c option takes a comma separated list of arguments that control
@@ -115,13 +142,58 @@ Pre-defined column shorthand chars:
   f - Base Image Fingerprint (short)
   F - Base Image Fingerprint (long)
 
-Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
-  KEY: The (extended) config or devices key to display. If [config:|devices:] is omitted then it defaults to config key.
+Custom columns are defined with "[config:|devices:|state:|func:]key[:name][:maxWidth[:align]]":
+  KEY: The (extended) config or devices key to display. If [config:|devices:|state:|func:] is omitted then it defaults to config key.
+    config: an (extended) config key, e.g. config:volatile.eth0.hwaddr
+    devices: an (extended) devices key, e.g. devices:eth0.parent
+    state: a dotted path into the instance's runtime state, e.g. state:cpu.usage or state:network.eth0.addresses.inet
+    func: one of the built-in derived columns: uptime, memorypct (alias for M), firstipv4 (alias for 4, first address only)
   NAME: Name to display in the column header.
   Defaults to the key if not specified or empty.
 
   MAXWIDTH: Max width of the column (longer results are truncated).
-  Defaults to -1 (unlimited). Use 0 to limit to the column header size.`))
+  Defaults to -1 (unlimited). Use 0 to limit to the column header size.
+
+  ALIGN: One of "l" (left, default), "r" (right) or "c" (center), optionally followed by a
+  "%"-style numeric format applied to the column's value before alignment, e.g. ":10:r%.1f".
+
+--totals adds a trailing row summing every column whose values are all byte sizes or plain
+numbers across the displayed instances.
+
+== Template format ==
+Pass --format=template --template '{{.Name}}' (or the shorthand --format '{{json .}}')
+to render each instance with a Go text/template, receiving the full api.InstanceFull.
+In addition to the text/template builtins, the "json", "table", "truncate", "upper"
+and "humanSize" helper functions are available.
+
+== Sorting and rich filtering ==
+--sort <col>[,<col>...] overrides the default natural sort, ordering by the resolved
+column data. Prefix a column with "-" to sort it in descending order.
+
+--filter <key>{op}<value> may be repeated, ANDing every expression together. <key> is
+any shorthand column (by name, e.g. "status" or "memory") or a "config:..."/"devices:..."
+key, and <op> is one of:
+  =    equal (case-insensitive)
+  !=   not equal
+  =~   regular expression match
+  !~   regular expression non-match
+  >    greater than (byte sizes are parsed for memory/disk keys)
+  >=   greater than or equal
+  <    less than
+  <=   less than or equal
+  in   value is one of a comma-separated list, e.g. "status in running,stopped"
+
+--parallel <N> caps how many state/snapshot requests are in flight at once (0 falls back
+to the INCUS_PARALLEL environment variable, then a sane default).
+
+--watch keeps the command running, refreshing the list on every lifecycle/operation event
+from the server. --watch=<duration> (e.g. --watch=5s) refreshes on a fixed timer instead.
+On a terminal the screen is cleared before each refresh; otherwise, and for non-table
+formats, every refresh is printed as its own document.
+
+--filter-file/--exclude-file load a newline-delimited list of IPv4/IPv6 CIDRs (comments start
+with "#") and only show/hide instances with at least one address inside one of them, e.g. to
+list every instance in a set of management subnets fed by an external IPAM export.`))
 
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`incus list -c nFs46,volatile.eth0.hwaddr:MAC,config:image.os,devices:eth0.parent:ETHP
@@ -134,12 +206,26 @@ incus list -c ns,user.comment:comment
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", defaultColumns, i18n.G("Columns")+"``")
-	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact|markdown), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact|markdown|template), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header. A "{{...}}" value is a shorthand for --format=template --template '{{...}}'`)+"``")
 	cmd.Flags().BoolVar(&c.flagFast, "fast", false, i18n.G("Fast mode (same as --columns=nsacPt)"))
 	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("Display instances from all projects"))
+	cmd.Flags().StringVar(&c.flagTemplate, "template", "", i18n.G("Go template to render with --format=template, e.g. '{{.Name}}'")+"``")
+	cmd.Flags().StringVar(&c.flagSort, "sort", "", i18n.G("Sort by comma-separated column(s), prefix a column with '-' to sort descending")+"``")
+	cmd.Flags().StringArrayVar(&c.flagFilter, "filter", nil, i18n.G("Filter by 'key{op}value', op is one of =, !=, =~, !~, >, >=, <, <=, in")+"``")
+	cmd.Flags().IntVar(&c.flagParallel, "parallel", 0, i18n.G("Maximum number of concurrent requests to make to fetch state and snapshots, 0 uses INCUS_PARALLEL or a sane default")+"``")
+	cmd.Flags().StringVar(&c.flagWatch, "watch", "", i18n.G("Keep running and refresh the list, either on the given interval (e.g. --watch=5s) or on server events (bare --watch)")+"``")
+	cmd.Flags().Lookup("watch").NoOptDefVal = "event"
+	cmd.Flags().BoolVar(&c.flagTotals, "totals", false, i18n.G("Add a totals row summing every numeric column"))
+	cmd.Flags().StringVar(&c.flagFilterFile, "filter-file", "", i18n.G("Only show instances with an address inside a CIDR listed in FILE")+"``")
+	cmd.Flags().StringVar(&c.flagExcludeFile, "exclude-file", "", i18n.G("Hide instances with an address inside a CIDR listed in FILE")+"``")
 
 	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
-		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+		format := cmd.Flag("format").Value.String()
+		if format == "template" || strings.HasPrefix(format, "{{") {
+			return nil
+		}
+
+		return cli.ValidateFlagFormatForListOutput(format)
 	}
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -158,30 +244,18 @@ const (
 	defaultColumnsAllProjects = "ens46tSL"
 	configColumnType          = "config"
 	deviceColumnType          = "devices"
+	stateColumnType           = "state"
+	funcColumnType            = "func"
 )
 
-func (c *cmdList) shouldShow(filters []string, inst *api.Instance, state *api.InstanceState) bool {
-	c.mapShorthandFilters()
-
-	for _, filter := range filters {
-		membs := strings.SplitN(filter, "=", 2)
-
-		key := membs[0]
-		var value string
-		if len(membs) < 2 {
-			value = ""
-		} else {
-			value = membs[1]
-		}
-
-		if c.evaluateShorthandFilter(key, value, inst, state) {
-			continue
-		}
-
-		return false
+func (c *cmdList) shouldShow(expr filterExprNode, inst *api.Instance, state *api.InstanceState) bool {
+	if expr == nil {
+		return true
 	}
 
-	return true
+	c.mapShorthandFilters()
+
+	return expr.eval(c, inst, state)
 }
 
 func (c *cmdList) evaluateShorthandFilter(key string, value string, inst *api.Instance, state *api.InstanceState) bool {
@@ -206,153 +280,630 @@ func (c *cmdList) evaluateShorthandFilter(key string, value string, inst *api.In
 	return matched
 }
 
-func (c *cmdList) listInstances(d incus.InstanceServer, instances []api.Instance, filters []string, columns []column) error {
-	threads := min(len(instances), 10)
+// genericFilterFieldValue resolves the raw string value of a non-shorthand filter key against
+// an instance, the same way prepareInstanceServerFilters decides what to send the server.
+func (c *cmdList) genericFilterFieldValue(inst *api.Instance, key string) string {
+	switch strings.ToLower(key) {
+	case "name":
+		return inst.Name
+	case "state", "status":
+		return inst.Status
+	}
 
-	// Shortcut when needing state and snapshot info.
-	hasSnapshots := false
-	hasState := false
-	for _, column := range columns {
-		if column.NeedsSnapshots {
-			hasSnapshots = true
+	raw, ok := inst.Config[key]
+	if !ok {
+		raw = inst.ExpandedConfig[key]
+	}
+
+	return raw
+}
+
+// genericFilterKeyMatch evaluates a non-shorthand "key<op>value" filter leaf against the
+// instance's name, status or config, mirroring the translations prepareInstanceServerFilters
+// sends to the server. Used when a filter expression mixes in a leaf the server can't
+// evaluate (e.g. "iface=") and the whole expression must fall back to client-side evaluation.
+// op is one of "=", "!=", "~=" (regex), "^=" (prefix) or "*=" (contains).
+func (c *cmdList) genericFilterKeyMatch(inst *api.Instance, key string, op string, value string) bool {
+	raw := c.genericFilterFieldValue(inst, key)
+
+	switch op {
+	case "!=":
+		return !c.genericFilterKeyMatch(inst, key, "=", value)
+	case "~=":
+		matched, _ := regexp.MatchString(value, raw)
+		return matched
+	case "^=":
+		return strings.HasPrefix(raw, value)
+	case "*=":
+		return strings.Contains(raw, value)
+	}
+
+	if strings.EqualFold(key, "name") {
+		matched, _ := regexp.MatchString(value, raw)
+		return matched || strings.HasPrefix(raw, value)
+	}
+
+	if strings.EqualFold(raw, value) {
+		return true
+	}
+
+	matched, _ := regexp.MatchString("^"+regexp.QuoteMeta(value)+"$", raw)
+
+	return matched
+}
+
+// filterExprNode is a node of the boolean expression parsed from positional "key=value" filter
+// arguments, letting "and", "or", "not" and parentheses combine shorthand and generic filters.
+// This replaces the old model where every positional argument was implicitly ANDed together,
+// with no way to express a disjunction.
+type filterExprNode interface {
+	// eval reports whether inst/state satisfy this node.
+	eval(c *cmdList, inst *api.Instance, state *api.InstanceState) bool
+
+	// serverExpr returns the server filter-language translation of this node, and whether it
+	// (and everything under it) could be translated; ok is false if any leaf needs the client,
+	// e.g. "iface=" or a network-address leaf when the remote lacks the extension for it.
+	serverExpr(networkKeysSupported bool) (string, bool)
+}
+
+// filterLeafNode is a single "key<op>value" filter, or a bare word matched against the name.
+// op is "=" (the zero value), "!=", "~=" (regex), "^=" (prefix) or "*=" (contains).
+type filterLeafNode struct {
+	raw   string
+	key   string
+	op    string
+	value string
+	bare  bool
+}
+
+func (n filterLeafNode) eval(c *cmdList, inst *api.Instance, state *api.InstanceState) bool {
+	if n.bare {
+		return c.genericFilterKeyMatch(inst, "name", "=", n.value)
+	}
+
+	if _, ok := c.shorthandFilters[strings.ToLower(n.key)]; ok && (n.op == "" || n.op == "=" || n.op == "!=") {
+		matched := c.evaluateShorthandFilter(n.key, n.value, inst, state)
+		if n.op == "!=" {
+			return !matched
 		}
 
-		if column.NeedsState {
-			hasState = true
+		return matched
+	}
+
+	return c.genericFilterKeyMatch(inst, n.key, n.op, n.value)
+}
+
+func (n filterLeafNode) serverExpr(networkKeysSupported bool) (string, bool) {
+	lowerKey := strings.ToLower(n.key)
+
+	if _, isNetworkKey := networkFilterServerKeys[lowerKey]; isNetworkKey {
+		if !networkKeysSupported || (n.op != "" && n.op != "=") {
+			return "", false
 		}
 	}
 
-	if hasSnapshots && hasState {
-		cInfo := []api.InstanceFull{}
-		cInfoLock := sync.Mutex{}
-		cInfoQueue := make(chan string, threads)
-		cInfoWg := sync.WaitGroup{}
-
-		for range threads {
-			cInfoWg.Add(1)
-			go func() {
-				for {
-					cName, more := <-cInfoQueue
-					if !more {
-						break
-					}
+	if lowerKey == "iface" {
+		return "", false
+	}
 
-					state, _, err := d.GetInstanceFull(cName)
-					if err != nil {
-						continue
-					}
+	return prepareInstanceServerFilters([]string{n.raw}, api.InstanceFull{})[0], true
+}
 
-					cInfoLock.Lock()
-					cInfo = append(cInfo, *state)
-					cInfoLock.Unlock()
-				}
+// filterNotNode negates the result of node.
+type filterNotNode struct {
+	node filterExprNode
+}
+
+func (n filterNotNode) eval(c *cmdList, inst *api.Instance, state *api.InstanceState) bool {
+	return !n.node.eval(c, inst, state)
+}
+
+func (n filterNotNode) serverExpr(networkKeysSupported bool) (string, bool) {
+	inner, ok := n.node.serverExpr(networkKeysSupported)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("not (%s)", inner), true
+}
+
+// filterBinNode is a binary "and"/"or" combination of two filter expressions.
+type filterBinNode struct {
+	op    string
+	left  filterExprNode
+	right filterExprNode
+}
+
+func (n filterBinNode) eval(c *cmdList, inst *api.Instance, state *api.InstanceState) bool {
+	left := n.left.eval(c, inst, state)
+	if n.op == "or" {
+		return left || n.right.eval(c, inst, state)
+	}
+
+	return left && n.right.eval(c, inst, state)
+}
 
-				cInfoWg.Done()
-			}()
+func (n filterBinNode) serverExpr(networkKeysSupported bool) (string, bool) {
+	left, ok := n.left.serverExpr(networkKeysSupported)
+	if !ok {
+		return "", false
+	}
+
+	right, ok := n.right.serverExpr(networkKeysSupported)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("(%s %s %s)", left, n.op, right), true
+}
+
+// tokenizeFilterArgs splits positional filter arguments into parser tokens, peeling any
+// leading/trailing "(" and ")" off of each word so an argument like "(state=running" parses
+// as the two tokens "(" and "state=running".
+func tokenizeFilterArgs(args []string) []string {
+	tokens := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		for arg != "" {
+			if arg[0] == '(' || arg[0] == ')' {
+				tokens = append(tokens, arg[:1])
+				arg = arg[1:]
+				continue
+			}
+
+			end := len(arg)
+			if idx := strings.IndexAny(arg, "()"); idx >= 0 {
+				end = idx
+			}
+
+			tokens = append(tokens, arg[:end])
+			arg = arg[end:]
 		}
+	}
 
-		for _, info := range instances {
-			cInfoQueue <- info.Name
+	return tokens
+}
+
+// filterExprParser is a recursive-descent parser over filter tokens, implementing (in
+// increasing precedence) or, and, not, and parenthesized grouping around "key=value" leaves.
+type filterExprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterExprParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *filterExprParser) next() string {
+	tok := p.peek()
+	p.pos++
+
+	return tok
+}
+
+func (p *filterExprParser) parseOr() (filterExprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
 		}
 
-		close(cInfoQueue)
-		cInfoWg.Wait()
+		left = filterBinNode{op: "or", left: left, right: right}
+	}
 
-		return c.showInstances(cInfo, filters, columns)
+	return left, nil
+}
+
+func (p *filterExprParser) parseAnd() (filterExprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
 	}
 
-	cStates := map[string]*api.InstanceState{}
-	cStatesLock := sync.Mutex{}
-	cStatesQueue := make(chan string, threads)
-	cStatesWg := sync.WaitGroup{}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
 
-	cSnapshots := map[string][]api.InstanceSnapshot{}
-	cSnapshotsLock := sync.Mutex{}
-	cSnapshotsQueue := make(chan string, threads)
-	cSnapshotsWg := sync.WaitGroup{}
-
-	for range threads {
-		cStatesWg.Add(1)
-		go func() {
-			for {
-				cName, more := <-cStatesQueue
-				if !more {
-					break
-				}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
 
-				state, _, err := d.GetInstanceState(cName)
-				if err != nil {
-					continue
-				}
+		left = filterBinNode{op: "and", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterExprParser) parseNot() (filterExprNode, error) {
+	if strings.EqualFold(p.peek(), "not") {
+		p.next()
+
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return filterNotNode{node: node}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *filterExprParser) parsePrimary() (filterExprNode, error) {
+	tok := p.peek()
+
+	if tok == "(" {
+		p.next()
+
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next() != ")" {
+			return nil, errors.New(i18n.G("Unbalanced parentheses in filter expression"))
+		}
+
+		return node, nil
+	}
+
+	if tok == "" || tok == ")" || strings.EqualFold(tok, "and") || strings.EqualFold(tok, "or") {
+		return nil, fmt.Errorf(i18n.G("Unexpected token %q in filter expression"), tok)
+	}
+
+	p.next()
+
+	key, op, value, ok := splitFilterKeyValue(tok)
+	if !ok {
+		return filterLeafNode{raw: tok, value: tok, bare: true}, nil
+	}
+
+	return filterLeafNode{raw: tok, key: key, op: op, value: value}, nil
+}
+
+// filterLeafOpPattern matches any operator a "key<op>value" filter argument may use: "=" (equal),
+// "!=" (not equal), "~=" (regex match), "^=" (prefix) or "*=" (contains). Alternatives that share
+// a trailing "=" are listed before the bare "=" so e.g. "!=" isn't mistaken for just "=".
+var filterLeafOpPattern = regexp.MustCompile(`~=|!=|\^=|\*=|=`)
+
+// splitFilterKeyValue splits a single "key<op>value" filter argument into its key, operator and
+// value. ok is false for a bare word with no operator at all (matched against the name instead).
+func splitFilterKeyValue(filter string) (key string, op string, value string, ok bool) {
+	loc := filterLeafOpPattern.FindStringIndex(filter)
+	if loc == nil {
+		return "", "", "", false
+	}
+
+	return filter[:loc[0]], filter[loc[0]:loc[1]], filter[loc[1]:], true
+}
+
+// parseFilterExprArgs tokenizes and parses cmdList's "[<filter>...]" positional arguments into
+// a single filterExprNode, or returns a nil node (matching everything) when there are none.
+func parseFilterExprArgs(filters []string) (filterExprNode, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	parser := &filterExprParser{tokens: tokenizeFilterArgs(filters)}
+
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos != len(parser.tokens) {
+		return nil, fmt.Errorf(i18n.G("Unexpected token %q in filter expression"), parser.peek())
+	}
+
+	return expr, nil
+}
+
+// filterOp is a comparison operator usable in a --filter expression.
+type filterOp string
+
+const (
+	filterOpEqual        filterOp = "="
+	filterOpNotEqual     filterOp = "!="
+	filterOpRegexMatch   filterOp = "=~"
+	filterOpRegexNoMatch filterOp = "!~"
+	filterOpGreater      filterOp = ">"
+	filterOpGreaterEqual filterOp = ">="
+	filterOpLess         filterOp = "<"
+	filterOpLessEqual    filterOp = "<="
+	filterOpIn           filterOp = "in"
+)
+
+// filterSizeKeys are the fields compared as byte sizes rather than plain numbers.
+var filterSizeKeys = map[string]bool{
+	"memory": true,
+	"disk":   true,
+}
+
+// filterPredicate is a single parsed "key{op}value" entry from --filter.
+type filterPredicate struct {
+	key    string
+	op     filterOp
+	value  string
+	values []string
+}
+
+// filterOpPattern matches any of the supported operators, longest first so that e.g. ">="
+// isn't mistaken for ">" followed by "=".
+var filterOpPattern = regexp.MustCompile(`!~|=~|!=|>=|<=|=|>|<| in `)
+
+// parseFilterExpr parses a single --filter value into a filterPredicate. Values may be
+// quoted with single or double quotes to allow embedded "=" or "," characters.
+func parseFilterExpr(expr string) (filterPredicate, error) {
+	loc := filterOpPattern.FindStringIndex(expr)
+	if loc == nil {
+		return filterPredicate{}, fmt.Errorf(i18n.G("Invalid filter %q: missing operator"), expr)
+	}
 
-				cStatesLock.Lock()
-				cStates[cName] = state
-				cStatesLock.Unlock()
+	key := strings.TrimSpace(expr[:loc[0]])
+	opStr := strings.TrimSpace(expr[loc[0]:loc[1]])
+	value := strings.TrimSpace(expr[loc[1]:])
+
+	if key == "" {
+		return filterPredicate{}, fmt.Errorf(i18n.G("Invalid filter %q: missing key"), expr)
+	}
+
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+
+	pred := filterPredicate{key: key, op: filterOp(opStr), value: value}
+	if pred.op == filterOpIn {
+		pred.values = strings.Split(value, ",")
+	}
+
+	return pred, nil
+}
+
+// filterFieldValue returns the rendered field used by shorthand columns for the given key, so
+// that --filter can compare against the same data the -c shorthand letters expose.
+func (c *cmdList) filterFieldValue(key string, inst api.InstanceFull) (string, bool) {
+	fields := map[string]columnData{
+		"name":         c.nameColumnData,
+		"status":       c.statusColumnData,
+		"state":        c.statusColumnData,
+		"type":         c.typeColumnData,
+		"architecture": c.architectureColumnData,
+		"location":     c.locationColumnData,
+		"description":  c.descriptionColumnData,
+		"memory":       c.memoryUsageColumnData,
+		"disk":         c.diskUsageColumnData,
+		"snapshots":    c.numberSnapshotsColumnData,
+		"project":      c.projectColumnData,
+	}
+
+	f, ok := fields[strings.ToLower(key)]
+	if !ok {
+		return "", false
+	}
+
+	return f(inst), true
+}
+
+// resolveFilterValue resolves the string value for key against an instance, supporting the
+// shorthand columns above as well as "config:..." and "devices:..." keys.
+func (c *cmdList) resolveFilterValue(key string, inst api.InstanceFull) (string, error) {
+	if strings.HasPrefix(key, configColumnType+":") {
+		k := strings.TrimPrefix(key, configColumnType+":")
+
+		v, ok := inst.Config[k]
+		if !ok {
+			v = inst.ExpandedConfig[k]
+		}
+
+		return v, nil
+	}
+
+	if strings.HasPrefix(key, deviceColumnType+":") {
+		k := strings.TrimPrefix(key, deviceColumnType+":")
+		d := strings.SplitN(k, ".", 2)
+		if len(d) != 2 {
+			return "", fmt.Errorf(i18n.G("Invalid devices filter key %q"), key)
+		}
+
+		v, ok := inst.Devices[d[0]][d[1]]
+		if !ok {
+			v = inst.ExpandedDevices[d[0]][d[1]]
+		}
+
+		return v, nil
+	}
+
+	v, ok := c.filterFieldValue(key, inst)
+	if !ok {
+		return "", fmt.Errorf(i18n.G("Unknown filter key %q"), key)
+	}
+
+	return v, nil
+}
+
+// filterPredicateMatches reports whether inst satisfies the predicate.
+func (c *cmdList) filterPredicateMatches(pred filterPredicate, inst api.InstanceFull) (bool, error) {
+	raw, err := c.resolveFilterValue(pred.key, inst)
+	if err != nil {
+		return false, err
+	}
+
+	switch pred.op {
+	case filterOpEqual:
+		return strings.EqualFold(raw, pred.value), nil
+	case filterOpNotEqual:
+		return !strings.EqualFold(raw, pred.value), nil
+	case filterOpRegexMatch:
+		return regexp.MatchString(pred.value, raw)
+	case filterOpRegexNoMatch:
+		matched, err := regexp.MatchString(pred.value, raw)
+		return !matched, err
+	case filterOpIn:
+		for _, v := range pred.values {
+			if strings.EqualFold(raw, strings.TrimSpace(v)) {
+				return true, nil
 			}
+		}
 
-			cStatesWg.Done()
-		}()
+		return false, nil
+	case filterOpGreater, filterOpGreaterEqual, filterOpLess, filterOpLessEqual:
+		rawNum, err := c.parseFilterNumber(pred.key, raw)
+		if err != nil {
+			return false, nil
+		}
 
-		cSnapshotsWg.Add(1)
-		go func() {
-			for {
-				cName, more := <-cSnapshotsQueue
-				if !more {
-					break
-				}
+		valNum, err := c.parseFilterNumber(pred.key, pred.value)
+		if err != nil {
+			return false, err
+		}
 
-				snaps, err := d.GetInstanceSnapshots(cName)
-				if err != nil {
-					continue
-				}
+		switch pred.op {
+		case filterOpGreater:
+			return rawNum > valNum, nil
+		case filterOpGreaterEqual:
+			return rawNum >= valNum, nil
+		case filterOpLess:
+			return rawNum < valNum, nil
+		case filterOpLessEqual:
+			return rawNum <= valNum, nil
+		}
+	}
+
+	return false, fmt.Errorf(i18n.G("Unsupported filter operator %q"), pred.op)
+}
+
+// parseFilterNumber parses a numeric comparison operand, using byte-size parsing for
+// memory/disk keys and plain floats otherwise.
+func (c *cmdList) parseFilterNumber(key string, raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, errors.New(i18n.G("Empty value"))
+	}
+
+	if filterSizeKeys[strings.ToLower(key)] {
+		size, err := units.ParseByteSizeString(raw)
+		if err != nil {
+			return 0, err
+		}
+
+		return float64(size), nil
+	}
 
-				cSnapshotsLock.Lock()
-				cSnapshots[cName] = snaps
-				cSnapshotsLock.Unlock()
+	return strconv.ParseFloat(raw, 64)
+}
+
+func (c *cmdList) listInstances(d incus.InstanceServer, instances []api.Instance, expr filterExprNode, columns []column) error {
+	ctx, cancel := parallel.WithInterrupt(context.Background())
+	defer cancel()
+
+	concurrency := c.parallelism()
+
+	// Shortcut when needing state and snapshot info.
+	hasSnapshots := c.templateText() != ""
+	hasState := c.templateText() != ""
+	for _, column := range columns {
+		if column.NeedsSnapshots {
+			hasSnapshots = true
+		}
+
+		if column.NeedsState {
+			hasState = true
+		}
+	}
+
+	if hasSnapshots && hasState {
+		results, errs := parallel.Enqueue(ctx, instances, concurrency, func(_ context.Context, inst api.Instance) (*api.InstanceFull, error) {
+			full, _, err := d.GetInstanceFull(inst.Name)
+			return full, err
+		})
+
+		cInfo := make([]api.InstanceFull, 0, len(results))
+		for i, full := range results {
+			if errs[i] != nil || full == nil {
+				continue
 			}
 
-			cSnapshotsWg.Done()
-		}()
+			cInfo = append(cInfo, *full)
+		}
+
+		return c.showInstances(cInfo, expr, columns)
+	}
+
+	wantsState := false
+	wantsSnapshots := false
+	for _, column := range columns {
+		wantsState = wantsState || column.NeedsState
+		wantsSnapshots = wantsSnapshots || column.NeedsSnapshots
 	}
 
+	needsState := make([]api.Instance, 0, len(instances))
+	needsSnapshots := make([]api.Instance, 0, len(instances))
 	for _, inst := range instances {
-		for _, column := range columns {
-			if column.NeedsState && inst.IsActive() {
-				cStatesLock.Lock()
-				_, ok := cStates[inst.Name]
-				cStatesLock.Unlock()
-				if ok {
-					continue
-				}
+		if wantsState && inst.IsActive() {
+			needsState = append(needsState, inst)
+		}
 
-				cStatesLock.Lock()
-				cStates[inst.Name] = nil
-				cStatesLock.Unlock()
+		if wantsSnapshots {
+			needsSnapshots = append(needsSnapshots, inst)
+		}
+	}
 
-				cStatesQueue <- inst.Name
-			}
+	var stateResults []*api.InstanceState
+	var stateErrs []error
+	var snapshotResults [][]api.InstanceSnapshot
+	var snapshotErrs []error
 
-			if column.NeedsSnapshots {
-				cSnapshotsLock.Lock()
-				_, ok := cSnapshots[inst.Name]
-				cSnapshotsLock.Unlock()
-				if ok {
-					continue
-				}
+	var wg sync.WaitGroup
+	wg.Add(2)
 
-				cSnapshotsLock.Lock()
-				cSnapshots[inst.Name] = nil
-				cSnapshotsLock.Unlock()
+	go func() {
+		defer wg.Done()
 
-				cSnapshotsQueue <- inst.Name
-			}
+		stateResults, stateErrs = parallel.Enqueue(ctx, needsState, concurrency, func(_ context.Context, inst api.Instance) (*api.InstanceState, error) {
+			state, _, err := d.GetInstanceState(inst.Name)
+			return state, err
+		})
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		snapshotResults, snapshotErrs = parallel.Enqueue(ctx, needsSnapshots, concurrency, func(_ context.Context, inst api.Instance) ([]api.InstanceSnapshot, error) {
+			return d.GetInstanceSnapshots(inst.Name)
+		})
+	}()
+
+	wg.Wait()
+
+	cStates := map[string]*api.InstanceState{}
+	for i, inst := range needsState {
+		if stateErrs[i] != nil {
+			continue
 		}
+
+		cStates[inst.Name] = stateResults[i]
 	}
 
-	close(cStatesQueue)
-	close(cSnapshotsQueue)
-	cStatesWg.Wait()
-	cSnapshotsWg.Wait()
+	cSnapshots := map[string][]api.InstanceSnapshot{}
+	for i, inst := range needsSnapshots {
+		if snapshotErrs[i] != nil {
+			continue
+		}
+
+		cSnapshots[inst.Name] = snapshotResults[i]
+	}
 
 	// Convert to Instance
 	data := make([]api.InstanceFull, len(instances))
@@ -362,21 +913,47 @@ func (c *cmdList) listInstances(d incus.InstanceServer, instances []api.Instance
 		data[i].Snapshots = cSnapshots[instances[i].Name]
 	}
 
-	return c.showInstances(data, filters, columns)
+	return c.showInstances(data, expr, columns)
 }
 
-func (c *cmdList) showInstances(instances []api.InstanceFull, filters []string, columns []column) error {
-	// Generate the table data
-	data := [][]string{}
+// parallelism returns the worker-pool size to use for client-side fan-out, honoring
+// --parallel and falling back to INCUS_PARALLEL then parallel.DefaultConcurrency.
+func (c *cmdList) parallelism() int {
+	if c.flagParallel > 0 {
+		return c.flagParallel
+	}
+
+	return parallel.ConcurrencyFromEnv("INCUS_PARALLEL", parallel.DefaultConcurrency)
+}
+
+func (c *cmdList) showInstances(instances []api.InstanceFull, expr filterExprNode, columns []column) error {
 	instancesFiltered := []api.InstanceFull{}
 
 	for _, inst := range instances {
-		if !c.shouldShow(filters, &inst.Instance, inst.State) {
+		if !c.shouldShow(expr, &inst.Instance, inst.State) {
+			continue
+		}
+
+		if !c.passesAddressFiles(inst) {
 			continue
 		}
 
 		instancesFiltered = append(instancesFiltered, inst)
+	}
+
+	instancesFiltered, err := c.applyFilterExprs(instancesFiltered)
+	if err != nil {
+		return err
+	}
 
+	tmpl := c.templateText()
+	if tmpl != "" {
+		return c.renderTemplate(tmpl, instancesFiltered)
+	}
+
+	// Generate the table data
+	data := [][]string{}
+	for _, inst := range instancesFiltered {
 		col := []string{}
 		for _, column := range columns {
 			col = append(col, column.Data(inst))
@@ -385,16 +962,298 @@ func (c *cmdList) showInstances(instances []api.InstanceFull, filters []string,
 		data = append(data, col)
 	}
 
-	sort.Sort(cli.SortColumnsNaturally(data))
-
 	headers := []string{}
 	for _, column := range columns {
 		headers = append(headers, column.Name)
 	}
 
+	err = c.sortData(headers, data)
+	if err != nil {
+		return err
+	}
+
+	if c.flagTotals && len(data) > 0 {
+		data = append(data, c.totalsRow(columns, data))
+	}
+
+	if isTableFormat(c.flagFormat) {
+		alignData(columns, data)
+	}
+
 	return cli.RenderTable(os.Stdout, c.flagFormat, headers, data, instancesFiltered)
 }
 
+// isTableFormat reports whether format renders as a fixed-width table, where column
+// alignment hints are meaningful (as opposed to csv/json/yaml, which carry raw values).
+func isTableFormat(format string) bool {
+	base, _, _ := strings.Cut(format, ",")
+
+	switch base {
+	case "table", "compact", "markdown":
+		return true
+	}
+
+	return false
+}
+
+// alignData pads table cells in place to their column's Width according to its Align hint.
+// Left alignment is left untouched since table renderers already left-align by default.
+func alignData(columns []column, data [][]string) {
+	for _, row := range data {
+		for i, column := range columns {
+			if i >= len(row) || column.Width <= 0 {
+				continue
+			}
+
+			switch column.Align {
+			case "r":
+				row[i] = padLeft(row[i], column.Width)
+			case "c":
+				row[i] = padCenter(row[i], column.Width)
+			}
+		}
+	}
+}
+
+func padLeft(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return strings.Repeat(" ", width-len(s)) + s
+}
+
+func padCenter(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	pad := width - len(s)
+	left := pad / 2
+
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", pad-left)
+}
+
+// totalsRow builds the --totals summary row: every column whose values are all byte sizes
+// or plain numbers is summed, everything else (including the first column) is left as a
+// row count label or blank.
+func (c *cmdList) totalsRow(columns []column, data [][]string) []string {
+	row := make([]string, len(columns))
+
+	for i := range columns {
+		if i == 0 {
+			row[i] = fmt.Sprintf(i18n.G("TOTAL (%d)"), len(data))
+			continue
+		}
+
+		sum, isByteSize, ok := sumColumn(data, i)
+		if !ok {
+			continue
+		}
+
+		if isByteSize {
+			row[i] = units.GetByteSizeStringIEC(int64(sum), 2)
+		} else {
+			row[i] = strconv.FormatFloat(sum, 'f', -1, 64)
+		}
+	}
+
+	return row
+}
+
+// sumColumn sums column idx across data, as either byte sizes or plain numbers. ok is false
+// if any non-empty cell in the column is neither, in which case the column isn't summable.
+func sumColumn(data [][]string, idx int) (sum float64, isByteSize bool, ok bool) {
+	any := false
+
+	for _, row := range data {
+		if idx >= len(row) {
+			return 0, false, false
+		}
+
+		v := strings.TrimSpace(row[idx])
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			sum += n
+			any = true
+			continue
+		}
+
+		size, err := units.ParseByteSizeString(v)
+		if err == nil {
+			sum += float64(size)
+			isByteSize = true
+			any = true
+			continue
+		}
+
+		return 0, false, false
+	}
+
+	return sum, isByteSize, any
+}
+
+// applyFilterExprs narrows instances down to those matching every --filter expression.
+func (c *cmdList) applyFilterExprs(instances []api.InstanceFull) ([]api.InstanceFull, error) {
+	if len(c.flagFilter) == 0 {
+		return instances, nil
+	}
+
+	preds := make([]filterPredicate, 0, len(c.flagFilter))
+	for _, expr := range c.flagFilter {
+		pred, err := parseFilterExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		preds = append(preds, pred)
+	}
+
+	filtered := make([]api.InstanceFull, 0, len(instances))
+	for _, inst := range instances {
+		matches := true
+		for _, pred := range preds {
+			ok, err := c.filterPredicateMatches(pred, inst)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			filtered = append(filtered, inst)
+		}
+	}
+
+	return filtered, nil
+}
+
+// sortData orders the rendered table rows by --sort, falling back to the default natural sort.
+func (c *cmdList) sortData(headers []string, data [][]string) error {
+	if c.flagSort == "" {
+		sort.Sort(cli.SortColumnsNaturally(data))
+		return nil
+	}
+
+	type sortKey struct {
+		index      int
+		descending bool
+	}
+
+	keys := make([]sortKey, 0)
+	for _, col := range strings.Split(c.flagSort, ",") {
+		col = strings.TrimSpace(col)
+		descending := strings.HasPrefix(col, "-")
+		col = strings.TrimPrefix(col, "-")
+
+		idx := -1
+		for i, header := range headers {
+			if strings.EqualFold(header, col) {
+				idx = i
+				break
+			}
+		}
+
+		if idx == -1 {
+			return fmt.Errorf(i18n.G("Unknown sort column %q"), col)
+		}
+
+		keys = append(keys, sortKey{index: idx, descending: descending})
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := data[i][key.index], data[j][key.index]
+			if a == b {
+				continue
+			}
+
+			if key.descending {
+				return a > b
+			}
+
+			return a < b
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+// templateText returns the Go template to render with, or "" if --format isn't requesting one.
+// Both "--format template --template '{{...}}'" and the "--format '{{json .}}'" shorthand are supported.
+func (c *cmdList) templateText() string {
+	if strings.HasPrefix(c.flagFormat, "{{") {
+		return c.flagFormat
+	}
+
+	if c.flagFormat == "template" {
+		return c.flagTemplate
+	}
+
+	return ""
+}
+
+// listTemplateFuncs are the helper functions made available to --format=template, on top of the
+// text/template builtins.
+var listTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+
+		return string(data), nil
+	},
+	"table": func(rows [][]string) string {
+		lines := make([]string, 0, len(rows))
+		for _, row := range rows {
+			lines = append(lines, strings.Join(row, "\t"))
+		}
+
+		return strings.Join(lines, "\n")
+	},
+	"truncate": func(n int, s string) string {
+		if n >= 0 && len(s) > n {
+			return s[:n]
+		}
+
+		return s
+	},
+	"upper": strings.ToUpper,
+	"humanSize": func(size int64) string {
+		return units.GetByteSizeStringIEC(size, 2)
+	},
+}
+
+// renderTemplate renders one line per instance by executing tmplText against each api.InstanceFull.
+func (c *cmdList) renderTemplate(tmplText string, instances []api.InstanceFull) error {
+	tmpl, err := template.New("list").Funcs(listTemplateFuncs).Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid template: %w"), err)
+	}
+
+	for _, inst := range instances {
+		err := tmpl.Execute(os.Stdout, inst)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+	}
+
+	return nil
+}
+
 // Run runs the actual command logic.
 func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
@@ -445,19 +1304,64 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get the list of columns
-	columns, needsData, err := c.parseColumns(d.IsClustered())
-	if err != nil {
-		return err
+	// Get the list of columns
+	columns, needsData, err := c.parseColumns(d.IsClustered())
+	if err != nil {
+		return err
+	}
+
+	// A template can reference any instance field, so always fetch state and snapshots for it.
+	if c.templateText() != "" {
+		needsData = true
+	}
+
+	if c.flagFilterFile != "" {
+		c.filterFileTrie, err = loadCIDRFile(c.flagFilterFile)
+		if err != nil {
+			return err
+		}
+
+		needsData = true
+	}
+
+	if c.flagExcludeFile != "" {
+		c.excludeFileTrie, err = loadCIDRFile(c.flagExcludeFile)
+		if err != nil {
+			return err
+		}
+
+		needsData = true
+	}
+
+	if c.flagWatch == "" {
+		return c.fetchAndShow(d, filters, columns, needsData)
+	}
+
+	return c.watch(d, filters, columns, needsData)
+}
+
+// fetchAndShow fetches the current set of instances and renders them once.
+func (c *cmdList) fetchAndShow(d incus.InstanceServer, filters []string, columns []column, needsData bool) error {
+	expr, err := parseFilterExprArgs(filters)
+	if err != nil {
+		return err
+	}
+
+	// If the whole expression can be translated to the server's filter language, send it
+	// there and skip client-side evaluation entirely; otherwise (e.g. it uses "iface=", or a
+	// network-address leaf the remote can't translate) fetch everything and filter client-side.
+	var serverFilters []string
+	clientExpr := expr
+	if expr != nil {
+		if serverExprStr, ok := expr.serverExpr(d.HasExtension("instance_list_filter_network")); ok {
+			serverFilters = []string{serverExprStr}
+			clientExpr = nil
+		}
 	}
 
 	if needsData && d.HasExtension("container_full") {
 		// Using the GetInstancesFull shortcut
 		var instances []api.InstanceFull
-
-		serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6"}, true)
-		serverFilters = prepareInstanceServerFilters(serverFilters, api.InstanceFull{})
-
 		if c.flagAllProjects {
 			instances, err = d.GetInstancesFullAllProjectsWithFilter(api.InstanceTypeAny, serverFilters)
 		} else {
@@ -468,14 +1372,11 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return c.showInstances(instances, clientFilters, columns)
+		return c.showInstances(instances, clientExpr, columns)
 	}
 
 	// Get the list of instances
 	var instances []api.Instance
-	serverFilters, clientFilters := getServerSupportedFilters(filters, []string{"ipv4", "ipv6"}, true)
-	serverFilters = prepareInstanceServerFilters(serverFilters, api.Instance{})
-
 	if c.flagAllProjects {
 		instances, err = d.GetInstancesAllProjectsWithFilter(api.InstanceTypeAny, serverFilters)
 	} else {
@@ -487,33 +1388,85 @@ func (c *cmdList) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Fetch any remaining data and render the table
-	return c.listInstances(d, instances, clientFilters, columns)
+	return c.listInstances(d, instances, clientExpr, columns)
+}
+
+// watch keeps re-rendering the instance list, either on a fixed interval (--watch=<duration>)
+// or whenever the server reports an instance lifecycle/state event (bare --watch).
+func (c *cmdList) watch(d incus.InstanceServer, filters []string, columns []column, needsData bool) error {
+	isTTY := termios.IsTerminal(int(os.Stdout.Fd()))
+
+	redraw := func() error {
+		if isTTY && isTableFormat(c.flagFormat) {
+			// Clear the screen and move the cursor to the top-left corner.
+			fmt.Print("\033[H\033[2J")
+		}
+
+		return c.fetchAndShow(d, filters, columns, needsData)
+	}
+
+	err := redraw()
+	if err != nil {
+		return err
+	}
+
+	if interval, parseErr := time.ParseDuration(c.flagWatch); parseErr == nil {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			err := redraw()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	handler := func(_ api.Event) {
+		_ = redraw()
+	}
+
+	listener, err := d.GetEvents()
+	if err != nil {
+		return err
+	}
+
+	_, err = listener.AddHandler([]string{"lifecycle", "operation"}, handler)
+	if err != nil {
+		return err
+	}
+
+	defer listener.Disconnect()
+
+	return listener.Wait()
 }
 
 func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 	columnsShorthandMap := map[rune]column{
-		'4': {i18n.G("IPV4"), c.ip4ColumnData, true, false},
-		'6': {i18n.G("IPV6"), c.ip6ColumnData, true, false},
-		'a': {i18n.G("ARCHITECTURE"), c.architectureColumnData, false, false},
-		'b': {i18n.G("STORAGE POOL"), c.storagePoolColumnData, false, false},
-		'c': {i18n.G("CREATED AT"), c.createdColumnData, false, false},
-		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData, false, false},
-		'D': {i18n.G("DISK USAGE"), c.diskUsageColumnData, true, false},
-		'e': {i18n.G("PROJECT"), c.projectColumnData, false, false},
-		'f': {i18n.G("BASE IMAGE"), c.baseImageColumnData, false, false},
-		'F': {i18n.G("BASE IMAGE"), c.baseImageFullColumnData, false, false},
-		'l': {i18n.G("LAST USED AT"), c.lastUsedColumnData, false, false},
-		'm': {i18n.G("MEMORY USAGE"), c.memoryUsageColumnData, true, false},
-		'M': {i18n.G("MEMORY USAGE%"), c.memoryUsagePercentColumnData, true, false},
-		'n': {i18n.G("NAME"), c.nameColumnData, false, false},
-		'N': {i18n.G("PROCESSES"), c.numberOfProcessesColumnData, true, false},
-		'p': {i18n.G("PID"), c.pidColumnData, true, false},
-		'P': {i18n.G("PROFILES"), c.profilesColumnData, false, false},
-		'S': {i18n.G("SNAPSHOTS"), c.numberSnapshotsColumnData, false, true},
-		's': {i18n.G("STATE"), c.statusColumnData, false, false},
-		't': {i18n.G("TYPE"), c.typeColumnData, false, false},
-		'u': {i18n.G("CPU USAGE"), c.cpuUsageSecondsColumnData, true, false},
-		'U': {i18n.G("STARTED AT"), c.startedColumnData, true, false},
+		'4': {i18n.G("IPV4"), c.ip4ColumnData, true, false, "l", 0},
+		'6': {i18n.G("IPV6"), c.ip6ColumnData, true, false, "l", 0},
+		'a': {i18n.G("ARCHITECTURE"), c.architectureColumnData, false, false, "l", 0},
+		'b': {i18n.G("STORAGE POOL"), c.storagePoolColumnData, false, false, "l", 0},
+		'c': {i18n.G("CREATED AT"), c.createdColumnData, false, false, "l", 0},
+		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData, false, false, "l", 0},
+		'D': {i18n.G("DISK USAGE"), c.diskUsageColumnData, true, false, "l", 0},
+		'e': {i18n.G("PROJECT"), c.projectColumnData, false, false, "l", 0},
+		'f': {i18n.G("BASE IMAGE"), c.baseImageColumnData, false, false, "l", 0},
+		'F': {i18n.G("BASE IMAGE"), c.baseImageFullColumnData, false, false, "l", 0},
+		'l': {i18n.G("LAST USED AT"), c.lastUsedColumnData, false, false, "l", 0},
+		'm': {i18n.G("MEMORY USAGE"), c.memoryUsageColumnData, true, false, "l", 0},
+		'M': {i18n.G("MEMORY USAGE%"), c.memoryUsagePercentColumnData, true, false, "l", 0},
+		'n': {i18n.G("NAME"), c.nameColumnData, false, false, "l", 0},
+		'N': {i18n.G("PROCESSES"), c.numberOfProcessesColumnData, true, false, "l", 0},
+		'p': {i18n.G("PID"), c.pidColumnData, true, false, "l", 0},
+		'P': {i18n.G("PROFILES"), c.profilesColumnData, false, false, "l", 0},
+		'S': {i18n.G("SNAPSHOTS"), c.numberSnapshotsColumnData, false, true, "l", 0},
+		's': {i18n.G("STATE"), c.statusColumnData, false, false, "l", 0},
+		't': {i18n.G("TYPE"), c.typeColumnData, false, false, "l", 0},
+		'u': {i18n.G("CPU USAGE"), c.cpuUsageSecondsColumnData, true, false, "l", 0},
+		'U': {i18n.G("STARTED AT"), c.startedColumnData, true, false, "l", 0},
 	}
 
 	// Add project column if --all-projects flag specified and
@@ -539,7 +1492,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 
 	if clustered {
 		columnsShorthandMap['L'] = column{
-			i18n.G("LOCATION"), c.locationColumnData, false, false,
+			i18n.G("LOCATION"), c.locationColumnData, false, false, "l", 0,
 		}
 	}
 
@@ -579,12 +1532,12 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		} else {
 			cc := strings.Split(columnEntry, ":")
 			colType := configColumnType
-			if (cc[0] == configColumnType || cc[0] == deviceColumnType) && len(cc) > 1 {
+			if (cc[0] == configColumnType || cc[0] == deviceColumnType || cc[0] == stateColumnType || cc[0] == funcColumnType) && len(cc) > 1 {
 				colType = cc[0]
 				cc = slices.Delete(cc, 0, 1)
 			}
 
-			if len(cc) > 3 {
+			if len(cc) > 4 {
 				return nil, false, fmt.Errorf(i18n.G("Invalid config key column format (too many fields): '%s'"), columnEntry)
 			}
 
@@ -596,9 +1549,18 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 				}
 			}
 
-			column := column{Name: k}
+			var funcHandler columnData
+			if colType == funcColumnType {
+				var ok bool
+				funcHandler, ok = c.funcColumnHandlers()[strings.ToLower(k)]
+				if !ok {
+					return nil, false, fmt.Errorf(i18n.G("Unknown column function '%s' in '%s'"), k, columnEntry)
+				}
+			}
+
+			column := column{Name: k, Align: "l"}
 			if len(cc) > 1 {
-				if len(cc[1]) == 0 && len(cc) != 3 {
+				if len(cc[1]) == 0 && len(cc) < 3 {
 					return nil, false, fmt.Errorf(i18n.G("Invalid name in '%s', empty string is only allowed when defining maxWidth"), columnEntry)
 				}
 
@@ -622,6 +1584,66 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 					maxWidth = int(temp)
 				}
 			}
+
+			format := ""
+			if len(cc) > 3 {
+				alignSpec := cc[3]
+				align := alignSpec
+				if idx := strings.Index(alignSpec, "%"); idx >= 0 {
+					align = alignSpec[:idx]
+					format = alignSpec[idx:]
+				}
+
+				if align == "" {
+					align = "l"
+				}
+
+				if align != "l" && align != "r" && align != "c" {
+					return nil, false, fmt.Errorf(i18n.G("Invalid column alignment '%s' in '%s'"), align, columnEntry)
+				}
+
+				column.Align = align
+				if maxWidth > 0 {
+					column.Width = maxWidth
+				} else {
+					column.Width = len(column.Name)
+				}
+			}
+
+			if colType == stateColumnType {
+				column.Data = func(cInfo api.InstanceFull) string {
+					if cInfo.State == nil {
+						return ""
+					}
+
+					v, err := resolveStatePath(cInfo.State, k)
+					if err != nil {
+						return ""
+					}
+
+					if maxWidth > 0 && len(v) > maxWidth {
+						return v[:maxWidth]
+					}
+
+					return v
+				}
+
+				column.NeedsState = true
+			}
+
+			if colType == funcColumnType {
+				column.Data = func(cInfo api.InstanceFull) string {
+					v := funcHandler(cInfo)
+					if maxWidth > 0 && len(v) > maxWidth {
+						return v[:maxWidth]
+					}
+
+					return v
+				}
+
+				column.NeedsState = true
+			}
+
 			if colType == configColumnType {
 				column.Data = func(cInfo api.InstanceFull) string {
 					v, ok := cInfo.Config[k]
@@ -659,6 +1681,21 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 					return v
 				}
 			}
+
+			if format != "" {
+				inner := column.Data
+				column.Data = func(cInfo api.InstanceFull) string {
+					v := inner(cInfo)
+
+					f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+					if err != nil {
+						return v
+					}
+
+					return fmt.Sprintf(format, f)
+				}
+			}
+
 			columns = append(columns, column)
 
 			if column.NeedsState || column.NeedsSnapshots {
@@ -872,6 +1909,114 @@ func (c *cmdList) startedColumnData(cInfo api.InstanceFull) string {
 	return ""
 }
 
+// uptimeColumnData is the "func:uptime" column: how long the instance has been running.
+func (c *cmdList) uptimeColumnData(cInfo api.InstanceFull) string {
+	if cInfo.IsActive() && cInfo.State != nil && !cInfo.State.StartedAt.IsZero() {
+		return time.Since(cInfo.State.StartedAt).Round(time.Second).String()
+	}
+
+	return ""
+}
+
+// firstIPv4ColumnData is the "func:firstipv4" column: the first address reported by the "4" column.
+func (c *cmdList) firstIPv4ColumnData(cInfo api.InstanceFull) string {
+	all := c.ip4ColumnData(cInfo)
+	if all == "" {
+		return ""
+	}
+
+	return strings.SplitN(all, "\n", 2)[0]
+}
+
+// funcColumnHandlers returns the built-in "func:" column transforms, keyed by lowercase name.
+func (c *cmdList) funcColumnHandlers() map[string]columnData {
+	return map[string]columnData{
+		"uptime":    c.uptimeColumnData,
+		"memorypct": c.memoryUsagePercentColumnData,
+		"firstipv4": c.firstIPv4ColumnData,
+	}
+}
+
+// resolveStatePath resolves a dotted "state:" column path against an instance's runtime
+// state, walking structs by (case-insensitive) field name and maps by key. As a special
+// case, "network.<iface>.addresses.<family>" returns the comma-separated addresses of the
+// given family on that interface, since api.InstanceStateNetwork.Addresses is a slice.
+func resolveStatePath(state *api.InstanceState, path string) (string, error) {
+	parts := strings.Split(path, ".")
+
+	if len(parts) == 4 && parts[0] == "network" && parts[2] == "addresses" {
+		iface := parts[1]
+		family := parts[3]
+
+		network, ok := state.Network[iface]
+		if !ok {
+			return "", fmt.Errorf(i18n.G("Unknown network interface %q"), iface)
+		}
+
+		addresses := []string{}
+		for _, addr := range network.Addresses {
+			if addr.Family == family {
+				addresses = append(addresses, addr.Address)
+			}
+		}
+
+		return strings.Join(addresses, ","), nil
+	}
+
+	current := reflect.ValueOf(*state)
+	for _, part := range parts {
+		for current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				return "", nil
+			}
+
+			current = current.Elem()
+		}
+
+		switch current.Kind() {
+		case reflect.Struct:
+			field := stateFieldByName(current, part)
+			if !field.IsValid() {
+				return "", fmt.Errorf(i18n.G("Unknown state field %q"), part)
+			}
+
+			current = field
+		case reflect.Map:
+			value := current.MapIndex(reflect.ValueOf(part))
+			if !value.IsValid() {
+				return "", fmt.Errorf(i18n.G("Unknown state key %q"), part)
+			}
+
+			current = value
+		default:
+			return "", fmt.Errorf(i18n.G("Cannot resolve %q, %q is not a struct or map"), path, part)
+		}
+	}
+
+	for current.Kind() == reflect.Ptr {
+		if current.IsNil() {
+			return "", nil
+		}
+
+		current = current.Elem()
+	}
+
+	return fmt.Sprintf("%v", current.Interface()), nil
+}
+
+// stateFieldByName returns the exported field of v matching name case-insensitively, or the
+// zero Value if there is no such field.
+func stateFieldByName(v reflect.Value, name string) reflect.Value {
+	t := v.Type()
+	for i := range t.NumField() {
+		if strings.EqualFold(t.Field(i).Name, name) {
+			return v.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
+
 func (c *cmdList) lastUsedColumnData(cInfo api.InstanceFull) string {
 	if !cInfo.LastUsedAt.IsZero() {
 		return cInfo.LastUsedAt.Local().Format(dateLayout)
@@ -892,6 +2037,167 @@ func (c *cmdList) locationColumnData(cInfo api.InstanceFull) string {
 	return cInfo.Location
 }
 
+// cidrTrieNode is a node of a binary trie over IP prefix bits. A leaf node marks a prefix that
+// was inserted (or is covered by a shorter prefix already in the trie), so a longest-prefix
+// "does any inserted CIDR contain this address" lookup is O(address bit length) rather than a
+// linear scan over every inserted CIDR.
+type cidrTrieNode struct {
+	children [2]*cidrTrieNode
+	leaf     bool
+}
+
+// insert adds the first ones bits of bits as a prefix, pruning the walk as soon as it crosses
+// a leaf already covering the new (necessarily longer or equal) prefix.
+func (n *cidrTrieNode) insert(bits []byte, ones int) {
+	cur := n
+
+	for i := range ones {
+		if cur.leaf {
+			return
+		}
+
+		bit := (bits[i/8] >> (7 - uint(i%8))) & 1
+		if cur.children[bit] == nil {
+			cur.children[bit] = &cidrTrieNode{}
+		}
+
+		cur = cur.children[bit]
+	}
+
+	cur.leaf = true
+	cur.children = [2]*cidrTrieNode{}
+}
+
+// contains reports whether any inserted prefix is a prefix of (i.e. contains) bits.
+func (n *cidrTrieNode) contains(bits []byte) bool {
+	cur := n
+
+	for i := range len(bits) * 8 {
+		if cur.leaf {
+			return true
+		}
+
+		bit := (bits[i/8] >> (7 - uint(i%8))) & 1
+
+		cur = cur.children[bit]
+		if cur == nil {
+			return false
+		}
+	}
+
+	return cur.leaf
+}
+
+// cidrTrie holds separate IPv4 and IPv6 prefix tries, since the two address families are
+// different bit widths and shouldn't be compared against each other.
+type cidrTrie struct {
+	v4 cidrTrieNode
+	v6 cidrTrieNode
+}
+
+// insert adds subnet to the trie matching its address family.
+func (t *cidrTrie) insert(subnet *net.IPNet) {
+	if v4 := subnet.IP.To4(); v4 != nil {
+		ones, _ := subnet.Mask.Size()
+		t.v4.insert(v4, ones)
+		return
+	}
+
+	ones, _ := subnet.Mask.Size()
+	t.v6.insert(subnet.IP.To16(), ones)
+}
+
+// contains reports whether ip falls inside any subnet inserted into the trie.
+func (t *cidrTrie) contains(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		return t.v4.contains(v4)
+	}
+
+	v6 := ip.To16()
+	if v6 == nil {
+		return false
+	}
+
+	return t.v6.contains(v6)
+}
+
+// loadCIDRFile reads a newline-delimited list of IPv4/IPv6 CIDRs (comments starting with "#",
+// blank lines ignored) as used by --filter-file/--exclude-file, and builds a cidrTrie from them.
+// A bare IP address (no "/bits") is treated as a single-address CIDR.
+func loadCIDRFile(path string) (*cidrTrie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trie := &cidrTrie{}
+
+	for lineNum, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.Contains(line, "/") {
+			ip := net.ParseIP(line)
+			if ip == nil {
+				return nil, fmt.Errorf(i18n.G("Invalid address %q on line %d of %q"), line, lineNum+1, path)
+			}
+
+			if ip.To4() != nil {
+				line += "/32"
+			} else {
+				line += "/128"
+			}
+		}
+
+		_, subnet, err := net.ParseCIDR(line)
+		if err != nil {
+			return nil, fmt.Errorf(i18n.G("Invalid CIDR %q on line %d of %q: %w"), line, lineNum+1, path, err)
+		}
+
+		trie.insert(subnet)
+	}
+
+	return trie, nil
+}
+
+// passesAddressFiles reports whether cInfo satisfies --filter-file/--exclude-file: included if
+// (no --filter-file, or at least one of its addresses is inside one of its CIDRs) and (no
+// --exclude-file, or none of its addresses is inside one of its CIDRs).
+func (c *cmdList) passesAddressFiles(cInfo api.InstanceFull) bool {
+	if c.filterFileTrie == nil && c.excludeFileTrie == nil {
+		return true
+	}
+
+	matchedFilter := c.filterFileTrie == nil
+
+	if cInfo.State != nil {
+		for _, network := range cInfo.State.Network {
+			for _, addr := range network.Addresses {
+				ip := net.ParseIP(addr.Address)
+				if ip == nil {
+					continue
+				}
+
+				if c.excludeFileTrie != nil && c.excludeFileTrie.contains(ip) {
+					return false
+				}
+
+				if c.filterFileTrie != nil && c.filterFileTrie.contains(ip) {
+					matchedFilter = true
+				}
+			}
+		}
+	}
+
+	return matchedFilter
+}
+
 func (c *cmdList) matchByNet(cState *api.InstanceState, query string, family string) bool {
 	// Skip if no state.
 	if cState == nil {
@@ -933,6 +2239,17 @@ func (c *cmdList) matchByNet(cState *api.InstanceState, query string, family str
 	return false
 }
 
+// networkFilterOp picks the server-side filter operator for a translated network predicate:
+// "in" when the value parses as a CIDR (a range to test membership in), "=" for an exact
+// address or MAC match.
+func networkFilterOp(value string) string {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return " in "
+	}
+
+	return "="
+}
+
 func (c *cmdList) matchByIPV6(_ *api.Instance, cState *api.InstanceState, query string) bool {
 	return c.matchByNet(cState, query, "ipv6")
 }
@@ -941,45 +2258,123 @@ func (c *cmdList) matchByIPV4(_ *api.Instance, cState *api.InstanceState, query
 	return c.matchByNet(cState, query, "ipv4")
 }
 
+// matchByMAC matches the "mac=" shorthand filter against every interface's hardware address,
+// accepting an exact match, a glob (e.g. "aa:bb:cc:*") or a prefix match on the normalized
+// (colon-stripped, lowercased) address, so "aabbcc" matches "aa:bb:cc:dd:ee:ff".
+func (c *cmdList) matchByMAC(_ *api.Instance, cState *api.InstanceState, query string) bool {
+	if cState == nil || cState.Network == nil {
+		return false
+	}
+
+	normalizedQuery := normalizeMAC(query)
+
+	for _, network := range cState.Network {
+		if network.Hwaddr == "" {
+			continue
+		}
+
+		if strings.EqualFold(network.Hwaddr, query) {
+			return true
+		}
+
+		if strings.HasPrefix(normalizeMAC(network.Hwaddr), normalizedQuery) {
+			return true
+		}
+
+		if matched, _ := path.Match(query, network.Hwaddr); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeMAC lowercases a MAC address and strips ":" separators, so "AA:BB" and "aabb"
+// compare equal.
+func normalizeMAC(mac string) string {
+	return strings.ToLower(strings.ReplaceAll(mac, ":", ""))
+}
+
+// matchByIface matches the "iface=" shorthand filter against every host-side interface name,
+// accepting an exact (case-insensitive) match or a glob (e.g. "veth*").
+func (c *cmdList) matchByIface(_ *api.Instance, cState *api.InstanceState, query string) bool {
+	if cState == nil || cState.Network == nil {
+		return false
+	}
+
+	for ifaceName := range cState.Network {
+		if strings.EqualFold(ifaceName, query) {
+			return true
+		}
+
+		if matched, _ := path.Match(query, ifaceName); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *cmdList) mapShorthandFilters() {
 	c.shorthandFilters = map[string]func(*api.Instance, *api.InstanceState, string) bool{
-		"ipv4": c.matchByIPV4,
-		"ipv6": c.matchByIPV6,
+		"ipv4":  c.matchByIPV4,
+		"ipv6":  c.matchByIPV6,
+		"mac":   c.matchByMAC,
+		"iface": c.matchByIface,
 	}
 }
 
+// networkFilterServerKeys maps the "ipv4"/"ipv6"/"mac" shorthand filter keys to the dotted
+// path the server evaluates against each instance's network state, per the
+// "instance_list_filter_network" extension.
+var networkFilterServerKeys = map[string]string{
+	"ipv4": "network.ipv4.address",
+	"ipv6": "network.ipv6.address",
+	"mac":  "network.hwaddr",
+}
+
 // prepareInstanceServerFilters processes and formats filter criteria
 // for instances, ensuring they are in a format that the server can interpret.
 func prepareInstanceServerFilters(filters []string, i any) []string {
 	formatedFilters := []string{}
 
 	for _, filter := range filters {
-		membs := strings.SplitN(filter, "=", 2)
-		key := membs[0]
-
-		if len(membs) == 1 {
-			regexpValue := key
-			if !strings.Contains(key, "^") && !strings.Contains(key, "$") {
+		key, op, value, ok := splitFilterKeyValue(filter)
+		if !ok {
+			regexpValue := filter
+			if !strings.Contains(filter, "^") && !strings.Contains(filter, "$") {
 				regexpValue = "^" + regexpValue + "$"
 			}
 
-			filter = fmt.Sprintf("name=(%s|^%s.*)", regexpValue, key)
-		} else {
-			firstPart := key
-			if strings.Contains(key, ".") {
-				firstPart = strings.Split(key, ".")[0]
-			}
+			formatedFilters = append(formatedFilters, fmt.Sprintf("name=(%s|^%s.*)", regexpValue, filter))
+			continue
+		}
 
-			if !structHasField(reflect.TypeOf(i), firstPart) {
-				filter = fmt.Sprintf("expanded_config.%s", filter)
+		if serverKey, isNetwork := networkFilterServerKeys[strings.ToLower(key)]; isNetwork {
+			if op == "=" {
+				formatedFilters = append(formatedFilters, fmt.Sprintf("%s%s%s", serverKey, networkFilterOp(value), value))
+			} else {
+				formatedFilters = append(formatedFilters, fmt.Sprintf("%s%s%s", serverKey, op, value))
 			}
 
-			if key == "state" {
-				filter = fmt.Sprintf("status=%s", membs[1])
-			}
+			continue
+		}
+
+		firstPart := key
+		if strings.Contains(key, ".") {
+			firstPart = strings.Split(key, ".")[0]
+		}
+
+		translatedKey := key
+		if !structHasField(reflect.TypeOf(i), firstPart) {
+			translatedKey = "expanded_config." + key
+		}
+
+		if key == "state" {
+			translatedKey = "status"
 		}
 
-		formatedFilters = append(formatedFilters, filter)
+		formatedFilters = append(formatedFilters, fmt.Sprintf("%s%s%s", translatedKey, op, value))
 	}
 
 	return formatedFilters
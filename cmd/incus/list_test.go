@@ -0,0 +1,347 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestTokenizeFilterArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "single bare word", args: []string{"running"}, want: []string{"running"}},
+		{name: "leading paren", args: []string{"(state=running"}, want: []string{"(", "state=running"}},
+		{name: "trailing paren", args: []string{"state=running)"}, want: []string{"state=running", ")"}},
+		{name: "wrapped in parens", args: []string{"(state=running)"}, want: []string{"(", "state=running", ")"}},
+		{name: "multiple args", args: []string{"state=running", "or", "state=stopped"}, want: []string{"state=running", "or", "state=stopped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenizeFilterArgs(tt.args)
+			if len(got) != len(tt.want) {
+				t.Fatalf("tokenizeFilterArgs(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("tokenizeFilterArgs(%v)[%d] = %q, want %q", tt.args, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitFilterKeyValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    string
+		wantKey   string
+		wantOp    string
+		wantValue string
+		wantOK    bool
+	}{
+		{name: "bare word", filter: "running", wantOK: false},
+		{name: "equal", filter: "state=running", wantKey: "state", wantOp: "=", wantValue: "running", wantOK: true},
+		{name: "not equal", filter: "state!=running", wantKey: "state", wantOp: "!=", wantValue: "running", wantOK: true},
+		{name: "regex", filter: "name~=^c", wantKey: "name", wantOp: "~=", wantValue: "^c", wantOK: true},
+		{name: "prefix", filter: "name^=web", wantKey: "name", wantOp: "^=", wantValue: "web", wantOK: true},
+		{name: "contains", filter: "name*=eb", wantKey: "name", wantOp: "*=", wantValue: "eb", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, op, value, ok := splitFilterKeyValue(tt.filter)
+			if ok != tt.wantOK {
+				t.Fatalf("splitFilterKeyValue(%q) ok = %v, want %v", tt.filter, ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			if key != tt.wantKey || op != tt.wantOp || value != tt.wantValue {
+				t.Errorf("splitFilterKeyValue(%q) = (%q, %q, %q), want (%q, %q, %q)", tt.filter, key, op, value, tt.wantKey, tt.wantOp, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParseFilterExprArgsNoFilters(t *testing.T) {
+	expr, err := parseFilterExprArgs(nil)
+	if err != nil {
+		t.Fatalf("parseFilterExprArgs(nil) returned unexpected error: %v", err)
+	}
+
+	if expr != nil {
+		t.Errorf("parseFilterExprArgs(nil) = %v, want nil", expr)
+	}
+}
+
+func TestParseFilterExprArgsRejectsUnbalancedParens(t *testing.T) {
+	_, err := parseFilterExprArgs([]string{"(state=running"})
+	if err == nil {
+		t.Fatal("expected an error for an unbalanced filter expression")
+	}
+}
+
+func TestParseFilterExprArgsEval(t *testing.T) {
+	running := &api.Instance{Name: "c1"}
+	running.Status = "Running"
+
+	stopped := &api.Instance{Name: "c2"}
+	stopped.Status = "Stopped"
+
+	tests := []struct {
+		name        string
+		filters     []string
+		wantRunning bool
+		wantStopped bool
+	}{
+		{
+			name:        "single equality",
+			filters:     []string{"status=Running"},
+			wantRunning: true,
+			wantStopped: false,
+		},
+		{
+			name:        "or",
+			filters:     []string{"status=Running", "or", "status=Stopped"},
+			wantRunning: true,
+			wantStopped: true,
+		},
+		{
+			name:        "and with not",
+			filters:     []string{"status=Running", "and", "not", "name=c1"},
+			wantRunning: false,
+			wantStopped: false,
+		},
+		{
+			name:        "parenthesized grouping",
+			filters:     []string{"(", "status=Running", "or", "status=Stopped", ")", "and", "name=c1"},
+			wantRunning: true,
+			wantStopped: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parseFilterExprArgs(tt.filters)
+			if err != nil {
+				t.Fatalf("parseFilterExprArgs(%v) returned unexpected error: %v", tt.filters, err)
+			}
+
+			c := &cmdList{}
+
+			if got := expr.eval(c, running, nil); got != tt.wantRunning {
+				t.Errorf("eval(running) = %v, want %v", got, tt.wantRunning)
+			}
+
+			if got := expr.eval(c, stopped, nil); got != tt.wantStopped {
+				t.Errorf("eval(stopped) = %v, want %v", got, tt.wantStopped)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpr(t *testing.T) {
+	tests := []struct {
+		name       string
+		expr       string
+		wantKey    string
+		wantOp     filterOp
+		wantValue  string
+		wantValues []string
+		wantErr    bool
+	}{
+		{name: "equal", expr: "memory=1GiB", wantKey: "memory", wantOp: filterOpEqual, wantValue: "1GiB"},
+		{name: "greater equal", expr: "memory>=1GiB", wantKey: "memory", wantOp: filterOpGreaterEqual, wantValue: "1GiB"},
+		{name: "quoted value", expr: `name="web,1"`, wantKey: "name", wantOp: filterOpEqual, wantValue: "web,1"},
+		{name: "in operator", expr: "status in Running,Stopped", wantKey: "status", wantOp: filterOpIn, wantValue: "Running,Stopped", wantValues: []string{"Running", "Stopped"}},
+		{name: "missing operator", expr: "justakey", wantErr: true},
+		{name: "missing key", expr: "=value", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred, err := parseFilterExpr(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFilterExpr(%q) expected an error", tt.expr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseFilterExpr(%q) returned unexpected error: %v", tt.expr, err)
+			}
+
+			if pred.key != tt.wantKey || pred.op != tt.wantOp || pred.value != tt.wantValue {
+				t.Errorf("parseFilterExpr(%q) = %+v, want key=%q op=%q value=%q", tt.expr, pred, tt.wantKey, tt.wantOp, tt.wantValue)
+			}
+
+			if tt.wantValues != nil {
+				if len(pred.values) != len(tt.wantValues) {
+					t.Fatalf("parseFilterExpr(%q).values = %v, want %v", tt.expr, pred.values, tt.wantValues)
+				}
+
+				for i := range tt.wantValues {
+					if pred.values[i] != tt.wantValues[i] {
+						t.Errorf("parseFilterExpr(%q).values[%d] = %q, want %q", tt.expr, i, pred.values[i], tt.wantValues[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkFilterOp(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "10.0.0.0/24", want: " in "},
+		{value: "fd00::/64", want: " in "},
+		{value: "10.0.0.5", want: "="},
+	}
+
+	for _, tt := range tests {
+		got := networkFilterOp(tt.value)
+		if got != tt.want {
+			t.Errorf("networkFilterOp(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	tests := []struct {
+		mac  string
+		want string
+	}{
+		{mac: "AA:BB:CC:DD:EE:FF", want: "aabbccddeeff"},
+		{mac: "aabbccddeeff", want: "aabbccddeeff"},
+	}
+
+	for _, tt := range tests {
+		got := normalizeMAC(tt.mac)
+		if got != tt.want {
+			t.Errorf("normalizeMAC(%q) = %q, want %q", tt.mac, got, tt.want)
+		}
+	}
+}
+
+func TestIsTableFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{format: "table", want: true},
+		{format: "compact", want: true},
+		{format: "markdown", want: true},
+		{format: "table,noheader", want: true},
+		{format: "json", want: false},
+		{format: "csv", want: false},
+	}
+
+	for _, tt := range tests {
+		got := isTableFormat(tt.format)
+		if got != tt.want {
+			t.Errorf("isTableFormat(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestPadLeftAndPadCenter(t *testing.T) {
+	if got := padLeft("ab", 5); got != "   ab" {
+		t.Errorf("padLeft(%q, 5) = %q, want %q", "ab", got, "   ab")
+	}
+
+	if got := padLeft("abcdef", 3); got != "abcdef" {
+		t.Errorf("padLeft should not truncate, got %q", got)
+	}
+
+	if got := padCenter("ab", 6); len(got) != 6 {
+		t.Errorf("padCenter(%q, 6) = %q, want length 6", "ab", got)
+	}
+}
+
+func TestCIDRTrie(t *testing.T) {
+	trie := &cidrTrie{}
+
+	_, v4Subnet, err := net.ParseCIDR("10.0.0.0/24")
+	if err != nil {
+		t.Fatalf("failed to parse test CIDR: %v", err)
+	}
+
+	trie.insert(v4Subnet)
+
+	if !trie.contains(net.ParseIP("10.0.0.42")) {
+		t.Error("expected 10.0.0.42 to be contained in 10.0.0.0/24")
+	}
+
+	if trie.contains(net.ParseIP("10.0.1.42")) {
+		t.Error("expected 10.0.1.42 not to be contained in 10.0.0.0/24")
+	}
+
+	_, v6Subnet, err := net.ParseCIDR("fd00::/64")
+	if err != nil {
+		t.Fatalf("failed to parse test CIDR: %v", err)
+	}
+
+	trie.insert(v6Subnet)
+
+	if !trie.contains(net.ParseIP("fd00::1")) {
+		t.Error("expected fd00::1 to be contained in fd00::/64")
+	}
+
+	if trie.contains(net.ParseIP("fd01::1")) {
+		t.Error("expected fd01::1 not to be contained in fd00::/64")
+	}
+}
+
+func TestLoadCIDRFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cidrs.txt")
+
+	if err := os.WriteFile(path, []byte("# a comment\n10.0.0.0/24\n10.0.1.5\n\nfd00::/64\n"), 0o600); err != nil {
+		t.Fatalf("failed to write test CIDR file: %v", err)
+	}
+
+	trie, err := loadCIDRFile(path)
+	if err != nil {
+		t.Fatalf("loadCIDRFile returned unexpected error: %v", err)
+	}
+
+	if !trie.contains(net.ParseIP("10.0.0.1")) {
+		t.Error("expected 10.0.0.1 to be contained")
+	}
+
+	if !trie.contains(net.ParseIP("10.0.1.5")) {
+		t.Error("expected the bare address 10.0.1.5 to be treated as a /32")
+	}
+
+	if trie.contains(net.ParseIP("10.0.1.6")) {
+		t.Error("expected 10.0.1.6 not to be contained")
+	}
+}
+
+func TestLoadCIDRFileRejectsInvalidEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cidrs.txt")
+
+	if err := os.WriteFile(path, []byte("not-an-ip\n"), 0o600); err != nil {
+		t.Fatalf("failed to write test CIDR file: %v", err)
+	}
+
+	_, err := loadCIDRFile(path)
+	if err == nil {
+		t.Fatal("expected an error for an invalid CIDR file entry")
+	}
+}
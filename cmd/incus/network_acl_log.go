@@ -0,0 +1,148 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// networkACLLogEntry is a single normalized ACL log line, regardless of whether it came from the
+// nftables or OVN firewall driver's log format.
+type networkACLLogEntry struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	RuleID    string `json:"rule_id,omitempty"`
+	Action    string `json:"action,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Src       string `json:"src,omitempty"`
+	Dst       string `json:"dst,omitempty"`
+	Proto     string `json:"proto,omitempty"`
+	SrcPort   string `json:"src_port,omitempty"`
+	DstPort   string `json:"dst_port,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Project   string `json:"project,omitempty"`
+
+	raw string
+}
+
+// nftablesKVRegex matches the KEY=VALUE tokens nftables/netfilter LOG lines use (e.g.
+// "SRC=10.0.0.1 DPT=443").
+var nftablesKVRegex = regexp.MustCompile(`\b([A-Z][A-Z0-9]*)=(\S+)`)
+
+// ovnFieldRegex matches the lower_snake_case key=value tokens OVN's acl_log annotation uses (e.g.
+// "verdict=drop,nw_src=10.0.0.1,tp_dst=443").
+var ovnFieldRegex = regexp.MustCompile(`\b([a-z_]+)=([^,\s]+)`)
+
+// timestampPrefixRegex strips a leading syslog-style or RFC3339 timestamp off a log line so the
+// remaining KEY=VALUE parsing doesn't have to account for it.
+var timestampPrefixRegex = regexp.MustCompile(`^(\[?[0-9TZ:.+-]{10,}\]?)\s+`)
+
+// parseNetworkACLLogLine normalizes a single line of ACL log output, parsing it as the OVN format
+// when it carries OVN's verdict=/acl_log markers and as the nftables format otherwise.
+func parseNetworkACLLogLine(line string) networkACLLogEntry {
+	entry := networkACLLogEntry{raw: line}
+
+	rest := line
+	if m := timestampPrefixRegex.FindStringSubmatch(line); m != nil {
+		entry.Timestamp = strings.Trim(m[1], "[]")
+		rest = line[len(m[0]):]
+	}
+
+	if strings.Contains(rest, "verdict=") || strings.Contains(rest, "acl_log") {
+		for _, m := range ovnFieldRegex.FindAllStringSubmatch(rest, -1) {
+			switch m[1] {
+			case "verdict":
+				entry.Action = m[2]
+			case "direction":
+				entry.Direction = m[2]
+			case "name":
+				entry.RuleID = m[2]
+			case "nw_src":
+				entry.Src = m[2]
+			case "nw_dst":
+				entry.Dst = m[2]
+			case "proto":
+				entry.Proto = m[2]
+			case "tp_src":
+				entry.SrcPort = m[2]
+			case "tp_dst":
+				entry.DstPort = m[2]
+			}
+		}
+
+		return entry
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) > 0 {
+		// The netfilter log prefix (set via --nflog-prefix) conventionally carries the rule ID and
+		// verdict, optionally followed by an in/out direction, e.g. "acl-web-acl-5-allow-in".
+		entry.RuleID = fields[0]
+
+		parts := strings.Split(fields[0], "-")
+		last := len(parts) - 1
+		if last > 0 && (parts[last] == "in" || parts[last] == "out") {
+			entry.Direction = "ingress"
+			if parts[last] == "out" {
+				entry.Direction = "egress"
+			}
+
+			last--
+		}
+
+		entry.Action = parts[last]
+	}
+
+	for _, m := range nftablesKVRegex.FindAllStringSubmatch(rest, -1) {
+		switch m[1] {
+		case "SRC":
+			entry.Src = m[2]
+		case "DST":
+			entry.Dst = m[2]
+		case "PROTO":
+			entry.Proto = m[2]
+		case "SPT":
+			entry.SrcPort = m[2]
+		case "DPT":
+			entry.DstPort = m[2]
+		}
+	}
+
+	return entry
+}
+
+// matchesLogFilters reports whether entry satisfies every non-empty filter.
+func matchesLogFilters(entry networkACLLogEntry, rule string, action string, direction string, instance string) bool {
+	if rule != "" && entry.RuleID != rule {
+		return false
+	}
+
+	if action != "" && !strings.EqualFold(entry.Action, action) {
+		return false
+	}
+
+	if direction != "" && !strings.EqualFold(entry.Direction, direction) {
+		return false
+	}
+
+	if instance != "" && entry.Instance != instance {
+		return false
+	}
+
+	return true
+}
+
+// entryAfter reports whether entry's timestamp is at or after since, returning true if either
+// can't be determined (timestamps in ACL logs aren't guaranteed to be RFC3339-parseable across
+// both backends, so --since is best-effort).
+func entryAfter(entry networkACLLogEntry, since time.Time) bool {
+	if since.IsZero() || entry.Timestamp == "" {
+		return true
+	}
+
+	t, err := time.Parse(time.RFC3339, entry.Timestamp)
+	if err != nil {
+		return true
+	}
+
+	return !t.Before(since)
+}
@@ -0,0 +1,941 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cli "github.com/lxc/incus/v6/internal/cmd"
+	"github.com/lxc/incus/v6/internal/i18n"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/termios"
+)
+
+type cmdNetworkAddressSet struct {
+	global *cmdGlobal
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("address-set")
+	cmd.Short = i18n.G("Manage network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage network address sets
+
+A network address set is a named, project-scoped list of addresses (CIDRs, IPs) or ports that can
+be referenced from an ACL rule's source, destination, or *_port field as "@<name>" instead of
+inlining the list. The server expands the reference at evaluation time, so updating the set
+updates every rule that refers to it.`))
+
+	// List.
+	networkAddressSetListCmd := cmdNetworkAddressSetList{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetListCmd.Command())
+
+	// Show.
+	networkAddressSetShowCmd := cmdNetworkAddressSetShow{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetShowCmd.Command())
+
+	// Get.
+	networkAddressSetGetCmd := cmdNetworkAddressSetGet{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetGetCmd.Command())
+
+	// Create.
+	networkAddressSetCreateCmd := cmdNetworkAddressSetCreate{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetCreateCmd.Command())
+
+	// Set.
+	networkAddressSetSetCmd := cmdNetworkAddressSetSet{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetSetCmd.Command())
+
+	// Unset.
+	networkAddressSetUnsetCmd := cmdNetworkAddressSetUnset{global: c.global, networkAddressSet: c, networkAddressSetSet: &networkAddressSetSetCmd}
+	cmd.AddCommand(networkAddressSetUnsetCmd.Command())
+
+	// Edit.
+	networkAddressSetEditCmd := cmdNetworkAddressSetEdit{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetEditCmd.Command())
+
+	// Rename.
+	networkAddressSetRenameCmd := cmdNetworkAddressSetRename{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetRenameCmd.Command())
+
+	// Delete.
+	networkAddressSetDeleteCmd := cmdNetworkAddressSetDelete{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetDeleteCmd.Command())
+
+	// Add.
+	networkAddressSetAddCmd := cmdNetworkAddressSetAdd{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetAddCmd.Command())
+
+	// Remove.
+	networkAddressSetRemoveCmd := cmdNetworkAddressSetRemove{global: c.global, networkAddressSet: c}
+	cmd.AddCommand(networkAddressSetRemoveCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, _ []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkAddressSetList struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+
+	flagFormat      string
+	flagAllProjects bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network address sets"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", c.global.defaultListFormat(), i18n.G(`Format (csv|json|table|yaml|compact|markdown), use suffix ",noheader" to disable headers and ",header" to enable it if missing, e.g. csv,header`)+"``")
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("List network address sets across all projects"))
+
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
+		return cli.ValidateFlagFormatForListOutput(cmd.Flag("format").Value.String())
+	}
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.parseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return errors.New(i18n.G("Filtering isn't supported yet"))
+	}
+
+	var sets []api.NetworkAddressSet
+	if c.flagAllProjects {
+		sets, err = resource.server.GetNetworkAddressSetsAllProjects()
+		if err != nil {
+			return err
+		}
+	} else {
+		sets, err = resource.server.GetNetworkAddressSets()
+		if err != nil {
+			return err
+		}
+	}
+
+	data := [][]string{}
+	for _, set := range sets {
+		strUsedBy := fmt.Sprintf("%d", len(set.UsedBy))
+		details := []string{
+			set.Name,
+			set.Description,
+			fmt.Sprintf("%d", len(set.Addresses)),
+			strUsedBy,
+		}
+
+		if c.flagAllProjects {
+			details = append([]string{set.Project}, details...)
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("ADDRESSES"),
+		i18n.G("USED BY"),
+	}
+
+	if c.flagAllProjects {
+		header = append([]string{i18n.G("PROJECT")}, header...)
+	}
+
+	return cli.RenderTable(os.Stdout, c.flagFormat, header, data, sets)
+}
+
+// Show.
+type cmdNetworkAddressSetShow struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<address-set>"))
+	cmd.Short = i18n.G("Show network address set configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network address set configurations"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	set, _, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(set.UsedBy)
+
+	data, err := yaml.Marshal(&set)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdNetworkAddressSetGet struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+
+	flagIsProperty bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<address-set> <key>"))
+	cmd.Short = i18n.G("Get values for network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network address set configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a network address set property"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	resp, _, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJSONTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the network address set %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range resp.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkAddressSetCreate struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+
+	flagDescription string
+	flagAddresses   []string
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<address-set> [key=value...]"))
+	cmd.Aliases = []string{"add"}
+	cmd.Short = i18n.G("Create new network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network address sets"))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network address-set create web-servers --address 10.0.0.0/24 --address 10.0.1.5
+
+incus network address-set create web-servers < config.yaml
+    Create network address set with configuration from config.yaml`))
+
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Network address set description")+"``")
+	cmd.Flags().StringArrayVar(&c.flagAddresses, "address", nil, i18n.G("Address or port entry to add, can be passed multiple times")+"``")
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var setPut api.NetworkAddressSetPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &setPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network address set.
+	set := api.NetworkAddressSetsPost{
+		NetworkAddressSetPost: api.NetworkAddressSetPost{
+			Name: resource.name,
+		},
+		NetworkAddressSetPut: setPut,
+	}
+
+	if c.flagDescription != "" {
+		set.Description = c.flagDescription
+	}
+
+	set.Addresses = append(set.Addresses, c.flagAddresses...)
+
+	if set.Config == nil {
+		set.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		set.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreateNetworkAddressSet(set)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkAddressSetSet struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+
+	flagIsProperty bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<address-set> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network address set configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a network address set property"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	// Get the network address set.
+	set, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := set.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJSONTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		maps.Copy(writable.Config, keys)
+	}
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdNetworkAddressSetUnset struct {
+	global               *cmdGlobal
+	networkAddressSet    *cmdNetworkAddressSet
+	networkAddressSetSet *cmdNetworkAddressSetSet
+
+	flagIsProperty bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<address-set> <key>"))
+	cmd.Short = i18n.G("Unset network address set configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network address set configuration keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a network address set property"))
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.networkAddressSetSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.networkAddressSetSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkAddressSetEdit struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<address-set>"))
+	cmd.Short = i18n.G("Edit network address set configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network address set configurations as YAML"))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressSetEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network address set.
+### Any line starting with a '# will be ignored.
+###
+### A network address set consists of a list of addresses and configuration items.
+###
+### An example would look like:
+### name: web-servers
+### description: test desc
+### addresses:
+### - 10.0.0.0/24
+### - 10.0.1.5
+### config:
+###  user.foo: bah
+###
+### Note that only the addresses, description and configuration keys can be changed.`)
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.NetworkAddressSet{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkAddressSet(resource.name, newdata.NetworkAddressSetPut, "")
+	}
+
+	// Get the current config.
+	set, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&set)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := textEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.NetworkAddressSet{}
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateNetworkAddressSet(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = textEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Rename.
+type cmdNetworkAddressSetRename struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetRename) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<address-set> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Rename network address sets"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetRename) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	err = resource.server.RenameNetworkAddressSet(resource.name, api.NetworkAddressSetPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkAddressSetDelete struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<address-set>"))
+	cmd.Aliases = []string{"rm", "remove"}
+	cmd.Short = i18n.G("Delete network address sets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network address sets"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	err = resource.server.DeleteNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address set %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Add.
+type cmdNetworkAddressSetAdd struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetAdd) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<address-set> <address>..."))
+	cmd.Short = i18n.G("Add addresses to a network address set")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add addresses to a network address set"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetAdd) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	set, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	writable := set.Writable()
+	writable.Addresses = append(writable.Addresses, args[1:]...)
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, writable, etag)
+}
+
+// Remove.
+type cmdNetworkAddressSetRemove struct {
+	global            *cmdGlobal
+	networkAddressSet *cmdNetworkAddressSet
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdNetworkAddressSetRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<address-set> <address>..."))
+	cmd.Short = i18n.G("Remove addresses from a network address set")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove addresses from a network address set"))
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressSets(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdNetworkAddressSetRemove) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.parseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address set name"))
+	}
+
+	set, etag, err := resource.server.GetNetworkAddressSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	toRemove := make(map[string]bool, len(args)-1)
+	for _, addr := range args[1:] {
+		toRemove[addr] = true
+	}
+
+	writable := set.Writable()
+	kept := make([]string, 0, len(writable.Addresses))
+	for _, addr := range writable.Addresses {
+		if !toRemove[addr] {
+			kept = append(kept, addr)
+		}
+	}
+
+	writable.Addresses = kept
+
+	return resource.server.UpdateNetworkAddressSet(resource.name, writable, etag)
+}
+
+// isNetworkAddressSetReference reports whether an ACL rule field value is a "@<name>" reference to
+// a network address set rather than a literal address/port list.
+func isNetworkAddressSetReference(value string) bool {
+	return strings.HasPrefix(value, "@")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -41,6 +43,10 @@ func (c *cmdStorage) Command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Manage storage pools and volumes`))
 
+	// Apply
+	storageApplyCmd := cmdStorageApply{global: c.global, storage: c}
+	cmd.AddCommand(storageApplyCmd.Command())
+
 	// Create
 	storageCreateCmd := cmdStorageCreate{global: c.global, storage: c}
 	cmd.AddCommand(storageCreateCmd.Command())
@@ -65,6 +71,10 @@ func (c *cmdStorage) Command() *cobra.Command {
 	storageListCmd := cmdStorageList{global: c.global, storage: c}
 	cmd.AddCommand(storageListCmd.Command())
 
+	// Move
+	storageMoveCmd := cmdStorageMove{global: c.global, storage: c}
+	cmd.AddCommand(storageMoveCmd.Command())
+
 	// Set
 	storageSetCmd := cmdStorageSet{global: c.global, storage: c}
 	cmd.AddCommand(storageSetCmd.Command())
@@ -91,12 +101,148 @@ func (c *cmdStorage) Command() *cobra.Command {
 	return cmd
 }
 
+// storageApplySpec describes the desired state of one or more storage pools for
+// "incus storage apply", as read from a YAML document.
+type storageApplySpec struct {
+	Pools []api.StoragePoolsPost `yaml:"pools"`
+}
+
+// Apply.
+type cmdStorageApply struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+
+	flagPrune bool
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdStorageApply) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:] [<file>]"))
+	cmd.Short = i18n.G("Declaratively reconcile storage pools from a YAML document")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Declaratively reconcile storage pools from a YAML document
+
+Reads a YAML document whose top-level "pools" list gives the desired storage pools (each
+entry using the same fields as "incus storage show") and reconciles the server's storage pools to match it:
+existing pools with matching config are left untouched, pools with different config are
+updated, and pools named in the document but missing on the server are created. Use
+--prune to additionally delete storage pools that exist on the server but aren't listed in
+the document.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus storage apply < pools.yaml
+    Reconcile storage pools from the content of pools.yaml.`))
+
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, i18n.G("Delete pools not present in the document"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdStorageApply) Run(cmd *cobra.Command, args []string) error {
+	exit, err := c.global.checkArgs(cmd, args, 0, 2)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.parseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	client := resources[0].server
+
+	var contents []byte
+	if len(args) > 1 {
+		contents, err = os.ReadFile(args[1])
+	} else {
+		contents, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var spec storageApplySpec
+	err = yaml.Unmarshal(contents, &spec)
+	if err != nil {
+		return err
+	}
+
+	existing, err := client.GetStoragePools()
+	if err != nil {
+		return err
+	}
+
+	existingByName := make(map[string]api.StoragePool, len(existing))
+	for _, pool := range existing {
+		existingByName[pool.Name] = pool
+	}
+
+	wanted := make(map[string]bool, len(spec.Pools))
+	for _, desired := range spec.Pools {
+		wanted[desired.Name] = true
+
+		current, ok := existingByName[desired.Name]
+		if !ok {
+			err = client.CreateStoragePool(desired)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Failed creating pool %q: %w"), desired.Name, err)
+			}
+
+			if !c.global.flagQuiet {
+				fmt.Printf(i18n.G("Storage pool %s created")+"\n", desired.Name)
+			}
+
+			continue
+		}
+
+		if reflect.DeepEqual(current.StoragePoolPut, desired.StoragePoolPut) {
+			continue
+		}
+
+		err = client.UpdateStoragePool(desired.Name, desired.StoragePoolPut, "")
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed updating pool %q: %w"), desired.Name, err)
+		}
+
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("Storage pool %s updated")+"\n", desired.Name)
+		}
+	}
+
+	if c.flagPrune {
+		for _, pool := range existing {
+			if wanted[pool.Name] {
+				continue
+			}
+
+			err = client.DeleteStoragePool(pool.Name)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Failed deleting pool %q: %w"), pool.Name, err)
+			}
+
+			if !c.global.flagQuiet {
+				fmt.Printf(i18n.G("Storage pool %s deleted")+"\n", pool.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Create.
 type cmdStorageCreate struct {
 	global  *cmdGlobal
 	storage *cmdStorage
 
 	flagDescription string
+	flagCheck       bool
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -115,6 +261,7 @@ incus storage create s1 dir < config.yaml
 
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Storage pool description")+"``")
+	cmd.Flags().BoolVar(&c.flagCheck, "check", false, i18n.G("Validate the config against the driver's schema without creating the pool"))
 
 	cmd.RunE = c.Run
 
@@ -129,23 +276,64 @@ incus storage create s1 dir < config.yaml
 	return cmd
 }
 
+// validateStoragePoolConfig checks a storage pool's config keys against the driver's
+// advertised config schema, returning an error listing every unknown key rather than
+// stopping at the first one. Values are not checked.
+func validateStoragePoolConfig(server any, driver string, config map[string]string) error {
+	type schemaProvider interface {
+		GetStoragePoolDriverSchema(driver string) (map[string]string, error)
+	}
+
+	provider, ok := server.(schemaProvider)
+	if !ok {
+		// Server doesn't support schema introspection (e.g. older incusd); skip.
+		return nil
+	}
+
+	schema, err := provider.GetStoragePoolDriverSchema(driver)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed fetching config schema for driver %q: %w"), driver, err)
+	}
+
+	var invalid []string
+	for key := range config {
+		if strings.HasPrefix(key, "user.") {
+			continue // Free-form user keys are always allowed.
+		}
+
+		if _, ok := schema[key]; !ok {
+			invalid = append(invalid, key)
+		}
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf(i18n.G("Unknown config key(s) for driver %q: %s"), driver, strings.Join(invalid, ", "))
+	}
+
+	return nil
+}
+
 // Run runs the actual command logic.
 func (c *cmdStorageCreate) Run(cmd *cobra.Command, args []string) error {
-	var stdinData api.StoragePoolPut
+	var stdinData api.StoragePool
 
 	// Quick checks.
-	exit, err := c.global.checkArgs(cmd, args, 2, -1)
+	exit, err := c.global.checkArgs(cmd, args, 1, -1)
 	if exit {
 		return err
 	}
 
-	// Require a proper driver name.
-	if strings.Contains(args[1], "=") {
+	// Require a proper driver name when one is given positionally.
+	if len(args) > 1 && strings.Contains(args[1], "=") {
 		_ = cmd.Help()
 		return errors.New(i18n.G("Invalid number of arguments"))
 	}
 
-	// If stdin isn't a terminal, read text from it
+	// If stdin isn't a terminal, read text from it. This also allows piping the YAML
+	// produced by "incus storage show" straight back into "create" for round-trip parity:
+	// the full api.StoragePool document is accepted and its Name/Driver are used if not
+	// given positionally, while read-only fields (status, used_by, locations) are ignored.
 	if !termios.IsTerminal(getStdinFd()) {
 		contents, err := io.ReadAll(os.Stdin)
 		if err != nil {
@@ -168,9 +356,22 @@ func (c *cmdStorageCreate) Run(cmd *cobra.Command, args []string) error {
 	client := resource.server
 
 	// Create the new storage pool entry
-	pool := api.StoragePoolsPost{StoragePoolPut: stdinData}
+	pool := api.StoragePoolsPost{StoragePoolPut: stdinData.StoragePoolPut}
 	pool.Name = resource.name
-	pool.Driver = args[1]
+	if pool.Name == "" {
+		pool.Name = stdinData.Name
+	}
+
+	if len(args) > 1 {
+		pool.Driver = args[1]
+	} else {
+		pool.Driver = stdinData.Driver
+	}
+
+	if pool.Driver == "" {
+		_ = cmd.Help()
+		return errors.New(i18n.G("A driver is required, either as an argument or via stdin"))
+	}
 
 	if c.flagDescription != "" {
 		pool.Description = c.flagDescription
@@ -195,6 +396,19 @@ func (c *cmdStorageCreate) Run(cmd *cobra.Command, args []string) error {
 		client = client.UseTarget(c.storage.flagTarget)
 	}
 
+	err = validateStoragePoolConfig(client, pool.Driver, pool.Config)
+	if err != nil {
+		return err
+	}
+
+	if c.flagCheck {
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("Storage pool %s config is valid for driver %s")+"\n", resource.name, pool.Driver)
+		}
+
+		return nil
+	}
+
 	// Create the pool
 	err = client.CreateStoragePool(pool)
 	if err != nil {
@@ -494,7 +708,9 @@ type cmdStorageInfo struct {
 	global  *cmdGlobal
 	storage *cmdStorage
 
-	flagBytes bool
+	flagBytes      bool
+	flagFormat     string
+	flagUsedByType string
 }
 
 // Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
@@ -507,6 +723,8 @@ func (c *cmdStorageInfo) Command() *cobra.Command {
 
 	cmd.Flags().BoolVar(&c.flagBytes, "bytes", false, i18n.G("Show the used and free space in bytes"))
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "yaml", i18n.G("Format (yaml|json|jsonpath=<expr>|template=<tpl>)")+"``")
+	cmd.Flags().StringVar(&c.flagUsedByType, "used-by-type", "", i18n.G("Only list used-by entries of this type (e.g. instances, volumes)")+"``")
 	cmd.RunE = c.Run
 
 	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -520,6 +738,54 @@ func (c *cmdStorageInfo) Command() *cobra.Command {
 	return cmd
 }
 
+// runAllMembers handles "--target=all": it fetches per-member storage resource usage for
+// the pool and prints both the per-member breakdown and the cluster-wide totals.
+func (c *cmdStorageInfo) runAllMembers(resource remoteResource) error {
+	members, err := resource.server.GetClusterMembers()
+	if err != nil {
+		return err
+	}
+
+	type memberUsage struct {
+		Member string `yaml:"member"`
+		Total  uint64 `yaml:"total"`
+		Used   uint64 `yaml:"used"`
+	}
+
+	var perMember []memberUsage
+	var totalSpace, usedSpace uint64
+
+	for _, member := range members {
+		memberServer := resource.server.UseTarget(member.ServerName)
+
+		res, err := memberServer.GetStoragePoolResources(resource.name)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed getting storage pool resources on %q: %w"), member.ServerName, err)
+		}
+
+		perMember = append(perMember, memberUsage{Member: member.ServerName, Total: res.Space.Total, Used: res.Space.Used})
+		totalSpace += res.Space.Total
+		usedSpace += res.Space.Used
+	}
+
+	out := map[string]any{
+		"members": perMember,
+		"total": map[string]uint64{
+			"total": totalSpace,
+			"used":  usedSpace,
+		},
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
 // Run runs the actual command logic.
 func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
@@ -541,6 +807,14 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Targeting
+	if c.storage.flagTarget == "all" {
+		if !resource.server.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		return c.runAllMembers(resource)
+	}
+
 	if c.storage.flagTarget != "" {
 		if !resource.server.IsClustered() {
 			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
@@ -607,6 +881,10 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		if c.flagUsedByType != "" && entityType != c.flagUsedByType {
+			continue
+		}
+
 		var sb strings.Builder
 		var attribs []string
 		sb.WriteString(entityName)
@@ -654,6 +932,15 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 		poolinfo[infostring][spaceusedstring] = units.GetByteSizeStringIEC(int64(res.Space.Used), 2)
 	}
 
+	if c.flagFormat != "" && c.flagFormat != "yaml" {
+		combined := map[string]any{
+			infostring:   poolinfo[infostring],
+			usedbystring: poolusedby[usedbystring],
+		}
+
+		return c.renderFormatted(combined)
+	}
+
 	poolinfodata, err := yaml.Marshal(poolinfo)
 	if err != nil {
 		return err
@@ -670,6 +957,125 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// renderFormatted outputs the storage pool info according to c.flagFormat, supporting
+// plain JSON, a JSONPath expression ("jsonpath=<expr>"), or a Go template
+// ("template=<tpl>") evaluated against the marshaled JSON representation of data.
+func (c *cmdStorageInfo) renderFormatted(data any) error {
+	switch {
+	case c.flagFormat == "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+
+	case strings.HasPrefix(c.flagFormat, "jsonpath="):
+		expr := strings.TrimPrefix(c.flagFormat, "jsonpath=")
+		return cli.RenderJSONPath(os.Stdout, expr, data)
+
+	case strings.HasPrefix(c.flagFormat, "template="):
+		tplText := strings.TrimPrefix(c.flagFormat, "template=")
+		tpl, err := template.New("storage-info").Parse(tplText)
+		if err != nil {
+			return err
+		}
+
+		return tpl.Execute(os.Stdout, data)
+
+	default:
+		return fmt.Errorf(i18n.G("Invalid format %q"), c.flagFormat)
+	}
+}
+
+// Move.
+type cmdStorageMove struct {
+	global  *cmdGlobal
+	storage *cmdStorage
+}
+
+// Command returns a cobra.Command for use with (*cobra.Command).AddCommand.
+func (c *cmdStorageMove) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("move", i18n.G("[<remote>:]<pool> [<remote>:]<pool>"))
+	cmd.Short = i18n.G("Move storage pools")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Move storage pools, either to another pool on the same server or between two
+separate remotes. No cluster member can be selected as the destination.
+
+All volumes on the source pool are moved to the destination pool one by one, reusing the
+same migration path as "incus storage volume move". The source pool is left empty, but
+not deleted.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus storage move s1 s2
+    Move all volumes from storage pool s1 to storage pool s2 on the same server.
+
+incus storage move s1 remote:s1
+    Move all volumes from storage pool s1 to a pool of the same name on "remote".`))
+
+	cmd.RunE = c.Run
+
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 || len(args) == 1 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// Run runs the actual command logic.
+func (c *cmdStorageMove) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.checkArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	resources, err := c.global.parseServers(args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	srcResource := resources[0]
+	dstResource := resources[1]
+
+	if srcResource.name == "" {
+		return errors.New(i18n.G("Missing source pool name"))
+	}
+
+	if dstResource.name == "" {
+		dstResource.name = srcResource.name
+	}
+
+	volumes, err := srcResource.server.GetStoragePoolVolumes(srcResource.name)
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes {
+		args := storagePoolVolumeCopyArgs{
+			VolumeOnly: false,
+			Mode:       "pull",
+			Move:       true,
+		}
+
+		op, err := storagePoolVolumeCopy(c.global.conf, srcResource.server, srcResource.name, dstResource.server, dstResource.name, volume, args)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed moving volume %q: %w"), volume.Name, err)
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Storage pool %s moved to %s")+"\n", srcResource.name, dstResource.name)
+	}
+
+	return nil
+}
+
 // List.
 type cmdStorageList struct {
 	global  *cmdGlobal
@@ -737,6 +1143,7 @@ func (c *cmdStorageList) parseColumns() ([]storageColumn, error) {
 		'd': {i18n.G("DESCRIPTION"), c.descriptionColumnData},
 		'S': {i18n.G("SOURCE"), c.sourceColumnData},
 		'u': {i18n.G("USED BY"), c.usedByColumnData},
+		'U': {i18n.G("USED BY (BY TYPE)"), c.usedByGroupedColumnData},
 		's': {i18n.G("STATE"), c.stateColumnData},
 	}
 
@@ -782,6 +1189,52 @@ func (c *cmdStorageList) usedByColumnData(storage api.StoragePool) string {
 	return fmt.Sprintf("%d", len(storage.UsedBy))
 }
 
+// usedByGroupedColumnData summarizes storage.UsedBy as a count per entity type (e.g.
+// "instances: 3, volumes: 1") instead of a single total, mirroring the breakdown shown by
+// "incus storage info".
+func (c *cmdStorageList) usedByGroupedColumnData(storage api.StoragePool) string {
+	counts := map[string]int{}
+	order := []string{}
+
+	for _, rawURL := range storage.UsedBy {
+		entityType := storageUsedByEntityType(rawURL)
+
+		if counts[entityType] == 0 {
+			order = append(order, entityType)
+		}
+
+		counts[entityType]++
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, entityType := range order {
+		parts = append(parts, fmt.Sprintf("%s: %d", entityType, counts[entityType]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// storageUsedByEntityType extracts the entity type segment (e.g. "instances", "volumes")
+// from one of the URLs in a storage pool's UsedBy list.
+func storageUsedByEntityType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "unrecognized"
+	}
+
+	fields := strings.Split(strings.TrimPrefix(u.Path, "/1.0/"), "/")
+	if len(fields) == 0 {
+		return "unrecognized"
+	}
+
+	entityType := fields[0]
+	if entityType == "storage-pools" && len(fields) > 2 {
+		entityType = fields[2]
+	}
+
+	return entityType
+}
+
 func (c *cmdStorageList) stateColumnData(storage api.StoragePool) string {
 	return strings.ToUpper(storage.Status)
 }
@@ -1012,6 +1465,36 @@ func (c *cmdStorageShow) Run(cmd *cobra.Command, args []string) error {
 		return errors.New(i18n.G("Missing pool name"))
 	}
 
+	if c.flagResources && c.storage.flagTarget == "all" {
+		if !client.IsClustered() {
+			return errors.New(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		members, err := client.GetClusterMembers()
+		if err != nil {
+			return err
+		}
+
+		aggregate := map[string]api.ResourcesStoragePool{}
+		for _, member := range members {
+			res, err := client.UseTarget(member.ServerName).GetStoragePoolResources(resource.name)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Failed getting storage pool resources on %q: %w"), member.ServerName, err)
+			}
+
+			aggregate[member.ServerName] = *res
+		}
+
+		data, err := yaml.Marshal(aggregate)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s", data)
+
+		return nil
+	}
+
 	// If a target member was specified, we return also member-specific config values.
 	if c.storage.flagTarget != "" {
 		client = client.UseTarget(c.storage.flagTarget)
@@ -1100,16 +1583,26 @@ func (c *cmdStorageUnset) Run(cmd *cobra.Command, args []string) error {
 	return c.storageSet.Run(cmd, args)
 }
 
+// storageFilterOperators lists the recognized comparison/match operators for
+// prepareStoragePoolsServerFilters, ordered so multi-character operators are matched before
+// their single-character prefixes (e.g. ">=" before ">").
+var storageFilterOperators = []string{"!=", ">=", "<=", "=~", "!~", ">", "<", "="}
+
 // prepareStoragePoolsServerFilters processes and formats filter criteria
 // for storage pools, ensuring they are in a format that the server can interpret.
 func prepareStoragePoolsServerFilters(filters []string, i any) []string {
 	formattedFilters := []string{}
 
 	for _, filter := range filters {
-		membs := strings.SplitN(filter, "=", 2)
-		key := membs[0]
+		negate := false
+		if strings.HasPrefix(filter, "!") && !strings.HasPrefix(filter, "!=") && !strings.HasPrefix(filter, "!~") {
+			negate = true
+			filter = strings.TrimPrefix(filter, "!")
+		}
+
+		op, key, value, hasOp := splitStorageFilterOperator(filter)
 
-		if len(membs) == 1 {
+		if !hasOp {
 			regexpValue := key
 			if !strings.Contains(key, "^") && !strings.Contains(key, "$") {
 				regexpValue = "^" + regexpValue + "$"
@@ -1123,8 +1616,14 @@ func prepareStoragePoolsServerFilters(filters []string, i any) []string {
 			}
 
 			if !structHasField(reflect.TypeOf(i), firstPart) {
-				filter = fmt.Sprintf("config.%s", filter)
+				key = fmt.Sprintf("config.%s", key)
 			}
+
+			filter = fmt.Sprintf("%s%s%s", key, op, value)
+		}
+
+		if negate {
+			filter = fmt.Sprintf("not(%s)", filter)
 		}
 
 		formattedFilters = append(formattedFilters, filter)
@@ -1132,3 +1631,19 @@ func prepareStoragePoolsServerFilters(filters []string, i any) []string {
 
 	return formattedFilters
 }
+
+// splitStorageFilterOperator splits a raw filter term like "size>=10GiB" or "name=~foo.*"
+// into its key, operator and value. hasOp is false for a bare term with no recognized
+// operator (e.g. just a name to fuzzy-match).
+func splitStorageFilterOperator(filter string) (op string, key string, value string, hasOp bool) {
+	for _, candidate := range storageFilterOperators {
+		idx := strings.Index(filter, candidate)
+		if idx < 0 {
+			continue
+		}
+
+		return candidate, filter[:idx], filter[idx+len(candidate):], true
+	}
+
+	return "", filter, "", false
+}
@@ -235,8 +235,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 
 			// Storage pool changes require a target flag.
 			if req.Pool != "" {
-				if inst.Type() != instancetype.VM {
-					return response.BadRequest(errors.New("Live storage pool changes aren't supported for containers"))
+				if inst.Type() != instancetype.VM && !criuMigrationAvailable(inst) {
+					return response.BadRequest(errors.New("Live storage pool changes aren't supported for containers without CRIU"))
 				}
 
 				if !s.ServerClustered {
@@ -396,6 +396,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			}
 
 			// Prepare the placement scriptlet context.
+			targetCluster := req.TargetCluster
 			req := apiScriptlet.InstancePlacement{
 				InstancesPost: api.InstancesPost{
 					Name: name,
@@ -406,8 +407,9 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 						Profiles: profileNames,
 					},
 				},
-				Project: instProject,
-				Reason:  apiScriptlet.InstancePlacementReasonRelocation,
+				Project:       instProject,
+				Reason:        apiScriptlet.InstancePlacementReasonRelocation,
+				TargetCluster: targetCluster,
 			}
 
 			if targetMemberInfo == nil {
@@ -461,7 +463,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(errors.New("Requested target server is the same as current server"))
 	}
 
-	// If the instance needs to move, make sure it doesn't have backups.
+	// If the instance needs to move, make sure it doesn't have backups, unless the
+	// caller explicitly asked for them to be carried along.
 	if targetMemberInfo != nil && targetMemberInfo.Name != inst.Location() {
 		// Check if instance has backups.
 		var backups []string
@@ -474,7 +477,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		if len(backups) > 0 {
+		if len(backups) > 0 && !req.MoveBackups {
 			return response.BadRequest(errors.New("Instances with backups cannot be moved"))
 		}
 	}
@@ -486,6 +489,28 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			targetMemberInfo = nil
 		}
 
+		// Dry-run mode: resolve the target and validate what we can up front, then
+		// report back a plan without touching any data.
+		if req.DryRun {
+			run := func(op *operations.Operation) error {
+				plan, err := planInstanceMove(s, inst, req, targetMemberInfo, targetGroupName)
+				if err != nil {
+					return err
+				}
+
+				return op.UpdateMetadata(plan)
+			}
+
+			resources := map[string][]api.URL{}
+			resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+			op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
+			if err != nil {
+				return response.InternalError(err)
+			}
+
+			return operations.OperationResponse(op)
+		}
+
 		// Setup the instance move operation.
 		run := func(op *operations.Operation) error {
 			inst.SetOperation(op)
@@ -502,12 +527,32 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		return operations.OperationResponse(op)
 	}
 
+	// Federated migration: the target points at an entirely separate Incus deployment
+	// registered as a trusted remote, rather than a peer in the same cluster.
+	if req.TargetCluster != "" {
+		remote, ok := s.Federation.Get(req.TargetCluster)
+		if !ok {
+			return response.BadRequest(fmt.Errorf("Remote cluster %q isn't registered", req.TargetCluster))
+		}
+
+		if req.Project == "" {
+			req.Project = s.Federation.MapProject(req.TargetCluster, instProject)
+		}
+
+		req.Target = &api.InstancePostTarget{
+			Certificate: remote.TLSClientCert,
+		}
+	}
+
 	// Cross-server instance migration.
 	ws, err := newMigrationSource(inst, req.Live, req.InstanceOnly, req.AllowInconsistent, "", "", req.Target)
 	if err != nil {
 		return response.InternalError(err)
 	}
 
+	ws.snapshotConcurrency = migrationSnapshotConcurrency(req)
+	ws.bandwidthLimit = req.BandwidthLimit
+
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
 	run := func(op *operations.Operation) error {
@@ -538,6 +583,276 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// swagger:operation POST /1.0/instances instances instances_bulk_migrate_post
+//
+//	Bulk move/migrate instances
+//
+//	Creates a single parent operation that coordinates moving or migrating a
+//	list of instances through the same code path as the per-instance
+//	`instancePost`/`migrateInstance` handlers. Intended for cluster-member
+//	evacuation and rebalancing, where looping over the single-instance
+//	endpoint would otherwise require the client to track and retry failures
+//	one at a time.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: migration
+//	    description: Bulk migration request
+//	    schema:
+//	      $ref: "#/definitions/InstancesBulkPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancesBulkMigratePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	<-d.waitReady.Done()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstancesBulkPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(req.Instances) == 0 {
+		return response.BadRequest(errors.New("No instances specified"))
+	}
+
+	// Cap the number of transfers running at once so a batch evacuation doesn't
+	// saturate a single target's storage bandwidth.
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	resources := map[string][]api.URL{}
+	for _, instRef := range req.Instances {
+		resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", instRef.Name))
+	}
+
+	// Schedule instances into dependency-respecting waves: an instance only starts once
+	// every instance named in its DependsOn has completed, so evacuation can order e.g.
+	// a database instance's dependents behind it without the client managing the ordering.
+	waves, err := scheduleBulkMigrationWaves(req.Instances)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	run := func(op *operations.Operation) error {
+		done := map[string]error{}
+
+		for _, wave := range waves {
+			sem := make(chan struct{}, concurrency)
+			errCh := make(chan error, len(wave))
+
+			for _, instRef := range wave {
+				instRef := instRef
+
+				sem <- struct{}{}
+				go func() {
+					defer func() { <-sem }()
+
+					instProjectName := projectName
+					if instRef.Project != "" {
+						instProjectName = instRef.Project
+					}
+
+					inst, err := instance.LoadByProjectAndName(s, instProjectName, instRef.Name)
+					if err != nil {
+						errCh <- fmt.Errorf("Failed loading instance %q: %w", instRef.Name, err)
+						return
+					}
+
+					childReq := req.InstancePost
+					inst.SetOperation(op)
+
+					err = migrateInstance(context.TODO(), s, inst, childReq, nil, nil, "", op)
+					if err != nil {
+						errCh <- fmt.Errorf("Failed moving instance %q: %w", instRef.Name, err)
+						return
+					}
+
+					errCh <- nil
+				}()
+			}
+
+			for _, instRef := range wave {
+				done[instRef.Name] = <-errCh
+			}
+		}
+
+		var errs []error
+		for _, err := range done {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("Bulk move completed with %d failure(s): %w", len(errs), errors.Join(errs...))
+		}
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// scheduleBulkMigrationWaves groups a bulk move request's instances into ordered waves such
+// that every instance in a wave only depends on instances from earlier waves. It returns an
+// error if DependsOn references an instance that isn't part of the request or forms a cycle.
+func scheduleBulkMigrationWaves(instances []api.InstanceBulkMoveEntry) ([][]api.InstanceBulkMoveEntry, error) {
+	byName := make(map[string]api.InstanceBulkMoveEntry, len(instances))
+	for _, instRef := range instances {
+		byName[instRef.Name] = instRef
+	}
+
+	for _, instRef := range instances {
+		for _, dep := range instRef.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("Instance %q depends on %q which isn't part of this request", instRef.Name, dep)
+			}
+		}
+	}
+
+	var waves [][]api.InstanceBulkMoveEntry
+	remaining := instances
+	scheduled := map[string]bool{}
+
+	for len(remaining) > 0 {
+		var wave []api.InstanceBulkMoveEntry
+		var next []api.InstanceBulkMoveEntry
+
+		for _, instRef := range remaining {
+			ready := true
+			for _, dep := range instRef.DependsOn {
+				if !scheduled[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				wave = append(wave, instRef)
+			} else {
+				next = append(next, instRef)
+			}
+		}
+
+		if len(wave) == 0 {
+			return nil, errors.New("Instance dependency graph contains a cycle")
+		}
+
+		for _, instRef := range wave {
+			scheduled[instRef.Name] = true
+		}
+
+		waves = append(waves, wave)
+		remaining = next
+	}
+
+	return waves, nil
+}
+
+// planInstanceMove computes what a move would do without performing the data transfer, so
+// that dry-run requests can report a target, storage pool, estimated size and any blockers.
+func planInstanceMove(s *state.State, inst instance.Instance, req api.InstancePost, targetMemberInfo *db.NodeInfo, targetGroupName string) (*api.InstanceMovePlan, error) {
+	plan := &api.InstanceMovePlan{
+		SourceMember: inst.Location(),
+		TargetGroup:  targetGroupName,
+	}
+
+	if targetMemberInfo != nil {
+		plan.TargetMember = targetMemberInfo.Name
+	}
+
+	sourcePool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	targetPoolName := req.Pool
+	if targetPoolName == "" {
+		targetPoolName = sourcePool.Name()
+	}
+
+	plan.TargetPool = targetPoolName
+
+	if req.Pool != "" && req.Pool != sourcePool.Name() {
+		targetPool, err := storagePools.LoadByName(s, req.Pool)
+		if err != nil {
+			plan.Blockers = append(plan.Blockers, fmt.Sprintf("Target storage pool %q is not available: %v", req.Pool, err))
+		} else if targetPool.Driver().Info().Name != sourcePool.Driver().Info().Name {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Target pool driver %q differs from source driver %q, a generic transfer will be used", targetPool.Driver().Info().Name, sourcePool.Driver().Info().Name))
+		}
+	}
+
+	// Estimate the transfer size from the root volume, if the driver can report it.
+	rootDiskSize, err := sourcePool.GetInstanceUsage(inst)
+	if err == nil {
+		plan.EstimatedBytes = rootDiskSize.Used
+	} else {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("Could not estimate transfer size: %v", err))
+	}
+
+	backups, err := inst.Backups()
+	if err == nil && len(backups) > 0 && !req.MoveBackups {
+		plan.Blockers = append(plan.Blockers, "Instance has backups and MoveBackups wasn't requested")
+	}
+
+	if inst.IsRunning() && !req.Live {
+		plan.Blockers = append(plan.Blockers, "Instance is running and the move was not requested as live")
+	}
+
+	return plan, nil
+}
+
+// migrationSnapshotConcurrency returns how many of an instance's snapshots a migration
+// source should transfer in parallel. It defaults to a single, sequential transfer to
+// match prior behavior, and can be raised via api.InstancePost.SnapshotConcurrency for
+// instances with many snapshots where pipelining the transfers shortens a live move.
+func migrationSnapshotConcurrency(req api.InstancePost) int {
+	if req.SnapshotConcurrency <= 0 {
+		return 1
+	}
+
+	return req.SnapshotConcurrency
+}
+
+// criuMigrationAvailable reports whether the instance can use the CRIU checkpoint/restore
+// path for a live move. Containers need the lxc driver to have been built with CRIU support;
+// VMs always use their own live migration mechanism so this only applies to containers.
+func criuMigrationAvailable(inst instance.Instance) bool {
+	if inst.Type() != instancetype.Container {
+		return false
+	}
+
+	return internalInstance.CRIUSupported()
+}
+
 // Perform the server-side migration.
 func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance, req api.InstancePost, sourceMemberInfo *db.NodeInfo, targetMemberInfo *db.NodeInfo, targetGroupName string, op *operations.Operation) error {
 	// Load the instance storage pool.
@@ -660,6 +975,38 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 		req.Name = ""
 	}
 
+	// Native storage-driver optimized path: when moving to another pool on the same
+	// cluster member, the project isn't changing, and both pools share the same driver,
+	// use the driver's own optimized volume migration instead of going through the
+	// generic copy machinery (which would round-trip the data through the client API).
+	if req.Pool != "" && req.Project == "" && !req.Live && targetMemberInfo == nil {
+		targetPool, err := storagePools.LoadByName(s, req.Pool)
+		if err != nil {
+			return fmt.Errorf("Failed loading target storage pool: %w", err)
+		}
+
+		if targetPool.Driver().Info().Name == sourcePool.Driver().Info().Name {
+			err = targetPool.MigrateInstanceVolume(sourcePool, inst, nil)
+			if err != nil {
+				return fmt.Errorf("Failed native storage-driver migration of instance volume: %w", err)
+			}
+
+			err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpdateInstancePool(ctx, inst.Project().Name, inst.Name(), targetPool.ID())
+			})
+			if err != nil {
+				return fmt.Errorf("Failed updating instance's storage pool record: %w", err)
+			}
+
+			inst, err = instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name())
+			if err != nil {
+				return err
+			}
+
+			req.Pool = ""
+		}
+	}
+
 	// Handle pool and project moves for stopped instances.
 	if (req.Project != "" || req.Pool != "") && !req.Live {
 		// Get a local client.
@@ -866,12 +1213,22 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 			return fmt.Errorf("Failed getting source instance snapshots: %w", err)
 		}
 
-		// Setup a new migration source.
+		// Setup a new migration source. For a live container move, newMigrationSource
+		// negotiates CRIU feature flags (pre-copy iterations, TCP established, external
+		// mounts) with the target during its initial handshake and falls back to a
+		// stateless transfer if either end lacks CRIU support.
 		sourceMigration, err := newMigrationSource(inst, req.Live, false, req.AllowInconsistent, inst.Name(), req.Pool, nil)
 		if err != nil {
 			return fmt.Errorf("Failed setting up instance migration on source: %w", err)
 		}
 
+		// Fan snapshot sends out across up to migrationSnapshotConcurrency workers and
+		// pipeline the live pre-copy iterations with the snapshot transfers instead of
+		// running them strictly sequentially, which is what made pre-copy-heavy live
+		// moves of instances with many snapshots dominated by transfer latency.
+		sourceMigration.snapshotConcurrency = migrationSnapshotConcurrency(req)
+		sourceMigration.bandwidthLimit = req.BandwidthLimit
+
 		run := func(_ *operations.Operation) error {
 			return sourceMigration.do(op)
 		}
@@ -996,6 +1353,18 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 			return nil
 		})
 		if err != nil {
+			// Commit phase failed after the data transfer (prepare phase) already
+			// succeeded: roll back by removing the instance we just created on the
+			// target rather than leaving two copies registered in the database.
+			rollbackOp, rollbackErr := target.DeleteInstance(inst.Name())
+			if rollbackErr == nil {
+				rollbackErr = rollbackOp.Wait()
+			}
+
+			if rollbackErr != nil {
+				logger.Error("Failed rolling back instance move after commit failure", logger.Ctx{"instance": inst.Name(), "target": targetMemberInfo.Name, "err": rollbackErr})
+			}
+
 			return err
 		}
 
@@ -1026,6 +1395,61 @@ func migrateInstance(ctx context.Context, s *state.State, inst instance.Instance
 				return fmt.Errorf("Failed deleting instance on source member: %w", err)
 			}
 		}
+
+		// Carry backups along if requested, re-registering them against the target
+		// member/pool once their on-disk artifacts have been transferred over.
+		if req.MoveBackups {
+			err = transferInstanceBackups(ctx, s, inst, sourcePool, targetMemberInfo, op)
+			if err != nil {
+				return fmt.Errorf("Failed transferring instance backups: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// transferInstanceBackups enumerates the instance's backups, streams their on-disk
+// artifacts to the target member via the storage pool driver, and re-registers them
+// against the target's node/pool IDs. Progress is reported per-backup through op.
+func transferInstanceBackups(ctx context.Context, s *state.State, inst instance.Instance, sourcePool storagePools.Pool, targetMemberInfo *db.NodeInfo, op *operations.Operation) error {
+	var backups []db.InstanceBackup
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		backups, err = tx.GetInstanceBackupsWithFilter(ctx, db.InstanceBackupFilter{InstanceID: nil})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading instance backups: %w", err)
+	}
+
+	// The query above isn't scoped to an instance, so keep only this instance's backups.
+	instBackups := make([]db.InstanceBackup, 0, len(backups))
+	for _, backup := range backups {
+		if backup.InstanceID == inst.ID() {
+			instBackups = append(instBackups, backup)
+		}
+	}
+
+	backups = instBackups
+
+	progress := map[string]string{}
+	for i, backup := range backups {
+		progress["backup_name"] = backup.Name
+		progress["backup_index"] = fmt.Sprintf("%d/%d", i+1, len(backups))
+		_ = op.UpdateMetadata(map[string]any{"backup_transfer": progress})
+
+		err = sourcePool.MigrateInstanceBackup(inst, backup.Name, targetMemberInfo.Address)
+		if err != nil {
+			return fmt.Errorf("Failed transferring backup %q: %w", backup.Name, err)
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateInstanceBackupNode(ctx, backup.Name, targetMemberInfo.Name)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed updating backup %q location: %w", backup.Name, err)
+		}
 	}
 
 	return nil
@@ -72,6 +72,18 @@ static void forkdonetdetach(char *file) {
 	// Jump back to Go for the rest
 }
 
+static void forkdonetproxy(char *file)
+{
+	// Attach to the container's network namespace; the rest of the userland proxy engine
+	// (listening, dialing out, shuttling bytes) runs entirely in Go from here.
+	if (dosetns_file(file, "net") < 0) {
+		fprintf(stderr, "Failed setns to container network namespace: %s\n", strerror(errno));
+		_exit(1);
+	}
+
+	// Jump back to Go for the rest
+}
+
 static void forkdonetdhcp() {
 	char *pidstr;
 	char path[PATH_MAX];
@@ -195,33 +207,50 @@ void forknet(void)
 
 	if (strcmp(command, "detach") == 0)
 		forkdonetdetach(cur);
+
+	if (strcmp(command, "proxy") == 0)
+		forkdonetproxy(cur);
 }
 */
 import "C"
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/insomniacslk/dhcp/dhcpv4"
 	"github.com/insomniacslk/dhcp/dhcpv4/nclient4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/nclient6"
+	"github.com/insomniacslk/dhcp/iana"
 	"github.com/spf13/cobra"
 
 	"github.com/lxc/incus/v6/internal/netutils"
 	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/internal/server/network/resolvconf"
 	_ "github.com/lxc/incus/v6/shared/cgo" // Used by cgo
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/lxc/incus/v6/shared/util"
 )
 
+// icmpv6TypeRouterAdvertisement is the ICMPv6 message type of a router advertisement, used by
+// waitRouterAdvertisement to pick RA packets out of the raw ICMPv6 socket.
+const icmpv6TypeRouterAdvertisement = 134
+
 type cmdForknet struct {
 	global *cmdGlobal
+
+	flagForce bool
 }
 
 func (c *cmdForknet) Command() *cobra.Command {
@@ -253,11 +282,26 @@ func (c *cmdForknet) Command() *cobra.Command {
 
 	// dhclient
 	cmdDHCP := &cobra.Command{}
-	cmdDHCP.Use = "dhcp <path>"
-	cmdDHCP.Args = cobra.ExactArgs(1)
+	cmdDHCP.Use = "dhcp <path> <iface>"
+	cmdDHCP.Args = cobra.ExactArgs(2)
 	cmdDHCP.RunE = c.RunDHCP
+	cmdDHCP.Flags().BoolVar(&c.flagForce, "force", false, "Overwrite resolv.conf even if it was modified outside Incus since the last write")
 	cmd.AddCommand(cmdDHCP)
 
+	// dhcp6
+	cmdDHCP6 := &cobra.Command{}
+	cmdDHCP6.Use = "dhcp6 <path>"
+	cmdDHCP6.Args = cobra.ExactArgs(1)
+	cmdDHCP6.RunE = c.RunDHCP6
+	cmd.AddCommand(cmdDHCP6)
+
+	// proxy
+	cmdProxy := &cobra.Command{}
+	cmdProxy.Use = "proxy <netns file> <protocol> <listen addr> <connect addr> <stats path> <project> <instance> <device>"
+	cmdProxy.Args = cobra.ExactArgs(8)
+	cmdProxy.RunE = c.RunProxy
+	cmd.AddCommand(cmdProxy)
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -281,14 +325,315 @@ func (c *cmdForknet) RunInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// RunDHCP runs a one time DHCPv4 client and applies address, route and DNS configuration.
+// dhcpLeaseState is the on-disk record of a DHCPv4 lease, persisted as dhcp.leases under the
+// container's state dir so a restarted "forknet dhcp" resumes mid-lease (unicast renew, broadcast
+// rebind, or a fresh DISCOVER, whichever RFC 2131 §4.4.5 phase the lease is in) instead of always
+// re-DISCOVERing from scratch. The MTU/NTPServers/WPADURL fields duplicate what's already in Ack,
+// but are kept alongside it so that something showing the lease's state (e.g. `incus config show`)
+// doesn't need to decode the wire-format ACK itself.
+type dhcpLeaseState struct {
+	Iface      string    `json:"iface"`
+	Xid        uint32    `json:"xid"`
+	ServerID   string    `json:"server_id"`
+	Ack        []byte    `json:"ack"` // Wire-format DHCPACK, so client.Renew/Rebind/Release can be replayed across restarts.
+	RenewAt    time.Time `json:"renew_at"`
+	RebindAt   time.Time `json:"rebind_at"`
+	ExpireAt   time.Time `json:"expire_at"`
+	MTU        uint16    `json:"mtu,omitempty"`
+	NTPServers []string  `json:"ntp_servers,omitempty"`
+	WPADURL    string    `json:"wpad_url,omitempty"`
+}
+
+// loadDHCPLeaseState reads a previously persisted lease for iface, or returns nil if there isn't
+// one (or it belongs to a different interface, or has already expired).
+func loadDHCPLeaseState(path string, iface string) *dhcpLeaseState {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var state dhcpLeaseState
+
+	err = json.Unmarshal(bb, &state)
+	if err != nil || state.Iface != iface || !time.Now().Before(state.ExpireAt) {
+		return nil
+	}
+
+	return &state
+}
+
+// save persists state to path, so a restarted process can resume this lease.
+func (state *dhcpLeaseState) save(path string) error {
+	bb, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bb, 0o600)
+}
+
+// dhcpLeaseStateFromAck derives the persisted lease state from a DHCPACK.
+func dhcpLeaseStateFromAck(iface string, ack *dhcpv4.DHCPv4) dhcpLeaseState {
+	now := time.Now()
+	lease := ack.IPAddressLeaseTime(time.Hour)
+
+	return dhcpLeaseState{
+		Iface:      iface,
+		Xid:        binary.BigEndian.Uint32(ack.TransactionID[:]),
+		ServerID:   ack.ServerIdentifier().String(),
+		Ack:        ack.ToBytes(),
+		RenewAt:    now.Add(ack.IPAddressRenewalTime(lease / 2)),
+		RebindAt:   now.Add(ack.IPAddressRebindingTime(lease * 7 / 8)),
+		ExpireAt:   now.Add(lease),
+		MTU:        dhcpInterfaceMTU(ack),
+		NTPServers: dhcpIPv4ListOption(ack, dhcpv4.OptionNTPServers),
+		WPADURL:    string(ack.Options.Get(dhcpWPADOption)),
+	}
+}
+
+// dhcpWPADOption is option 252 (the WPAD proxy auto-config URL), which never received an IANA
+// assignment and so has no named constant in the dhcpv4 package.
+var dhcpWPADOption = dhcpv4.GenericOptionCode(252)
+
+// dhcpInterfaceMTU decodes option 26 (Interface MTU) from ack, returning 0 if the server didn't
+// send one.
+func dhcpInterfaceMTU(ack *dhcpv4.DHCPv4) uint16 {
+	raw := ack.Options.Get(dhcpv4.OptionInterfaceMTU)
+	if len(raw) != 2 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint16(raw)
+}
+
+// dhcpIPv4ListOption decodes a DHCP option whose value is one or more consecutive 4 byte IPv4
+// addresses (e.g. option 42/NTP servers, the same wire format as option 6/DNS or option 3/Router).
+func dhcpIPv4ListOption(ack *dhcpv4.DHCPv4, code dhcpv4.OptionCode) []string {
+	raw := ack.Options.Get(code)
+
+	var addrs []string
+
+	for len(raw) >= net.IPv4len {
+		addrs = append(addrs, net.IP(raw[:net.IPv4len]).String())
+		raw = raw[net.IPv4len:]
+	}
+
+	return addrs
+}
+
+// dhcpBackoff returns how long to wait before the next retransmission of attempt (0-based),
+// doubling from a 4s base up to a 64s cap and adding up to 1s of jitter, per the retransmission
+// algorithm described in RFC 2131 §4.1 (reused here for the §4.4.5 renew/rebind retries too).
+func dhcpBackoff(attempt int) time.Duration {
+	base := 4 * time.Second
+
+	for i := 0; i < attempt && base < 64*time.Second; i++ {
+		base *= 2
+	}
+
+	if base > 64*time.Second {
+		base = 64 * time.Second
+	}
+
+	return base + time.Duration(rand.Int63n(int64(time.Second)))
+}
+
+// installDHCPLease applies ack's address, MTU, default route and static routes to iface.
+func installDHCPLease(iface string, ack *dhcpv4.DHCPv4) error {
+	netMask, _ := ack.SubnetMask().Size()
+
+	addr := &ip.Addr{
+		DevName: iface,
+		Address: fmt.Sprintf("%s/%d", ack.YourIPAddr, netMask),
+		Family:  ip.FamilyV4,
+	}
+
+	err := addr.Add()
+	if err != nil {
+		return fmt.Errorf("Couldn't add IP to %q: %w", iface, err)
+	}
+
+	mtu := dhcpInterfaceMTU(ack)
+	if mtu > 0 {
+		link := &ip.Link{Name: iface}
+
+		err = link.SetMTU(uint32(mtu))
+		if err != nil {
+			return fmt.Errorf("Couldn't set MTU on %q: %w", iface, err)
+		}
+	}
+
+	if len(ack.Router()) > 0 {
+		route := &ip.Route{
+			DevName: iface,
+			Route:   "default",
+			Via:     ack.Router()[0].String(),
+			Family:  ip.FamilyV4,
+		}
+
+		err = route.Add()
+		if err != nil {
+			return fmt.Errorf("Couldn't add default route to %q: %w", iface, err)
+		}
+	}
+
+	for _, staticRoute := range dhcpStaticRoutes(ack) {
+		route := &ip.Route{
+			DevName: iface,
+			Route:   staticRoute.dest,
+			Via:     staticRoute.router,
+			Family:  ip.FamilyV4,
+		}
+
+		err = route.Add()
+		if err != nil {
+			return fmt.Errorf("Couldn't add static route to %q: %w", iface, err)
+		}
+	}
+
+	return nil
+}
+
+// dhcpStaticRoute is a single destination/gateway pair, parsed from either option 121 (classless
+// static routes) or option 33 (legacy classful static routes).
+type dhcpStaticRoute struct {
+	dest   string
+	router string
+}
+
+// dhcpStaticRoutes returns ack's static routes, preferring option 121 (classless static routes)
+// over option 33 (legacy classful static routes) when both are present, per RFC 3442's instruction
+// that a client understanding option 121 MUST ignore option 33 if option 121 is present.
+func dhcpStaticRoutes(ack *dhcpv4.DHCPv4) []dhcpStaticRoute {
+	classless := ack.ClasslessStaticRoute()
+	if len(classless) > 0 {
+		routes := make([]dhcpStaticRoute, 0, len(classless))
+		for _, route := range classless {
+			routes = append(routes, dhcpStaticRoute{dest: route.Dest.String(), router: route.Router.String()})
+		}
+
+		return routes
+	}
+
+	return dhcpClassfulStaticRoutes(ack)
+}
+
+// dhcpClassfulStaticRoutes decodes option 33 (Static Routes), a sequence of 4 byte destination / 4
+// byte gateway pairs with no explicit netmask, so the destination's classful mask (per the historic
+// class A/B/C rules) is inferred instead.
+func dhcpClassfulStaticRoutes(ack *dhcpv4.DHCPv4) []dhcpStaticRoute {
+	raw := ack.Options.Get(dhcpv4.OptionStaticRoutingTable)
+
+	var routes []dhcpStaticRoute
+
+	for len(raw) >= 2*net.IPv4len {
+		dest := net.IP(raw[:net.IPv4len])
+		router := net.IP(raw[net.IPv4len : 2*net.IPv4len])
+		raw = raw[2*net.IPv4len:]
+
+		ones := classfulNetmaskBits(dest)
+
+		routes = append(routes, dhcpStaticRoute{
+			dest:   fmt.Sprintf("%s/%d", dest, ones),
+			router: router.String(),
+		})
+	}
+
+	return routes
+}
+
+// classfulNetmaskBits infers the historic class A/B/C netmask width for an IPv4 address with no
+// explicit mask of its own, the same inference option 33's classful routes rely on.
+func classfulNetmaskBits(addr net.IP) int {
+	switch {
+	case addr[0] < 128:
+		return 8
+	case addr[0] < 192:
+		return 16
+	default:
+		return 24
+	}
+}
+
+// removeDHCPLease undoes installDHCPLease, flushing every address this lease added to iface.
+func removeDHCPLease(iface string) error {
+	addr := &ip.Addr{DevName: iface}
+	return addr.Flush()
+}
+
+// applyDHCPResolvConf merges ack's DNS servers and search domain into the resolv.conf at
+// resolvPath through the resolvconf manager, rather than truncating and rewriting it from
+// scratch, so admin-added lines (marked with resolvconf.AddUserLine) survive every lease refresh.
+func applyDHCPResolvConf(resolvPath string, stateDir string, ack *dhcpv4.DHCPv4, force bool) error {
+	file, err := resolvconf.Parse(resolvPath)
+	if err != nil {
+		return err
+	}
+
+	nameservers := make([]string, 0, len(ack.DNS()))
+	for _, nameserver := range ack.DNS() {
+		nameservers = append(nameservers, nameserver.String())
+	}
+
+	var search []string
+
+	if ack.DomainSearch() != nil && len(ack.DomainSearch().Labels) > 0 {
+		search = ack.DomainSearch().Labels
+	} else if ack.DomainName() != "" {
+		search = []string{ack.DomainName()}
+	}
+
+	file.Update(nameservers, search, true)
+
+	return file.Write(resolvPath, stateDir, force)
+}
+
+// writeDHCPExtraFiles writes ntp.conf and timesyncd.conf.d/dhcp.conf snippets for ack's NTP servers
+// (option 42) under stateDir, the same "write under the state dir, not the host path directly"
+// convention applyDHCPResolvConf uses for resolv.conf, so that a separate incus-agent process
+// running inside the instance can pick these up and apply them itself. Nothing is written if the
+// server didn't send any NTP servers.
+func writeDHCPExtraFiles(stateDir string, ack *dhcpv4.DHCPv4) error {
+	servers := dhcpIPv4ListOption(ack, dhcpv4.OptionNTPServers)
+	if len(servers) == 0 {
+		return nil
+	}
+
+	var ntpConf strings.Builder
+	for _, server := range servers {
+		ntpConf.WriteString(fmt.Sprintf("server %s iburst\n", server))
+	}
+
+	err := os.WriteFile(filepath.Join(stateDir, "ntp.conf"), []byte(ntpConf.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	timesyncdDir := filepath.Join(stateDir, "timesyncd.conf.d")
+
+	err = os.MkdirAll(timesyncdDir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	timesyncdConf := fmt.Sprintf("[Time]\nNTP=%s\n", strings.Join(servers, " "))
+
+	return os.WriteFile(filepath.Join(timesyncdDir, "dhcp.conf"), []byte(timesyncdConf), 0o644)
+}
+
+// RunDHCP runs a DHCPv4 client against iface and keeps its lease current for as long as the
+// process runs: T1 drives a unicast renew, a renew that goes unanswered past T2 falls back to a
+// broadcast rebind, and a rebind that goes unanswered past lease expiry falls back to a fresh
+// DISCOVER, with jittered exponential backoff between retries at every stage (RFC 2131 §4.4.5). A
+// SIGTERM sends a DHCPRELEASE and removes the address this process installed. Lease state is kept
+// in dhcp.leases under the state dir so a restarted process resumes the same lease instead of
+// always re-DISCOVERing.
 func (c *cmdForknet) RunDHCP(cmd *cobra.Command, args []string) error {
-	iface := "eth0"
+	statePath := args[0]
+	iface := args[1]
 
 	// Bring the interface up.
-	link := &ip.Link{
-		Name: iface,
-	}
+	link := &ip.Link{Name: iface}
 
 	err := link.SetUp()
 	if err != nil {
@@ -297,14 +642,24 @@ func (c *cmdForknet) RunDHCP(cmd *cobra.Command, args []string) error {
 	}
 
 	// Read the hostname.
-	bb, err := os.ReadFile(filepath.Join(args[0], "hostname"))
+	bb, err := os.ReadFile(filepath.Join(statePath, "hostname"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to read hostname file: %v\n", err)
 	}
 
 	hostname := strings.TrimSpace(string(bb))
+	modifiers := []dhcpv4.Modifier{
+		dhcpv4.WithOption(dhcpv4.OptHostName(hostname)),
+		dhcpv4.WithRequestedOptions(
+			dhcpv4.OptionInterfaceMTU,
+			dhcpv4.OptionNTPServers,
+			dhcpv4.OptionStaticRoutingTable,
+			dhcpWPADOption,
+			dhcpv4.OptionDNSDomainSearchList,
+			dhcpv4.OptionIPAddressLeaseTime,
+		),
+	}
 
-	// Try to get a lease.
 	client, err := nclient4.New(iface)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't set up client for %q: %v\n", iface, err)
@@ -313,122 +668,425 @@ func (c *cmdForknet) RunDHCP(cmd *cobra.Command, args []string) error {
 
 	defer func() { _ = client.Close() }()
 
-	lease, err := client.Request(context.Background(), dhcpv4.WithOption(dhcpv4.OptHostName(hostname)))
+	leasePath := filepath.Join(statePath, "dhcp.leases")
+	resolvPath := filepath.Join(statePath, "resolv.conf")
+
+	var ack *dhcpv4.DHCPv4
+
+	state := loadDHCPLeaseState(leasePath, iface)
+	if state != nil {
+		ack, err = dhcpv4.FromBytes(state.Ack)
+		if err != nil {
+			state = nil
+		}
+	}
+
+	if state == nil {
+		lease, err := client.Request(context.Background(), modifiers...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't get a lease on %q (%q): %v\n", iface, hostname, err)
+			return nil
+		}
+
+		ack = lease.Offer
+		if ack == nil || ack.YourIPAddr == nil || ack.YourIPAddr.Equal(net.IPv4zero) || ack.SubnetMask() == nil {
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, lease for %q didn't contain required fields\n", iface)
+			return nil
+		}
+
+		newState := dhcpLeaseStateFromAck(iface, ack)
+		state = &newState
+
+		err = installDHCPLease(iface, ack)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, %v\n", err)
+			return nil
+		}
+	}
+
+	err = applyDHCPResolvConf(resolvPath, statePath, ack, c.flagForce)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't get a lease on %q (%q): %v\n", iface, hostname, err)
+		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
 		return nil
 	}
 
-	// Parse the response.
-	if lease.Offer == nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't get a lease on %q after 5s\n", iface)
+	err = writeDHCPExtraFiles(statePath, ack)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't write NTP configuration: %v\n", err)
 		return nil
 	}
 
-	if lease.Offer.YourIPAddr == nil || lease.Offer.YourIPAddr.Equal(net.IPv4zero) || lease.Offer.SubnetMask() == nil || len(lease.Offer.Router()) != 1 || len(lease.Offer.DNS()) < 1 {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, lease for %q didn't contain required fields\n", iface)
+	err = state.save(leasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't persist lease state: %v\n", err)
 		return nil
 	}
 
-	// DNS configuration.
-	f, err := os.Create(filepath.Join(args[0], "resolv.conf"))
+	// Create PID file.
+	err = os.WriteFile(filepath.Join(statePath, "dhcp.pid"), []byte(fmt.Sprintf("%d", os.Getpid())), 0o644)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't write PID file: %v\n", err)
 		return nil
 	}
 
-	defer f.Close()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM)
 
-	for _, nameserver := range lease.Offer.DNS() {
-		_, err = f.Write([]byte(fmt.Sprintf("nameserver %s\n", nameserver)))
+	// Handle DHCP renewal, rebinding and release.
+	for {
+		var wait time.Duration
+
+		if now := time.Now(); now.Before(state.RenewAt) {
+			wait = state.RenewAt.Sub(now)
+		}
+
+		select {
+		case <-sigCh:
+			releaseErr := client.Release(context.Background(), &nclient4.Lease{Offer: ack})
+			if releaseErr != nil {
+				fmt.Fprintf(os.Stderr, "Couldn't send DHCPRELEASE for %q: %v\n", iface, releaseErr)
+			}
+
+			_ = removeDHCPLease(iface)
+			_ = os.Remove(leasePath)
+
+			return nil
+		case <-time.After(wait):
+		}
+
+		var newLease *nclient4.Lease
+
+		switch {
+		case time.Now().Before(state.RebindAt):
+			// T1: try a unicast renew with the original server, retrying with backoff until T2.
+			for attempt := 0; newLease == nil && time.Now().Before(state.RebindAt); attempt++ {
+				newLease, err = client.Renew(context.Background(), &nclient4.Lease{Offer: ack}, modifiers...)
+				if err != nil {
+					newLease = nil
+					time.Sleep(dhcpBackoff(attempt))
+				}
+			}
+		case time.Now().Before(state.ExpireAt):
+			// T2: no renew landed in time, so broadcast a rebind to any server until the lease expires.
+			for attempt := 0; newLease == nil && time.Now().Before(state.ExpireAt); attempt++ {
+				newLease, err = client.Rebind(context.Background(), &nclient4.Lease{Offer: ack}, modifiers...)
+				if err != nil {
+					newLease = nil
+					time.Sleep(dhcpBackoff(attempt))
+				}
+			}
+		}
+
+		if newLease != nil && newLease.Offer != nil {
+			ack = newLease.Offer
+
+			err = applyDHCPResolvConf(resolvPath, statePath, ack, c.flagForce)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
+				return nil
+			}
+
+			err = writeDHCPExtraFiles(statePath, ack)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't write NTP configuration: %v\n", err)
+				return nil
+			}
+
+			newState := dhcpLeaseStateFromAck(iface, ack)
+			state = &newState
+
+			err = state.save(leasePath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't persist lease state: %v\n", err)
+				return nil
+			}
+
+			continue
+		}
+
+		// The renew window closed without an answer but the lease is still valid: go round again
+		// so the rebind phase runs until the lease expires.
+		if time.Now().Before(state.ExpireAt) {
+			continue
+		}
+
+		// The lease expired with no renew or rebind accepted: drop what we installed and start over
+		// with a fresh DISCOVER rather than leaving the container permanently without networking.
+		fmt.Fprintf(os.Stderr, "Lease for %q expired without a renew or rebind, restarting with DISCOVER\n", iface)
+
+		_ = removeDHCPLease(iface)
+
+		lease, err := client.Request(context.Background(), modifiers...)
+		for attempt := 0; err != nil; attempt++ {
+			fmt.Fprintf(os.Stderr, "Couldn't get a new lease on %q, retrying: %v\n", iface, err)
+			time.Sleep(dhcpBackoff(attempt))
+			lease, err = client.Request(context.Background(), modifiers...)
+		}
+
+		ack = lease.Offer
+
+		err = installDHCPLease(iface, ack)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, %v\n", err)
 			return nil
 		}
-	}
 
-	if lease.Offer.DomainName() != "" {
-		_, err = f.Write([]byte(fmt.Sprintf("domain %s\n", lease.Offer.DomainName())))
+		err = applyDHCPResolvConf(resolvPath, statePath, ack, c.flagForce)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
 			return nil
 		}
-	}
 
-	if lease.Offer.DomainSearch() != nil && len(lease.Offer.DomainSearch().Labels) > 0 {
-		_, err = f.Write([]byte(fmt.Sprintf("search %s\n", strings.Join(lease.Offer.DomainSearch().Labels, ", "))))
+		err = writeDHCPExtraFiles(statePath, ack)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't prepare resolv.conf: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't write NTP configuration: %v\n", err)
+			return nil
+		}
+
+		newState := dhcpLeaseStateFromAck(iface, ack)
+		state = &newState
+
+		err = state.save(leasePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't persist lease state: %v\n", err)
 			return nil
 		}
 	}
+}
 
-	// Network configuration.
-	netMask, _ := lease.Offer.SubnetMask().Size()
+// RunDHCP6 runs a DHCPv6 client, applies address, delegated prefix and DNS configuration, and
+// then keeps renewing (or rebinding) the lease for as long as the process runs. It first listens briefly for a router advertisement to tell stateless networks
+// (SLAAC assigns the address itself, and only a DHCPv6 Information-Request is needed for DNS and
+// other options) apart from stateful ones (DHCPv6 also hands out an IA_NA address and/or an IA_PD
+// delegated prefix).
+func (c *cmdForknet) RunDHCP6(cmd *cobra.Command, args []string) error {
+	iface := "eth0"
 
-	addr := &ip.Addr{
-		DevName: iface,
-		Address: fmt.Sprintf("%s/%d", lease.Offer.YourIPAddr, netMask),
-		Family:  ip.FamilyV4,
+	// Bring the interface up.
+	link := &ip.Link{Name: iface}
+
+	err := link.SetUp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't bring up %q\n", iface)
+		return nil
 	}
 
-	err = addr.Add()
+	// Reuse the same DUID across restarts so the server recognizes this client.
+	duid, err := loadOrCreateDUID(filepath.Join(args[0], "dhcp6.duid"), iface)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't add IP to %q\n", iface)
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't set up a DUID: %v\n", err)
 		return nil
 	}
 
-	route := &ip.Route{
-		DevName: iface,
-		Route:   "default",
-		Via:     lease.Offer.Router()[0].String(),
-		Family:  ip.FamilyV4,
+	// Check whether a router advertisement says the network is stateless.
+	stateless, err := waitRouterAdvertisement(5 * time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't listen for router advertisements on %q: %v\n", iface, err)
+		return nil
 	}
 
-	err = route.Add()
+	client, err := nclient6.New(iface, nclient6.WithClientID(duid))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't add default route to %q\n", iface)
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't set up client for %q: %v\n", iface, err)
 		return nil
 	}
 
-	for _, staticRoute := range lease.Offer.ClasslessStaticRoute() {
-		route := &ip.Route{
-			DevName: iface,
-			Route:   staticRoute.Dest.String(),
-			Via:     staticRoute.Router.String(),
-			Family:  ip.FamilyV4,
+	defer func() { _ = client.Close() }()
+
+	var lease *dhcpv6.Message
+
+	if stateless {
+		lease, err = client.InformationRequest(context.Background())
+	} else {
+		lease, err = client.RapidSolicit(context.Background(), dhcpv6.WithIAID(dhcp6IAID(iface)), dhcpv6.WithIAPD([4]byte{}))
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't get a lease on %q: %v\n", iface, err)
+		return nil
+	}
+
+	// DNS configuration.
+	f, err := os.Create(filepath.Join(args[0], "resolv.conf"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't prepare resolv.conf: %v\n", err)
+		return nil
+	}
+
+	defer f.Close()
+
+	for _, nameserver := range lease.Options.DNS() {
+		_, err = f.Write([]byte(fmt.Sprintf("nameserver %s\n", nameserver)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't prepare resolv.conf: %v\n", err)
+			return nil
 		}
+	}
 
-		err = route.Add()
+	domains := lease.Options.DomainSearchList()
+	if domains != nil && len(domains.Labels) > 0 {
+		_, err = f.Write([]byte(fmt.Sprintf("search %s\n", strings.Join(domains.Labels, " "))))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't add classless static route to %q: %v\n", iface, err)
+			fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't prepare resolv.conf: %v\n", err)
 			return nil
 		}
 	}
 
+	if stateless {
+		// SLAAC already configured the address via the kernel; there's nothing left to install.
+		return nil
+	}
+
+	// Install the IA_NA address, if one was handed out.
+	ia := lease.Options.OneIANA()
+	if ia != nil {
+		for _, addr := range ia.Options.Addresses() {
+			ipAddr := &ip.Addr{
+				DevName: iface,
+				Address: fmt.Sprintf("%s/128", addr.IPv6Addr),
+				Family:  ip.FamilyV6,
+			}
+
+			err = ipAddr.Add()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't add IA_NA address to %q: %v\n", iface, err)
+				return nil
+			}
+		}
+	}
+
+	// Install a route for any delegated prefix.
+	iapd := lease.Options.OneIAPD()
+	if iapd != nil {
+		for _, prefix := range iapd.Options.Prefixes() {
+			route := &ip.Route{
+				DevName: iface,
+				Route:   prefix.Prefix.String(),
+				Family:  ip.FamilyV6,
+			}
+
+			err = route.Add()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't add delegated prefix route to %q: %v\n", iface, err)
+				return nil
+			}
+		}
+	}
+
 	// Create PID file.
-	err = os.WriteFile(filepath.Join(args[0], "dhcp.pid"), []byte(fmt.Sprintf("%d", os.Getpid())), 0o644)
+	err = os.WriteFile(filepath.Join(args[0], "dhcp6.pid"), []byte(fmt.Sprintf("%d", os.Getpid())), 0o644)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't write PID file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't write PID file: %v\n", err)
 		return nil
 	}
 
-	// Handle DHCP renewal.
+	// Handle DHCPv6 renewal and rebinding.
 	for {
-		// Wait until it's renewal time.
-		time.Sleep(lease.Offer.IPAddressRenewalTime(time.Minute))
+		time.Sleep(dhcp6RenewTime(ia))
 
-		// Renew the lease.
-		newLease, err := client.Renew(context.Background(), lease, dhcpv4.WithOption(dhcpv4.OptHostName(hostname)))
+		newLease, err := client.Renew(context.Background(), lease)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Giving up on DHCP, couldn't renew the lease for %q\n", iface)
-			return nil
+			// T1 expired without a response from the original server; fall back to rebinding with
+			// any server on the link before giving up entirely.
+			newLease, err = client.Rebind(context.Background(), lease)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Giving up on DHCPv6, couldn't renew or rebind the lease for %q\n", iface)
+				return nil
+			}
 		}
 
 		lease = newLease
+		ia = lease.Options.OneIANA()
 	}
+}
 
-	return nil
+// dhcp6IAID derives a stable 4 byte identity association ID from the interface name, so repeated
+// runs against the same interface ask for the same IA_NA.
+func dhcp6IAID(iface string) [4]byte {
+	var iaid [4]byte
+	copy(iaid[:], iface)
+	return iaid
+}
+
+// dhcp6RenewTime returns how long to wait before renewing ia's lease, falling back to a
+// conservative default if the server didn't set T1.
+func dhcp6RenewTime(ia *dhcpv6.OptIANA) time.Duration {
+	if ia == nil || ia.T1 <= 0 {
+		return time.Minute
+	}
+
+	return ia.T1
+}
+
+// loadOrCreateDUID returns the DUID to identify this client with, generating and persisting a new
+// link-layer DUID under path if one doesn't already exist, so the server keeps recognizing the same
+// client across container restarts.
+func loadOrCreateDUID(path string, iface string) (dhcpv6.DUID, error) {
+	bb, err := os.ReadFile(path)
+	if err == nil {
+		return dhcpv6.DUIDFromBytes(bb)
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	link, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+
+	duid := &dhcpv6.DUIDLLT{
+		HWType:        iana.HWTypeEthernet,
+		Time:          dhcpv6.GetTime(),
+		LinkLayerAddr: link.HardwareAddr,
+	}
+
+	err = os.WriteFile(path, duid.ToBytes(), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return duid, nil
+}
+
+// waitRouterAdvertisement listens on a raw ICMPv6 socket for a router advertisement and reports
+// whether it asks for stateless configuration (M=0, O=1): addresses come from SLAAC and only the
+// other configuration (DNS, etc.) needs to be fetched via DHCPv6. If none arrives before timeout
+// elapses, a stateful DHCPv6 exchange is assumed instead.
+func waitRouterAdvertisement(timeout time.Duration) (bool, error) {
+	conn, err := net.ListenPacket("ip6:ipv6-icmp", "::")
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			// No RA arrived before the deadline; assume a stateful network.
+			return false, nil
+		}
+
+		if n < 6 || buf[0] != icmpv6TypeRouterAdvertisement {
+			continue
+		}
+
+		// Bytes 0-3 are the ICMPv6 type, code and checksum, byte 4 the current hop limit, and
+		// byte 5 the M/O flags.
+		flags := buf[5]
+		managed := flags&0x80 != 0 // M flag: addresses are assigned via DHCPv6.
+		other := flags&0x40 != 0   // O flag: other configuration comes from DHCPv6.
+
+		return !managed && other, nil
+	}
 }
 
 func (c *cmdForknet) RunDetach(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus/v6/internal/server/device"
+)
+
+// udpBatchSize is how many datagrams a single recvmmsg/sendmmsg call handles at once.
+const udpBatchSize = 32
+
+// udpSessionIdleTimeout is how long a client's UDP session is kept around (and its dedicated
+// upstream socket left open) without traffic before it's torn down.
+const udpSessionIdleTimeout = 2 * time.Minute
+
+// proxyStats is this process's view of the byte/connection counters for the proxy device it's
+// running as, persisted to a JSON file incusd reads at Prometheus scrape time (see
+// internal/server/device/proxy_userland.go's userlandProxyCollector). Counters are kept in memory
+// and flushed periodically rather than written on every packet, since fsync-ing per datagram
+// would defeat the point of a zero-copy proxy.
+type proxyStats struct {
+	path string
+
+	project  string
+	instance string
+	device   string
+
+	txBytes     atomic.Int64
+	rxBytes     atomic.Int64
+	connections atomic.Int64
+}
+
+func (s *proxyStats) addConnection() {
+	s.connections.Add(1)
+}
+
+func (s *proxyStats) flush() error {
+	record := device.ProxyStatsRecord{
+		Project:     s.project,
+		Instance:    s.instance,
+		Device:      s.device,
+		TxBytes:     s.txBytes.Load(),
+		RxBytes:     s.rxBytes.Load(),
+		Connections: s.connections.Load(),
+	}
+
+	bb, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, bb, 0o644)
+}
+
+// RunProxy implements the proxy device's engine=userland path: having already entered the
+// instance's network namespace (via the cgo dosetns_file call in forknet's C dispatcher in
+// main_forknet.go),
+// it listens on listenAddr, shuttles bytes to connectAddr using splice(2) for TCP or batched
+// recvmmsg/sendmmsg for UDP, and persists byte/connection counters to statsPath until it receives
+// SIGTERM.
+func (c *cmdForknet) RunProxy(cmd *cobra.Command, args []string) error {
+	protocol := args[1]
+	listenAddr := args[2]
+	connectAddr := args[3]
+	statsPath := args[4]
+
+	stats := &proxyStats{
+		path:     statsPath,
+		project:  args[5],
+		instance: args[6],
+		device:   args[7],
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	flushTicker := time.NewTicker(time.Second)
+	defer flushTicker.Stop()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-flushTicker.C:
+				_ = stats.flush()
+			}
+		}
+	}()
+
+	var err error
+
+	switch protocol {
+	case "udp":
+		err = runUserlandProxyUDP(ctx, listenAddr, connectAddr, stats)
+	default:
+		err = runUserlandProxyTCP(ctx, listenAddr, connectAddr, stats)
+	}
+
+	_ = stats.flush()
+
+	return err
+}
+
+// runUserlandProxyTCP accepts connections on listenAddr and, for each one, dials connectAddr and
+// shuttles bytes between the two with splice(2) via spliceCopy, closing down cleanly once ctx is
+// cancelled.
+func runUserlandProxyTCP(ctx context.Context, listenAddr string, connectAddr string, stats *proxyStats) error {
+	ln, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		tcpConn, ok := conn.(*net.TCPConn)
+		if !ok {
+			_ = conn.Close()
+			continue
+		}
+
+		go handleUserlandProxyTCPConn(tcpConn, connectAddr, stats)
+	}
+}
+
+// handleUserlandProxyTCPConn dials connectAddr for a single accepted conn and pipes bytes in both
+// directions via spliceCopy until either side closes.
+func handleUserlandProxyTCPConn(conn *net.TCPConn, connectAddr string, stats *proxyStats) {
+	defer func() { _ = conn.Close() }()
+
+	upstream, err := net.Dial("tcp", connectAddr)
+	if err != nil {
+		return
+	}
+
+	defer func() { _ = upstream.Close() }()
+
+	upConn, ok := upstream.(*net.TCPConn)
+	if !ok {
+		return
+	}
+
+	clientFile, err := conn.File()
+	if err != nil {
+		return
+	}
+
+	defer func() { _ = clientFile.Close() }()
+
+	upstreamFile, err := upConn.File()
+	if err != nil {
+		return
+	}
+
+	defer func() { _ = upstreamFile.Close() }()
+
+	stats.addConnection()
+
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		n, _ := spliceCopy(upstreamFile, clientFile)
+		stats.txBytes.Add(n)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		n, _ := spliceCopy(clientFile, upstreamFile)
+		stats.rxBytes.Add(n)
+	}()
+
+	wg.Wait()
+}
+
+// spliceCopy copies from src to dst using splice(2) through an intermediate pipe, the standard
+// technique for splicing between two sockets (Linux can't splice directly socket-to-socket).
+// It returns the number of bytes copied once src hits EOF or either side errors.
+func spliceCopy(dst *os.File, src *os.File) (int64, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = pr.Close() }()
+	defer func() { _ = pw.Close() }()
+
+	var total int64
+
+	for {
+		n, err := unix.Splice(int(src.Fd()), nil, int(pw.Fd()), nil, 1<<20, unix.SPLICE_F_MOVE)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+				continue
+			}
+
+			return total, err
+		}
+
+		if n == 0 {
+			return total, nil
+		}
+
+		for n > 0 {
+			w, err := unix.Splice(int(pr.Fd()), nil, int(dst.Fd()), nil, int(n), unix.SPLICE_F_MOVE)
+			if err != nil {
+				if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+					continue
+				}
+
+				return total, err
+			}
+
+			n -= w
+			total += w
+		}
+	}
+}
+
+// udpSession is one client's dedicated upstream socket, so replies from connectAddr are routed
+// back to the right client despite UDP having no notion of a connection on the listen side.
+type udpSession struct {
+	upstream *net.UDPConn
+	lastSeen atomic.Int64 // Unix nanoseconds, read/written without the sessions map lock.
+}
+
+// runUserlandProxyUDP relays datagrams between listenAddr and connectAddr, reading and writing in
+// batches with recvmmsg/sendmmsg (via golang.org/x/net/ipv4's PacketConn.ReadBatch/WriteBatch)
+// instead of one syscall per datagram. Each client gets its own upstream socket so replies can be
+// routed back to it; idle sessions are reaped periodically.
+func runUserlandProxyUDP(ctx context.Context, listenAddr string, connectAddr string, stats *proxyStats) error {
+	front, err := net.ListenPacket("udp", listenAddr)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = front.Close() }()
+
+	cAddr, err := net.ResolveUDPAddr("udp", connectAddr)
+	if err != nil {
+		return err
+	}
+
+	frontPC := ipv4.NewPacketConn(front)
+
+	go func() {
+		<-ctx.Done()
+		_ = front.Close()
+	}()
+
+	var sessions sync.Map
+
+	cleanup := time.NewTicker(udpSessionIdleTimeout / 2)
+	defer cleanup.Stop()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cleanup.C:
+				sessions.Range(func(key, value any) bool {
+					sess, _ := value.(*udpSession)
+					if sess != nil && time.Since(time.Unix(0, sess.lastSeen.Load())) > udpSessionIdleTimeout {
+						_ = sess.upstream.Close()
+						sessions.Delete(key)
+					}
+
+					return true
+				})
+			}
+		}
+	}()
+
+	bufs := make([][]byte, udpBatchSize)
+	msgs := make([]ipv4.Message, udpBatchSize)
+
+	for i := range msgs {
+		bufs[i] = make([]byte, 65507)
+		msgs[i].Buffers = [][]byte{bufs[i]}
+	}
+
+	for {
+		n, err := frontPC.ReadBatch(msgs, 0)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		for i := range n {
+			clientAddr, ok := msgs[i].Addr.(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+
+			data := bufs[i][:msgs[i].N]
+
+			sess := getOrCreateUDPSession(&sessions, clientAddr, cAddr, frontPC, stats)
+			if sess == nil {
+				continue
+			}
+
+			sess.lastSeen.Store(time.Now().UnixNano())
+
+			written, err := sess.upstream.Write(data)
+			if err == nil {
+				stats.txBytes.Add(int64(written))
+			}
+		}
+	}
+}
+
+// getOrCreateUDPSession returns the existing session for clientAddr, or dials a fresh upstream
+// socket and starts relayUserlandProxyUDPReplies for it if this is the first datagram seen from
+// that client.
+func getOrCreateUDPSession(sessions *sync.Map, clientAddr *net.UDPAddr, connectAddr *net.UDPAddr, front *ipv4.PacketConn, stats *proxyStats) *udpSession {
+	key := clientAddr.String()
+
+	if existing, ok := sessions.Load(key); ok {
+		return existing.(*udpSession)
+	}
+
+	upstream, err := net.DialUDP("udp", nil, connectAddr)
+	if err != nil {
+		return nil
+	}
+
+	sess := &udpSession{upstream: upstream}
+	sess.lastSeen.Store(time.Now().UnixNano())
+
+	actual, loaded := sessions.LoadOrStore(key, sess)
+	if loaded {
+		_ = upstream.Close()
+		return actual.(*udpSession)
+	}
+
+	stats.addConnection()
+
+	go relayUserlandProxyUDPReplies(clientAddr, upstream, front, stats)
+
+	return sess
+}
+
+// relayUserlandProxyUDPReplies reads batches of replies from a client's dedicated upstream socket
+// and relays them back to that client through the shared frontend socket, until the upstream
+// socket is closed (by the idle reaper in runUserlandProxyUDP).
+func relayUserlandProxyUDPReplies(clientAddr *net.UDPAddr, upstream *net.UDPConn, front *ipv4.PacketConn, stats *proxyStats) {
+	upstreamPC := ipv4.NewPacketConn(upstream)
+
+	bufs := make([][]byte, udpBatchSize)
+	msgs := make([]ipv4.Message, udpBatchSize)
+
+	for i := range msgs {
+		bufs[i] = make([]byte, 65507)
+		msgs[i].Buffers = [][]byte{bufs[i]}
+	}
+
+	for {
+		n, err := upstreamPC.ReadBatch(msgs, 0)
+		if err != nil {
+			return
+		}
+
+		for i := range n {
+			msgs[i].Addr = clientAddr
+		}
+
+		written, err := front.WriteBatch(msgs[:n], 0)
+		for _, w := range msgs[:written] {
+			stats.rxBytes.Add(int64(w.N))
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package parallel provides a small bounded worker-pool for fanning client-side work out
+// across a fixed number of goroutines, with per-item error propagation and context-based
+// cancellation.
+package parallel
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+)
+
+// DefaultConcurrency is used by callers that don't otherwise size their worker pool.
+const DefaultConcurrency = 10
+
+// Enqueue runs fn for every item in items using at most concurrency worker goroutines,
+// returning one result and one error per item, indexed the same way as items. If ctx is
+// cancelled while items are still queued, the remaining items get ctx.Err() as their error
+// and a zero value result.
+func Enqueue[T any, R any](ctx context.Context, items []T, concurrency int, fn func(context.Context, T) (R, error)) ([]R, []error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	if len(items) == 0 {
+		return results, errs
+	}
+
+	threads := concurrency
+	if threads > len(items) {
+		threads = len(items)
+	}
+
+	if threads < 1 {
+		threads = 1
+	}
+
+	type job struct {
+		index int
+		item  T
+	}
+
+	jobs := make(chan job)
+	done := make(chan struct{})
+
+	for range threads {
+		go func() {
+			for j := range jobs {
+				select {
+				case <-ctx.Done():
+					errs[j.index] = ctx.Err()
+				default:
+					result, err := fn(ctx, j.item)
+					results[j.index] = result
+					errs[j.index] = err
+				}
+			}
+
+			done <- struct{}{}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+
+		for i, item := range items {
+			select {
+			case <-ctx.Done():
+				// Every item from here on was never handed to a worker, so it would
+				// otherwise be left with a nil error indistinguishable from "ran and
+				// returned a zero value". Fill them in before giving up.
+				for j := i; j < len(items); j++ {
+					errs[j] = ctx.Err()
+				}
+
+				return
+			case jobs <- job{index: i, item: item}:
+			}
+		}
+	}()
+
+	for range threads {
+		<-done
+	}
+
+	return results, errs
+}
+
+// ConcurrencyFromEnv returns the concurrency to use by default: the value of the given
+// environment variable if it parses as a positive integer, otherwise fallback.
+func ConcurrencyFromEnv(envVar string, fallback int) int {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fallback
+	}
+
+	return n
+}
+
+// WithInterrupt returns a copy of ctx that's cancelled on SIGINT, along with a cancel
+// function the caller must invoke to stop listening for the signal once done.
+func WithInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt)
+}
@@ -0,0 +1,126 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnqueue(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	results, errs := Enqueue(context.Background(), items, 2, func(_ context.Context, item int) (int, error) {
+		return item * 2, nil
+	})
+
+	for i, item := range items {
+		if errs[i] != nil {
+			t.Errorf("errs[%d] = %v, want nil", i, errs[i])
+		}
+
+		if results[i] != item*2 {
+			t.Errorf("results[%d] = %d, want %d", i, results[i], item*2)
+		}
+	}
+}
+
+func TestEnqueuePropagatesPerItemErrors(t *testing.T) {
+	items := []int{1, 2, 3}
+	boom := errors.New("boom")
+
+	_, errs := Enqueue(context.Background(), items, len(items), func(_ context.Context, item int) (int, error) {
+		if item == 2 {
+			return 0, boom
+		}
+
+		return item, nil
+	})
+
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("errs = %v, want only index 1 to have an error", errs)
+	}
+
+	if !errors.Is(errs[1], boom) {
+		t.Errorf("errs[1] = %v, want %v", errs[1], boom)
+	}
+}
+
+func TestEnqueueEmptyItems(t *testing.T) {
+	results, errs := Enqueue(context.Background(), []int{}, 4, func(_ context.Context, item int) (int, error) {
+		return item, nil
+	})
+
+	if len(results) != 0 || len(errs) != 0 {
+		t.Errorf("Enqueue with no items = (%v, %v), want two empty slices", results, errs)
+	}
+}
+
+func TestEnqueueFillsUndispatchedErrorsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var ran int
+
+	_, errs := Enqueue(ctx, items, 1, func(_ context.Context, item int) (int, error) {
+		ran++
+		if ran == 1 {
+			cancel()
+		}
+
+		return item, nil
+	})
+
+	var gotCtxErr bool
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("errs[%d] = %v, want nil or context.Canceled", i, err)
+		}
+
+		gotCtxErr = true
+	}
+
+	if !gotCtxErr {
+		t.Error("expected at least one undispatched item to be filled in with ctx.Err() after cancellation")
+	}
+}
+
+func TestConcurrencyFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		fallback int
+		want     int
+	}{
+		{name: "unset falls back", value: "", fallback: 7, want: 7},
+		{name: "valid value is used", value: "3", fallback: 7, want: 3},
+		{name: "non-numeric falls back", value: "nope", fallback: 7, want: 7},
+		{name: "non-positive falls back", value: "0", fallback: 7, want: 7},
+	}
+
+	const envVar = "INCUS_TEST_PARALLEL_CONCURRENCY"
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value == "" {
+				t.Setenv(envVar, "")
+			} else {
+				t.Setenv(envVar, tt.value)
+			}
+
+			got := ConcurrencyFromEnv(envVar, tt.fallback)
+			if got != tt.want {
+				t.Errorf("ConcurrencyFromEnv() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,19 @@
 package auth
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"reflect"
+	"regexp"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,10 +22,367 @@ import (
 	"github.com/openfga/go-sdk/credentials"
 
 	"github.com/lxc/incus/v6/internal/server/certificate"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/logger"
 )
 
+// fgaOfflineMode controls how CheckPermission behaves while the OpenFGA server is
+// unreachable, set via the "openfga.offline.mode" configuration key.
+type fgaOfflineMode string
+
+const (
+	// fgaOfflineModeDeny rejects every request while offline. This is the default, and
+	// matches the behavior before offline modes were configurable.
+	fgaOfflineModeDeny fgaOfflineMode = "deny"
+
+	// fgaOfflineModeCached serves the most recently cached decision for a given
+	// (user, object, entitlement) triple while offline, and falls back to denying on a
+	// cache miss.
+	fgaOfflineModeCached fgaOfflineMode = "cached"
+
+	// fgaOfflineModeAllowRead serves cached decisions the same way fgaOfflineModeCached
+	// does, but on a cache miss allows the request through if the entitlement being
+	// checked is read-only, rather than denying it.
+	fgaOfflineModeAllowRead fgaOfflineMode = "allow-read"
+)
+
+// readOnlyEntitlements lists the entitlements considered safe to allow under
+// openfga.offline.mode=allow-read when no cached decision is available for them.
+var readOnlyEntitlements = map[Entitlement]bool{
+	EntitlementCanView:        true,
+	EntitlementCanViewState:   true,
+	EntitlementCanViewMetrics: true,
+	EntitlementCanViewEvents:  true,
+}
+
+const (
+	fgaDecisionCacheMaxEntries  = 8192
+	fgaDecisionCachePositiveTTL = 30 * time.Second
+	fgaDecisionCacheNegativeTTL = 5 * time.Second
+)
+
+// fgaRouteTarget is where a matched fgaChainRule sends a permission check: a terminal
+// allow/deny, or a hand-off to another driver.
+type fgaRouteTarget string
+
+const (
+	// fgaRouteAllow terminates the chain, granting the request without consulting OpenFGA.
+	fgaRouteAllow fgaRouteTarget = "allow"
+
+	// fgaRouteDeny terminates the chain, rejecting the request without consulting OpenFGA.
+	fgaRouteDeny fgaRouteTarget = "deny"
+
+	// fgaRouteTLS delegates the request to the TLS driver.
+	fgaRouteTLS fgaRouteTarget = "tls"
+
+	// fgaRouteFGA delegates the request to this driver's own OpenFGA-backed check. Every
+	// chain implicitly ends with a catch-all rule routing here, so unmatched requests get
+	// the pre-chain behavior rather than being silently denied.
+	fgaRouteFGA fgaRouteTarget = "fga"
+)
+
+// fgaChainRule is one entry of the authorization driver chain: a predicate over the
+// caller's authentication protocol, the object type being checked, and the entitlement
+// being checked (each empty field matches anything), plus where to route a match.
+type fgaChainRule struct {
+	protocol    api.AuthenticationMethod
+	objectType  ObjectType
+	entitlement Entitlement
+	target      fgaRouteTarget
+}
+
+// matches reports whether every non-empty predicate field on the rule matches the given
+// request attributes.
+func (rule fgaChainRule) matches(protocol api.AuthenticationMethod, objectType ObjectType, entitlement Entitlement) bool {
+	if rule.protocol != "" && rule.protocol != protocol {
+		return false
+	}
+
+	if rule.objectType != "" && rule.objectType != objectType {
+		return false
+	}
+
+	if rule.entitlement != "" && rule.entitlement != entitlement {
+		return false
+	}
+
+	return true
+}
+
+// defaultFGADriverChain reproduces the authorizer's behavior from before "openfga.drivers"
+// became configurable: TLS-authenticated callers go to the TLS driver, everything else is
+// checked against OpenFGA.
+func defaultFGADriverChain() []fgaChainRule {
+	return []fgaChainRule{
+		{protocol: api.AuthenticationMethodTLS, target: fgaRouteTLS},
+	}
+}
+
+// parseFGADriverChain parses the optional "openfga.drivers" configuration key into an
+// ordered rule chain. Its value is a ";"-separated list of rules of the form
+// "[field=value[,field=value...]]=>target", matched in order, e.g.:
+//
+//	protocol=tls=>tls;object=server,entitlement=can_edit=>deny;=>fga
+//
+// routes TLS-authenticated callers to the TLS driver, denies edits on the server object
+// outright, and sends everything else on to OpenFGA. Supported match fields are "protocol",
+// "object" and "entitlement"; a rule with no fields before "=>" matches everything.
+// Supported targets are "allow", "deny", "tls" and "fga". A catch-all "=>fga" rule is always
+// appended, so a chain that doesn't mention every case still falls back to OpenFGA rather
+// than denying silently. Returns the default chain if the key is unset.
+func parseFGADriverChain(config map[string]any, key string) ([]fgaChainRule, error) {
+	val, ok := config[key]
+	if !ok || val == nil {
+		return defaultFGADriverChain(), nil
+	}
+
+	valStr, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("Expected a string for configuration key %q, got: %T", key, val)
+	}
+
+	var rules []fgaChainRule
+	for _, ruleStr := range strings.Split(valStr, ";") {
+		ruleStr = strings.TrimSpace(ruleStr)
+		if ruleStr == "" {
+			continue
+		}
+
+		matchPart, targetPart, ok := strings.Cut(ruleStr, "=>")
+		if !ok {
+			return nil, fmt.Errorf("Invalid rule %q for configuration key %q: missing \"=>\"", ruleStr, key)
+		}
+
+		rule := fgaChainRule{target: fgaRouteTarget(strings.TrimSpace(targetPart))}
+		switch rule.target {
+		case fgaRouteAllow, fgaRouteDeny, fgaRouteTLS, fgaRouteFGA:
+		default:
+			return nil, fmt.Errorf("Invalid target %q for configuration key %q", targetPart, key)
+		}
+
+		matchPart = strings.TrimSpace(matchPart)
+		if matchPart != "" {
+			for _, field := range strings.Split(matchPart, ",") {
+				fieldKey, fieldVal, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("Invalid match field %q for configuration key %q: expected \"field=value\"", field, key)
+				}
+
+				fieldVal = strings.TrimSpace(fieldVal)
+				switch strings.TrimSpace(fieldKey) {
+				case "protocol":
+					rule.protocol = api.AuthenticationMethod(fieldVal)
+				case "object":
+					rule.objectType = ObjectType(fieldVal)
+				case "entitlement":
+					rule.entitlement = Entitlement(fieldVal)
+				default:
+					return nil, fmt.Errorf("Unknown match field %q for configuration key %q", fieldKey, key)
+				}
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	// Always fall back to the OpenFGA check itself so a chain that doesn't cover every case
+	// degrades to the pre-chain behavior instead of denying everything unmatched.
+	rules = append(rules, fgaChainRule{target: fgaRouteFGA})
+
+	return rules, nil
+}
+
+// route walks the driver chain and returns the target of the first matching rule. The
+// chain always ends with a catch-all rule, so this never runs off the end.
+func (f *FGA) route(protocol api.AuthenticationMethod, objectType ObjectType, entitlement Entitlement) fgaRouteTarget {
+	for _, rule := range f.driverChain {
+		if rule.matches(protocol, objectType, entitlement) {
+			return rule.target
+		}
+	}
+
+	return fgaRouteFGA
+}
+
+// fgaDecision is a single cached OpenFGA Check result.
+type fgaDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+// fgaDecisionCache is a bounded, LRU-evicted cache of recent OpenFGA Check results keyed
+// by (user, object, entitlement). It is consulted on every check to avoid a round trip to
+// OpenFGA for a still-fresh decision, and serves as the fallback source of truth for
+// openfga.offline.mode=cached/allow-read while the OpenFGA server is unreachable.
+type fgaDecisionCache struct {
+	mu          sync.Mutex
+	elements    map[string]*list.Element
+	order       *list.List
+	byUser      map[string]map[string]bool
+	byObject    map[string]map[string]bool
+	maxEntries  int
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type fgaDecisionCacheEntry struct {
+	key      string
+	user     string
+	object   string
+	decision fgaDecision
+}
+
+func newFGADecisionCache(maxEntries int, positiveTTL time.Duration, negativeTTL time.Duration) *fgaDecisionCache {
+	return &fgaDecisionCache{
+		elements:    map[string]*list.Element{},
+		order:       list.New(),
+		byUser:      map[string]map[string]bool{},
+		byObject:    map[string]map[string]bool{},
+		maxEntries:  maxEntries,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func fgaDecisionCacheKey(user string, object string, entitlement Entitlement) string {
+	return user + "\x00" + object + "\x00" + string(entitlement)
+}
+
+// get returns the cached decision for the given triple and whether it is still fresh
+// (within its TTL). A present-but-stale entry is returned with fresh=false so that
+// offline fallback modes can still use it.
+func (c *fgaDecisionCache) get(user string, object string, entitlement Entitlement) (decision fgaDecision, present bool, fresh bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.elements[fgaDecisionCacheKey(user, object, entitlement)]
+	if !ok {
+		c.misses++
+		return fgaDecision{}, false, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	decision = elem.Value.(*fgaDecisionCacheEntry).decision
+
+	return decision, true, time.Now().Before(decision.expires)
+}
+
+// set records the outcome of a Check call, evicting the least recently used entry if the
+// cache is at capacity.
+func (c *fgaDecisionCache) set(user string, object string, entitlement Entitlement, allowed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.negativeTTL
+	if allowed {
+		ttl = c.positiveTTL
+	}
+
+	key := fgaDecisionCacheKey(user, object, entitlement)
+	decision := fgaDecision{allowed: allowed, expires: time.Now().Add(ttl)}
+
+	elem, ok := c.elements[key]
+	if ok {
+		elem.Value.(*fgaDecisionCacheEntry).decision = decision
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem = c.order.PushFront(&fgaDecisionCacheEntry{key: key, user: user, object: object, decision: decision})
+	c.elements[key] = elem
+	c.indexLocked(key, user, object)
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+
+		c.removeLocked(oldest)
+		c.evictions++
+	}
+}
+
+// indexLocked records key under its user/object in byUser/byObject so invalidate can find it
+// without scanning the whole cache. Must be called with c.mu held.
+func (c *fgaDecisionCache) indexLocked(key string, user string, object string) {
+	if c.byUser[user] == nil {
+		c.byUser[user] = map[string]bool{}
+	}
+
+	c.byUser[user][key] = true
+
+	if c.byObject[object] == nil {
+		c.byObject[object] = map[string]bool{}
+	}
+
+	c.byObject[object][key] = true
+}
+
+// removeLocked drops elem from every index and the LRU list. Must be called with c.mu held.
+func (c *fgaDecisionCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*fgaDecisionCacheEntry)
+
+	c.order.Remove(elem)
+	delete(c.elements, entry.key)
+
+	delete(c.byUser[entry.user], entry.key)
+	if len(c.byUser[entry.user]) == 0 {
+		delete(c.byUser, entry.user)
+	}
+
+	delete(c.byObject[entry.object], entry.key)
+	if len(c.byObject[entry.object]) == 0 {
+		delete(c.byObject, entry.object)
+	}
+}
+
+// invalidate evicts every cached decision involving user as the subject or object as the
+// target, called whenever updateTuples writes a tuple naming either, since a relationship
+// change involving either side can change what that decision should be.
+func (c *fgaDecisionCache) invalidate(user string, object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := map[string]bool{}
+	for key := range c.byUser[user] {
+		keys[key] = true
+	}
+
+	for key := range c.byObject[object] {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		elem, ok := c.elements[key]
+		if ok {
+			c.removeLocked(elem)
+			c.evictions++
+		}
+	}
+}
+
+// stats returns the cache's cumulative hit/miss/eviction counters, exposed as OpenFGA
+// authorizer metrics.
+func (c *fgaDecisionCache) stats() (hits int64, misses int64, evictions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses, c.evictions
+}
+
+// CacheStats returns the decision cache's cumulative hit/miss/eviction counters, for the
+// metrics subsystem to publish as openfga_decision_cache_{hits,misses,evictions}_total.
+func (f *FGA) CacheStats() (hits int64, misses int64, evictions int64) {
+	return f.decisionCache.stats()
+}
+
 // FGA represents an OpenFGA authorizer.
 type FGA struct {
 	commonAuthorizer
@@ -28,6 +392,34 @@ type FGA struct {
 	apiToken string
 	storeID  string
 
+	offlineMode   fgaOfflineMode
+	decisionCache *fgaDecisionCache
+	writeQueue    *fgaWriteQueue
+
+	oidcGroupClaims  []string
+	oidcRoleClaims   []string
+	oidcGroupNameMap *fgaClaimNameMap
+	oidcRoleNameMap  *fgaClaimNameMap
+
+	resourcesFunc func() (*Resources, error)
+
+	// changeTokenFunc/setChangeTokenFunc persist the last OpenFGA store change token
+	// incrementalSyncResources has processed, so a restart can resume from there via
+	// ReadChanges instead of re-running a full projectObjects sweep. Either may be nil, in
+	// which case incrementalSyncResources always falls back to a full sync.
+	changeTokenFunc    func() (string, error)
+	setChangeTokenFunc func(string) error
+
+	driverChain []fgaChainRule
+
+	lastReconcileMu sync.Mutex
+	lastReconcile   *FGAReconcileReport
+
+	writeQueueMu           sync.Mutex
+	writeQueueFailures     int
+	writeQueueFirstFailure time.Time
+	writeQueueNextAttempt  time.Time
+
 	onlineMu sync.Mutex
 	online   bool
 
@@ -72,9 +464,178 @@ func (f *FGA) configure(opts Opts) error {
 		return fmt.Errorf("Expected a string for configuration key %q, got: %T", "openfga.store.id", val)
 	}
 
+	f.offlineMode = fgaOfflineModeDeny
+	val, ok = opts.config["openfga.offline.mode"]
+	if ok && val != nil {
+		modeStr, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("Expected a string for configuration key %q, got: %T", "openfga.offline.mode", val)
+		}
+
+		mode := fgaOfflineMode(modeStr)
+		switch mode {
+		case fgaOfflineModeDeny, fgaOfflineModeCached, fgaOfflineModeAllowRead:
+			f.offlineMode = mode
+		default:
+			return fmt.Errorf("Invalid value %q for configuration key %q", modeStr, "openfga.offline.mode")
+		}
+	}
+
+	cacheMaxEntries, err := fgaConfigInt(opts.config, "openfga.cache.max_entries", fgaDecisionCacheMaxEntries)
+	if err != nil {
+		return err
+	}
+
+	cachePositiveTTL, err := fgaConfigDuration(opts.config, "openfga.cache.ttl", fgaDecisionCachePositiveTTL)
+	if err != nil {
+		return err
+	}
+
+	f.decisionCache = newFGADecisionCache(cacheMaxEntries, cachePositiveTTL, fgaDecisionCacheNegativeTTL)
+
+	f.oidcGroupClaims, err = fgaClaimPaths(opts.config, "openfga.oidc.claims.groups")
+	if err != nil {
+		return err
+	}
+
+	f.oidcRoleClaims, err = fgaClaimPaths(opts.config, "openfga.oidc.claims.roles")
+	if err != nil {
+		return err
+	}
+
+	f.oidcGroupNameMap, err = parseFGAClaimNameMap(opts.config, "openfga.oidc.claims.groups.map")
+	if err != nil {
+		return err
+	}
+
+	f.oidcRoleNameMap, err = parseFGAClaimNameMap(opts.config, "openfga.oidc.claims.roles.map")
+	if err != nil {
+		return err
+	}
+
+	f.driverChain, err = parseFGADriverChain(opts.config, "openfga.drivers")
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// fgaConfigInt parses an optional integer configuration key, returning def if the key is unset.
+func fgaConfigInt(config map[string]any, key string, def int) (int, error) {
+	val, ok := config[key]
+	if !ok || val == nil {
+		return def, nil
+	}
+
+	valStr, ok := val.(string)
+	if !ok {
+		return 0, fmt.Errorf("Expected a string for configuration key %q, got: %T", key, val)
+	}
+
+	parsed, err := strconv.Atoi(valStr)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value %q for configuration key %q", valStr, key)
+	}
+
+	return parsed, nil
+}
+
+// fgaConfigDuration parses an optional duration configuration key (e.g. "30s"), returning
+// def if the key is unset.
+func fgaConfigDuration(config map[string]any, key string, def time.Duration) (time.Duration, error) {
+	val, ok := config[key]
+	if !ok || val == nil {
+		return def, nil
+	}
+
+	valStr, ok := val.(string)
+	if !ok {
+		return 0, fmt.Errorf("Expected a string for configuration key %q, got: %T", key, val)
+	}
+
+	parsed, err := time.ParseDuration(valStr)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value %q for configuration key %q", valStr, key)
+	}
+
+	return parsed, nil
+}
+
+// fgaClaimPaths parses an optional comma-separated configuration key into a list of
+// dot-separated OIDC claim paths, used to project group/role membership out of a token's
+// claims. Returns nil if the key is unset.
+func fgaClaimPaths(config map[string]any, key string) ([]string, error) {
+	val, ok := config[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	valStr, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("Expected a string for configuration key %q, got: %T", key, val)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(valStr, ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// fgaClaimNameMap holds an optional regex-based transformation applied to the raw string
+// values pulled out of an OIDC claim before they become part of a group/role object's name
+// (e.g. turning an IdP's "CN=Admins,OU=Groups,DC=example,DC=com" into "admins").
+type fgaClaimNameMap struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// apply rewrites value using the configured pattern/replacement, or returns it unchanged if
+// no mapping was configured or the pattern doesn't match.
+func (m *fgaClaimNameMap) apply(value string) string {
+	if m == nil || m.pattern == nil {
+		return value
+	}
+
+	if !m.pattern.MatchString(value) {
+		return value
+	}
+
+	return m.pattern.ReplaceAllString(value, m.replacement)
+}
+
+// parseFGAClaimNameMap parses an optional "<regex>=<replacement>" configuration key used to
+// transform claim values into group/role names, e.g. "^CN=([^,]+).*$=${1}" to pull the
+// common name out of a distinguished name. Returns nil if the key is unset.
+func parseFGAClaimNameMap(config map[string]any, key string) (*fgaClaimNameMap, error) {
+	val, ok := config[key]
+	if !ok || val == nil {
+		return nil, nil
+	}
+
+	valStr, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("Expected a string for configuration key %q, got: %T", key, val)
+	}
+
+	pattern, replacement, ok := strings.Cut(valStr, "=")
+	if !ok {
+		return nil, fmt.Errorf("Expected configuration key %q to be of the form \"<regex>=<replacement>\"", key)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regular expression for configuration key %q: %w", key, err)
+	}
+
+	return &fgaClaimNameMap{pattern: re, replacement: replacement}, nil
+}
+
 func (f *FGA) load(ctx context.Context, certificateCache *certificate.Cache, opts Opts) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -108,6 +669,17 @@ func (f *FGA) load(ctx context.Context, certificateCache *certificate.Cache, opt
 
 	f.shutdownCtx, f.shutdownCancel = context.WithCancel(context.Background())
 
+	f.writeQueue = newFGAWriteQueue(internalUtil.VarPath("auth", "openfga_write_queue.json"))
+
+	// Drain the durable write queue in the background, independently of the connection
+	// state, so mutations queued during an outage go out as soon as we're back online.
+	go f.runWriteQueueWorker(f.shutdownCtx)
+
+	// Poll for tuple changes made by other cluster members (which updateTuples' synchronous
+	// invalidation never sees, since that only runs on whichever member issued the write)
+	// and evict any cached decision they touch.
+	go f.runDecisionCacheInvalidator(f.shutdownCtx)
+
 	// Connect in the background.
 	go func(ctx context.Context, certificateCache *certificate.Cache, opts Opts) {
 		first := true
@@ -191,6 +763,33 @@ func (f *FGA) ApplyPatch(ctx context.Context, name string) error {
 	return nil
 }
 
+// fgaModelNeedsUpgrade reports whether the store's current authorization model differs from
+// the one embedded in this build, by comparing type definitions and conditions directly
+// rather than trusting a version number neither side tracks. A mismatch here is what drives
+// connect to push the embedded model to the store, e.g. on first bootstrap or after an Incus
+// upgrade that adds new conditions.
+func fgaModelNeedsUpgrade(current *openfga.AuthorizationModel) (bool, error) {
+	if current == nil {
+		return true, nil
+	}
+
+	var embedded client.ClientWriteAuthorizationModelRequest
+	err := json.Unmarshal([]byte(authModel), &embedded)
+	if err != nil {
+		return false, fmt.Errorf("Failed to unmarshal built in authorization model: %w", err)
+	}
+
+	if !reflect.DeepEqual(current.GetTypeDefinitions(), embedded.TypeDefinitions) {
+		return true, nil
+	}
+
+	if !reflect.DeepEqual(current.GetConditions(), embedded.Conditions) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func (f *FGA) refreshModel(ctx context.Context) error {
 	var builtinAuthorizationModel client.ClientWriteAuthorizationModelRequest
 	err := json.Unmarshal([]byte(authModel), &builtinAuthorizationModel)
@@ -213,16 +812,32 @@ func (f *FGA) connect(ctx context.Context, certificateCache *certificate.Cache,
 		return fmt.Errorf("Failed to read pre-existing OpenFGA model: %w", err)
 	}
 
-	// Check if we need to upload an initial model.
-	if readModelResponse.AuthorizationModel == nil {
-		logger.Info("Upload initial OpenFGA model")
+	bootstrap := readModelResponse.AuthorizationModel == nil
+
+	// Check if we need to upload an initial model, or migrate the store to a newer one this
+	// build of Incus ships (e.g. one adding the non_expired_grant/in_allowed_cidr
+	// conditions), which the model's type definitions/conditions no longer matching the
+	// embedded one is taken as a proxy for rather than tracking an explicit version number.
+	upgrade, err := fgaModelNeedsUpgrade(readModelResponse.AuthorizationModel)
+	if err != nil {
+		return fmt.Errorf("Failed to compare OpenFGA model versions: %w", err)
+	}
+
+	if upgrade {
+		if bootstrap {
+			logger.Info("Upload initial OpenFGA model")
+		} else {
+			logger.Info("Migrating to a newer OpenFGA model")
+		}
 
 		// Upload the model itself.
 		err := f.refreshModel(ctx)
 		if err != nil {
 			return fmt.Errorf("Failed to load initial model: %w", err)
 		}
+	}
 
+	if bootstrap {
 		// Allow basic authenticated access.
 		err = f.sendTuples(ctx, []client.ClientTupleKey{
 			{User: "user:*", Relation: "authenticated", Object: ObjectServer().String()},
@@ -232,7 +847,14 @@ func (f *FGA) connect(ctx context.Context, certificateCache *certificate.Cache,
 		}
 	}
 
+	if opts.changeTokenFunc != nil && opts.setChangeTokenFunc != nil {
+		f.changeTokenFunc = opts.changeTokenFunc
+		f.setChangeTokenFunc = opts.setChangeTokenFunc
+	}
+
 	if opts.resourcesFunc != nil {
+		f.resourcesFunc = opts.resourcesFunc
+
 		// Start resource sync routine.
 		go func(resourcesFunc func() (*Resources, error)) {
 			for {
@@ -240,10 +862,27 @@ func (f *FGA) connect(ctx context.Context, certificateCache *certificate.Cache,
 				if err == nil {
 					// resources will be nil on cluster members that shouldn't be performing updates.
 					if resources != nil {
-						err := f.syncResources(f.shutdownCtx, *resources)
+						err := f.incrementalSyncResources(f.shutdownCtx, *resources)
 						if err != nil {
 							logger.Error("Failed background OpenFGA resource sync", logger.Ctx{"err": err})
 						}
+
+						// Detect tuples that no longer correspond to any local object (e.g. left
+						// behind by a crash mid-delete, or an out-of-band tuple edit) by reading
+						// the raw tuple store rather than trusting ListObjects. This is a dry
+						// run: operators repair the drift explicitly via the reconcile endpoint.
+						report, err := f.reconcile(f.shutdownCtx, *resources, false)
+						if err != nil {
+							logger.Error("Failed OpenFGA tuple drift detection", logger.Ctx{"err": err})
+						} else {
+							f.lastReconcileMu.Lock()
+							f.lastReconcile = report
+							f.lastReconcileMu.Unlock()
+
+							if len(report.ExtraTuples) > 0 || len(report.MissingTuples) > 0 {
+								logger.Warn("Detected drift between local state and OpenFGA tuples", logger.Ctx{"extra": len(report.ExtraTuples), "missing": len(report.MissingTuples)})
+							}
+						}
 					}
 				} else {
 					logger.Error("Failed getting local OpenFGA resources", logger.Ctx{"err": err})
@@ -262,6 +901,24 @@ func (f *FGA) connect(ctx context.Context, certificateCache *certificate.Cache,
 	return nil
 }
 
+// fgaRequestContext builds the ABAC context values conditions in the authorization model
+// evaluate against: the current time, for conditions like non_expired_grant, and the
+// caller's IP address, for conditions like in_allowed_cidr. It's threaded into every
+// Check/ListObjects/BatchCheck call so a conditional tuple actually gets to restrict access
+// rather than only ever matching unconditionally.
+func fgaRequestContext(r *http.Request) map[string]any {
+	clientIP := r.RemoteAddr
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err == nil {
+		clientIP = host
+	}
+
+	return map[string]any{
+		"current_time": time.Now().Format(time.RFC3339),
+		"client_ip":    clientIP,
+	}
+}
+
 // CheckPermission returns an error if the user does not have the given Entitlement on the given Object.
 func (f *FGA) CheckPermission(ctx context.Context, r *http.Request, object Object, entitlement Entitlement) error {
 	logCtx := logger.Ctx{"object": object, "entitlement": entitlement, "url": r.URL.String(), "method": r.Method}
@@ -277,41 +934,172 @@ func (f *FGA) CheckPermission(ctx context.Context, r *http.Request, object Objec
 		return nil
 	}
 
-	// Use the TLS driver if the user authenticated with TLS.
-	if details.authenticationProtocol() == api.AuthenticationMethodTLS {
+	// Route the check according to the configured driver chain (by default, TLS-authenticated
+	// callers go to the TLS driver and everything else is checked against OpenFGA).
+	switch f.route(details.authenticationProtocol(), object.Type(), entitlement) {
+	case fgaRouteAllow:
+		return nil
+	case fgaRouteDeny:
+		return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+	case fgaRouteTLS:
 		return f.tls.CheckPermission(ctx, r, object, entitlement)
 	}
 
-	// If offline, return a clear error to the user.
-	f.onlineMu.Lock()
-	defer f.onlineMu.Unlock()
-	if !f.online {
-		return api.StatusErrorf(http.StatusForbidden, "The authorization server is currently offline, please try again later")
-	}
-
 	username := details.username()
 	logCtx["username"] = username
 	logCtx["protocol"] = details.Protocol
 
-	objectUser := ObjectUser(username)
-	body := client.ClientCheckRequest{
-		User:     objectUser.String(),
-		Relation: string(entitlement),
-		Object:   object.String(),
+	// The caller is represented by their own user object plus, for OIDC-authenticated
+	// requests, whatever group/role objects their token's claims project into. Access is
+	// granted if any of these subjects has the entitlement.
+	subjects := []Object{ObjectUser(username)}
+	if details.authenticationProtocol() == api.AuthenticationMethodOIDC {
+		claims, ok := details.oidcClaims()
+		if ok {
+			subjects = append(subjects, f.oidcClaimObjects(claims)...)
+		}
 	}
 
-	f.logger.Debug("Checking OpenFGA relation", logCtx)
-	resp, err := f.client.Check(ctx).Body(body).Execute()
-	if err != nil {
-		return fmt.Errorf("Failed to check OpenFGA relation: %w", err)
-	}
+	objectStr := object.String()
 
-	if !resp.GetAllowed() {
-		return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
-	}
+	f.onlineMu.Lock()
+	online := f.online
+	f.onlineMu.Unlock()
 
-	return nil
-}
+	deniedErr := api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+
+	for _, subject := range subjects {
+		subjectStr := subject.String()
+
+		// Serve a still-fresh cached decision without involving OpenFGA at all.
+		decision, present, fresh := f.decisionCache.get(subjectStr, objectStr, entitlement)
+		if present && fresh {
+			if decision.allowed {
+				return nil
+			}
+
+			continue
+		}
+
+		// If offline, fall back to the configured offline mode instead of hard denying.
+		if !online {
+			err := f.checkPermissionOffline(object, entitlement, present, decision)
+			if err == nil {
+				return nil
+			}
+
+			continue
+		}
+
+		reqContext := fgaRequestContext(r)
+		body := client.ClientCheckRequest{
+			User:     subjectStr,
+			Relation: string(entitlement),
+			Object:   objectStr,
+			Context:  &reqContext,
+		}
+
+		f.logger.Debug("Checking OpenFGA relation", logCtx)
+		resp, err := f.client.Check(ctx).Body(body).Execute()
+		if err != nil {
+			return fmt.Errorf("Failed to check OpenFGA relation: %w", err)
+		}
+
+		allowed := resp.GetAllowed()
+		f.decisionCache.set(subjectStr, objectStr, entitlement, allowed)
+
+		if allowed {
+			return nil
+		}
+	}
+
+	return deniedErr
+}
+
+// oidcClaimObjects projects the configured claim paths out of an OIDC token's claims into
+// the ObjectGroup/ObjectRole identities that represent the caller for FGA purposes, so an
+// operator only has to maintain a handful of group/role tuples rather than one per human.
+func (f *FGA) oidcClaimObjects(claims map[string]any) []Object {
+	var objects []Object
+
+	for _, path := range f.oidcGroupClaims {
+		for _, value := range claimPathValues(claims, path) {
+			objects = append(objects, ObjectGroup(f.oidcGroupNameMap.apply(value)))
+		}
+	}
+
+	for _, path := range f.oidcRoleClaims {
+		for _, value := range claimPathValues(claims, path) {
+			objects = append(objects, ObjectRole(f.oidcRoleNameMap.apply(value)))
+		}
+	}
+
+	return objects
+}
+
+// claimPathValues resolves a dot-separated claim path (e.g. "realm_access.roles") against
+// a decoded OIDC claims map, returning every string value found there whether the leaf is
+// a single string or a list of strings.
+func claimPathValues(claims map[string]any, path string) []string {
+	var cur any = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		cur, ok = m[part]
+		if !ok {
+			return nil
+		}
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// checkPermissionOffline applies the configured openfga.offline.mode when the OpenFGA
+// server is unreachable and no still-fresh cached decision was found.
+func (f *FGA) checkPermissionOffline(object Object, entitlement Entitlement, cachedPresent bool, cached fgaDecision) error {
+	switch f.offlineMode {
+	case fgaOfflineModeCached:
+		if cachedPresent {
+			if !cached.allowed {
+				return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+			}
+
+			return nil
+		}
+	case fgaOfflineModeAllowRead:
+		if cachedPresent {
+			if !cached.allowed {
+				return api.StatusErrorf(http.StatusForbidden, "User does not have entitlement %q on object %q", entitlement, object)
+			}
+
+			return nil
+		}
+
+		if readOnlyEntitlements[entitlement] {
+			return nil
+		}
+	}
+
+	return api.StatusErrorf(http.StatusForbidden, "The authorization server is currently offline, please try again later")
+}
 
 // GetPermissionChecker returns a function that can be used to check whether a user has the required entitlement on an authorization object.
 func (f *FGA) GetPermissionChecker(ctx context.Context, r *http.Request, entitlement Entitlement, objectType ObjectType) (PermissionChecker, error) {
@@ -331,8 +1119,14 @@ func (f *FGA) GetPermissionChecker(ctx context.Context, r *http.Request, entitle
 		return allowFunc(true), nil
 	}
 
-	// Use the TLS driver if the user authenticated with TLS.
-	if details.authenticationProtocol() == api.AuthenticationMethodTLS {
+	// Route the check according to the configured driver chain (by default, TLS-authenticated
+	// callers go to the TLS driver and everything else is checked against OpenFGA).
+	switch f.route(details.authenticationProtocol(), objectType, entitlement) {
+	case fgaRouteAllow:
+		return allowFunc(true), nil
+	case fgaRouteDeny:
+		return allowFunc(false), nil
+	case fgaRouteTLS:
 		return f.tls.GetPermissionChecker(ctx, r, entitlement, objectType)
 	}
 
@@ -340,38 +1134,368 @@ func (f *FGA) GetPermissionChecker(ctx context.Context, r *http.Request, entitle
 	logCtx["username"] = username
 	logCtx["protocol"] = details.Protocol
 
-	f.logger.Debug("Listing related objects for user", logCtx)
-	resp, err := f.client.ListObjects(ctx).Body(client.ClientListObjectsRequest{
-		User:     ObjectUser(username).String(),
-		Relation: string(entitlement),
-		Type:     string(objectType),
-	}).Execute()
+	subjects := []Object{ObjectUser(username)}
+	if details.authenticationProtocol() == api.AuthenticationMethodOIDC {
+		claims, ok := details.oidcClaims()
+		if ok {
+			subjects = append(subjects, f.oidcClaimObjects(claims)...)
+		}
+	}
+
+	reqContext := fgaRequestContext(r)
+	objectSet := map[string]bool{}
+	for _, subject := range subjects {
+		f.logger.Debug("Listing related objects for user", logCtx)
+		resp, err := f.client.ListObjects(ctx).Body(client.ClientListObjectsRequest{
+			User:     subject.String(),
+			Relation: string(entitlement),
+			Type:     string(objectType),
+			Context:  &reqContext,
+		}).Execute()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to OpenFGA objects of type %q with relation %q for user %q: %w", objectType, entitlement, username, err)
+		}
+
+		for _, obj := range resp.GetObjects() {
+			objectSet[obj] = true
+		}
+	}
+
+	return func(object Object) bool {
+		return objectSet[object.String()]
+	}, nil
+}
+
+// fgaBatchCheckChunkSize bounds the number of checks sent in a single OpenFGA BatchCheck
+// call, matching the chunking already used for tuple writes in sendTuples.
+const fgaBatchCheckChunkSize = 50
+
+// GetPermissionCheckerForObjects returns a function that can be used to check whether a
+// user has the required entitlement on each of the given objects. Unlike
+// GetPermissionChecker, it doesn't ask OpenFGA to enumerate every object of objectType the
+// caller can access via ListObjects; instead it issues a BatchCheck against exactly the
+// candidate objects a list handler already fetched, which is cheaper once the number of
+// objects of that type the caller can see is large.
+func (f *FGA) GetPermissionCheckerForObjects(ctx context.Context, r *http.Request, entitlement Entitlement, objects []Object) (PermissionChecker, error) {
+	allowFunc := func(b bool) func(Object) bool {
+		return func(Object) bool {
+			return b
+		}
+	}
+
+	logCtx := logger.Ctx{"entitlement": entitlement, "url": r.URL.String(), "method": r.Method}
+	details, err := f.requestDetails(r)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to OpenFGA objects of type %q with relation %q for user %q: %w", objectType, entitlement, username, err)
+		return nil, api.StatusErrorf(http.StatusForbidden, "Failed to extract request details: %v", err)
+	}
+
+	if details.isInternalOrUnix() {
+		return allowFunc(true), nil
+	}
+
+	// Use the TLS driver if the user authenticated with TLS.
+	if details.authenticationProtocol() == api.AuthenticationMethodTLS {
+		return f.tls.GetPermissionCheckerForObjects(ctx, r, entitlement, objects)
+	}
+
+	username := details.username()
+	logCtx["username"] = username
+	logCtx["protocol"] = details.Protocol
+
+	subjects := []Object{ObjectUser(username)}
+	if details.authenticationProtocol() == api.AuthenticationMethodOIDC {
+		claims, ok := details.oidcClaims()
+		if ok {
+			subjects = append(subjects, f.oidcClaimObjects(claims)...)
+		}
 	}
 
-	objects := resp.GetObjects()
+	reqContext := fgaRequestContext(r)
+	objectSet := map[string]bool{}
+	remaining := objects
+
+	for _, subject := range subjects {
+		if len(remaining) == 0 {
+			break
+		}
+
+		var checked []Object
+		for chunkStart := 0; chunkStart < len(remaining); chunkStart += fgaBatchCheckChunkSize {
+			chunkEnd := min(chunkStart+fgaBatchCheckChunkSize, len(remaining))
+			chunk := remaining[chunkStart:chunkEnd]
+
+			checks := make([]client.ClientBatchCheckItem, len(chunk))
+			for i, object := range chunk {
+				checks[i] = client.ClientBatchCheckItem{
+					User:          subject.String(),
+					Relation:      string(entitlement),
+					Object:        object.String(),
+					Context:       &reqContext,
+					CorrelationId: strconv.Itoa(i),
+				}
+			}
+
+			f.logger.Debug("Batch checking OpenFGA relation", logCtx)
+			resp, err := f.client.BatchCheck(ctx).Body(client.ClientBatchCheckRequest{Checks: checks}).Execute()
+			if err != nil {
+				return nil, fmt.Errorf("Failed to batch check OpenFGA relation %q for user %q: %w", entitlement, username, err)
+			}
+
+			result := resp.GetResult()
+			for i, object := range chunk {
+				item, ok := result[strconv.Itoa(i)]
+				if ok && item.GetAllowed() {
+					objectSet[object.String()] = true
+				} else {
+					checked = append(checked, object)
+				}
+			}
+		}
+
+		// Subsequent subjects only need to check objects this one wasn't granted access to.
+		remaining = checked
+	}
 
 	return func(object Object) bool {
-		return slices.Contains(objects, object.String())
+		return objectSet[object.String()]
 	}, nil
 }
 
-// AddProject adds a project to the authorizer.
-func (f *FGA) AddProject(ctx context.Context, _ int64, projectName string) error {
+// AddOrganization adds an organization to the authorizer. Organization admins/operators/
+// viewers inherit the corresponding role on every project the organization contains via
+// relationOrganization userset rewrites, without per-project role bindings.
+func (f *FGA) AddOrganization(ctx context.Context, organizationName string) error {
 	writes := []client.ClientTupleKey{
 		{
 			User:     ObjectServer().String(),
 			Relation: relationServer,
-			Object:   ObjectProject(projectName).String(),
+			Object:   ObjectOrganization(organizationName).String(),
 		},
+	}
+
+	return f.updateTuples(ctx, writes, nil)
+}
+
+// DeleteOrganization deletes an organization from the authorizer. Only empty organizations
+// (with no member projects) can be deleted, so there are no relationOrganization parent
+// tuples left to clean up.
+func (f *FGA) DeleteOrganization(ctx context.Context, organizationName string) error {
+	deletions := []client.ClientTupleKeyWithoutCondition{
 		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectProfile(projectName, "default").String(),
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectOrganization(organizationName).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, nil, deletions)
+}
+
+// RenameOrganization renames an organization in the authorizer. Only empty organizations
+// can be renamed, so there are no relationOrganization parent tuples to rewrite.
+func (f *FGA) RenameOrganization(ctx context.Context, oldName string, newName string) error {
+	writes := []client.ClientTupleKey{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectOrganization(newName).String(),
+		},
+	}
+
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectOrganization(oldName).String(),
 		},
 	}
 
+	return f.updateTuples(ctx, writes, deletions)
+}
+
+// SetProjectOrganization sets or clears the project's parent organization, replacing any
+// previous relationOrganization tuple. Pass an empty organizationName to detach the project
+// from whichever organization it currently belongs to.
+func (f *FGA) SetProjectOrganization(ctx context.Context, projectName string, organizationName string) error {
+	var writes []client.ClientTupleKey
+	if organizationName != "" {
+		writes = []client.ClientTupleKey{
+			{
+				User:     ObjectOrganization(organizationName).String(),
+				Relation: relationOrganization,
+				Object:   ObjectProject(projectName).String(),
+			},
+		}
+	}
+
+	deletions, err := f.projectOrganizationTuples(ctx, projectName, organizationName)
+	if err != nil {
+		return err
+	}
+
+	return f.updateTuples(ctx, writes, deletions)
+}
+
+// projectOrganizationTuples returns the deletion for the project's current
+// relationOrganization tuple, if any, skipping it when it already points at
+// keepOrganizationName (so SetProjectOrganization doesn't delete the tuple it just wrote).
+func (f *FGA) projectOrganizationTuples(ctx context.Context, projectName string, keepOrganizationName string) ([]client.ClientTupleKeyWithoutCondition, error) {
+	resp, err := f.client.Read(ctx).Body(client.ClientReadRequest{
+		Relation: relationOrganization,
+		Object:   ObjectProject(projectName).String(),
+	}).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading the current organization of project %q: %w", projectName, err)
+	}
+
+	var deletions []client.ClientTupleKeyWithoutCondition
+	for _, tuple := range resp.GetTuples() {
+		key := tuple.GetKey()
+		if keepOrganizationName != "" && key.GetUser() == ObjectOrganization(keepOrganizationName).String() {
+			continue
+		}
+
+		deletions = append(deletions, client.ClientTupleKeyWithoutCondition{
+			User:     key.GetUser(),
+			Relation: key.GetRelation(),
+			Object:   key.GetObject(),
+		})
+	}
+
+	return deletions, nil
+}
+
+// GetOrganizationAccess returns the list of entities who have access to the organization.
+func (f *FGA) GetOrganizationAccess(ctx context.Context, organizationName string) (*api.Access, error) {
+	// Get all the entries from OpenFGA.
+	entries := map[string]string{}
+
+	userFilters := []openfga.UserTypeFilter{{Type: "user"}}
+	relations := []string{"admin", "operator", "user", "viewer"}
+	for _, relation := range relations {
+		resp, err := f.client.ListUsers(ctx).Body(client.ClientListUsersRequest{
+			Object: openfga.FgaObject{
+				Type: "organization",
+				Id:   organizationName,
+			},
+			Relation:    relation,
+			UserFilters: userFilters,
+		}).Execute()
+		if err != nil {
+			var fgaAPIErr openfga.FgaApiValidationError
+			ok := errors.As(err, &fgaAPIErr)
+			if !ok || fgaAPIErr.ResponseCode() != openfga.ERRORCODE_RELATION_NOT_FOUND {
+				var fgaNotFoundErr openfga.FgaApiNotFoundError
+				ok := errors.As(err, &fgaNotFoundErr)
+				if ok && fgaNotFoundErr.ResponseCode() == openfga.NOTFOUNDERRORCODE_UNDEFINED_ENDPOINT {
+					return nil, errors.New("OpenFGA server doesn't support listing users")
+				}
+
+				return nil, fmt.Errorf("Failed to list objects with relation %q: %w: %T", relation, err, err)
+			}
+		}
+
+		for _, user := range resp.GetUsers() {
+			obj := user.GetObject()
+			if obj.Id == "" {
+				continue
+			}
+
+			_, ok := entries[obj.Id]
+			if !ok {
+				entries[obj.Id] = relation
+			}
+		}
+	}
+
+	// Convert to our access records.
+	access := api.Access{}
+	for user, relation := range entries {
+		access = append(access, api.AccessEntry{
+			Identifier: user,
+			Role:       relation,
+			Provider:   "openfga",
+		})
+	}
+
+	return &access, nil
+}
+
+// Condition attaches an OpenFGA ABAC condition (one defined by name in the authorization
+// model, e.g. "non_expired_grant" or "in_allowed_cidr") to a tuple being written, scoping it
+// with whatever context that condition's expression needs (an expiration timestamp, an
+// allowed CIDR list, etc). A tuple written with a condition only grants access while the
+// condition evaluates true against the context threaded into the Check/ListObjects call that
+// consults it, which request-scoped context (current time, client IP) is computed in
+// fgaRequestContext for.
+type Condition struct {
+	// Name is the condition's name as declared in the authorization model.
+	Name string
+
+	// Context supplies the values the condition's expression is evaluated against, e.g.
+	// {"expiration": "2025-01-01T00:00:00Z"} for non_expired_grant or
+	// {"allowed_cidrs": []string{"10.0.0.0/8"}} for in_allowed_cidr.
+	Context map[string]any
+}
+
+// conditionedTupleKey builds a tuple write, attaching the first of conditions if any were
+// given. Callers that only ever wrote unconditional tuples (AddProject, AddProfile) take
+// conditions as a trailing variadic argument purely so they don't need changing at every
+// call site just to stay source-compatible.
+func conditionedTupleKey(user string, relation string, object string, conditions ...Condition) client.ClientTupleKey {
+	key := client.ClientTupleKey{User: user, Relation: relation, Object: object}
+	if len(conditions) == 0 {
+		return key
+	}
+
+	condition := conditions[0]
+	key.Condition = &openfga.RelationshipCondition{
+		Name:    condition.Name,
+		Context: &condition.Context,
+	}
+
+	return key
+}
+
+// GrantAccess grants subject the given relation on object, optionally scoped by condition, so
+// that e.g. "operator on project Y until 2025-01-01" or "viewer on project Y only from
+// 10.0.0.0/8" can be expressed as a single tuple rather than requiring the caller to revoke it
+// later themselves.
+func (f *FGA) GrantAccess(ctx context.Context, subject Object, relation string, object Object, condition *Condition) error {
+	var conditions []Condition
+	if condition != nil {
+		conditions = []Condition{*condition}
+	}
+
+	writes := []client.ClientTupleKey{conditionedTupleKey(subject.String(), relation, object.String(), conditions...)}
+
+	return f.updateTuples(ctx, writes, nil)
+}
+
+// RevokeAccess removes a grant previously added by GrantAccess.
+func (f *FGA) RevokeAccess(ctx context.Context, subject Object, relation string, object Object) error {
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     subject.String(),
+			Relation: relation,
+			Object:   object.String(),
+		},
+	}
+
+	return f.updateTuples(ctx, nil, deletions)
+}
+
+// AddProject adds a project to the authorizer.
+// AddProject adds a project to the authorizer. An optional condition (e.g. non_expired_grant)
+// scopes the project's tuples, for callers provisioning a time-bounded or otherwise
+// conditional project rather than a permanent one; existing callers passing none are
+// unaffected.
+func (f *FGA) AddProject(ctx context.Context, _ int64, projectName string, conditions ...Condition) error {
+	writes := []client.ClientTupleKey{
+		conditionedTupleKey(ObjectServer().String(), relationServer, ObjectProject(projectName).String(), conditions...),
+		conditionedTupleKey(ObjectProject(projectName).String(), relationProject, ObjectProfile(projectName, "default").String(), conditions...),
+	}
+
 	return f.updateTuples(ctx, writes, nil)
 }
 
@@ -454,6 +1578,54 @@ func (f *FGA) DeleteCertificate(ctx context.Context, fingerprint string) error {
 	return f.updateTuples(ctx, nil, deletions)
 }
 
+// AddGroup adds a group to the authorizer. Entitlements bound to the group apply to every
+// user whose OIDC token claims resolve to it, without requiring a tuple per user.
+func (f *FGA) AddGroup(ctx context.Context, groupName string) error {
+	writes := []client.ClientTupleKey{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectGroup(groupName).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, writes, nil)
+}
+
+// DeleteGroup deletes a group from the authorizer.
+func (f *FGA) DeleteGroup(ctx context.Context, groupName string) error {
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectGroup(groupName).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, nil, deletions)
+}
+
+// RenameGroup renames a group in the authorizer.
+func (f *FGA) RenameGroup(ctx context.Context, oldName string, newName string) error {
+	writes := []client.ClientTupleKey{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectGroup(newName).String(),
+		},
+	}
+
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectServer().String(),
+			Relation: relationServer,
+			Object:   ObjectGroup(oldName).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, writes, deletions)
+}
+
 // AddStoragePool adds a storage pool to the authorizer.
 func (f *FGA) AddStoragePool(ctx context.Context, storagePoolName string) error {
 	writes := []client.ClientTupleKey{
@@ -814,14 +1986,11 @@ func (f *FGA) RenameNetworkAddressSet(ctx context.Context, projectName string, o
 	return f.updateTuples(ctx, writes, deletions)
 }
 
-// AddProfile is a no-op.
-func (f *FGA) AddProfile(ctx context.Context, projectName string, profileName string) error {
+// AddProfile adds a profile to the authorizer. An optional condition scopes the profile's
+// tuple the same way AddProject's does.
+func (f *FGA) AddProfile(ctx context.Context, projectName string, profileName string, conditions ...Condition) error {
 	writes := []client.ClientTupleKey{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectProfile(projectName, profileName).String(),
-		},
+		conditionedTupleKey(ObjectProject(projectName).String(), relationProject, ObjectProfile(projectName, profileName).String(), conditions...),
 	}
 
 	return f.updateTuples(ctx, writes, nil)
@@ -871,87 +2040,506 @@ func (f *FGA) AddStoragePoolVolume(ctx context.Context, projectName string, stor
 		},
 	}
 
-	return f.updateTuples(ctx, writes, nil)
+	return f.updateTuples(ctx, writes, nil)
+}
+
+// DeleteStoragePoolVolume deletes a storage volume from the authorizer.
+func (f *FGA) DeleteStoragePoolVolume(ctx context.Context, projectName string, storagePoolName string, storageVolumeType string, storageVolumeName string, storageVolumeLocation string) error {
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectProject(projectName).String(),
+			Relation: relationProject,
+			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, storageVolumeName, storageVolumeLocation).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, nil, deletions)
+}
+
+// RenameStoragePoolVolume renames a storage volume in the authorizer.
+func (f *FGA) RenameStoragePoolVolume(ctx context.Context, projectName string, storagePoolName string, storageVolumeType string, oldStorageVolumeName string, newStorageVolumeName string, storageVolumeLocation string) error {
+	writes := []client.ClientTupleKey{
+		{
+			User:     ObjectProject(projectName).String(),
+			Relation: relationProject,
+			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, newStorageVolumeName, storageVolumeLocation).String(),
+		},
+	}
+
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectProject(projectName).String(),
+			Relation: relationProject,
+			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, oldStorageVolumeName, storageVolumeLocation).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, writes, deletions)
+}
+
+// AddStorageBucket adds a storage bucket to the authorizer.
+func (f *FGA) AddStorageBucket(ctx context.Context, projectName string, storagePoolName string, storageBucketName string, storageBucketLocation string) error {
+	writes := []client.ClientTupleKey{
+		{
+			User:     ObjectProject(projectName).String(),
+			Relation: relationProject,
+			Object:   ObjectStorageBucket(projectName, storagePoolName, storageBucketName, storageBucketLocation).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, writes, nil)
+}
+
+// DeleteStorageBucket deletes a storage bucket from the authorizer.
+func (f *FGA) DeleteStorageBucket(ctx context.Context, projectName string, storagePoolName string, storageBucketName string, storageBucketLocation string) error {
+	deletions := []client.ClientTupleKeyWithoutCondition{
+		{
+			User:     ObjectProject(projectName).String(),
+			Relation: relationProject,
+			Object:   ObjectStorageBucket(projectName, storagePoolName, storageBucketName, storageBucketLocation).String(),
+		},
+	}
+
+	return f.updateTuples(ctx, nil, deletions)
+}
+
+// updateTuples enqueues a tuple mutation for asynchronous, batched delivery to OpenFGA.
+// Queueing rather than writing synchronously means that AddInstance/DeleteInstance/
+// RenameNetwork/etc never block on OpenFGA latency, and keep succeeding uninterrupted
+// through an OpenFGA outage; the durable write queue is drained in the background by
+// runWriteQueueWorker once the server is reachable again.
+func (f *FGA) updateTuples(ctx context.Context, writes []client.ClientTupleKey, deletions []client.ClientTupleKeyWithoutCondition) error {
+	if len(writes) == 0 && len(deletions) == 0 {
+		return nil
+	}
+
+	err := f.writeQueue.enqueue(writes, deletions)
+	if err != nil {
+		return err
+	}
+
+	// Evict any cached decision whose subject or object this mutation touches before the
+	// write even reaches OpenFGA, since a cached "allow" now describing a revoked tuple
+	// (or vice versa) is worse than a cache miss.
+	if f.decisionCache != nil {
+		for _, write := range writes {
+			f.decisionCache.invalidate(write.User, write.Object)
+		}
+
+		for _, deletion := range deletions {
+			f.decisionCache.invalidate(deletion.User, deletion.Object)
+		}
+	}
+
+	if depth := f.writeQueue.Len(); depth > fgaWriteQueueBacklogWarnDepth {
+		logger.Warn("OpenFGA write queue backlog is growing", logger.Ctx{"depth": depth})
+	}
+
+	return nil
+}
+
+// fgaWriteQueueBacklogWarnDepth is the pending-mutation count above which updateTuples logs a
+// backpressure warning, so a slow or unreachable OpenFGA shows up in logs well before the
+// durable queue file grows large enough to matter.
+const fgaWriteQueueBacklogWarnDepth = 500
+
+const fgaWriteQueueBatchSize = 50
+
+// fgaWriteQueueBackoffBase/Max bound the exponential backoff applied between retries after
+// a transient batch failure, so a wedged OpenFGA server doesn't get hammered every tick.
+const fgaWriteQueueBackoffBase = 2 * time.Second
+
+const fgaWriteQueueBackoffMax = 2 * time.Minute
+
+// fgaWriteQueueOfflineThreshold is how long the writer tolerates consecutive transient
+// failures before flipping f.online false, which makes CheckPermission stop issuing Check
+// calls (falling back to the configured offline mode) and triggers a full syncResources once
+// the connection recovers. GetPermissionChecker's ListObjects calls don't look at f.online.
+const fgaWriteQueueOfflineThreshold = time.Minute
+
+// runWriteQueueWorker periodically batches pending tuple mutations from the write queue
+// into OpenFGA Write calls, retrying later if OpenFGA is unreachable or a batch fails.
+func (f *FGA) runWriteQueueWorker(ctx context.Context) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.flushWriteQueue(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fgaDecisionCacheInvalidationInterval is how often runDecisionCacheInvalidator polls
+// OpenFGA's change log.
+const fgaDecisionCacheInvalidationInterval = 5 * time.Second
+
+// runDecisionCacheInvalidator polls ReadChanges and evicts any cached decision a tuple change
+// since the last poll touches. This is what catches writes made directly against OpenFGA, or
+// applied by another cluster member, neither of which updateTuples' synchronous invalidation
+// can see.
+func (f *FGA) runDecisionCacheInvalidator(ctx context.Context) {
+	// Bookmark the store's current position without evicting anything: the cache starts
+	// empty, so there's nothing yet to invalidate. This pages through the full retained
+	// change history once and discards it, keeping only the returned token.
+	_, token, err := f.readChangesSince(ctx, "")
+	if err != nil {
+		logger.Error("Failed bootstrapping OpenFGA decision cache invalidator", logger.Ctx{"err": err})
+	}
+
+	for {
+		select {
+		case <-time.After(fgaDecisionCacheInvalidationInterval):
+		case <-ctx.Done():
+			return
+		}
+
+		changes, newToken, err := f.readChangesSince(ctx, token)
+		if err != nil {
+			logger.Warn("Failed polling OpenFGA changes for decision cache invalidation, resetting cursor", logger.Ctx{"err": err})
+			token = ""
+			continue
+		}
+
+		for _, change := range changes {
+			f.decisionCache.invalidate(change.Tuple.User, change.Tuple.Object)
+		}
+
+		token = newToken
+	}
+}
+
+// flushWriteQueue drains and writes the durable queue in batches until it's empty or a
+// batch fails transiently, in which case the unacknowledged remainder is left in place for
+// a later tick, once the backoff from the last failure has elapsed. Entries that fail with
+// a permanent validation error are logged and dropped rather than retried forever.
+func (f *FGA) flushWriteQueue(ctx context.Context) {
+	f.onlineMu.Lock()
+	online := f.online
+	f.onlineMu.Unlock()
+	if !online {
+		return
+	}
+
+	f.writeQueueMu.Lock()
+	ready := time.Now().After(f.writeQueueNextAttempt)
+	f.writeQueueMu.Unlock()
+	if !ready {
+		return
+	}
+
+	for {
+		writes, writeKeys, deletions, deleteKeys := f.writeQueue.drain(fgaWriteQueueBatchSize)
+		if len(writeKeys) == 0 && len(deleteKeys) == 0 {
+			f.recordWriteQueueSuccess()
+			return
+		}
+
+		writeErrs, deleteErrs, err := f.sendTuplesDetailed(ctx, writes, deletions)
+		if err != nil {
+			f.recordWriteQueueFailure(err)
+			return
+		}
+
+		var ackKeys []fgaQueueKey
+		for i, writeErr := range writeErrs {
+			if writeErr == nil {
+				ackKeys = append(ackKeys, writeKeys[i])
+			} else if isPermanentFGAError(writeErr) {
+				logger.Error("Dropping invalid queued OpenFGA tuple write", logger.Ctx{"err": writeErr})
+				ackKeys = append(ackKeys, writeKeys[i])
+			}
+		}
+
+		for i, deleteErr := range deleteErrs {
+			if deleteErr == nil {
+				ackKeys = append(ackKeys, deleteKeys[i])
+			} else if isPermanentFGAError(deleteErr) {
+				logger.Error("Dropping invalid queued OpenFGA tuple deletion", logger.Ctx{"err": deleteErr})
+				ackKeys = append(ackKeys, deleteKeys[i])
+			}
+		}
+
+		err = f.writeQueue.ack(ackKeys)
+		if err != nil {
+			logger.Error("Failed acknowledging flushed OpenFGA tuple writes", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(ackKeys) < len(writeKeys)+len(deleteKeys) {
+			// Some entries hit a transient error and are still pending: stop for this tick
+			// rather than spinning on the same failing entries, and back off before retrying.
+			f.recordWriteQueueFailure(errors.New("Batch contained transient OpenFGA errors"))
+			return
+		}
+
+		f.recordWriteQueueSuccess()
+	}
+}
+
+// recordWriteQueueFailure applies exponential backoff before the writer's next attempt, and
+// flips f.online false if failures have persisted beyond fgaWriteQueueOfflineThreshold.
+func (f *FGA) recordWriteQueueFailure(err error) {
+	f.writeQueueMu.Lock()
+	f.writeQueueFailures++
+	if f.writeQueueFailures == 1 {
+		f.writeQueueFirstFailure = time.Now()
+	}
+
+	backoff := fgaWriteQueueBackoffBase * time.Duration(1<<min(f.writeQueueFailures-1, 6))
+	backoff = min(backoff, fgaWriteQueueBackoffMax)
+	f.writeQueueNextAttempt = time.Now().Add(backoff)
+	prolonged := time.Since(f.writeQueueFirstFailure) > fgaWriteQueueOfflineThreshold
+	f.writeQueueMu.Unlock()
+
+	logger.Error("Failed flushing queued OpenFGA tuple writes, will retry", logger.Ctx{"err": err, "backoff": backoff})
+
+	if !prolonged {
+		return
+	}
+
+	f.onlineMu.Lock()
+	wasOnline := f.online
+	f.online = false
+	f.onlineMu.Unlock()
+
+	if wasOnline {
+		logger.Warn("Marking OpenFGA offline after prolonged write queue failures")
+		go f.waitForFGAReconnect()
+	}
+}
+
+// recordWriteQueueSuccess clears the writer's backoff state after a fully successful flush.
+func (f *FGA) recordWriteQueueSuccess() {
+	f.writeQueueMu.Lock()
+	defer f.writeQueueMu.Unlock()
+
+	f.writeQueueFailures = 0
+	f.writeQueueFirstFailure = time.Time{}
+	f.writeQueueNextAttempt = time.Time{}
+}
+
+// waitForFGAReconnect polls OpenFGA until it responds again after the writer marked it
+// offline, then flips f.online back on and runs a full syncResources so that whatever
+// accumulated while writes were failing (and anything ListObjects-based checks missed while
+// offline) gets reconciled immediately rather than waiting for the next hourly cycle.
+func (f *FGA) waitForFGAReconnect() {
+	for {
+		select {
+		case <-f.shutdownCtx.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(f.shutdownCtx, 10*time.Second)
+		_, err := f.client.ReadLatestAuthorizationModel(ctx).Execute()
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		f.onlineMu.Lock()
+		f.online = true
+		f.onlineMu.Unlock()
+
+		logger.Warn("Connection with OpenFGA re-established")
+
+		if f.resourcesFunc != nil {
+			resources, err := f.resourcesFunc()
+			if err != nil {
+				logger.Error("Failed getting local OpenFGA resources", logger.Ctx{"err": err})
+			} else if resources != nil {
+				err := f.syncResources(f.shutdownCtx, *resources)
+				if err != nil {
+					logger.Error("Failed OpenFGA resource sync after reconnect", logger.Ctx{"err": err})
+				}
+			}
+		}
+
+		return
+	}
+}
+
+// isPermanentFGAError reports whether err is a validation error that will never succeed on
+// retry (e.g. a malformed tuple, or one that doesn't fit the authorization model), as
+// opposed to a transient error (timeout, connection refused, server unavailable) worth
+// retrying.
+func isPermanentFGAError(err error) bool {
+	var validationErr openfga.FgaApiValidationError
+	return errors.As(err, &validationErr)
+}
+
+// fgaQueuedTuple is a single pending tuple mutation awaiting a batched write to OpenFGA.
+type fgaQueuedTuple struct {
+	Write  *client.ClientTupleKey                `json:"write,omitempty"`
+	Delete *client.ClientTupleKeyWithoutCondition `json:"delete,omitempty"`
+}
+
+// fgaQueueKey identifies the tuple a queued mutation applies to, used to coalesce
+// repeated writes/deletions of the same tuple into a single pending mutation.
+type fgaQueueKey struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+func fgaQueueKeyOf(entry fgaQueuedTuple) fgaQueueKey {
+	if entry.Write != nil {
+		return fgaQueueKey{User: entry.Write.User, Relation: entry.Write.Relation, Object: entry.Write.Object}
+	}
+
+	return fgaQueueKey{User: entry.Delete.User, Relation: entry.Delete.Relation, Object: entry.Delete.Object}
 }
 
-// DeleteStoragePoolVolume deletes a storage volume from the authorizer.
-func (f *FGA) DeleteStoragePoolVolume(ctx context.Context, projectName string, storagePoolName string, storageVolumeType string, storageVolumeName string, storageVolumeLocation string) error {
-	deletions := []client.ClientTupleKeyWithoutCondition{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, storageVolumeName, storageVolumeLocation).String(),
-		},
+// fgaWriteQueue is a coalesced, durable write-ahead queue for OpenFGA tuple mutations.
+// updateTuples enqueues into it instead of writing to OpenFGA synchronously; a background
+// worker drains it in batches. It is persisted as a JSON file so pending mutations survive
+// a daemon restart rather than being silently lost if the server never reconnects in time.
+type fgaWriteQueue struct {
+	mu      sync.Mutex
+	path    string
+	pending map[fgaQueueKey]fgaQueuedTuple
+}
+
+func newFGAWriteQueue(path string) *fgaWriteQueue {
+	q := &fgaWriteQueue{path: path, pending: map[fgaQueueKey]fgaQueuedTuple{}}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var entries []fgaQueuedTuple
+		err = json.Unmarshal(data, &entries)
+		if err == nil {
+			for _, entry := range entries {
+				q.pending[fgaQueueKeyOf(entry)] = entry
+			}
+		}
 	}
 
-	return f.updateTuples(ctx, nil, deletions)
+	return q
 }
 
-// RenameStoragePoolVolume renames a storage volume in the authorizer.
-func (f *FGA) RenameStoragePoolVolume(ctx context.Context, projectName string, storagePoolName string, storageVolumeType string, oldStorageVolumeName string, newStorageVolumeName string, storageVolumeLocation string) error {
-	writes := []client.ClientTupleKey{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, newStorageVolumeName, storageVolumeLocation).String(),
-		},
+// enqueue coalesces writes/deletions for the same tuple into the queue's current pending
+// state and persists the result.
+func (q *fgaWriteQueue) enqueue(writes []client.ClientTupleKey, deletions []client.ClientTupleKeyWithoutCondition) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := range writes {
+		entry := fgaQueuedTuple{Write: &writes[i]}
+		q.pending[fgaQueueKeyOf(entry)] = entry
 	}
 
-	deletions := []client.ClientTupleKeyWithoutCondition{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectStorageVolume(projectName, storagePoolName, storageVolumeType, oldStorageVolumeName, storageVolumeLocation).String(),
-		},
+	for i := range deletions {
+		entry := fgaQueuedTuple{Delete: &deletions[i]}
+		q.pending[fgaQueueKeyOf(entry)] = entry
 	}
 
-	return f.updateTuples(ctx, writes, deletions)
+	return q.persistLocked()
 }
 
-// AddStorageBucket adds a storage bucket to the authorizer.
-func (f *FGA) AddStorageBucket(ctx context.Context, projectName string, storagePoolName string, storageBucketName string, storageBucketLocation string) error {
-	writes := []client.ClientTupleKey{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectStorageBucket(projectName, storagePoolName, storageBucketName, storageBucketLocation).String(),
-		},
+// drain removes up to maxBatch pending mutations from the queue without persisting the
+// removal yet, returning them split back into writes/deletions for a single Write call,
+// each paired with the key to ack (or leave pending, on a per-entry transient failure) once
+// the result of that call is known.
+func (q *fgaWriteQueue) drain(maxBatch int) ([]client.ClientTupleKey, []fgaQueueKey, []client.ClientTupleKeyWithoutCondition, []fgaQueueKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var writes []client.ClientTupleKey
+	var writeKeys []fgaQueueKey
+	var deletions []client.ClientTupleKeyWithoutCondition
+	var deleteKeys []fgaQueueKey
+
+	count := 0
+	for key, entry := range q.pending {
+		if count >= maxBatch {
+			break
+		}
+
+		if entry.Write != nil {
+			writes = append(writes, *entry.Write)
+			writeKeys = append(writeKeys, key)
+		} else {
+			deletions = append(deletions, *entry.Delete)
+			deleteKeys = append(deleteKeys, key)
+		}
+
+		count++
 	}
 
-	return f.updateTuples(ctx, writes, nil)
+	return writes, writeKeys, deletions, deleteKeys
 }
 
-// DeleteStorageBucket deletes a storage bucket from the authorizer.
-func (f *FGA) DeleteStorageBucket(ctx context.Context, projectName string, storagePoolName string, storageBucketName string, storageBucketLocation string) error {
-	deletions := []client.ClientTupleKeyWithoutCondition{
-		{
-			User:     ObjectProject(projectName).String(),
-			Relation: relationProject,
-			Object:   ObjectStorageBucket(projectName, storagePoolName, storageBucketName, storageBucketLocation).String(),
-		},
+// Len reports the number of mutations currently pending, so callers enqueueing faster than
+// the background flusher can drain can detect and log backpressure rather than growing the
+// durable queue file unbounded.
+func (q *fgaWriteQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// ack removes successfully flushed entries from the queue and persists the result.
+func (q *fgaWriteQueue) ack(keys []fgaQueueKey) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, key := range keys {
+		delete(q.pending, key)
 	}
 
-	return f.updateTuples(ctx, nil, deletions)
+	return q.persistLocked()
 }
 
-// updateTuples sends an object update to OpenFGA if it's currently online.
-func (f *FGA) updateTuples(ctx context.Context, writes []client.ClientTupleKey, deletions []client.ClientTupleKeyWithoutCondition) error {
-	// If offline, skip updating as a full sync will happen after connection.
-	f.onlineMu.Lock()
-	defer f.onlineMu.Unlock()
-	if !f.online {
-		return nil
+func (q *fgaWriteQueue) persistLocked() error {
+	entries := make([]fgaQueuedTuple, 0, len(q.pending))
+	for _, entry := range q.pending {
+		entries = append(entries, entry)
 	}
 
-	if len(writes) == 0 && len(deletions) == 0 {
-		return nil
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
 	}
 
-	return f.sendTuples(ctx, writes, deletions)
+	return os.WriteFile(q.path, data, 0o600)
 }
 
-// sendTuples directly sends the write/deletion tuples to OpenFGA.
+// sendTuples directly sends the write/deletion tuples to OpenFGA, failing on the first
+// per-tuple error reported back (if any). Used by callers issuing tuples synchronously
+// outside the write queue (initial model bootstrap, ApplyPatch).
 func (f *FGA) sendTuples(ctx context.Context, writes []client.ClientTupleKey, deletions []client.ClientTupleKeyWithoutCondition) error {
+	writeErrs, deleteErrs, err := f.sendTuplesDetailed(ctx, writes, deletions)
+	if err != nil {
+		return err
+	}
+
+	for _, writeErr := range writeErrs {
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	for _, deleteErr := range deleteErrs {
+		if deleteErr != nil {
+			return deleteErr
+		}
+	}
+
+	return nil
+}
+
+// sendTuplesDetailed sends the write/deletion tuples to OpenFGA as a single non-transactional,
+// chunked batch and reports a per-tuple result: writeErrs[i]/deleteErrs[i] is the error (if
+// any) for writes[i]/deletions[i], so a caller retrying a batch can tell which entries
+// actually need retrying rather than treating the whole batch as having failed. The returned
+// err is non-nil only if the request as a whole couldn't be made (e.g. the server is
+// unreachable), in which case no per-tuple results are available.
+func (f *FGA) sendTuplesDetailed(ctx context.Context, writes []client.ClientTupleKey, deletions []client.ClientTupleKeyWithoutCondition) (writeErrs []error, deleteErrs []error, err error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -977,24 +2565,26 @@ func (f *FGA) sendTuples(ctx context.Context, writes []client.ClientTupleKey, de
 		body.Deletes = []openfga.TupleKeyWithoutCondition{}
 	}
 
-	clientWriteResponse, err := f.client.Write(ctx).Options(opts).Body(body).Execute()
-	if err != nil {
-		return fmt.Errorf("Failed to write to OpenFGA store: %w", err)
+	clientWriteResponse, execErr := f.client.Write(ctx).Options(opts).Body(body).Execute()
+	if execErr != nil {
+		return nil, nil, fmt.Errorf("Failed to write to OpenFGA store: %w", execErr)
 	}
 
-	for _, write := range clientWriteResponse.Writes {
+	writeErrs = make([]error, len(clientWriteResponse.Writes))
+	for i, write := range clientWriteResponse.Writes {
 		if write.Error != nil {
-			return fmt.Errorf("Failed to write tuple to OpenFGA store (user: %q; relation: %q; object: %q): %w", write.TupleKey.User, write.TupleKey.Relation, write.TupleKey.Object, write.Error)
+			writeErrs[i] = fmt.Errorf("Failed to write tuple to OpenFGA store (user: %q; relation: %q; object: %q): %w", write.TupleKey.User, write.TupleKey.Relation, write.TupleKey.Object, write.Error)
 		}
 	}
 
-	for _, deletion := range clientWriteResponse.Deletes {
+	deleteErrs = make([]error, len(clientWriteResponse.Deletes))
+	for i, deletion := range clientWriteResponse.Deletes {
 		if deletion.Error != nil {
-			return fmt.Errorf("Failed to delete tuple from OpenFGA store (user: %q; relation: %q; object: %q): %w", deletion.TupleKey.User, deletion.TupleKey.Relation, deletion.TupleKey.Object, deletion.Error)
+			deleteErrs[i] = fmt.Errorf("Failed to delete tuple from OpenFGA store (user: %q; relation: %q; object: %q): %w", deletion.TupleKey.User, deletion.TupleKey.Relation, deletion.TupleKey.Object, deletion.Error)
 		}
 	}
 
-	return nil
+	return writeErrs, deleteErrs, nil
 }
 
 func (f *FGA) projectObjects(ctx context.Context, projectName string) ([]string, error) {
@@ -1029,6 +2619,75 @@ func (f *FGA) projectObjects(ctx context.Context, projectName string) ([]string,
 	return allObjects, nil
 }
 
+// fgaProjectObjectsWorkers bounds how many projects' projectObjects calls run concurrently
+// in allProjectObjects.
+const fgaProjectObjectsWorkers = 8
+
+// allProjectObjects fetches projectObjects for every project named by projectObjectStrs
+// behind a bounded worker pool, rather than one project at a time, so a full sync's
+// round-trip count no longer scales with wall-clock time on clusters with many projects.
+func (f *FGA) allProjectObjects(ctx context.Context, projectObjectStrs []string) ([]string, error) {
+	type result struct {
+		objects []string
+		err     error
+	}
+
+	jobs := make(chan string)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for range min(fgaProjectObjectsWorkers, len(projectObjectStrs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for projectObjectStr := range jobs {
+				projectObject, err := ObjectFromString(projectObjectStr)
+				if err != nil {
+					results <- result{err: err}
+					continue
+				}
+
+				objects, err := f.projectObjects(ctx, projectObject.Project())
+				results <- result{objects: objects, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+
+		for _, projectObjectStr := range projectObjectStrs {
+			jobs <- projectObjectStr
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var allObjects []string
+	var firstErr error
+	for res := range results {
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = res.err
+			}
+
+			continue
+		}
+
+		allObjects = append(allObjects, res.objects...)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return allObjects, nil
+}
+
 func (f *FGA) syncResources(ctx context.Context, resources Resources) error {
 	var writes []client.ClientTupleKey
 	var deletions []client.ClientTupleKeyWithoutCondition
@@ -1140,21 +2799,75 @@ func (f *FGA) syncResources(ctx context.Context, resources Resources) error {
 		return err
 	}
 
-	// Get a slice of project level resources for all projects.
-	var remoteProjectResourceObjectStrs []string
+	// List the organizations we have added to OpenFGA already.
+	organizationsResp, err := f.client.ListObjects(ctx).Body(client.ClientListObjectsRequest{
+		User:     ObjectServer().String(),
+		Relation: relationServer,
+		Type:     string(ObjectTypeOrganization),
+	}).Execute()
+	if err != nil {
+		return err
+	}
+
+	// Compare with local organizations.
+	err = diffObjects(relationServer, organizationsResp.GetObjects(), resources.OrganizationObjects)
+	if err != nil {
+		return err
+	}
+
+	// Diff the organization->project parent tuples against resources.ProjectOrganization, which
+	// maps a project name to the name of the organization it belongs to (projects with no entry
+	// there aren't part of any organization).
 	for _, remoteProjectObjectStr := range remoteProjectObjectStrs {
 		remoteProjectObject, err := ObjectFromString(remoteProjectObjectStr)
 		if err != nil {
 			return err
 		}
 
-		// project level resources just for this project.
-		remoteProjectResources, err := f.projectObjects(ctx, remoteProjectObject.Project())
+		projectName := remoteProjectObject.Project()
+
+		orgResp, err := f.client.Read(ctx).Body(client.ClientReadRequest{
+			Relation: relationOrganization,
+			Object:   remoteProjectObject.String(),
+		}).Execute()
 		if err != nil {
 			return err
 		}
 
-		remoteProjectResourceObjectStrs = append(remoteProjectResourceObjectStrs, remoteProjectResources...)
+		var currentOrg string
+		for _, tuple := range orgResp.GetTuples() {
+			_, currentOrg, _ = strings.Cut(tuple.GetKey().GetUser(), ":")
+			break
+		}
+
+		desiredOrg := resources.ProjectOrganization[projectName]
+		if desiredOrg == currentOrg {
+			continue
+		}
+
+		if currentOrg != "" {
+			deletions = append(deletions, client.ClientTupleKeyWithoutCondition{
+				User:     ObjectOrganization(currentOrg).String(),
+				Relation: relationOrganization,
+				Object:   remoteProjectObject.String(),
+			})
+		}
+
+		if desiredOrg != "" {
+			writes = append(writes, client.ClientTupleKey{
+				User:     ObjectOrganization(desiredOrg).String(),
+				Relation: relationOrganization,
+				Object:   remoteProjectObject.String(),
+			})
+		}
+	}
+
+	// Get a slice of project level resources for all projects, fetched behind a bounded
+	// worker pool since each project requires its own round of ListObjects calls and
+	// clusters can have thousands of projects.
+	remoteProjectResourceObjectStrs, err := f.allProjectObjects(ctx, remoteProjectObjectStrs)
+	if err != nil {
+		return err
 	}
 
 	// Compose a slice of all project level objects from the given Resources.
@@ -1178,6 +2891,319 @@ func (f *FGA) syncResources(ctx context.Context, resources Resources) error {
 	return f.updateTuples(ctx, writes, deletions)
 }
 
+// fgaChangesPageSize bounds how many change events client.ReadChanges returns per page
+// while paginating through the store's change log.
+const fgaChangesPageSize = 100
+
+// fgaTupleChange is a single entry from the OpenFGA store's change log: either the tuple
+// was written, or it was deleted.
+type fgaTupleChange struct {
+	Tuple   client.ClientTupleKey
+	Deleted bool
+}
+
+// readChangesSince pages through every change recorded after continuationToken, returning
+// them in order along with the token to resume from next time. Passing an empty
+// continuationToken reads from the start of the store's retained change log; callers doing
+// this only to bookmark "now" should discard the changes and keep just the returned token.
+func (f *FGA) readChangesSince(ctx context.Context, continuationToken string) ([]fgaTupleChange, string, error) {
+	var changes []fgaTupleChange
+	pageSize := int32(fgaChangesPageSize)
+
+	for {
+		resp, err := f.client.ReadChanges(ctx).Body(client.ClientReadChangesRequest{}).Options(client.ClientReadChangesOptions{
+			PageSize:          &pageSize,
+			ContinuationToken: &continuationToken,
+		}).Execute()
+		if err != nil {
+			return nil, "", err
+		}
+
+		entries := resp.GetChanges()
+		for _, entry := range entries {
+			key := entry.GetTupleKey()
+			changes = append(changes, fgaTupleChange{
+				Tuple: client.ClientTupleKey{
+					User:     key.GetUser(),
+					Relation: key.GetRelation(),
+					Object:   key.GetObject(),
+				},
+				Deleted: entry.GetOperation() == openfga.TUPLEOPERATION_DELETE,
+			})
+		}
+
+		continuationToken = resp.GetContinuationToken()
+
+		// An empty page means we've caught up; a non-empty page always comes with a
+		// continuation token to fetch the next one.
+		if len(entries) == 0 {
+			return changes, continuationToken, nil
+		}
+	}
+}
+
+// incrementalSyncResources keeps OpenFGA's ownership tuples in sync with resources the same
+// way syncResources does, but on every call after the first tries to do so by replaying the
+// store's change log since the last call rather than re-running a full projectObjects sweep
+// across every project. It falls back to a full syncResources on first bootstrap (no token
+// yet persisted) and whenever the persisted token has aged out of OpenFGA's retained change
+// log, in which case the changes needed to catch up can no longer be read at all.
+func (f *FGA) incrementalSyncResources(ctx context.Context, resources Resources) error {
+	if f.changeTokenFunc == nil || f.setChangeTokenFunc == nil {
+		return f.syncResources(ctx, resources)
+	}
+
+	token, err := f.changeTokenFunc()
+	if err != nil {
+		return fmt.Errorf("Failed getting last OpenFGA change token: %w", err)
+	}
+
+	if token == "" {
+		err := f.syncResources(ctx, resources)
+		if err != nil {
+			return err
+		}
+
+		// Bookmark the store's current position so the next call can go incremental,
+		// without replaying the (potentially long) history leading up to it.
+		_, bookmark, err := f.readChangesSince(ctx, "")
+		if err != nil {
+			return fmt.Errorf("Failed bookmarking OpenFGA change token: %w", err)
+		}
+
+		return f.setChangeTokenFunc(bookmark)
+	}
+
+	changes, newToken, err := f.readChangesSince(ctx, token)
+	if err != nil {
+		logger.Warn("OpenFGA change token expired or invalid, falling back to a full resource sync", logger.Ctx{"err": err})
+
+		err := f.setChangeTokenFunc("")
+		if err != nil {
+			return err
+		}
+
+		return f.syncResources(ctx, resources)
+	}
+
+	if len(changes) > 0 {
+		expectedSet := map[fgaQueueKey]bool{}
+		for _, tuple := range fgaExpectedTuples(resources) {
+			expectedSet[fgaQueueKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}] = true
+		}
+
+		var writes []client.ClientTupleKey
+		var deletions []client.ClientTupleKeyWithoutCondition
+		for _, change := range changes {
+			expected := expectedSet[fgaQueueKey{User: change.Tuple.User, Relation: change.Tuple.Relation, Object: change.Tuple.Object}]
+
+			// A change is drift worth correcting only if it moved a tuple away from
+			// what resources says it should be; changes that land on the expected
+			// state (most of them, since they're normally our own writes) are left
+			// alone.
+			if change.Deleted && expected {
+				writes = append(writes, change.Tuple)
+			} else if !change.Deleted && !expected {
+				deletions = append(deletions, client.ClientTupleKeyWithoutCondition{User: change.Tuple.User, Relation: change.Tuple.Relation, Object: change.Tuple.Object})
+			}
+		}
+
+		err := f.updateTuples(ctx, writes, deletions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return f.setChangeTokenFunc(newToken)
+}
+
+// FGAReconcileReport is the result of diffing the ownership tuples actually stored in
+// OpenFGA against the ones Incus' local state says should exist.
+type FGAReconcileReport struct {
+	// ExtraTuples exist in OpenFGA but no longer correspond to any local object.
+	ExtraTuples []client.ClientTupleKeyWithoutCondition `json:"extra_tuples"`
+
+	// MissingTuples correspond to a local object but are absent from OpenFGA.
+	MissingTuples []client.ClientTupleKey `json:"missing_tuples"`
+
+	// Applied is true if ExtraTuples/MissingTuples were written back to OpenFGA rather
+	// than just reported.
+	Applied bool `json:"applied"`
+}
+
+// Reconcile diffs the current local resources against the raw OpenFGA tuple store and,
+// if apply is true, writes/deletes tuples to correct any drift found. It is the backing
+// implementation for the /1.0/auth/reconcile admin endpoint (dry-run unless ?apply=true).
+func (f *FGA) Reconcile(ctx context.Context, apply bool) (*FGAReconcileReport, error) {
+	if f.resourcesFunc == nil {
+		return nil, errors.New("OpenFGA resource reconciliation is not available on this server")
+	}
+
+	resources, err := f.resourcesFunc()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting local OpenFGA resources: %w", err)
+	}
+
+	if resources == nil {
+		return &FGAReconcileReport{}, nil
+	}
+
+	return f.reconcile(ctx, *resources, apply)
+}
+
+// reconcile reads the raw ownership tuples (relationServer/relationProject/relationOrganization) back from the
+// OpenFGA tuple store, rather than trusting ListObjects, and diffs them against the tuples
+// syncResources would derive from resources. Unlike syncResources, which only ever adds
+// missing tuples and removes ones its own diff can see, this also catches tuples left
+// behind by e.g. a crash mid-DeleteInstance or an out-of-band edit directly against the
+// OpenFGA store.
+func (f *FGA) reconcile(ctx context.Context, resources Resources, apply bool) (*FGAReconcileReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	var actual []client.ClientTupleKey
+	for _, relation := range []string{relationServer, relationProject, relationOrganization} {
+		tuples, err := f.readTuplesByRelation(ctx, relation)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading OpenFGA tuples for relation %q: %w", relation, err)
+		}
+
+		actual = append(actual, tuples...)
+	}
+
+	expected := fgaExpectedTuples(resources)
+
+	expectedSet := map[fgaQueueKey]bool{}
+	for _, tuple := range expected {
+		expectedSet[fgaQueueKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}] = true
+	}
+
+	actualSet := map[fgaQueueKey]bool{}
+	for _, tuple := range actual {
+		actualSet[fgaQueueKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}] = true
+	}
+
+	report := &FGAReconcileReport{}
+
+	for _, tuple := range expected {
+		if !actualSet[fgaQueueKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}] {
+			report.MissingTuples = append(report.MissingTuples, tuple)
+		}
+	}
+
+	for _, tuple := range actual {
+		if !expectedSet[fgaQueueKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}] {
+			report.ExtraTuples = append(report.ExtraTuples, client.ClientTupleKeyWithoutCondition{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object})
+		}
+	}
+
+	if apply && (len(report.MissingTuples) > 0 || len(report.ExtraTuples) > 0) {
+		err := f.updateTuples(ctx, report.MissingTuples, report.ExtraTuples)
+		if err != nil {
+			return report, err
+		}
+
+		report.Applied = true
+	}
+
+	return report, nil
+}
+
+// fgaReadPageSize bounds how many tuples client.Read returns per page while paginating
+// through the raw tuple store during reconciliation.
+const fgaReadPageSize = 100
+
+// readTuplesByRelation paginates through every tuple in the OpenFGA store with the given
+// relation, regardless of what the authorization model's computed relations would return
+// for ListObjects/Check, so reconciliation can see tuples a broken model or a manual edit
+// left in a state ListObjects wouldn't reflect.
+func (f *FGA) readTuplesByRelation(ctx context.Context, relation string) ([]client.ClientTupleKey, error) {
+	var tuples []client.ClientTupleKey
+	continuationToken := ""
+	pageSize := int32(fgaReadPageSize)
+
+	for {
+		resp, err := f.client.Read(ctx).Body(client.ClientReadRequest{
+			Relation: relation,
+		}).Options(client.ClientReadOptions{
+			PageSize:          &pageSize,
+			ContinuationToken: &continuationToken,
+		}).Execute()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tuple := range resp.GetTuples() {
+			key := tuple.GetKey()
+			tuples = append(tuples, client.ClientTupleKey{
+				User:     key.GetUser(),
+				Relation: key.GetRelation(),
+				Object:   key.GetObject(),
+			})
+		}
+
+		continuationToken = resp.GetContinuationToken()
+		if continuationToken == "" {
+			return tuples, nil
+		}
+	}
+}
+
+// fgaExpectedTuples derives the ownership tuples (relationServer/relationProject, plus the
+// relationOrganization parent tuples of projects that belong to an organization) that
+// should exist in OpenFGA given the current local resources, mirroring the tuples
+// syncResources/Add*/Delete* maintain.
+func fgaExpectedTuples(resources Resources) []client.ClientTupleKey {
+	server := ObjectServer().String()
+
+	var tuples []client.ClientTupleKey
+	for _, object := range resources.CertificateObjects {
+		tuples = append(tuples, client.ClientTupleKey{User: server, Relation: relationServer, Object: object.String()})
+	}
+
+	for _, object := range resources.NetworkIntegrationObjects {
+		tuples = append(tuples, client.ClientTupleKey{User: server, Relation: relationServer, Object: object.String()})
+	}
+
+	for _, object := range resources.StoragePoolObjects {
+		tuples = append(tuples, client.ClientTupleKey{User: server, Relation: relationServer, Object: object.String()})
+	}
+
+	for _, object := range resources.ProjectObjects {
+		tuples = append(tuples, client.ClientTupleKey{User: server, Relation: relationServer, Object: object.String()})
+	}
+
+	for _, object := range resources.OrganizationObjects {
+		tuples = append(tuples, client.ClientTupleKey{User: server, Relation: relationServer, Object: object.String()})
+	}
+
+	for projectName, organizationName := range resources.ProjectOrganization {
+		if organizationName == "" {
+			continue
+		}
+
+		tuples = append(tuples, client.ClientTupleKey{User: ObjectOrganization(organizationName).String(), Relation: relationOrganization, Object: ObjectProject(projectName).String()})
+	}
+
+	var projectOwned []Object
+	projectOwned = append(projectOwned, resources.ImageObjects...)
+	projectOwned = append(projectOwned, resources.ImageAliasObjects...)
+	projectOwned = append(projectOwned, resources.InstanceObjects...)
+	projectOwned = append(projectOwned, resources.NetworkObjects...)
+	projectOwned = append(projectOwned, resources.NetworkZoneObjects...)
+	projectOwned = append(projectOwned, resources.NetworkACLObjects...)
+	projectOwned = append(projectOwned, resources.NetworkAddressSetObjects...)
+	projectOwned = append(projectOwned, resources.ProfileObjects...)
+	projectOwned = append(projectOwned, resources.StoragePoolVolumeObjects...)
+	projectOwned = append(projectOwned, resources.StorageBucketObjects...)
+
+	for _, object := range projectOwned {
+		tuples = append(tuples, client.ClientTupleKey{User: ObjectProject(object.Project()).String(), Relation: relationProject, Object: object.String()})
+	}
+
+	return tuples
+}
+
 // GetInstanceAccess returns the list of entities who have access to the instance.
 func (f *FGA) GetInstanceAccess(ctx context.Context, projectName string, instanceName string) (*api.Access, error) {
 	// Get all the entries from OpenFGA.
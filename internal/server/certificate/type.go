@@ -18,6 +18,9 @@ const TypeServer = Type(2)
 // TypeMetrics indicates a metrics certificate type.
 const TypeMetrics = Type(3)
 
+// TypeRevoked indicates a certificate that has been revoked and must no longer be trusted.
+const TypeRevoked = Type(4)
+
 // FromAPIType converts an API type to the equivalent Type.
 func FromAPIType(apiType string) (Type, error) {
 	switch apiType {
@@ -27,7 +30,25 @@ func FromAPIType(apiType string) (Type, error) {
 		return TypeServer, nil
 	case api.CertificateTypeMetrics:
 		return TypeMetrics, nil
+	case api.CertificateTypeRevoked:
+		return TypeRevoked, nil
 	}
 
 	return -1, errors.New("Invalid certificate type")
 }
+
+// ToAPIType converts a Type to the equivalent API type.
+func ToAPIType(certType Type) (string, error) {
+	switch certType {
+	case TypeClient:
+		return api.CertificateTypeClient, nil
+	case TypeServer:
+		return api.CertificateTypeServer, nil
+	case TypeMetrics:
+		return api.CertificateTypeMetrics, nil
+	case TypeRevoked:
+		return api.CertificateTypeRevoked, nil
+	}
+
+	return "", errors.New("Invalid certificate type")
+}
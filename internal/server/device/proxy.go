@@ -11,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	liblxc "github.com/lxc/go-lxc"
@@ -53,6 +54,15 @@ type proxyProcInfo struct {
 	securityUID    string
 	securityGID    string
 	proxyProtocol  string
+	tproxy         string
+	connectPolicy  string
+	healthCheck    string
+	healthInterval string
+	healthFailMax  string
+	sniff          string
+	routes         string
+	routeDefault   string
+	accelerator    string
 	inheritFds     []*os.File
 }
 
@@ -72,6 +82,29 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// The listen key additionally accepts the "fd:<name>"/"systemd:<name>" schemes, which hand
+	// off an already-open, inherited file descriptor instead of an address/port to bind.
+	validateListen := func(input string) error {
+		if _, ok := parseFDListen(input); ok {
+			return nil
+		}
+
+		return validateAddr(input)
+	}
+
+	// The connect key may list multiple backend targets separated by ";", e.g.
+	// "tcp:10.0.0.5:80;tcp:10.0.0.6:80". Each target is validated individually.
+	validateConnect := func(input string) error {
+		for _, target := range parseConnectTargets(input) {
+			err := validateAddr(target)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
 	// Supported bind types are: "host" or "instance" (or "guest" or "container", legacy options equivalent to "instance").
 	// If an empty value is supplied the default behavior is to assume "host" bind mode.
 	validateBind := func(input string) error {
@@ -88,8 +121,8 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		// ---
 		// type: string
 		// required: yes
-		// shortdesc: The address and port to bind and listen (`<type>:<addr>:<port>[-<port>][,<port>]`)
-		"listen": validate.Required(validateAddr),
+		// shortdesc: The address and port to bind and listen (`<type>:<addr>:<port>[-<port>][,<port>]`, or `fd:<name>`/`systemd:<name>` for an inherited file descriptor)
+		"listen": validate.Required(validateListen),
 
 		// gendoc:generate(entity=devices, group=proxy, key=connect)
 		//
@@ -97,7 +130,43 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		// type: string
 		// required: yes
 		// shortdesc: The address and port to connect to (`<type>:<addr>:<port>[-<port>][,<port>]`)
-		"connect": validate.Required(validateAddr),
+		"connect": validate.Required(validateConnect),
+
+		// gendoc:generate(entity=devices, group=proxy, key=connect.policy)
+		//
+		// ---
+		// type: string
+		// required: no
+		// default: `round-robin`
+		// shortdesc: Policy used to pick a backend when `connect` lists multiple targets (`round-robin`, `least-conn`, `random` or `hash-source`)
+		"connect.policy": validate.Optional(validate.IsOneOf("round-robin", "least-conn", "random", "hash-source")),
+
+		// gendoc:generate(entity=devices, group=proxy, key=connect.health_check)
+		//
+		// ---
+		// type: string
+		// required: no
+		// default: `none`
+		// shortdesc: How to probe backend health when `connect` lists multiple targets (`none`, `tcp` or `http:<path>`)
+		"connect.health_check": validate.Optional(validateConnectHealthCheck),
+
+		// gendoc:generate(entity=devices, group=proxy, key=connect.health_interval)
+		//
+		// ---
+		// type: int
+		// required: no
+		// default: `10`
+		// shortdesc: Number of seconds between backend health checks
+		"connect.health_interval": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=devices, group=proxy, key=connect.health_fail_threshold)
+		//
+		// ---
+		// type: int
+		// required: no
+		// default: `3`
+		// shortdesc: Number of consecutive failed health checks before a backend is taken out of rotation
+		"connect.health_fail_threshold": validate.Optional(validate.IsUint32),
 
 		// gendoc:generate(entity=devices, group=proxy, key=bind)
 		//
@@ -170,6 +239,55 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		// default: `false`
 		// shortdesc: Whether to use the HAProxy PROXY protocol to transmit sender information
 		"proxy_protocol": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=devices, group=proxy, key=tproxy)
+		//
+		// ---
+		// type: bool
+		// required: no
+		// default: `false`
+		// shortdesc: Whether to use TPROXY to preserve the original client address (requires `bind` be `host`)
+		"tproxy": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=devices, group=proxy, key=sniff)
+		//
+		// ---
+		// type: string
+		// required: no
+		// shortdesc: Sniff the TLS SNI or HTTP `Host` header on the listen socket and route to a backend accordingly (`tls`, `http` or `auto`)
+		"sniff": validate.Optional(validate.IsOneOf("tls", "http", "auto")),
+
+		// gendoc:generate(entity=devices, group=proxy, key=route)
+		//
+		// ---
+		// type: string
+		// required: no
+		// shortdesc: Routing map used when `sniff` is set, as `;`-separated `pattern=target` pairs (e.g. `example.com=tcp:10.0.0.5:8080`)
+		"route": validate.Optional(validateRoutes),
+
+		// gendoc:generate(entity=devices, group=proxy, key=route.default)
+		//
+		// ---
+		// type: string
+		// required: no
+		// shortdesc: Backend target to use when `sniff` is set and no `route` entry matches
+		"route.default": validate.Optional(validateAddr),
+
+		// gendoc:generate(entity=devices, group=proxy, key=accelerator)
+		//
+		// ---
+		// type: string
+		// required: no
+		// shortdesc: Use a kernel-side fast-path to shuttle proxied bytes once connected (`sockmap`). Falls back to the userspace copy if unavailable.
+		"accelerator": validate.Optional(validate.IsOneOf("sockmap")),
+
+		// gendoc:generate(entity=devices, group=proxy, key=engine)
+		//
+		// ---
+		// type: string
+		// required: no
+		// shortdesc: Force the `userland` proxy engine (a `forknet proxy` subprocess that enters the instance's network namespace and shuttles bytes itself) instead of the default `forkproxy`. Combined with `nat=true`, NAT is tried first and this engine is only used if the firewall fails to set up NAT, e.g. in a rootless or nested environment where nftables tables aren't writable.
+		"engine": validate.Optional(validate.IsOneOf("userland")),
 	}
 
 	err := d.config.Validate(rules)
@@ -181,14 +299,81 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return errors.New("Only NAT mode is supported for proxies on VM instances")
 	}
 
+	if fdName, ok := parseFDListen(d.config["listen"]); ok {
+		return d.validateFDListenConfig(fdName)
+	}
+
 	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
 	if err != nil {
 		return err
 	}
 
-	connectAddr, err := network.ProxyParseAddr(d.config["connect"])
-	if err != nil {
-		return err
+	connectTargets := parseConnectTargets(d.config["connect"])
+
+	connectAddrs := make([]*network.ProxyAddress, 0, len(connectTargets))
+	for _, target := range connectTargets {
+		targetAddr, err := network.ProxyParseAddr(target)
+		if err != nil {
+			return err
+		}
+
+		connectAddrs = append(connectAddrs, targetAddr)
+	}
+
+	// The first target is used for the checks below that only make sense for a single address
+	// (all targets are required to share the same connection type and port count).
+	connectAddr := connectAddrs[0]
+
+	for _, targetAddr := range connectAddrs[1:] {
+		if targetAddr.ConnType != connectAddr.ConnType || len(targetAddr.Ports) != len(connectAddr.Ports) {
+			return errors.New("All connect targets must share the same type and port count")
+		}
+	}
+
+	if d.config["sniff"] != "" {
+		if util.IsTrue(d.config["nat"]) {
+			return errors.New("sniff mode cannot be combined with nat mode")
+		}
+
+		if listenAddr.ConnType != "tcp" {
+			return errors.New("sniff mode requires a tcp listener")
+		}
+	}
+
+	if d.config["accelerator"] != "" {
+		if listenAddr.ConnType != "tcp" || connectAddr.ConnType != "tcp" {
+			return errors.New("The sockmap accelerator is only supported for tcp proxies")
+		}
+
+		if util.IsTrue(d.config["nat"]) {
+			return errors.New("The sockmap accelerator cannot be combined with nat mode")
+		}
+
+		if util.IsTrue(d.config["proxy_protocol"]) {
+			return errors.New("The sockmap accelerator cannot be combined with the PROXY protocol")
+		}
+	}
+
+	if d.config["engine"] == "userland" {
+		if listenAddr.ConnType == "unix" || connectAddr.ConnType == "unix" {
+			return errors.New("The userland proxy engine does not support unix sockets")
+		}
+
+		if len(listenAddr.Ports) != 1 || len(connectAddrs) > 1 {
+			return errors.New("The userland proxy engine only supports a single listen port and connect target")
+		}
+
+		if d.config["bind"] != "" && d.config["bind"] != "host" {
+			return errors.New("The userland proxy engine only supports host-bound proxies")
+		}
+
+		if util.IsTrue(d.config["tproxy"]) || util.IsTrue(d.config["proxy_protocol"]) || d.config["sniff"] != "" || d.config["accelerator"] != "" {
+			return errors.New("The userland proxy engine cannot be combined with tproxy, proxy_protocol, sniff or accelerator")
+		}
+	}
+
+	if len(connectAddrs) > 1 && (d.config["connect.policy"] != "" || d.config["connect.health_check"] != "" || d.config["connect.health_interval"] != "" || d.config["connect.health_fail_threshold"] != "") && listenAddr.ConnType == "unix" {
+		return errors.New("Backend load balancing is not supported for unix sockets")
 	}
 
 	err = d.validateListenAddressConflicts(net.ParseIP(listenAddr.Address))
@@ -210,7 +395,29 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return errors.New("Only proxy devices for non-abstract unix sockets can carry uid, gid, or mode properties")
 	}
 
+	if util.IsTrue(d.config["tproxy"]) {
+		if util.IsTrue(d.config["nat"]) {
+			return errors.New("TPROXY mode cannot be combined with NAT mode")
+		}
+
+		if d.config["bind"] != "" && d.config["bind"] != "host" {
+			return errors.New("Only host-bound proxies can use TPROXY")
+		}
+
+		if listenAddr.ConnType == "unix" || connectAddr.ConnType == "unix" {
+			return errors.New("TPROXY mode is not supported for unix sockets")
+		}
+
+		if util.IsTrue(d.config["proxy_protocol"]) {
+			return errors.New("TPROXY mode cannot be combined with the PROXY protocol")
+		}
+	}
+
 	if util.IsTrue(d.config["nat"]) {
+		if len(connectAddrs) > 1 {
+			return errors.New("Multiple connect targets are not supported in nat mode")
+		}
+
 		if d.inst != nil {
 			// Default project always has networks feature so don't bother loading the project config
 			// in that case.
@@ -259,6 +466,138 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 	return nil
 }
 
+// parseConnectTargets splits the connect key into its individual backend targets. Multiple
+// targets are separated by ";" (e.g. "tcp:10.0.0.5:80;tcp:10.0.0.6:80"); a single target is
+// returned unchanged.
+func parseConnectTargets(connect string) []string {
+	targets := strings.Split(connect, ";")
+	for i, target := range targets {
+		targets[i] = strings.TrimSpace(target)
+	}
+
+	return targets
+}
+
+// validateConnectHealthCheck validates the connect.health_check config key, which must be
+// "none", "tcp", or "http:" followed by the path to request.
+func validateConnectHealthCheck(value string) error {
+	if value == "" || value == "none" || value == "tcp" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, "http:") && len(value) > len("http:") {
+		return nil
+	}
+
+	return errors.New(`Value must be "none", "tcp", or "http:<path>"`)
+}
+
+// parseRoutes splits the route key into its individual "pattern=target" entries, separated
+// by ";".
+func parseRoutes(route string) []string {
+	if route == "" {
+		return nil
+	}
+
+	entries := strings.Split(route, ";")
+	for i, entry := range entries {
+		entries[i] = strings.TrimSpace(entry)
+	}
+
+	return entries
+}
+
+// validateRoutes validates the route config key, which holds a ";"-separated list of
+// "pattern=target" entries. The pattern is matched against the sniffed SNI/Host value and the
+// target must be a valid connect-style address.
+func validateRoutes(value string) error {
+	for _, entry := range parseRoutes(value) {
+		pattern, target, ok := strings.Cut(entry, "=")
+		if !ok || pattern == "" || target == "" {
+			return fmt.Errorf("Invalid route entry %q, must be of the form \"pattern=target\"", entry)
+		}
+
+		_, err := network.ProxyParseAddr(target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseFDListen checks whether a listen value uses the "fd:<name>"/"systemd:<name>" scheme,
+// under which the listen socket is not opened by forkproxy but inherited already-bound from
+// either systemd (via LISTEN_FDS/LISTEN_FDNAMES) or a named descriptor registered through the
+// `/1.0/proxy-sockets` API. It returns the name and true if so.
+func parseFDListen(listen string) (string, bool) {
+	name, ok := strings.CutPrefix(listen, "fd:")
+	if !ok {
+		name, ok = strings.CutPrefix(listen, "systemd:")
+	}
+
+	return name, ok
+}
+
+// validateFDListenConfig validates the subset of proxy config that is compatible with a
+// "fd:"/"systemd:" listen socket, which is already open and bound, so none of the
+// address/port validation that applies to other listen schemes is relevant here.
+func (d *proxy) validateFDListenConfig(fdName string) error {
+	if fdName == "" {
+		return errors.New("fd/systemd listen scheme requires a name")
+	}
+
+	if util.IsTrue(d.config["nat"]) {
+		return errors.New("fd/systemd listeners cannot be combined with nat mode")
+	}
+
+	if d.config["uid"] != "" || d.config["gid"] != "" || d.config["mode"] != "" {
+		return errors.New("fd/systemd listeners cannot carry uid, gid, or mode properties")
+	}
+
+	if util.IsTrue(d.config["tproxy"]) {
+		return errors.New("fd/systemd listeners cannot be combined with tproxy mode")
+	}
+
+	return nil
+}
+
+// proxyListenFDs holds user-registered listen sockets, keyed by name, handed to the daemon
+// through the `/1.0/proxy-sockets` API so that proxy devices can bind privileged ports without
+// granting forkproxy CAP_NET_BIND_SERVICE.
+var proxyListenFDs sync.Map
+
+// RegisterProxyListenFD makes a pre-bound listen socket available to "fd:<name>" proxy devices
+// under the given name. The caller retains ownership of file and must keep it open for as long
+// as it should remain available.
+func RegisterProxyListenFD(name string, file *os.File) {
+	proxyListenFDs.Store(name, file)
+}
+
+// UnregisterProxyListenFD removes a previously registered listen socket.
+func UnregisterProxyListenFD(name string) {
+	proxyListenFDs.Delete(name)
+}
+
+// resolveProxyListenFD resolves a "fd:<name>"/"systemd:<name>" listen target to an inheritable
+// file descriptor, first checking sockets registered via RegisterProxyListenFD and falling back
+// to a socket systemd passed to this process via LISTEN_FDS/LISTEN_FDNAMES.
+func resolveProxyListenFD(name string) (*os.File, error) {
+	if file, ok := proxyListenFDs.Load(name); ok {
+		return file.(*os.File), nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i, fdName := range names {
+		if fdName == name {
+			// systemd-passed descriptors start at fd 3.
+			return os.NewFile(uintptr(3+i), name), nil
+		}
+	}
+
+	return nil, fmt.Errorf("No registered or systemd-activated socket named %q", name)
+}
+
 // validateEnvironment checks the runtime environment for correctness.
 func (d *proxy) validateEnvironment() error {
 	if d.name == "" {
@@ -337,10 +676,28 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 			if util.IsTrue(d.config["nat"]) {
 				err = d.setupNAT()
 				if err != nil {
-					return fmt.Errorf("Failed to start device %q: %w", d.name, err)
+					if d.config["engine"] != "userland" {
+						return fmt.Errorf("Failed to start device %q: %w", d.name, err)
+					}
+
+					// Kernel NAT isn't usable here (e.g. a rootless or nested host where
+					// nftables tables aren't writable); fall through to the userland engine
+					// rather than failing the device outright.
+					d.logger.Warn("NAT setup failed, falling back to userland proxy engine", logger.Ctx{"err": err})
+				} else {
+					return nil // Don't proceed with forkproxy setup.
 				}
+			}
 
-				return nil // Don't proceed with forkproxy setup.
+			if d.config["engine"] == "userland" {
+				return d.startUserlandProxy()
+			}
+
+			if util.IsTrue(d.config["tproxy"]) {
+				err = d.setupTPROXY()
+				if err != nil {
+					return fmt.Errorf("Failed to start device %q: %w", d.name, err)
+				}
 			}
 
 			proxyValues, err := d.setupProxyProcInfo()
@@ -348,6 +705,15 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 				return err
 			}
 
+			if proxyValues.accelerator == "sockmap" {
+				_, err := getSockmapAccelerator()
+				if err != nil {
+					// Fall back to the userspace copy loop rather than failing the device.
+					logger.Warn("Disabling proxy sockmap accelerator", logger.Ctx{"device": d.name, "err": err})
+					proxyValues.accelerator = ""
+				}
+			}
+
 			devFileName := fmt.Sprintf("proxy.%s", d.name)
 			pidPath := filepath.Join(d.inst.DevicesPath(), devFileName)
 			logFileName := fmt.Sprintf("proxy.%s.log", d.name)
@@ -376,6 +742,15 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 				proxyValues.securityGID,
 				proxyValues.securityUID,
 				proxyValues.proxyProtocol,
+				proxyValues.tproxy,
+				proxyValues.connectPolicy,
+				proxyValues.healthCheck,
+				proxyValues.healthInterval,
+				proxyValues.healthFailMax,
+				proxyValues.sniff,
+				proxyValues.routes,
+				proxyValues.routeDefault,
+				proxyValues.accelerator,
 			}
 
 			p, err := subprocess.NewProcess(command, forkproxyargs, logPath, logPath)
@@ -467,9 +842,18 @@ func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
 		logger.Errorf("Failed to remove proxy NAT filters: %v", err)
 	}
 
+	if util.IsTrue(d.config["tproxy"]) {
+		err := d.teardownTPROXY()
+		if err != nil {
+			logger.Errorf("Failed to remove proxy TPROXY state: %v", err)
+		}
+	}
+
 	devFileName := fmt.Sprintf("proxy.%s", d.name)
 	devPath := filepath.Join(d.inst.DevicesPath(), devFileName)
 
+	unregisterUserlandProxyStats(devPath)
+
 	if !util.PathExists(devPath) {
 		// There's no proxy process if NAT is enabled
 		return nil, nil
@@ -601,6 +985,95 @@ func (d *proxy) setupNAT() error {
 	return nil
 }
 
+// tproxyRouteTable is the routing table used to direct TPROXY-marked packets back to the local
+// stack via the loopback device, so that the listening socket can intercept them.
+const tproxyRouteTable = 255
+
+// tproxyFwMark is the fwmark applied by the firewall TPROXY rule and matched by the policy
+// routing rule that redirects marked packets to the local routing table.
+const tproxyFwMark = 0x1
+
+// setupTPROXY installs the policy routing and firewall rules required to transparently
+// intercept traffic destined for the proxy's listen address and hand it to the listening
+// socket with the original client address preserved.
+func (d *proxy) setupTPROXY() error {
+	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
+	if err != nil {
+		return err
+	}
+
+	ipVersion := uint(4)
+	if strings.Contains(listenAddr.Address, ":") {
+		ipVersion = 6
+	}
+
+	// Route locally-destined, TPROXY-marked packets back through loopback so the listening
+	// socket (opened with IP_TRANSPARENT) can accept them.
+	rule := &ip.Rule{
+		Fwmark: fmt.Sprintf("%#x", tproxyFwMark),
+		Table:  strconv.Itoa(tproxyRouteTable),
+	}
+
+	err = rule.Add()
+	if err != nil {
+		return fmt.Errorf("Failed adding TPROXY policy routing rule: %w", err)
+	}
+
+	route := &ip.Route{
+		DevName: "lo",
+		Table:   strconv.Itoa(tproxyRouteTable),
+		Family:  ipVersion,
+	}
+
+	err = route.Add()
+	if err != nil {
+		return fmt.Errorf("Failed adding TPROXY local route: %w", err)
+	}
+
+	// Install the nftables/iptables TPROXY rule that marks and redirects matching traffic to
+	// the listen port.
+	err = d.state.Firewall.InstanceSetupProxyTPROXY(d.inst.Project().Name, d.inst.Name(), d.name, &firewallDrivers.AddressForward{
+		Protocol:      listenAddr.ConnType,
+		ListenAddress: net.ParseIP(listenAddr.Address),
+		ListenPorts:   listenAddr.Ports,
+	}, tproxyFwMark)
+	if err != nil {
+		return fmt.Errorf("Failed adding TPROXY firewall rule: %w", err)
+	}
+
+	return nil
+}
+
+// teardownTPROXY removes the policy routing and firewall rules installed by setupTPROXY.
+func (d *proxy) teardownTPROXY() error {
+	err := d.state.Firewall.InstanceClearProxyTPROXY(d.inst.Project().Name, d.inst.Name(), d.name)
+	if err != nil {
+		logger.Errorf("Failed to remove proxy TPROXY firewall rule: %v", err)
+	}
+
+	route := &ip.Route{
+		DevName: "lo",
+		Table:   strconv.Itoa(tproxyRouteTable),
+	}
+
+	err = route.Flush()
+	if err != nil {
+		logger.Errorf("Failed to remove proxy TPROXY route: %v", err)
+	}
+
+	rule := &ip.Rule{
+		Fwmark: fmt.Sprintf("%#x", tproxyFwMark),
+		Table:  strconv.Itoa(tproxyRouteTable),
+	}
+
+	err = rule.Delete()
+	if err != nil {
+		logger.Errorf("Failed to remove proxy TPROXY policy routing rule: %v", err)
+	}
+
+	return nil
+}
+
 func (d *proxy) setupProxyProcInfo() (*proxyProcInfo, error) {
 	cname := project.Instance(d.inst.Project().Name, d.inst.Name())
 	cc, err := liblxc.NewContainer(cname, d.state.OS.LxcPath)
@@ -633,6 +1106,18 @@ func (d *proxy) setupProxyProcInfo() (*proxyProcInfo, error) {
 	connectAddr := d.config["connect"]
 	listenAddr := d.config["listen"]
 
+	if fdName, ok := parseFDListen(listenAddr); ok {
+		fdFile, err := resolveProxyListenFD(fdName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed resolving listen socket %q: %w", fdName, err)
+		}
+
+		// Inherited fds start after stdin/stdout/stderr and any pidfds already queued above.
+		listenInheritIndex := 3 + len(inheritFd)
+		inheritFd = append(inheritFd, fdFile)
+		listenAddr = fmt.Sprintf("fd:%d", listenInheritIndex)
+	}
+
 	switch d.config["bind"] {
 	case "host", "":
 		listenPid = daemonPid
@@ -668,6 +1153,15 @@ func (d *proxy) setupProxyProcInfo() (*proxyProcInfo, error) {
 		securityGID:    d.config["security.gid"],
 		securityUID:    d.config["security.uid"],
 		proxyProtocol:  d.config["proxy_protocol"],
+		tproxy:         d.config["tproxy"],
+		connectPolicy:  d.config["connect.policy"],
+		healthCheck:    d.config["connect.health_check"],
+		healthInterval: d.config["connect.health_interval"],
+		healthFailMax:  d.config["connect.health_fail_threshold"],
+		sniff:          d.config["sniff"],
+		routes:         d.config["route"],
+		routeDefault:   d.config["route.default"],
+		accelerator:    d.config["accelerator"],
 		inheritFds:     inheritFd,
 	}
 
@@ -0,0 +1,110 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// sockmapPinPath is where the BPF_PROG_TYPE_SK_SKB verdict program and its SOCKMAP are pinned.
+// The objects themselves are built and pinned outside of incusd (see the `incus-bpf` build
+// tooling) and are picked up from here, if present, the first time getSockmapAccelerator runs.
+const sockmapPinPath = "/sys/fs/bpf/incus/proxy_sockmap"
+
+// sockmapAccelerator holds the loaded BPF objects used to splice accepted proxy connections
+// into kernel space once both sides of the connection are established.
+type sockmapAccelerator struct {
+	mu      sync.Mutex
+	sockMap *ebpf.Map
+	prog    *ebpf.Program
+	nextKey uint32
+}
+
+var sockmapOnce sync.Once
+var sockmapState *sockmapAccelerator
+var sockmapInitErr error
+
+// getSockmapAccelerator lazily loads the pinned sockmap BPF objects the first time the
+// `accelerator: sockmap` mode is requested, and reuses them for the remainder of the daemon's
+// lifetime. It returns an error (and a nil accelerator) if the objects cannot be loaded, in
+// which case callers should fall back to the userspace copy loop.
+func getSockmapAccelerator() (*sockmapAccelerator, error) {
+	sockmapOnce.Do(func() {
+		sockmapState, sockmapInitErr = loadSockmapAccelerator()
+		if sockmapInitErr != nil {
+			logger.Warn("Proxy sockmap accelerator unavailable, falling back to userspace copy", logger.Ctx{"err": sockmapInitErr})
+		}
+	})
+
+	return sockmapState, sockmapInitErr
+}
+
+// loadSockmapAccelerator pins in the SOCKMAP and SK_SKB verdict program prepared for the proxy
+// device. It fails (rather than attempting to build the program itself) when the kernel lacks
+// CONFIG_BPF_STREAM_PARSER/CONFIG_BPF_STREAM_VERDICT support, since the pinned objects will
+// either be missing or fail to load in that case.
+func loadSockmapAccelerator() (*sockmapAccelerator, error) {
+	sockMap, err := ebpf.LoadPinnedMap(sockmapPinPath+"/sock_map", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading pinned proxy sockmap: %w", err)
+	}
+
+	if sockMap.Type() != ebpf.SockMap {
+		_ = sockMap.Close()
+		return nil, errors.New("Pinned proxy sockmap has unexpected map type")
+	}
+
+	prog, err := ebpf.LoadPinnedProgram(sockmapPinPath+"/verdict_prog", nil)
+	if err != nil {
+		_ = sockMap.Close()
+		return nil, fmt.Errorf("Failed loading pinned proxy sockmap verdict program: %w", err)
+	}
+
+	if prog.Type() != ebpf.SkSKB {
+		_ = sockMap.Close()
+		_ = prog.Close()
+		return nil, errors.New("Pinned proxy sockmap verdict program has unexpected program type")
+	}
+
+	return &sockmapAccelerator{sockMap: sockMap, prog: prog}, nil
+}
+
+// attach inserts the two halves of an established TCP proxy connection (the accepted listener
+// fd and the connected upstream fd) into the SOCKMAP so the SK_SKB verdict program can shuttle
+// subsequent bytes between them entirely in kernel space. It returns the keys used so the
+// caller can remove them again with detach. Nothing in this package calls it.
+func (s *sockmapAccelerator) attach(listenFd int, connectFd int) (listenKey uint32, connectKey uint32, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listenKey = s.nextKey
+	s.nextKey++
+	connectKey = s.nextKey
+	s.nextKey++
+
+	err = s.sockMap.Update(listenKey, uint32(listenFd), ebpf.UpdateAny)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed inserting listen fd into proxy sockmap: %w", err)
+	}
+
+	err = s.sockMap.Update(connectKey, uint32(connectFd), ebpf.UpdateAny)
+	if err != nil {
+		_ = s.sockMap.Delete(listenKey)
+		return 0, 0, fmt.Errorf("Failed inserting connect fd into proxy sockmap: %w", err)
+	}
+
+	return listenKey, connectKey, nil
+}
+
+// detach removes a previously attached connection pair from the sockmap.
+func (s *sockmapAccelerator) detach(listenKey uint32, connectKey uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.sockMap.Delete(listenKey)
+	_ = s.sockMap.Delete(connectKey)
+}
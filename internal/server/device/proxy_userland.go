@@ -0,0 +1,183 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	liblxc "github.com/lxc/go-lxc"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lxc/incus/v6/internal/server/network"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// ProxyStatsRecord is the userland proxy engine's JSON-persisted byte/connection counters. It is
+// written by `forknet proxy` (see cmd/incusd/main_forknet_proxy.go) as it runs, and read back by
+// userlandProxyCollector at Prometheus scrape time, since the counters are owned by that
+// subprocess rather than by incusd itself.
+type ProxyStatsRecord struct {
+	Project     string `json:"project"`
+	Instance    string `json:"instance"`
+	Device      string `json:"device"`
+	TxBytes     int64  `json:"tx_bytes"`
+	RxBytes     int64  `json:"rx_bytes"`
+	Connections int64  `json:"connections"`
+}
+
+// userlandProxyStats tracks the stats file of every currently running engine=userland proxy
+// device, keyed by its PID file path (the same devPath killProxyProc uses), so
+// userlandProxyCollector knows which files to read without having to glob the filesystem.
+var userlandProxyStats sync.Map
+
+// registerUserlandProxyStats records statsPath as belonging to the engine=userland proxy device
+// at devPath, making it visible to the Prometheus collector once one exists (see
+// registerUserlandProxyMetrics).
+func registerUserlandProxyStats(devPath string, statsPath string) {
+	registerUserlandProxyMetrics()
+	userlandProxyStats.Store(devPath, statsPath)
+}
+
+// unregisterUserlandProxyStats removes the stats file association added by
+// registerUserlandProxyStats. It is a no-op if devPath was never registered, so Stop() can call
+// it unconditionally for every proxy device.
+func unregisterUserlandProxyStats(devPath string) {
+	userlandProxyStats.Delete(devPath)
+}
+
+var userlandProxyMetricsOnce sync.Once
+
+// registerUserlandProxyMetrics registers userlandProxyCollector with the default Prometheus
+// registry the first time an engine=userland proxy device starts, mirroring how
+// getSockmapAccelerator lazily initializes its BPF objects on first use rather than unconditionally
+// at daemon startup.
+func registerUserlandProxyMetrics() {
+	userlandProxyMetricsOnce.Do(func() {
+		prometheus.MustRegister(&userlandProxyCollector{})
+	})
+}
+
+var (
+	userlandProxyBytesDesc = prometheus.NewDesc(
+		"incus_proxy_userland_bytes_total",
+		"Bytes shuttled by engine=userland proxy devices.",
+		[]string{"project", "instance", "device", "direction"}, nil)
+
+	userlandProxyConnectionsDesc = prometheus.NewDesc(
+		"incus_proxy_userland_connections_total",
+		"Connections shuttled by engine=userland proxy devices.",
+		[]string{"project", "instance", "device"}, nil)
+)
+
+// userlandProxyCollector implements prometheus.Collector by reading every registered
+// engine=userland proxy device's JSON stats file at scrape time, rather than keeping its own
+// counters, since the forknet proxy subprocess that owns them has no HTTP endpoint of its own to
+// scrape directly.
+type userlandProxyCollector struct{}
+
+// Describe implements prometheus.Collector.
+func (c *userlandProxyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- userlandProxyBytesDesc
+	ch <- userlandProxyConnectionsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *userlandProxyCollector) Collect(ch chan<- prometheus.Metric) {
+	userlandProxyStats.Range(func(_, value any) bool {
+		statsPath, ok := value.(string)
+		if !ok {
+			return true
+		}
+
+		bb, err := os.ReadFile(statsPath)
+		if err != nil {
+			// Not written yet, or the subprocess hasn't started shuttling bytes.
+			return true
+		}
+
+		var record ProxyStatsRecord
+
+		err = json.Unmarshal(bb, &record)
+		if err != nil {
+			logger.Warn("Failed parsing userland proxy stats file", logger.Ctx{"path": statsPath, "err": err})
+
+			return true
+		}
+
+		ch <- prometheus.MustNewConstMetric(userlandProxyBytesDesc, prometheus.CounterValue, float64(record.TxBytes), record.Project, record.Instance, record.Device, "tx")
+		ch <- prometheus.MustNewConstMetric(userlandProxyBytesDesc, prometheus.CounterValue, float64(record.RxBytes), record.Project, record.Instance, record.Device, "rx")
+		ch <- prometheus.MustNewConstMetric(userlandProxyConnectionsDesc, prometheus.CounterValue, float64(record.Connections), record.Project, record.Instance, record.Device)
+
+		return true
+	})
+}
+
+// startUserlandProxy spawns a `forknet proxy` subprocess for this device, the engine=userland
+// alternative to forkproxy: it enters the instance's network namespace itself and shuttles bytes
+// with splice(2)/recvmmsg/sendmmsg instead of relying on nftables DNAT or TPROXY rules.
+func (d *proxy) startUserlandProxy() error {
+	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
+	if err != nil {
+		return err
+	}
+
+	connectAddr, err := network.ProxyParseAddr(d.config["connect"])
+	if err != nil {
+		return err
+	}
+
+	cname := project.Instance(d.inst.Project().Name, d.inst.Name())
+
+	cc, err := liblxc.NewContainer(cname, d.state.OS.LxcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = cc.Release() }()
+
+	netnsPath := fmt.Sprintf("/proc/%d/ns/net", cc.InitPid())
+
+	devFileName := fmt.Sprintf("proxy.%s", d.name)
+	pidPath := filepath.Join(d.inst.DevicesPath(), devFileName)
+	statsPath := filepath.Join(d.inst.DevicesPath(), fmt.Sprintf("proxy.%s.stats", d.name))
+	logFileName := fmt.Sprintf("proxy.%s.log", d.name)
+	logPath := filepath.Join(d.inst.LogPath(), logFileName)
+
+	args := []string{
+		"forknet",
+		"proxy",
+		netnsPath,
+		listenAddr.ConnType,
+		fmt.Sprintf("%s:%d", listenAddr.Address, listenAddr.Ports[0]),
+		fmt.Sprintf("%s:%d", connectAddr.Address, connectAddr.Ports[0]),
+		statsPath,
+		d.inst.Project().Name,
+		d.inst.Name(),
+		d.name,
+	}
+
+	p, err := subprocess.NewProcess(d.state.OS.ExecPath, args, logPath, logPath)
+	if err != nil {
+		return fmt.Errorf("Failed to start device %q: Failed to creating subprocess: %w", d.name, err)
+	}
+
+	err = p.Start(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed to start device %q: %w", d.name, err)
+	}
+
+	err = p.Save(pidPath)
+	if err != nil {
+		_ = p.Stop()
+		return fmt.Errorf("Failed to start device %q: Failed saving subprocess details: %w", d.name, err)
+	}
+
+	registerUserlandProxyStats(pidPath, statsPath)
+
+	return nil
+}
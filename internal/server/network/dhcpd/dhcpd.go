@@ -0,0 +1,207 @@
+// Package dhcpd implements a native, in-process DHCPv4/DHCPv6 server for Incus-managed bridge
+// networks, as an alternative to shelling out to dnsmasq for lease service (see
+// bridge.dhcp.backend=native in internal/server/network). It binds a raw socket on the bridge
+// with github.com/insomniacslk/dhcp's dhcpv4/server4 and dhcpv6/server6, allocates addresses from
+// a bitset-backed pool seeded from ipv4.address/ipv4.dhcp.ranges (and their IPv6 equivalents),
+// honours static reservations taken from NIC device config, and persists every lease it hands out
+// to leases.json so restarts don't forget them and so the existing Leases() API keeps working
+// without needing to know the backend in use.
+package dhcpd
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"github.com/insomniacslk/dhcp/dhcpv6/server6"
+
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+)
+
+// IPRange is an inclusive range of addresses to allocate dynamic leases from, parsed from a
+// network's ipv4.dhcp.ranges/ipv6.dhcp.ranges config key.
+type IPRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// Route is a destination/gateway pair to advertise as a DHCPv4 option 121 classless static route,
+// parsed from a network's ipv4.dhcp.routes config key.
+type Route struct {
+	Destination *net.IPNet
+	Gateway     net.IP
+}
+
+// IPv4Config is the subset of a bridge network's config needed to serve DHCPv4 leases.
+type IPv4Config struct {
+	// Subnet is the bridge's own ipv4.address CIDR, used to derive the subnet mask. The gateway
+	// address is not derived from it; only Gateway is advertised as the router.
+	Subnet *net.IPNet
+
+	// Ranges are the pools dynamic leases are allocated from. If empty, no dynamic leases are
+	// allocated and only Static reservations are served.
+	Ranges []IPRange
+
+	// Static maps a NIC's hardware address to the fixed address reserved for it, taken from
+	// that device's ipv4.address config.
+	Static map[string]net.IP
+
+	Gateway      net.IP
+	DNS          []net.IP
+	DomainSearch []string
+	Routes       []Route
+	VendorInfo   []byte
+	LeaseTime    time.Duration
+}
+
+// IPv6Config is the subset of a bridge network's config needed to serve DHCPv6 leases.
+type IPv6Config struct {
+	Subnet    *net.IPNet
+	Ranges    []IPRange
+	Static    map[string]net.IP
+	DNS       []net.IP
+	LeaseTime time.Duration
+}
+
+// Config is everything the native backend needs to serve DHCP for one network.
+type Config struct {
+	// Interface is the bridge device to bind the DHCPv4/DHCPv6 raw sockets on.
+	Interface string
+
+	// IPv4 is nil if the network isn't offering DHCPv4.
+	IPv4 *IPv4Config
+
+	// IPv6 is nil if the network isn't offering DHCPv6.
+	IPv6 *IPv6Config
+}
+
+// Server is a running native DHCP server for one network. Obtained from Start.
+type Server struct {
+	name   string
+	leases *leaseStore
+	pool4  *ipPool
+	pool6  *ipPool
+	srv4   *server4.Server
+	srv6   *server6.Server
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Server{}
+)
+
+// Start begins serving DHCP for network name according to cfg, stopping and replacing any server
+// already running for that name in this process. Leases handed out previously (as recorded in
+// leases.json) are restored into the pool before the first request is served, so a daemon restart
+// doesn't result in the same address being offered to two different clients.
+func Start(name string, cfg Config) (*Server, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if existing := registry[name]; existing != nil {
+		_ = existing.stop()
+	}
+
+	leases, err := openLeaseStore(internalUtil.VarPath("networks", name, "leases.json"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening lease store: %w", err)
+	}
+
+	srv := &Server{name: name, leases: leases}
+
+	if cfg.IPv4 != nil {
+		pool, err := newIPPool(cfg.IPv4.Ranges, cfg.IPv4.Static)
+		if err != nil {
+			return nil, fmt.Errorf("Failed building IPv4 address pool: %w", err)
+		}
+
+		for _, lease := range leases.list(leaseFamilyIPv4) {
+			pool.mark(lease.Address)
+		}
+
+		s4, err := startDHCPv4Server(cfg.Interface, cfg.IPv4, pool, leases)
+		if err != nil {
+			return nil, fmt.Errorf("Failed starting DHCPv4 server: %w", err)
+		}
+
+		srv.pool4 = pool
+		srv.srv4 = s4
+	}
+
+	if cfg.IPv6 != nil {
+		pool, err := newIPPool(cfg.IPv6.Ranges, cfg.IPv6.Static)
+		if err != nil {
+			_ = srv.stop()
+
+			return nil, fmt.Errorf("Failed building IPv6 address pool: %w", err)
+		}
+
+		for _, lease := range leases.list(leaseFamilyIPv6) {
+			pool.mark(lease.Address)
+		}
+
+		s6, err := startDHCPv6Server(cfg.Interface, cfg.IPv6, pool, leases)
+		if err != nil {
+			_ = srv.stop()
+
+			return nil, fmt.Errorf("Failed starting DHCPv6 server: %w", err)
+		}
+
+		srv.pool6 = pool
+		srv.srv6 = s6
+	}
+
+	registry[name] = srv
+
+	return srv, nil
+}
+
+// Stop stops and forgets the server for network name, if one is running in this process. It is a
+// no-op if none is, so callers don't need to track whether Start ever succeeded for name.
+func Stop(name string) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	srv := registry[name]
+	if srv == nil {
+		return nil
+	}
+
+	delete(registry, name)
+
+	return srv.stop()
+}
+
+// stop closes the underlying DHCPv4/DHCPv6 sockets, returning the first error encountered.
+func (s *Server) stop() error {
+	var firstErr error
+
+	if s.srv4 != nil {
+		if err := s.srv4.Close(); err != nil {
+			firstErr = err
+		}
+	}
+
+	if s.srv6 != nil {
+		if err := s.srv6.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Leases returns a snapshot of every lease currently persisted for network name, read directly
+// from its leases.json. Unlike Stop/Start this doesn't require a Server for name to be running in
+// this process, so it can back the existing Leases() API on a cluster member that merely reads the
+// state left behind by whichever member is actually serving DHCP for that network.
+func Leases(name string) ([]Lease, error) {
+	store, err := openLeaseStore(internalUtil.VarPath("networks", name, "leases.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return store.list(leaseFamilyAny), nil
+}
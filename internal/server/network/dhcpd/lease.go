@@ -0,0 +1,153 @@
+package dhcpd
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// leaseFamily filters leaseStore.list by address family.
+type leaseFamily int
+
+const (
+	leaseFamilyAny leaseFamily = iota
+	leaseFamilyIPv4
+	leaseFamilyIPv6
+)
+
+// Lease is a single DHCPv4 or DHCPv6 lease handed out by a native Server, in the form persisted to
+// leases.json.
+type Lease struct {
+	Hostname string
+	Hwaddr   net.HardwareAddr
+	Address  net.IP
+	Expiry   time.Time
+	Static   bool
+
+	// Offered marks a lease recorded from a DISCOVER/OFFER rather than a REQUEST/ACK: it reserves
+	// Address for Hwaddr until Expiry so a follow-up REQUEST commits the same address, but hasn't
+	// actually been acked to the client yet.
+	Offered bool
+}
+
+// leaseStore is the JSON-backed persistence for one network's leases.json, keyed by hardware
+// address so a renewing client always gets back the same record it was last given.
+type leaseStore struct {
+	mu    sync.Mutex
+	path  string
+	byMAC map[string]Lease
+}
+
+// leaseFile is the on-disk representation of leases.json; a thin wrapper so the format can grow
+// fields later without breaking older files (unknown fields are just ignored by encoding/json).
+type leaseFile struct {
+	Leases []Lease
+}
+
+// openLeaseStore loads path if it exists, or starts with an empty store if it doesn't (e.g. before
+// this network has ever handed out a lease).
+func openLeaseStore(path string) (*leaseStore, error) {
+	s := &leaseStore{path: path, byMAC: make(map[string]Lease)}
+
+	if !util.PathExists(path) {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f leaseFile
+
+	err = json.Unmarshal(content, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range f.Leases {
+		s.byMAC[l.Hwaddr.String()] = l
+	}
+
+	return s, nil
+}
+
+// put records l, keyed by its hardware address, and persists the updated store to disk.
+func (s *leaseStore) put(l Lease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.byMAC[l.Hwaddr.String()] = l
+
+	return s.save()
+}
+
+// delete forgets the lease for hwaddr, e.g. following a DHCPRELEASE.
+func (s *leaseStore) delete(hwaddr net.HardwareAddr) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.byMAC, hwaddr.String())
+
+	return s.save()
+}
+
+// get returns the lease previously recorded for hwaddr, if any.
+func (s *leaseStore) get(hwaddr net.HardwareAddr) (Lease, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.byMAC[hwaddr.String()]
+
+	return l, ok
+}
+
+// list returns every lease matching family, sorted by neither hostname nor address; callers that
+// need a stable order should sort the result themselves.
+func (s *leaseStore) list(family leaseFamily) []Lease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Lease, 0, len(s.byMAC))
+
+	for _, l := range s.byMAC {
+		isV4 := l.Address.To4() != nil
+
+		if family == leaseFamilyIPv4 && !isV4 {
+			continue
+		}
+
+		if family == leaseFamilyIPv6 && isV4 {
+			continue
+		}
+
+		out = append(out, l)
+	}
+
+	return out
+}
+
+// save rewrites the leases.json file with the store's current contents. Callers must hold s.mu.
+func (s *leaseStore) save() error {
+	f := leaseFile{Leases: make([]Lease, 0, len(s.byMAC))}
+	for _, l := range s.byMAC {
+		f.Leases = append(f.Leases, l)
+	}
+
+	content, err := json.MarshalIndent(f, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, content, 0o644)
+}
@@ -0,0 +1,115 @@
+package dhcpd
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func mustMAC(t *testing.T, s string) net.HardwareAddr {
+	t.Helper()
+
+	hwaddr, err := net.ParseMAC(s)
+	if err != nil {
+		t.Fatalf("ParseMAC(%q) returned unexpected error: %v", s, err)
+	}
+
+	return hwaddr
+}
+
+func TestLeaseStorePutGetDelete(t *testing.T) {
+	store, err := openLeaseStore(filepath.Join(t.TempDir(), "leases.json"))
+	if err != nil {
+		t.Fatalf("openLeaseStore returned unexpected error: %v", err)
+	}
+
+	hwaddr := mustMAC(t, "00:11:22:33:44:55")
+
+	_, ok := store.get(hwaddr)
+	if ok {
+		t.Fatal("expected no lease before one is put")
+	}
+
+	err = store.put(Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.5"), Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("put returned unexpected error: %v", err)
+	}
+
+	lease, ok := store.get(hwaddr)
+	if !ok || !lease.Address.Equal(net.ParseIP("10.0.0.5")) {
+		t.Fatalf("get() = (%+v, %v), want the lease just put", lease, ok)
+	}
+
+	err = store.delete(hwaddr)
+	if err != nil {
+		t.Fatalf("delete returned unexpected error: %v", err)
+	}
+
+	_, ok = store.get(hwaddr)
+	if ok {
+		t.Error("expected no lease after delete")
+	}
+}
+
+func TestLeaseStorePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leases.json")
+
+	store, err := openLeaseStore(path)
+	if err != nil {
+		t.Fatalf("openLeaseStore returned unexpected error: %v", err)
+	}
+
+	hwaddr := mustMAC(t, "00:11:22:33:44:55")
+
+	err = store.put(Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.5"), Expiry: time.Now().Add(time.Hour), Static: true})
+	if err != nil {
+		t.Fatalf("put returned unexpected error: %v", err)
+	}
+
+	reopened, err := openLeaseStore(path)
+	if err != nil {
+		t.Fatalf("openLeaseStore returned unexpected error on reopen: %v", err)
+	}
+
+	lease, ok := reopened.get(hwaddr)
+	if !ok {
+		t.Fatal("expected the lease put before reopening to have been persisted")
+	}
+
+	if !lease.Address.Equal(net.ParseIP("10.0.0.5")) || !lease.Static {
+		t.Errorf("reopened lease = %+v, want Address=10.0.0.5 Static=true", lease)
+	}
+}
+
+func TestLeaseStoreList(t *testing.T) {
+	store, err := openLeaseStore(filepath.Join(t.TempDir(), "leases.json"))
+	if err != nil {
+		t.Fatalf("openLeaseStore returned unexpected error: %v", err)
+	}
+
+	err = store.put(Lease{Hwaddr: mustMAC(t, "00:11:22:33:44:55"), Address: net.ParseIP("10.0.0.5")})
+	if err != nil {
+		t.Fatalf("put returned unexpected error: %v", err)
+	}
+
+	err = store.put(Lease{Hwaddr: mustMAC(t, "aa:bb:cc:dd:ee:ff"), Address: net.ParseIP("fd00::5")})
+	if err != nil {
+		t.Fatalf("put returned unexpected error: %v", err)
+	}
+
+	v4 := store.list(leaseFamilyIPv4)
+	if len(v4) != 1 || !v4[0].Address.Equal(net.ParseIP("10.0.0.5")) {
+		t.Errorf("list(leaseFamilyIPv4) = %+v, want only the IPv4 lease", v4)
+	}
+
+	v6 := store.list(leaseFamilyIPv6)
+	if len(v6) != 1 || !v6[0].Address.Equal(net.ParseIP("fd00::5")) {
+		t.Errorf("list(leaseFamilyIPv6) = %+v, want only the IPv6 lease", v6)
+	}
+
+	all := store.list(leaseFamilyAny)
+	if len(all) != 2 {
+		t.Errorf("list(leaseFamilyAny) returned %d leases, want 2", len(all))
+	}
+}
@@ -0,0 +1,190 @@
+package dhcpd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// ipPool is a bitset-backed allocator over one or more inclusive IPv4 or IPv6 address ranges.
+// Addresses reserved statically by MAC (from NIC device config) are marked used in the bitset up
+// front by newIPPool, so allocate never hands them out as a dynamic lease.
+type ipPool struct {
+	ranges   []uint64Range
+	used     []byte // one bit per address across all ranges, in range order
+	total    uint64
+	static   map[string]net.IP // hardware address string -> reserved address
+	template net.IP            // a real address from the pool, used to pick IPv4 vs IPv6 when synthesising addresses
+}
+
+// uint64Range is an inclusive [start, end] range of addresses, represented as big-endian integers
+// so IPv4 (32-bit) and IPv6 (128-bit, truncated to the low 64 bits of the host portion) ranges can
+// share the same bitset logic.
+type uint64Range struct {
+	start uint64
+	end   uint64
+}
+
+// newIPPool builds a pool over ranges, with the addresses in static reserved up front so
+// allocate never returns them. An empty ranges list is valid and simply means no dynamic leases
+// can be allocated until an allocatable range is known (e.g. a v6-only network not yet configured
+// with ipv6.dhcp.ranges).
+func newIPPool(ranges []IPRange, static map[string]net.IP) (*ipPool, error) {
+	p := &ipPool{static: make(map[string]net.IP, len(static))}
+
+	for mac, addr := range static {
+		p.static[mac] = addr
+	}
+
+	for _, r := range ranges {
+		start, err := addrToUint64(r.Start)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid range start %q: %w", r.Start, err)
+		}
+
+		end, err := addrToUint64(r.End)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid range end %q: %w", r.End, err)
+		}
+
+		if end < start {
+			return nil, fmt.Errorf("Range end %q precedes start %q", r.End, r.Start)
+		}
+
+		p.ranges = append(p.ranges, uint64Range{start: start, end: end})
+		p.total += end - start + 1
+
+		if p.template == nil {
+			p.template = r.Start
+		}
+	}
+
+	p.used = make([]byte, (p.total+7)/8)
+
+	// Mark every static reservation used so allocate() never hands it out as a dynamic lease,
+	// even when it falls inside a configured range.
+	for _, addr := range p.static {
+		p.mark(addr)
+	}
+
+	return p, nil
+}
+
+// addrToUint64 reduces an IPv4 address, or the low 64 bits of an IPv6 address, to an integer so
+// it can be compared and bit-indexed without per-octet arithmetic.
+func addrToUint64(ip net.IP) (uint64, error) {
+	if v4 := ip.To4(); v4 != nil {
+		return uint64(binary.BigEndian.Uint32(v4)), nil
+	}
+
+	v6 := ip.To16()
+	if v6 == nil {
+		return 0, fmt.Errorf("Not a valid IP address: %q", ip)
+	}
+
+	return binary.BigEndian.Uint64(v6[8:]), nil
+}
+
+// offset returns the bit index addr occupies across all ranges, or false if addr falls outside
+// every range in the pool.
+func (p *ipPool) offset(ip net.IP) (uint64, bool) {
+	v, err := addrToUint64(ip)
+	if err != nil {
+		return 0, false
+	}
+
+	var base uint64
+
+	for _, r := range p.ranges {
+		if v >= r.start && v <= r.end {
+			return base + (v - r.start), true
+		}
+
+		base += r.end - r.start + 1
+	}
+
+	return 0, false
+}
+
+// addrAt returns the address at bit index i, reusing sample as the template for the bytes outside
+// the portion offset actually varies (so IPv6 addresses keep their prefix).
+func (p *ipPool) addrAt(i uint64, sample net.IP) net.IP {
+	var base uint64
+
+	for _, r := range p.ranges {
+		count := r.end - r.start + 1
+		if i < base+count {
+			return uint64ToAddr(r.start+(i-base), sample)
+		}
+
+		base += count
+	}
+
+	return nil
+}
+
+func uint64ToAddr(v uint64, sample net.IP) net.IP {
+	if sample.To4() != nil {
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(v))
+
+		return net.IP(b)
+	}
+
+	out := make(net.IP, 16)
+	copy(out, sample.To16())
+	binary.BigEndian.PutUint64(out[8:], v)
+
+	return out
+}
+
+func (p *ipPool) bit(i uint64) bool {
+	return p.used[i/8]&(1<<(i%8)) != 0
+}
+
+func (p *ipPool) setBit(i uint64, v bool) {
+	if v {
+		p.used[i/8] |= 1 << (i % 8)
+	} else {
+		p.used[i/8] &^= 1 << (i % 8)
+	}
+}
+
+// reservedFor returns the static reservation for hwaddr, if any.
+func (p *ipPool) reservedFor(hwaddr net.HardwareAddr) (net.IP, bool) {
+	addr, ok := p.static[hwaddr.String()]
+
+	return addr, ok
+}
+
+// allocate returns the first free address in the pool not already marked used, marking it used.
+// Static reservations are not considered "free" dynamic addresses and are never returned here;
+// callers should check reservedFor first.
+func (p *ipPool) allocate() (net.IP, error) {
+	for i := uint64(0); i < p.total; i++ {
+		if !p.bit(i) {
+			p.setBit(i, true)
+
+			return p.addrAt(i, p.template), nil
+		}
+	}
+
+	return nil, fmt.Errorf("No free addresses left in pool")
+}
+
+// mark flags addr as in use without requiring it to have come from allocate, so that leases
+// restored from leases.json at startup aren't handed out again to a different client.
+func (p *ipPool) mark(addr net.IP) {
+	i, ok := p.offset(addr)
+	if ok {
+		p.setBit(i, true)
+	}
+}
+
+// release flags addr as free again, e.g. following a DHCPRELEASE or lease expiry.
+func (p *ipPool) release(addr net.IP) {
+	i, ok := p.offset(addr)
+	if ok {
+		p.setBit(i, false)
+	}
+}
@@ -0,0 +1,129 @@
+package dhcpd
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNewIPPoolMarksStaticReservationsUsed(t *testing.T) {
+	ranges := []IPRange{{Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.3")}}
+	static := map[string]net.IP{"00:11:22:33:44:55": net.ParseIP("10.0.0.2")}
+
+	pool, err := newIPPool(ranges, static)
+	if err != nil {
+		t.Fatalf("newIPPool returned unexpected error: %v", err)
+	}
+
+	// The statically reserved address falls inside the dynamic range, so allocate() must never
+	// return it even though it was never itself passed to allocate().
+	for i := 0; i < 2; i++ {
+		addr, err := pool.allocate()
+		if err != nil {
+			t.Fatalf("allocate() returned unexpected error: %v", err)
+		}
+
+		if addr.Equal(net.ParseIP("10.0.0.2")) {
+			t.Fatalf("allocate() returned the statically reserved address %s", addr)
+		}
+	}
+
+	_, err = pool.allocate()
+	if err == nil {
+		t.Fatal("expected allocate() to fail once the pool (minus the static reservation) is exhausted")
+	}
+}
+
+func TestIPPoolAllocateAndRelease(t *testing.T) {
+	ranges := []IPRange{{Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.2")}}
+
+	pool, err := newIPPool(ranges, nil)
+	if err != nil {
+		t.Fatalf("newIPPool returned unexpected error: %v", err)
+	}
+
+	first, err := pool.allocate()
+	if err != nil {
+		t.Fatalf("allocate() returned unexpected error: %v", err)
+	}
+
+	second, err := pool.allocate()
+	if err != nil {
+		t.Fatalf("allocate() returned unexpected error: %v", err)
+	}
+
+	if first.Equal(second) {
+		t.Fatalf("allocate() returned the same address twice: %s", first)
+	}
+
+	_, err = pool.allocate()
+	if err == nil {
+		t.Fatal("expected allocate() to fail once the pool is exhausted")
+	}
+
+	pool.release(first)
+
+	released, err := pool.allocate()
+	if err != nil {
+		t.Fatalf("allocate() returned unexpected error after release: %v", err)
+	}
+
+	if !released.Equal(first) {
+		t.Errorf("allocate() after release = %s, want the released address %s", released, first)
+	}
+}
+
+func TestIPPoolMark(t *testing.T) {
+	ranges := []IPRange{{Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.2")}}
+
+	pool, err := newIPPool(ranges, nil)
+	if err != nil {
+		t.Fatalf("newIPPool returned unexpected error: %v", err)
+	}
+
+	pool.mark(net.ParseIP("10.0.0.1"))
+
+	addr, err := pool.allocate()
+	if err != nil {
+		t.Fatalf("allocate() returned unexpected error: %v", err)
+	}
+
+	if !addr.Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("allocate() = %s, want 10.0.0.2 (10.0.0.1 was marked used)", addr)
+	}
+}
+
+func TestIPPoolReservedFor(t *testing.T) {
+	hwaddr, err := net.ParseMAC("00:11:22:33:44:55")
+	if err != nil {
+		t.Fatalf("ParseMAC returned unexpected error: %v", err)
+	}
+
+	pool, err := newIPPool(nil, map[string]net.IP{hwaddr.String(): net.ParseIP("10.0.0.5")})
+	if err != nil {
+		t.Fatalf("newIPPool returned unexpected error: %v", err)
+	}
+
+	addr, ok := pool.reservedFor(hwaddr)
+	if !ok || !addr.Equal(net.ParseIP("10.0.0.5")) {
+		t.Errorf("reservedFor() = (%s, %v), want (10.0.0.5, true)", addr, ok)
+	}
+
+	other, err := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	if err != nil {
+		t.Fatalf("ParseMAC returned unexpected error: %v", err)
+	}
+
+	_, ok = pool.reservedFor(other)
+	if ok {
+		t.Error("reservedFor() unexpectedly found a reservation for an unregistered MAC")
+	}
+}
+
+func TestNewIPPoolRejectsInvertedRange(t *testing.T) {
+	ranges := []IPRange{{Start: net.ParseIP("10.0.0.10"), End: net.ParseIP("10.0.0.1")}}
+
+	_, err := newIPPool(ranges, nil)
+	if err == nil {
+		t.Fatal("expected newIPPool to reject a range whose end precedes its start")
+	}
+}
@@ -0,0 +1,34 @@
+package dhcpd
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-ping/ping"
+)
+
+// probeTimeout is how long to wait for an ICMP echo reply before concluding an address isn't
+// already in use. Kept short since this runs inline before every DHCPOFFER/ADVERTISE.
+const probeTimeout = 300 * time.Millisecond
+
+// addrInUse sends a single ICMP echo to addr and reports whether anything answered, which would
+// mean the address is already in use by a host this server has no lease record for (e.g. a static
+// IP assigned outside of Incus) and so shouldn't be offered.
+func addrInUse(addr net.IP) (bool, error) {
+	pinger, err := ping.NewPinger(addr.String())
+	if err != nil {
+		return false, fmt.Errorf("Failed creating prober for %q: %w", addr, err)
+	}
+
+	pinger.Count = 1
+	pinger.Timeout = probeTimeout
+	pinger.SetPrivileged(true)
+
+	err = pinger.Run()
+	if err != nil {
+		return false, fmt.Errorf("Failed probing %q: %w", addr, err)
+	}
+
+	return pinger.Statistics().PacketsRecv > 0, nil
+}
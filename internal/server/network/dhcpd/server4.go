@@ -0,0 +1,256 @@
+package dhcpd
+
+import (
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/server4"
+	"github.com/insomniacslk/dhcp/rfc1035label"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// maxProbeAttempts bounds how many addresses offerDHCPv4 will try before giving up on a DISCOVER,
+// so a subnet that's unexpectedly full of unmanaged hosts can't hang a client's DISCOVER loop
+// forever.
+const maxProbeAttempts = 4
+
+const defaultLeaseTime = 1 * time.Hour
+
+// offerValidity bounds how long an OFFERed address is held against a follow-up REQUEST before it's
+// treated as abandoned and released back to the pool.
+const offerValidity = 30 * time.Second
+
+// startDHCPv4Server binds a DHCPv4 server4.Server to iface and starts serving in the background.
+// Close the returned server to stop it.
+func startDHCPv4Server(iface string, cfg *IPv4Config, pool *ipPool, leases *leaseStore) (*server4.Server, error) {
+	handler := func(conn net.PacketConn, peer net.Addr, m *dhcpv4.DHCPv4) {
+		reply, err := handleDHCPv4(m, cfg, pool, leases)
+		if err != nil {
+			logger.Warn("Failed handling DHCPv4 request", logger.Ctx{"err": err})
+
+			return
+		}
+
+		if reply == nil {
+			return
+		}
+
+		_, _ = conn.WriteTo(reply.ToBytes(), peer)
+	}
+
+	srv, err := server4.NewServer(iface, nil, handler)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := srv.Serve()
+		if err != nil {
+			logger.Debug("DHCPv4 server stopped", logger.Ctx{"iface": iface, "err": err})
+		}
+	}()
+
+	return srv, nil
+}
+
+// handleDHCPv4 dispatches a single incoming message to the appropriate reply builder, returning a
+// nil reply (and nil error) for message types that don't warrant one.
+func handleDHCPv4(m *dhcpv4.DHCPv4, cfg *IPv4Config, pool *ipPool, leases *leaseStore) (*dhcpv4.DHCPv4, error) {
+	switch m.MessageType() {
+	case dhcpv4.MessageTypeDiscover:
+		return offerDHCPv4(m, cfg, pool, leases)
+	case dhcpv4.MessageTypeRequest:
+		return ackDHCPv4(m, cfg, pool, leases)
+	case dhcpv4.MessageTypeRelease:
+		return nil, releaseDHCPv4(m, pool, leases)
+	default:
+		return nil, nil
+	}
+}
+
+// offerDHCPv4 answers a DISCOVER. It prefers, in order: an existing static reservation for the
+// client's MAC, its previous dynamic lease if one is still recorded, and otherwise the next free
+// address from the pool. Before offering a freshly allocated address it probes it with a single
+// ICMP echo; a reply means some host not tracked by this server already holds it, so that address
+// is permanently marked used and allocation retries, up to maxProbeAttempts times. A freshly
+// allocated address is recorded as a pending, not-yet-acked lease so a follow-up REQUEST commits
+// the same address rather than allocating a second one.
+func offerDHCPv4(m *dhcpv4.DHCPv4, cfg *IPv4Config, pool *ipPool, leases *leaseStore) (*dhcpv4.DHCPv4, error) {
+	addr, err := leaseAddressFor(m.ClientHWAddr, cfg, pool, leases)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaticReservation(cfg, m.ClientHWAddr) {
+		_, hadLease := leases.get(m.ClientHWAddr)
+		if !hadLease {
+			err = leases.put(Lease{
+				Hostname: m.HostName(),
+				Hwaddr:   m.ClientHWAddr,
+				Address:  addr,
+				Expiry:   time.Now().Add(offerValidity),
+				Offered:  true,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buildReply(m, dhcpv4.MessageTypeOffer, addr, cfg)
+}
+
+// ackDHCPv4 answers a REQUEST by committing whatever address offerDHCPv4 would have offered (the
+// client's reservation, renewal, or a freshly probed address) and persisting it as a lease.
+func ackDHCPv4(m *dhcpv4.DHCPv4, cfg *IPv4Config, pool *ipPool, leases *leaseStore) (*dhcpv4.DHCPv4, error) {
+	addr, err := leaseAddressFor(m.ClientHWAddr, cfg, pool, leases)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseTime := cfg.LeaseTime
+	if leaseTime == 0 {
+		leaseTime = defaultLeaseTime
+	}
+
+	err = leases.put(Lease{
+		Hostname: m.HostName(),
+		Hwaddr:   m.ClientHWAddr,
+		Address:  addr,
+		Expiry:   time.Now().Add(leaseTime),
+		Static:   isStaticReservation(cfg, m.ClientHWAddr),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildReply(m, dhcpv4.MessageTypeAck, addr, cfg)
+}
+
+// releaseDHCPv4 handles a RELEASE by forgetting the client's lease and returning its address to the
+// pool, unless it was a static reservation (those are never part of the dynamic pool).
+func releaseDHCPv4(m *dhcpv4.DHCPv4, pool *ipPool, leases *leaseStore) error {
+	lease, ok := leases.get(m.ClientHWAddr)
+	if !ok {
+		return nil
+	}
+
+	if !lease.Static {
+		pool.release(lease.Address)
+	}
+
+	return leases.delete(m.ClientHWAddr)
+}
+
+// leaseAddressFor resolves the address that should be offered/acked to hwaddr: its static
+// reservation, its existing (committed or still-pending-REQUEST) lease, or a newly probed address
+// from the pool. A pending offer past its offerValidity window is treated as abandoned and
+// released before a fresh address is allocated.
+func leaseAddressFor(hwaddr net.HardwareAddr, cfg *IPv4Config, pool *ipPool, leases *leaseStore) (net.IP, error) {
+	if addr, ok := pool.reservedFor(hwaddr); ok {
+		return addr, nil
+	}
+
+	if lease, ok := leases.get(hwaddr); ok {
+		if !lease.Offered || time.Now().Before(lease.Expiry) {
+			return lease.Address, nil
+		}
+
+		// The OFFER was never followed by a REQUEST and has expired; release it back to the pool
+		// rather than handing it out again while also allocating a second address below.
+		pool.release(lease.Address)
+
+		err := leases.delete(hwaddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; attempt < maxProbeAttempts; attempt++ {
+		addr, err := pool.allocate()
+		if err != nil {
+			return nil, err
+		}
+
+		inUse, err := addrInUse(addr)
+		if err != nil {
+			logger.Warn("Failed probing address for conflicts, offering it anyway", logger.Ctx{"address": addr, "err": err})
+
+			return addr, nil
+		}
+
+		if !inUse {
+			return addr, nil
+		}
+
+		// Leave it marked used in the pool (skip it) and try the next one.
+		logger.Warn("Address already in use by an unmanaged host, skipping", logger.Ctx{"address": addr})
+	}
+
+	return nil, &leaseAllocationError{hwaddr: hwaddr}
+}
+
+func isStaticReservation(cfg *IPv4Config, hwaddr net.HardwareAddr) bool {
+	_, ok := cfg.Static[hwaddr.String()]
+
+	return ok
+}
+
+// leaseAllocationError reports that no conflict-free address could be found for hwaddr.
+type leaseAllocationError struct {
+	hwaddr net.HardwareAddr
+}
+
+func (e *leaseAllocationError) Error() string {
+	return "Failed allocating a conflict-free address for " + e.hwaddr.String()
+}
+
+// buildReply assembles a DHCPv4 reply of type mt offering/acking addr, with options derived from
+// cfg: subnet mask and gateway from cfg.Subnet/cfg.Gateway, DNS servers, option 119 domain search,
+// option 121 classless static routes, and option 43 vendor-specific data when configured.
+func buildReply(m *dhcpv4.DHCPv4, mt dhcpv4.MessageType, addr net.IP, cfg *IPv4Config) (*dhcpv4.DHCPv4, error) {
+	leaseTime := cfg.LeaseTime
+	if leaseTime == 0 {
+		leaseTime = defaultLeaseTime
+	}
+
+	modifiers := []dhcpv4.Modifier{
+		dhcpv4.WithMessageType(mt),
+		dhcpv4.WithYourIP(addr),
+		dhcpv4.WithLeaseTime(uint32(leaseTime.Seconds())),
+	}
+
+	if cfg.Subnet != nil {
+		modifiers = append(modifiers, dhcpv4.WithNetmask(cfg.Subnet.Mask))
+	}
+
+	if cfg.Gateway != nil {
+		modifiers = append(modifiers, dhcpv4.WithRouter(cfg.Gateway))
+		modifiers = append(modifiers, dhcpv4.WithServerIP(cfg.Gateway))
+	}
+
+	if len(cfg.DNS) > 0 {
+		modifiers = append(modifiers, dhcpv4.WithDNS(cfg.DNS...))
+	}
+
+	if len(cfg.DomainSearch) > 0 {
+		modifiers = append(modifiers, dhcpv4.WithOption(dhcpv4.OptDomainSearch(&rfc1035label.Labels{Labels: cfg.DomainSearch})))
+	}
+
+	if len(cfg.Routes) > 0 {
+		routes := make([]*dhcpv4.Route, 0, len(cfg.Routes))
+		for _, r := range cfg.Routes {
+			routes = append(routes, &dhcpv4.Route{Dest: r.Destination, Router: r.Gateway})
+		}
+
+		modifiers = append(modifiers, dhcpv4.WithOption(dhcpv4.OptClasslessStaticRoute(routes...)))
+	}
+
+	if len(cfg.VendorInfo) > 0 {
+		modifiers = append(modifiers, dhcpv4.WithOption(dhcpv4.OptGeneric(dhcpv4.OptionVendorSpecificInformation, cfg.VendorInfo)))
+	}
+
+	return dhcpv4.NewReplyFromRequest(m, modifiers...)
+}
@@ -0,0 +1,205 @@
+package dhcpd
+
+import (
+	"net"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/server6"
+	"github.com/insomniacslk/dhcp/iana"
+
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// startDHCPv6Server binds a DHCPv6 server6.Server to iface and starts serving in the background.
+// Close the returned server to stop it.
+func startDHCPv6Server(iface string, cfg *IPv6Config, pool *ipPool, leases *leaseStore) (*server6.Server, error) {
+	handler := func(conn net.PacketConn, peer net.Addr, msg dhcpv6.DHCPv6) {
+		reply, err := handleDHCPv6(msg, cfg, pool, leases)
+		if err != nil {
+			logger.Warn("Failed handling DHCPv6 request", logger.Ctx{"err": err})
+
+			return
+		}
+
+		if reply == nil {
+			return
+		}
+
+		_, _ = conn.WriteTo(reply.ToBytes(), peer)
+	}
+
+	srv, err := server6.NewServer(iface, nil, handler)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := srv.Serve()
+		if err != nil {
+			logger.Debug("DHCPv6 server stopped", logger.Ctx{"iface": iface, "err": err})
+		}
+	}()
+
+	return srv, nil
+}
+
+// handleDHCPv6 dispatches a single incoming message, mirroring handleDHCPv4's split between the
+// types that get a reply built (SOLICIT, and REQUEST/RENEW/REBIND, which also record the lease)
+// and RELEASE, which only needs its side effect applied.
+func handleDHCPv6(msg dhcpv6.DHCPv6, cfg *IPv6Config, pool *ipPool, leases *leaseStore) (dhcpv6.DHCPv6, error) {
+	m, err := msg.GetInnerMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := duidToHWAddr(m)
+
+	switch m.MessageType {
+	case dhcpv6.MessageTypeSolicit:
+		return advertiseDHCPv6(m, clientID, cfg, pool, leases)
+	case dhcpv6.MessageTypeRequest, dhcpv6.MessageTypeRenew, dhcpv6.MessageTypeRebind:
+		return replyDHCPv6(m, clientID, cfg, pool, leases)
+	case dhcpv6.MessageTypeRelease:
+		return nil, releaseDHCPv6(clientID, pool, leases)
+	default:
+		return nil, nil
+	}
+}
+
+// duidToHWAddr reduces a client's DUID to a net.HardwareAddr so it can key the same leaseStore
+// DHCPv4 uses, rather than keeping a second lookup keyed by raw DUID bytes.
+func duidToHWAddr(m *dhcpv6.Message) net.HardwareAddr {
+	cid := m.Options.ClientID()
+	if cid == nil {
+		return nil
+	}
+
+	return net.HardwareAddr(cid.ToBytes())
+}
+
+func advertiseDHCPv6(m *dhcpv6.Message, clientID net.HardwareAddr, cfg *IPv6Config, pool *ipPool, leases *leaseStore) (dhcpv6.DHCPv6, error) {
+	addr, err := leaseAddressFor6(clientID, cfg, pool, leases)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAdvertise(m, addr, cfg)
+}
+
+func replyDHCPv6(m *dhcpv6.Message, clientID net.HardwareAddr, cfg *IPv6Config, pool *ipPool, leases *leaseStore) (dhcpv6.DHCPv6, error) {
+	addr, err := leaseAddressFor6(clientID, cfg, pool, leases)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseTime := cfg.LeaseTime
+	if leaseTime == 0 {
+		leaseTime = defaultLeaseTime
+	}
+
+	err = leases.put(Lease{
+		Hwaddr:  clientID,
+		Address: addr,
+		Expiry:  time.Now().Add(leaseTime),
+		Static:  isStaticReservation6(cfg, clientID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildReply6(m, addr, cfg)
+}
+
+func releaseDHCPv6(clientID net.HardwareAddr, pool *ipPool, leases *leaseStore) error {
+	lease, ok := leases.get(clientID)
+	if !ok {
+		return nil
+	}
+
+	if !lease.Static {
+		pool.release(lease.Address)
+	}
+
+	return leases.delete(clientID)
+}
+
+// leaseAddressFor6 mirrors leaseAddressFor: a static reservation, then an existing lease, then a
+// freshly probed address from the pool, retrying past unmanaged hosts that answer the ICMP probe.
+func leaseAddressFor6(clientID net.HardwareAddr, cfg *IPv6Config, pool *ipPool, leases *leaseStore) (net.IP, error) {
+	if addr, ok := pool.reservedFor(clientID); ok {
+		return addr, nil
+	}
+
+	if lease, ok := leases.get(clientID); ok {
+		return lease.Address, nil
+	}
+
+	for attempt := 0; attempt < maxProbeAttempts; attempt++ {
+		addr, err := pool.allocate()
+		if err != nil {
+			return nil, err
+		}
+
+		inUse, err := addrInUse(addr)
+		if err != nil {
+			logger.Warn("Failed probing address for conflicts, offering it anyway", logger.Ctx{"address": addr, "err": err})
+
+			return addr, nil
+		}
+
+		if !inUse {
+			return addr, nil
+		}
+
+		logger.Warn("Address already in use by an unmanaged host, skipping", logger.Ctx{"address": addr})
+	}
+
+	return nil, &leaseAllocationError{hwaddr: clientID}
+}
+
+func isStaticReservation6(cfg *IPv6Config, clientID net.HardwareAddr) bool {
+	_, ok := cfg.Static[clientID.String()]
+
+	return ok
+}
+
+func buildAdvertise(m *dhcpv6.Message, addr net.IP, cfg *IPv6Config) (dhcpv6.DHCPv6, error) {
+	return dhcpv6.NewAdvertiseFromSolicit(m, ia6Modifiers(m, addr, cfg)...)
+}
+
+func buildReply6(m *dhcpv6.Message, addr net.IP, cfg *IPv6Config) (dhcpv6.DHCPv6, error) {
+	return dhcpv6.NewReplyFromMessage(m, ia6Modifiers(m, addr, cfg)...)
+}
+
+// ia6Modifiers builds an IA_NA carrying addr (only when the client's message has an IA_NA; the
+// client's IAID is not copied into it), plus DNS servers if
+// configured. Option 121/119/43 equivalents don't exist for DHCPv6 the way they do for v4; ipv6's
+// router and search domain options are normally carried by router advertisements instead, which
+// this server doesn't emit (see the bridge driver's own RA handling).
+func ia6Modifiers(m *dhcpv6.Message, addr net.IP, cfg *IPv6Config) []dhcpv6.Modifier {
+	leaseTime := cfg.LeaseTime
+	if leaseTime == 0 {
+		leaseTime = defaultLeaseTime
+	}
+
+	iaNA := m.Options.OneIANA()
+
+	modifiers := []dhcpv6.Modifier{
+		dhcpv6.WithServerID(dhcpv6.Duid{Type: dhcpv6.DUID_LL, HwType: iana.HWTypeEthernet}),
+	}
+
+	if iaNA != nil {
+		modifiers = append(modifiers, dhcpv6.WithIANA(&dhcpv6.OptIAAddress{
+			IPv6Addr:          addr,
+			PreferredLifetime: leaseTime,
+			ValidLifetime:     leaseTime,
+		}))
+	}
+
+	if len(cfg.DNS) > 0 {
+		modifiers = append(modifiers, dhcpv6.WithDNS(cfg.DNS...))
+	}
+
+	return modifiers
+}
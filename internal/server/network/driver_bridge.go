@@ -1,10 +1,15 @@
 package network
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"maps"
 	"net"
@@ -14,8 +19,12 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/nclient6"
 	"github.com/mdlayher/netx/eui64"
 
 	incus "github.com/lxc/incus/v6/client"
@@ -32,6 +41,7 @@ import (
 	"github.com/lxc/incus/v6/internal/server/ip"
 	"github.com/lxc/incus/v6/internal/server/network/acl"
 	addressset "github.com/lxc/incus/v6/internal/server/network/address-set"
+	"github.com/lxc/incus/v6/internal/server/network/dhcpd"
 	"github.com/lxc/incus/v6/internal/server/project"
 	localUtil "github.com/lxc/incus/v6/internal/server/util"
 	"github.com/lxc/incus/v6/internal/server/warnings"
@@ -58,106 +68,1378 @@ func (n *bridge) DBType() db.NetworkType {
 	return db.NetworkTypeBridge
 }
 
+// bridgeDriver returns the platform bridgeDriver used to create/delete the bridge interface and
+// its ports (netlink on Linux, ifconfig/pf on FreeBSD).
+func (n *bridge) bridgeDriver() bridgeDriver {
+	return newBridgeDriver()
+}
+
 // Config returns the network driver info.
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
 
-	return info
+	return info
+}
+
+// checkClusterWideMACSafe returns whether it is safe to use the same MAC address for the bridge interface on all
+// cluster nodes. It is not suitable to use a static MAC address when "bridge.external_interfaces" is non-empty and
+// the bridge interface has no IPv4 or IPv6 address set. This is because in a clustered environment the same bridge
+// config is applied to all nodes, and if the bridge is being used to connect multiple nodes to the same network
+// segment it would cause MAC conflicts to use the same MAC on all nodes. If an IP address is specified then
+// connecting multiple nodes to the same network segment would also cause IP conflicts, so if an IP is defined
+// then we assume this is not being done. However if IP addresses are explicitly set to "none" and
+// "bridge.external_interfaces" is set then it may not be safe to use a the same MAC address on all nodes.
+func (n *bridge) checkClusterWideMACSafe(config map[string]string) error {
+	// A MAC derived from the network ID and the node name is unique per node by construction, so
+	// sharing the "cluster-wide" derivation mode across nodes never causes a MAC conflict.
+	if config["bridge.hwaddr.mode"] == "derived" {
+		return nil
+	}
+
+	// We can't be sure that multiple clustered nodes aren't connected to the same network segment so don't
+	// use a static MAC address for the bridge interface to avoid introducing a MAC conflict.
+	if config["bridge.external_interfaces"] != "" && config["ipv4.address"] == "none" && config["ipv6.address"] == "none" {
+		return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge has no IP addresses and has external interfaces set`)
+	}
+
+	// We may have MAC conflicts if tunnels are in use.
+	for k := range config {
+		if strings.HasPrefix(k, "tunnel.") {
+			return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge has tunnels connected`)
+		}
+	}
+
+	// If using a generated IPv6 address, we need a unique MAC.
+	if config["ipv6.address"] != "none" && validate.IsNetworkV6(config["ipv6.address"]) == nil {
+		return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge uses a host-specific IPv6 address`)
+	}
+
+	return nil
+}
+
+// derivedBridgeMAC deterministically derives a locally-administered MAC address from the
+// network's ID and the node name, using HMAC-SHA256 keyed on the node name. This gives each
+// cluster member a stable (across reboots, and not dependent on which node currently holds
+// seedNodeID 0) MAC for a given network, without two nodes ever deriving the same address.
+func derivedBridgeMAC(networkID int64, nodeName string) net.HardwareAddr {
+	mac := hmac.New(sha256.New, []byte(nodeName))
+	_, _ = fmt.Fprintf(mac, "network.%d", networkID)
+	sum := mac.Sum(nil)
+
+	hwAddr := make(net.HardwareAddr, 6)
+	copy(hwAddr, sum[:6])
+
+	// Clear the multicast bit and set the locally administered bit of the first byte, leaving
+	// the remaining 46 bits derived from the HMAC.
+	hwAddr[0] &^= 0x01
+	hwAddr[0] |= 0x02
+
+	return hwAddr
+}
+
+// stableBridgeMAC deterministically derives a locally-administered MAC address from the
+// network's ID alone (unlike derivedBridgeMAC, it does not also key on the node name), so that
+// every cluster member converges on the same address. This is used to give the bridge a stable
+// identity (and therefore a stable IPv6 link-local address) across host reboots, independent of
+// which member currently has the interface up.
+func stableBridgeMAC(networkID int64) net.HardwareAddr {
+	mac := sha256.Sum256([]byte(fmt.Sprintf("network.%d.hwaddr", networkID)))
+
+	hwAddr := make(net.HardwareAddr, 6)
+	copy(hwAddr, mac[:6])
+
+	// Clear the multicast bit and set the locally administered bit of the first byte, leaving
+	// the remaining 46 bits derived from the hash.
+	hwAddr[0] &^= 0x01
+	hwAddr[0] |= 0x02
+
+	return hwAddr
+}
+
+// ensurePersistentHwaddr makes sure "bridge.hwaddr" is recorded in the network's config before the
+// bridge interface is first brought up. Left unset, the kernel picks the MAC when the interface is
+// created, which in turn seeds the router's IPv6 link-local address; that address would then be
+// free to change across host reboots whenever member ports come up in a different order, breaking
+// neighbors that pinned it. Populating and persisting "bridge.hwaddr" on first start (rather than
+// just deriving it afresh on every start like "bridge.hwaddr.mode=derived" does) gives the bridge a
+// stable, inspectable identity from then on.
+func (n *bridge) ensurePersistentHwaddr() error {
+	if n.config["bridge.hwaddr"] != "" {
+		return nil
+	}
+
+	hwAddr := stableBridgeMAC(n.ID())
+
+	newConfig := make(map[string]string, len(n.config)+1)
+	maps.Copy(newConfig, n.config)
+	newConfig["bridge.hwaddr"] = hwAddr.String()
+
+	err := n.common.update(api.NetworkPut{Description: n.description, Config: newConfig}, "", request.ClientTypeNormal)
+	if err != nil {
+		return fmt.Errorf("Failed persisting generated %q: %w", "bridge.hwaddr", err)
+	}
+
+	n.logger.Debug("Generated persistent bridge MAC", logger.Ctx{"hwAddr": hwAddr.String()})
+
+	return nil
+}
+
+// FillConfig fills requested config with any default values.
+func (n *bridge) FillConfig(config map[string]string) error {
+	// Set some default values where needed.
+	if config["ipv4.address"] == "" {
+		config["ipv4.address"] = "auto"
+	}
+
+	if config["ipv4.address"] == "auto" && config["ipv4.nat"] == "" {
+		config["ipv4.nat"] = "true"
+	}
+
+	if config["ipv6.address"] == "" {
+		content, err := os.ReadFile("/proc/sys/net/ipv6/conf/default/disable_ipv6")
+		if err == nil && string(content) == "0\n" {
+			config["ipv6.address"] = "auto"
+		}
+	}
+
+	if config["ipv6.address"] == "auto" && config["ipv6.nat"] == "" {
+		config["ipv6.nat"] = "true"
+	}
+
+	// Default newly created, clustered networks to a deterministically derived MAC so that
+	// stable-across-reboots per-node MACs are used without operators having to opt in.
+	if config["bridge.hwaddr"] == "" && config["bridge.hwaddr.mode"] == "" && n.state.ServerClustered {
+		config["bridge.hwaddr.mode"] = "derived"
+	}
+
+	// Now replace any "auto" keys with generated values.
+	err := n.populateAutoConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed generating auto config: %w", err)
+	}
+
+	return nil
+}
+
+// populateAutoConfig replaces "auto" in config with generated values.
+func (n *bridge) populateAutoConfig(config map[string]string) error {
+	changedConfig := false
+
+	// Now populate "auto" values where needed.
+	if config["ipv4.address"] == "auto" {
+		subnet, err := randomSubnetV4()
+		if err != nil {
+			return err
+		}
+
+		config["ipv4.address"] = subnet
+		changedConfig = true
+	}
+
+	if config["ipv6.address"] == "auto" {
+		subnet, err := randomSubnetV6()
+		if err != nil {
+			return err
+		}
+
+		config["ipv6.address"] = subnet
+		changedConfig = true
+	}
+
+	// Re-validate config if changed.
+	if changedConfig && n.state != nil {
+		return n.Validate(config)
+	}
+
+	return nil
+}
+
+// validateNAT validates the value of "ipv4.nat"/"ipv6.nat", which in addition to the usual bool
+// values also accepts "insert"/"append" as a shorthand for enabling NAT with a specific rule
+// placement (equivalent to setting "ipv[46].nat.order" to "before"/"after" respectively).
+func validateNAT(value string) error {
+	if value == "insert" || value == "append" {
+		return nil
+	}
+
+	return validate.IsBool(value)
+}
+
+// natEnabled returns whether NAT is enabled for the given "ipv4.nat"/"ipv6.nat" config value.
+func natEnabled(value string) bool {
+	return value == "insert" || value == "append" || util.IsTrue(value)
+}
+
+// natAppend returns whether the NAT rule should be appended to (rather than inserted at the head
+// of) the relevant chain, taking natValue (the "ipv[46].nat" setting) and orderValue (the
+// "ipv[46].nat.order" setting) into account. An explicit "ipv[46].nat.order" always takes
+// precedence over the shorthand encoded in "ipv[46].nat" for backwards compatibility.
+func natAppend(natValue string, orderValue string) bool {
+	if orderValue != "" {
+		return orderValue == "after"
+	}
+
+	return natValue == "append"
+}
+
+// maxNATPoolSize caps the number of addresses an "ipv[46].nat.pool" range is expanded to, as a
+// safety limit against a mistyped huge range being expanded into memory.
+const maxNATPoolSize = 65536
+
+// parseNATPool parses a ","-separated list of "start-end" (or single-address) ranges, such as
+// "ipv4.nat.pool"/"ipv6.nat.pool", into the individual addresses to round-robin as the SNAT
+// source when "ipv[46].nat.mode" is "snat-pool".
+func parseNATPool(value string) ([]net.IP, error) {
+	var pool []net.IP
+
+	for _, entry := range util.SplitNTrimSpace(value, ",", -1, false) {
+		startStr, endStr, found := strings.Cut(entry, "-")
+		if !found {
+			endStr = startStr
+		}
+
+		start := net.ParseIP(startStr)
+		end := net.ParseIP(endStr)
+		if start == nil || end == nil {
+			return nil, fmt.Errorf("Invalid NAT pool range %q", entry)
+		}
+
+		for addr := start; bytes.Compare(addr.To16(), end.To16()) <= 0; addr = nextIP(addr) {
+			if len(pool) >= maxNATPoolSize {
+				return nil, fmt.Errorf("NAT pool %q exceeds the maximum of %d addresses", value, maxNATPoolSize)
+			}
+
+			pool = append(pool, addr)
+		}
+	}
+
+	return pool, nil
+}
+
+// parseNATExcludeDestinations parses the ","-separated list of CIDR subnets used by
+// "ipv4.nat.none_for"/"ipv6.nat.none_for" into the destinations to exempt from NAT.
+func parseNATExcludeDestinations(value string) ([]*net.IPNet, error) {
+	var excludes []*net.IPNet
+
+	for _, entry := range util.SplitNTrimSpace(value, ",", -1, false) {
+		_, subnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid NAT exclude destination %q: %w", entry, err)
+		}
+
+		excludes = append(excludes, subnet)
+	}
+
+	return excludes, nil
+}
+
+// dhcpv6PDRenewBuffer is how long before a delegated prefix's valid lifetime expires that it is
+// renewed, to allow for the renewal itself to take some time.
+const dhcpv6PDRenewBuffer = 30 * time.Second
+
+// dhcpv6PDClients tracks the cancel function of the running DHCPv6-PD renewal goroutine for
+// each bridge network using `ipv6.address: dhcp6-pd`, keyed by network name.
+var dhcpv6PDClients sync.Map
+
+// stopDHCPv6PDClient cancels and forgets the DHCPv6-PD renewal goroutine for the named network,
+// if one is running. It is a no-op otherwise.
+func stopDHCPv6PDClient(name string) {
+	cancel, ok := dhcpv6PDClients.LoadAndDelete(name)
+	if ok {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// setupDHCPv6PD acquires a delegated IPv6 prefix from the configured upstream interface, slices
+// a /64 out of it for use as this bridge's ipv6.address, and starts a background goroutine that
+// renews the lease and reconfigures the bridge (without touching instance NICs) whenever the
+// delegated prefix changes.
+func (n *bridge) setupDHCPv6PD() error {
+	upstream := n.config["ipv6.prefix.upstream"]
+	if upstream == "" {
+		return errors.New(`"ipv6.prefix.upstream" must be set when "ipv6.address" is "dhcp6-pd"`)
+	}
+
+	// Stop any previous client before acquiring a fresh lease.
+	stopDHCPv6PDClient(n.name)
+
+	lease, err := acquireDHCPv6PD(upstream)
+	if err != nil {
+		return err
+	}
+
+	bridgeSubnet, err := dhcpv6PDBridgeSubnet(lease.prefix)
+	if err != nil {
+		return err
+	}
+
+	n.config["ipv6.address"] = bridgeSubnet.String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dhcpv6PDClients.Store(n.name, cancel)
+
+	renewIn := lease.validLifetime - dhcpv6PDRenewBuffer
+	if renewIn <= 0 {
+		renewIn = dhcpv6PDRenewBuffer
+	}
+
+	go n.dhcpv6PDRenewalLoop(ctx, upstream, renewIn)
+
+	return nil
+}
+
+// dhcpv6PDRenewalLoop periodically renews the DHCPv6-PD lease on upstream and, if the delegated
+// prefix has changed, re-runs setup() so the bridge address, routes, and dnsmasq/RA config are
+// brought up to date online (instance NICs are never touched by setup()).
+func (n *bridge) dhcpv6PDRenewalLoop(ctx context.Context, upstream string, renewIn time.Duration) {
+	timer := time.NewTimer(renewIn)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		lease, err := acquireDHCPv6PD(upstream)
+		if err != nil {
+			n.logger.Warn("Failed renewing DHCPv6-PD lease", logger.Ctx{"upstream": upstream, "err": err})
+			timer.Reset(dhcpv6PDRenewBuffer)
+			continue
+		}
+
+		bridgeSubnet, err := dhcpv6PDBridgeSubnet(lease.prefix)
+		if err != nil {
+			n.logger.Warn("Failed deriving bridge subnet from delegated prefix", logger.Ctx{"err": err})
+			timer.Reset(dhcpv6PDRenewBuffer)
+			continue
+		}
+
+		if bridgeSubnet.String() != n.config["ipv6.address"] {
+			n.logger.Info("Delegated IPv6 prefix changed, reconfiguring bridge", logger.Ctx{"network": n.name, "prefix": bridgeSubnet.String()})
+
+			oldConfig := maps.Clone(n.config)
+			n.config["ipv6.address"] = bridgeSubnet.String()
+
+			err = n.setup(oldConfig)
+			if err != nil {
+				n.logger.Warn("Failed reconfiguring bridge after DHCPv6-PD renewal", logger.Ctx{"err": err})
+			}
+		}
+
+		renewIn := lease.validLifetime - dhcpv6PDRenewBuffer
+		if renewIn <= 0 {
+			renewIn = dhcpv6PDRenewBuffer
+		}
+
+		timer.Reset(renewIn)
+	}
+}
+
+// dhcpv6PDLease describes a delegated prefix obtained from an upstream DHCPv6-PD server.
+type dhcpv6PDLease struct {
+	prefix        *net.IPNet
+	validLifetime time.Duration
+}
+
+// acquireDHCPv6PD runs a DHCPv6-PD solicit/request exchange on upstream and returns the
+// delegated prefix.
+func acquireDHCPv6PD(upstream string) (*dhcpv6PDLease, error) {
+	client, err := nclient6.New(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating DHCPv6 client on %q: %w", upstream, err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	reply, err := client.RapidSolicit(dhcpv6.WithIAPD(dhcpv6.GenerateTransactionID))
+	if err != nil {
+		return nil, fmt.Errorf("DHCPv6-PD exchange failed on %q: %w", upstream, err)
+	}
+
+	iaPD := reply.Options.OneIAPD()
+	if iaPD == nil {
+		return nil, fmt.Errorf("No IA_PD option in DHCPv6-PD reply on %q", upstream)
+	}
+
+	prefixes := iaPD.Options.Prefixes()
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("No delegated prefix in DHCPv6-PD reply on %q", upstream)
+	}
+
+	prefix := prefixes[0]
+
+	return &dhcpv6PDLease{
+		prefix:        &net.IPNet{IP: prefix.Prefix.IP, Mask: prefix.Prefix.Mask},
+		validLifetime: prefix.ValidLifetime,
+	}, nil
+}
+
+// dhcpv6PDBridgeSubnet slices a /64 out of a (typically shorter) delegated prefix for use as
+// the bridge's own ipv6.address.
+func dhcpv6PDBridgeSubnet(prefix *net.IPNet) (*net.IPNet, error) {
+	prefixSize, _ := prefix.Mask.Size()
+	if prefixSize > 64 {
+		return nil, fmt.Errorf("Delegated prefix %q is smaller than the /64 required for the bridge", prefix.String())
+	}
+
+	return &net.IPNet{IP: prefix.IP, Mask: net.CIDRMask(64, 128)}, nil
+}
+
+// dynamicRoutePeerSpec describes a BGP peer to import additional routes from, as parsed from the
+// "ipv4.routes.dynamic" or "ipv6.routes.dynamic" config keys.
+type dynamicRoutePeerSpec struct {
+	asn      uint32
+	address  string
+	password string
+	holdTime time.Duration
+	filter   *net.IPNet
+}
+
+// validateDynamicRoutePeer validates the value of "ipv4.routes.dynamic"/"ipv6.routes.dynamic".
+func validateDynamicRoutePeer(value string) error {
+	_, err := parseDynamicRoutePeer(value)
+	return err
+}
+
+// parseDynamicRoutePeer parses a `key=value,...` BGP peer spec. The "asn" and "address" keys are
+// required, "password", "holdtime" and "filter" are optional.
+func parseDynamicRoutePeer(value string) (*dynamicRoutePeerSpec, error) {
+	spec := &dynamicRoutePeerSpec{holdTime: 90 * time.Second}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("Invalid BGP peer entry %q (expected key=value)", pair)
+		}
+
+		switch key {
+		case "asn":
+			asn, err := strconv.ParseUint(val, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid BGP peer ASN %q: %w", val, err)
+			}
+
+			spec.asn = uint32(asn)
+		case "address":
+			if net.ParseIP(val) == nil {
+				return nil, fmt.Errorf("Invalid BGP peer address %q", val)
+			}
+
+			spec.address = val
+		case "password":
+			spec.password = val
+		case "holdtime":
+			seconds, err := strconv.ParseUint(val, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid BGP peer hold time %q: %w", val, err)
+			}
+
+			spec.holdTime = time.Duration(seconds) * time.Second
+		case "filter":
+			_, filter, err := net.ParseCIDR(val)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid BGP peer route filter %q: %w", val, err)
+			}
+
+			spec.filter = filter
+		default:
+			return nil, fmt.Errorf("Invalid BGP peer entry key %q", key)
+		}
+	}
+
+	if spec.asn == 0 {
+		return nil, errors.New(`BGP peer entry is missing required "asn" key`)
+	}
+
+	if spec.address == "" {
+		return nil, errors.New(`BGP peer entry is missing required "address" key`)
+	}
+
+	return spec, nil
+}
+
+// dynamicRouteStats holds the route counters exposed for a running dynamic route BGP session, for
+// consumption by an (as yet unimplemented) `/1.0/networks/{name}/routes` API endpoint.
+type dynamicRouteStats struct {
+	received  atomic.Uint64
+	installed atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+// dynamicRouteClient tracks the running state of a single dynamic route BGP session.
+type dynamicRouteClient struct {
+	cancel context.CancelFunc
+	stats  *dynamicRouteStats
+}
+
+// dynamicRouteClients tracks the running dynamic route BGP sessions, keyed by
+// "<network name>/<4 or 6>".
+var dynamicRouteClients sync.Map
+
+// DynamicRoutesState returns the route counters for the running dynamic route BGP session (if
+// any) for the given network name and IP family (4 or 6). It is the seam an API handler exposing
+// these counters under `/1.0/networks/{name}/routes` would call.
+func DynamicRoutesState(name string, family uint) (received uint64, installed uint64, rejected uint64, ok bool) {
+	v, found := dynamicRouteClients.Load(fmt.Sprintf("%s/%d", name, family))
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	client := v.(*dynamicRouteClient)
+
+	return client.stats.received.Load(), client.stats.installed.Load(), client.stats.rejected.Load(), true
+}
+
+// stopDynamicRouteClient cancels and forgets the running dynamic route BGP session (if any) for
+// the given network name and IP family (4 or 6), withdrawing any routes it had installed.
+func (n *bridge) stopDynamicRouteClient(family uint) {
+	key := fmt.Sprintf("%s/%d", n.name, family)
+
+	v, ok := dynamicRouteClients.LoadAndDelete(key)
+	if !ok {
+		return
+	}
+
+	v.(*dynamicRouteClient).cancel()
+}
+
+// setupDynamicRoutes starts a BGP session to the peer described by configKey
+// ("ipv4.routes.dynamic" or "ipv6.routes.dynamic") and installs/withdraws routes it advertises
+// onto the bridge as they are received, optionally restricted to prefixes matching spec.filter.
+func (n *bridge) setupDynamicRoutes(configKey string, family uint, ipFamily ip.Family) error {
+	n.stopDynamicRouteClient(family)
+
+	value := n.config[configKey]
+	if value == "" {
+		return nil
+	}
+
+	spec, err := parseDynamicRoutePeer(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stats := &dynamicRouteStats{}
+
+	dynamicRouteClients.Store(fmt.Sprintf("%s/%d", n.name, family), &dynamicRouteClient{cancel: cancel, stats: stats})
+
+	go n.dynamicRouteSessionLoop(ctx, spec, family, ipFamily, stats)
+
+	return nil
+}
+
+// dynamicRouteSessionLoop runs (and restarts on failure) a BGP peer session to spec, installing
+// and withdrawing kernel routes on the bridge as paths are received.
+func (n *bridge) dynamicRouteSessionLoop(ctx context.Context, spec *dynamicRoutePeerSpec, family uint, ipFamily ip.Family, stats *dynamicRouteStats) {
+	installed := make(map[string]*ip.Route)
+
+	defer func() {
+		for _, route := range installed {
+			_ = route.Flush()
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := runDynamicRouteSession(ctx, spec, func(prefix *net.IPNet, withdrawn bool) {
+			stats.received.Add(1)
+
+			if spec.filter != nil && !spec.filter.Contains(prefix.IP) {
+				stats.rejected.Add(1)
+				return
+			}
+
+			key := prefix.String()
+
+			if withdrawn {
+				route, ok := installed[key]
+				if ok {
+					_ = route.Flush()
+					delete(installed, key)
+				}
+
+				return
+			}
+
+			if installed[key] != nil {
+				return
+			}
+
+			route := &ip.Route{
+				DevName: n.name,
+				Route:   prefix,
+				Proto:   "bgp",
+				Family:  ipFamily,
+			}
+
+			err := route.Add()
+			if err != nil {
+				n.logger.Warn("Failed installing dynamically learned route", logger.Ctx{"network": n.name, "route": key, "err": err})
+				return
+			}
+
+			installed[key] = route
+			stats.installed.Add(1)
+		})
+		if err != nil && ctx.Err() == nil {
+			n.logger.Warn("Dynamic route BGP session failed, reconnecting", logger.Ctx{"network": n.name, "peer": spec.address, "err": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// routingPolicyRule describes a single entry of the "routing.policy" config key.
+type routingPolicyRule struct {
+	src    *net.IPNet
+	dst    *net.IPNet
+	fwmark string
+	table  string
+}
+
+// validateRoutingPolicy validates the value of "routing.policy".
+func validateRoutingPolicy(value string) error {
+	_, err := parseRoutingPolicyRules(value)
+	return err
+}
+
+// parseRoutingPolicyRules parses a ";"-separated list of `key=value,...` policy routing rules.
+// The "table" key is required on every rule, "src", "dst" and "fwmark" are optional match
+// criteria.
+func parseRoutingPolicyRules(value string) ([]routingPolicyRule, error) {
+	var rules []routingPolicyRule
+
+	for _, entry := range strings.Split(value, ";") {
+		rule := routingPolicyRule{}
+
+		for _, pair := range strings.Split(entry, ",") {
+			key, val, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, fmt.Errorf("Invalid routing policy entry %q (expected key=value)", pair)
+			}
+
+			switch key {
+			case "src":
+				_, subnet, err := net.ParseCIDR(val)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid routing policy src %q: %w", val, err)
+				}
+
+				rule.src = subnet
+			case "dst":
+				_, subnet, err := net.ParseCIDR(val)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid routing policy dst %q: %w", val, err)
+				}
+
+				rule.dst = subnet
+			case "fwmark":
+				rule.fwmark = val
+			case "table":
+				rule.table = val
+			default:
+				return nil, fmt.Errorf("Invalid routing policy entry key %q", key)
+			}
+		}
+
+		if rule.table == "" {
+			return nil, errors.New(`Routing policy entry is missing required "table" key`)
+		}
+
+		if rule.src == nil && rule.dst == nil && rule.fwmark == "" {
+			return nil, errors.New(`Routing policy entry requires at least one of "src", "dst" or "fwmark"`)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// setupEgressRoutingPolicy installs the dedicated routing tables and "ip rule" policy routing
+// entries used to steer the bridge's egress traffic (and any traffic matched by "routing.policy")
+// into specific uplink routing tables.
+func (n *bridge) setupEgressRoutingPolicy() error {
+	if n.config["ipv4.routing.table"] != "" {
+		_, subnet, err := net.ParseCIDR(n.config["ipv4.address"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing ipv4.address: %w", err)
+		}
+
+		rule := &ip.Rule{
+			Src:   subnet.String(),
+			Table: n.config["ipv4.routing.table"],
+		}
+
+		err = rule.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding IPv4 egress routing policy rule: %w", err)
+		}
+	}
+
+	if n.config["ipv6.routing.table"] != "" {
+		_, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing ipv6.address: %w", err)
+		}
+
+		rule := &ip.Rule{
+			Src:   subnet.String(),
+			Table: n.config["ipv6.routing.table"],
+		}
+
+		err = rule.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding IPv6 egress routing policy rule: %w", err)
+		}
+	}
+
+	if n.config["routing.policy"] != "" {
+		rules, err := parseRoutingPolicyRules(n.config["routing.policy"])
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range rules {
+			ipRule := &ip.Rule{Table: rule.table}
+
+			if rule.src != nil {
+				ipRule.Src = rule.src.String()
+			}
+
+			if rule.dst != nil {
+				ipRule.Dst = rule.dst.String()
+			}
+
+			if rule.fwmark != "" {
+				ipRule.Fwmark = rule.fwmark
+			}
+
+			err := ipRule.Add()
+			if err != nil {
+				return fmt.Errorf("Failed adding routing policy rule (table %s): %w", rule.table, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// teardownEgressRoutingPolicy removes the policy routing rules installed by
+// setupEgressRoutingPolicy. It is best-effort: failures are logged rather than returned, so that
+// Stop/Delete can still proceed to tear down the rest of the bridge.
+func (n *bridge) teardownEgressRoutingPolicy() {
+	if n.config["ipv4.routing.table"] != "" {
+		_, subnet, err := net.ParseCIDR(n.config["ipv4.address"])
+		if err == nil {
+			rule := &ip.Rule{Src: subnet.String(), Table: n.config["ipv4.routing.table"]}
+
+			err = rule.Delete()
+			if err != nil {
+				n.logger.Warn("Failed removing IPv4 egress routing policy rule", logger.Ctx{"err": err})
+			}
+		}
+	}
+
+	if n.config["ipv6.routing.table"] != "" {
+		_, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+		if err == nil {
+			rule := &ip.Rule{Src: subnet.String(), Table: n.config["ipv6.routing.table"]}
+
+			err = rule.Delete()
+			if err != nil {
+				n.logger.Warn("Failed removing IPv6 egress routing policy rule", logger.Ctx{"err": err})
+			}
+		}
+	}
+
+	if n.config["routing.policy"] != "" {
+		rules, err := parseRoutingPolicyRules(n.config["routing.policy"])
+		if err != nil {
+			return
+		}
+
+		for _, rule := range rules {
+			ipRule := &ip.Rule{Table: rule.table}
+
+			if rule.src != nil {
+				ipRule.Src = rule.src.String()
+			}
+
+			if rule.dst != nil {
+				ipRule.Dst = rule.dst.String()
+			}
+
+			if rule.fwmark != "" {
+				ipRule.Fwmark = rule.fwmark
+			}
+
+			err := ipRule.Delete()
+			if err != nil {
+				n.logger.Warn("Failed removing routing policy rule", logger.Ctx{"table": rule.table, "err": err})
+			}
+		}
+	}
+}
+
+// publishedPortRange is an inclusive port range used by a "ports.publish" entry.
+type publishedPortRange struct {
+	start uint64
+	end   uint64
+}
+
+// expand returns the individual ports covered by the range, in order.
+func (r publishedPortRange) expand() []uint64 {
+	ports := make([]uint64, 0, r.end-r.start+1)
+	for port := r.start; port <= r.end; port++ {
+		ports = append(ports, port)
+	}
+
+	return ports
+}
+
+// publishedPort describes a single entry of the "ports.publish" config key: traffic arriving on
+// listenAddress/listenPorts is forwarded to targetAddress/targetPorts.
+type publishedPort struct {
+	protocol      string
+	listenAddress net.IP
+	listenPorts   publishedPortRange
+	targetAddress net.IP
+	targetPorts   publishedPortRange
+}
+
+// validatePublishedPorts validates the value of "ports.publish".
+func validatePublishedPorts(value string) error {
+	_, err := parsePublishedPorts(value)
+	return err
+}
+
+// parsePublishedPortAddr parses a "<address>:<port>" or "<address>:<start>-<end>" fragment, as
+// used on either side of a "ports.publish" entry.
+func parsePublishedPortAddr(value string) (net.IP, publishedPortRange, error) {
+	host, portStr, err := net.SplitHostPort(value)
+	if err != nil {
+		return nil, publishedPortRange{}, err
+	}
+
+	address := net.ParseIP(host)
+	if address == nil {
+		return nil, publishedPortRange{}, fmt.Errorf("Invalid IP address %q", host)
+	}
+
+	startStr, endStr, found := strings.Cut(portStr, "-")
+	if !found {
+		endStr = startStr
+	}
+
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return nil, publishedPortRange{}, fmt.Errorf("Invalid port %q: %w", startStr, err)
+	}
+
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil {
+		return nil, publishedPortRange{}, fmt.Errorf("Invalid port %q: %w", endStr, err)
+	}
+
+	if end < start {
+		return nil, publishedPortRange{}, fmt.Errorf("Invalid port range %q", portStr)
+	}
+
+	return address, publishedPortRange{start: start, end: end}, nil
 }
 
-// checkClusterWideMACSafe returns whether it is safe to use the same MAC address for the bridge interface on all
-// cluster nodes. It is not suitable to use a static MAC address when "bridge.external_interfaces" is non-empty and
-// the bridge interface has no IPv4 or IPv6 address set. This is because in a clustered environment the same bridge
-// config is applied to all nodes, and if the bridge is being used to connect multiple nodes to the same network
-// segment it would cause MAC conflicts to use the same MAC on all nodes. If an IP address is specified then
-// connecting multiple nodes to the same network segment would also cause IP conflicts, so if an IP is defined
-// then we assume this is not being done. However if IP addresses are explicitly set to "none" and
-// "bridge.external_interfaces" is set then it may not be safe to use a the same MAC address on all nodes.
-func (n *bridge) checkClusterWideMACSafe(config map[string]string) error {
-	// We can't be sure that multiple clustered nodes aren't connected to the same network segment so don't
-	// use a static MAC address for the bridge interface to avoid introducing a MAC conflict.
-	if config["bridge.external_interfaces"] != "" && config["ipv4.address"] == "none" && config["ipv6.address"] == "none" {
-		return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge has no IP addresses and has external interfaces set`)
+// parsePublishedPorts parses a ","-separated list of
+// "<protocol>:<listen address>:<listen ports>-><target address>:<target ports>" entries, e.g.
+// "tcp:0.0.0.0:8080->192.0.2.10:80" or "udp:[::]:8000-8010->192.0.2.10:9000-9010".
+func parsePublishedPorts(value string) ([]publishedPort, error) {
+	var ports []publishedPort
+
+	for _, entry := range util.SplitNTrimSpace(value, ",", -1, false) {
+		listenPart, targetPart, found := strings.Cut(entry, "->")
+		if !found {
+			return nil, fmt.Errorf("Invalid port publish entry %q (expected listen->target)", entry)
+		}
+
+		protocol, listenAddrPort, found := strings.Cut(listenPart, ":")
+		if !found {
+			return nil, fmt.Errorf("Invalid port publish entry %q (expected protocol:address:ports)", entry)
+		}
+
+		if protocol != "tcp" && protocol != "udp" {
+			return nil, fmt.Errorf("Invalid port publish protocol %q (must be tcp or udp)", protocol)
+		}
+
+		listenAddress, listenPorts, err := parsePublishedPortAddr(listenAddrPort)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port publish listen address %q: %w", listenAddrPort, err)
+		}
+
+		targetAddress, targetPorts, err := parsePublishedPortAddr(targetPart)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid port publish target address %q: %w", targetPart, err)
+		}
+
+		if targetPorts.end-targetPorts.start != listenPorts.end-listenPorts.start {
+			return nil, fmt.Errorf("Port publish entry %q has mismatched listen/target port range sizes", entry)
+		}
+
+		ports = append(ports, publishedPort{
+			protocol:      protocol,
+			listenAddress: listenAddress,
+			listenPorts:   listenPorts,
+			targetAddress: targetAddress,
+			targetPorts:   targetPorts,
+		})
 	}
 
-	// We may have MAC conflicts if tunnels are in use.
-	for k := range config {
-		if strings.HasPrefix(k, "tunnel.") {
-			return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge has tunnels connected`)
+	return ports, nil
+}
+
+// publishedPortsToFirewallForwards converts "ports.publish" entries into the same
+// firewallDrivers.AddressForward format used by network forwards, so they can be DNATed/SNATed
+// through the regular firewall driver rather than needing their own rule-generation path.
+func publishedPortsToFirewallForwards(ports []publishedPort) []firewallDrivers.AddressForward {
+	var forwards []firewallDrivers.AddressForward
+
+	for _, p := range ports {
+		forwards = append(forwards, firewallDrivers.AddressForward{
+			ListenAddress: p.listenAddress,
+			Protocol:      p.protocol,
+			TargetAddress: p.targetAddress,
+			ListenPorts:   p.listenPorts.expand(),
+			TargetPorts:   p.targetPorts.expand(),
+		})
+	}
+
+	return forwards
+}
+
+// publishedPortProxies tracks the running userland port-publish proxies (used when
+// "ports.publish.mode" is "userland"), keyed by "<network name>/<protocol>:<listen address>:
+// <listen port>".
+var publishedPortProxies sync.Map
+
+// teardownPublishedPortProxies stops and removes any running userland port-publish proxies for
+// this network, ready for setupPublishedPortProxies to start the current set from scratch.
+func (n *bridge) teardownPublishedPortProxies() {
+	prefix := n.name + "/"
+
+	publishedPortProxies.Range(func(key, value any) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			value.(context.CancelFunc)()
+			publishedPortProxies.Delete(key)
+		}
+
+		return true
+	})
+}
+
+// setupPublishedPortProxies starts a userland accept-and-forward goroutine for each port of each
+// given entry, mirroring Docker's EnableUserlandProxy fallback for cases where DNAT hairpinning
+// of host traffic back onto the bridge is undesirable.
+func (n *bridge) setupPublishedPortProxies(ports []publishedPort) {
+	for _, p := range ports {
+		listenPorts := p.listenPorts.expand()
+		targetPorts := p.targetPorts.expand()
+
+		for i, listenPort := range listenPorts {
+			targetPort := targetPorts[i]
+
+			listenAddr := net.JoinHostPort(p.listenAddress.String(), strconv.FormatUint(listenPort, 10))
+			targetAddr := net.JoinHostPort(p.targetAddress.String(), strconv.FormatUint(targetPort, 10))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			publishedPortProxies.Store(fmt.Sprintf("%s/%s:%s", n.name, p.protocol, listenAddr), cancel)
+
+			if p.protocol == "tcp" {
+				go n.publishedPortProxyTCP(ctx, listenAddr, targetAddr)
+			} else {
+				go n.publishedPortProxyUDP(ctx, listenAddr, targetAddr)
+			}
 		}
 	}
+}
 
-	// If using a generated IPv6 address, we need a unique MAC.
-	if config["ipv6.address"] != "none" && validate.IsNetworkV6(config["ipv6.address"]) == nil {
-		return errors.New(`Cannot use static "bridge.hwaddr" MAC address when bridge uses a host-specific IPv6 address`)
+// publishedPortProxyTCP accepts TCP connections on listenAddr and forwards them to targetAddr
+// until ctx is cancelled.
+func (n *bridge) publishedPortProxyTCP(ctx context.Context, listenAddr string, targetAddr string) {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		n.logger.Warn("Failed starting port publish proxy", logger.Ctx{"listen": listenAddr, "err": err})
+		return
 	}
 
-	return nil
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return // Listener was closed (or failed), either way there's nothing left to do.
+		}
+
+		go relayTCP(conn, targetAddr, n.logger)
+	}
 }
 
-// FillConfig fills requested config with any default values.
-func (n *bridge) FillConfig(config map[string]string) error {
-	// Set some default values where needed.
-	if config["ipv4.address"] == "" {
-		config["ipv4.address"] = "auto"
+// relayTCP dials targetAddr and pipes conn and the resulting connection together until either
+// side closes.
+func relayTCP(conn net.Conn, targetAddr string, l logger.Logger) {
+	defer func() { _ = conn.Close() }()
+
+	upstream, err := net.Dial("tcp", targetAddr)
+	if err != nil {
+		l.Warn("Failed dialing port publish target", logger.Ctx{"target": targetAddr, "err": err})
+		return
 	}
 
-	if config["ipv4.address"] == "auto" && config["ipv4.nat"] == "" {
-		config["ipv4.nat"] = "true"
+	defer func() { _ = upstream.Close() }()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(upstream, conn)
+	}()
+
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(conn, upstream)
+	}()
+
+	wg.Wait()
+}
+
+// publishedPortProxyUDP relays UDP datagrams between listenAddr and a single upstream connection
+// to targetAddr until ctx is cancelled. Only the most recent client is tracked, which is
+// sufficient for the connectionless, single-client-at-a-time services (such as DNS) that this
+// fallback mode primarily targets.
+func (n *bridge) publishedPortProxyUDP(ctx context.Context, listenAddr string, targetAddr string) {
+	udpListenAddr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if err != nil {
+		n.logger.Warn("Failed resolving port publish listen address", logger.Ctx{"listen": listenAddr, "err": err})
+		return
 	}
 
-	if config["ipv6.address"] == "" {
-		content, err := os.ReadFile("/proc/sys/net/ipv6/conf/default/disable_ipv6")
-		if err == nil && string(content) == "0\n" {
-			config["ipv6.address"] = "auto"
+	conn, err := net.ListenUDP("udp", udpListenAddr)
+	if err != nil {
+		n.logger.Warn("Failed starting port publish proxy", logger.Ctx{"listen": listenAddr, "err": err})
+		return
+	}
+
+	udpTargetAddr, err := net.ResolveUDPAddr("udp", targetAddr)
+	if err != nil {
+		n.logger.Warn("Failed resolving port publish target address", logger.Ctx{"target": targetAddr, "err": err})
+		_ = conn.Close()
+		return
+	}
+
+	upstream, err := net.DialUDP("udp", nil, udpTargetAddr)
+	if err != nil {
+		n.logger.Warn("Failed dialing port publish target", logger.Ctx{"target": targetAddr, "err": err})
+		_ = conn.Close()
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		_ = upstream.Close()
+	}()
+
+	var clientAddr atomic.Pointer[net.UDPAddr]
+
+	go func() {
+		buf := make([]byte, 65507)
+		for {
+			readLen, err := upstream.Read(buf)
+			if err != nil {
+				return
+			}
+
+			addr := clientAddr.Load()
+			if addr != nil {
+				_, _ = conn.WriteToUDP(buf[:readLen], addr)
+			}
 		}
+	}()
+
+	buf := make([]byte, 65507)
+	for {
+		readLen, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		clientAddr.Store(addr)
+		_, _ = upstream.Write(buf[:readLen])
 	}
+}
 
-	if config["ipv6.address"] == "auto" && config["ipv6.nat"] == "" {
-		config["ipv6.nat"] = "true"
+// forwardProxies tracks the running userland proxy cancel function for each network's address
+// forwards, keyed by network name, mirroring publishedPortProxies.
+var forwardProxies sync.Map
+
+// teardownForwardProxies stops any running userland proxies for this network's address forwards,
+// ready for setupForwardProxies to start the current set from scratch.
+func (n *bridge) teardownForwardProxies() {
+	v, ok := forwardProxies.LoadAndDelete(n.name)
+	if ok {
+		v.(context.CancelFunc)()
 	}
+}
 
-	// Now replace any "auto" keys with generated values.
-	err := n.populateAutoConfig(config)
+// forwardUsesUserlandProxy returns whether a network address forward should be implemented with
+// a userland proxy rather than a firewall DNAT rule, taking the forward's own "userland_proxy"
+// config key as an override of the network-wide "network.userland_proxy" default.
+func (n *bridge) forwardUsesUserlandProxy(forward *api.NetworkForward) bool {
+	if forward.Config["userland_proxy"] != "" {
+		return util.IsTrue(forward.Config["userland_proxy"])
+	}
+
+	return util.IsTrue(n.config["network.userland_proxy"])
+}
+
+// setupForwardProxies starts a userland accept-and-forward goroutine for every listen tuple of
+// the supplied address forwards, in place of the firewall DNAT rule that forwardSetupFirewall
+// skips for them. This unlocks forwards listening on loopback addresses, and fixes hairpin NAT
+// on hosts where br_netfilter-based DNAT hairpinning isn't available.
+func (n *bridge) setupForwardProxies(fwForwards []firewallDrivers.AddressForward) {
+	n.teardownForwardProxies()
+
+	if len(fwForwards) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	forwardProxies.Store(n.name, cancel)
+
+	for _, fwForward := range fwForwards {
+		if len(fwForward.ListenPorts) == 0 {
+			// An all-ports default target forward isn't supported by the userland proxy.
+			n.logger.Warn("Skipping userland proxy for an all-ports address forward", logger.Ctx{"listenAddress": fwForward.ListenAddress})
+			continue
+		}
+
+		protocols := []string{fwForward.Protocol}
+		if fwForward.Protocol == "" {
+			protocols = []string{"tcp", "udp"}
+		}
+
+		for i, listenPort := range fwForward.ListenPorts {
+			targetPort := listenPort
+			if i < len(fwForward.TargetPorts) {
+				targetPort = fwForward.TargetPorts[i]
+			}
+
+			listenAddr := net.JoinHostPort(fwForward.ListenAddress.String(), strconv.FormatUint(listenPort, 10))
+			targetAddr := net.JoinHostPort(fwForward.TargetAddress.String(), strconv.FormatUint(targetPort, 10))
+
+			for _, protocol := range protocols {
+				if protocol == "udp" {
+					go n.publishedPortProxyUDP(ctx, listenAddr, targetAddr)
+				} else {
+					go n.publishedPortProxyTCP(ctx, listenAddr, targetAddr)
+				}
+			}
+		}
+	}
+}
+
+// checkAllocationRangesNoOverlap validates that the "<family>.allocation.ranges" config key
+// doesn't overlap with the DHCP or OVN ranges carved out of the same address, so an address
+// dynamically leased or routed to an OVN uplink can never collide with one handed out by
+// AllocateAddress. Family must be 4 or 6.
+func (n *bridge) checkAllocationRangesNoOverlap(config map[string]string, family uint) error {
+	keyPrefix := "ipv4"
+	dhcpSubnet := n.DHCPv4Subnet()
+	if family == 6 {
+		keyPrefix = "ipv6"
+		dhcpSubnet = n.DHCPv6Subnet()
+	}
+
+	allocKey := keyPrefix + ".allocation.ranges"
+	if config[allocKey] == "" {
+		return nil
+	}
+
+	allowedNets := []*net.IPNet{}
+	if dhcpSubnet != nil {
+		allowedNets = append(allowedNets, dhcpSubnet)
+	}
+
+	allocRanges, err := parseIPRanges(config[allocKey], allowedNets...)
 	if err != nil {
-		return fmt.Errorf("Failed generating auto config: %w", err)
+		return fmt.Errorf("Failed parsing %s: %w", allocKey, err)
+	}
+
+	for _, otherKey := range []string{keyPrefix + ".dhcp.ranges", keyPrefix + ".ovn.ranges"} {
+		if config[otherKey] == "" {
+			continue
+		}
+
+		otherRanges, err := parseIPRanges(config[otherKey], allowedNets...)
+		if err != nil {
+			return fmt.Errorf("Failed parsing %s: %w", otherKey, err)
+		}
+
+		for _, allocRange := range allocRanges {
+			for _, otherRange := range otherRanges {
+				if IPRangesOverlap(allocRange, otherRange) {
+					return fmt.Errorf("The range specified in %q (%q) cannot overlap with %q", allocKey, allocRange, otherKey)
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
-// populateAutoConfig replaces "auto" in config with generated values.
-func (n *bridge) populateAutoConfig(config map[string]string) error {
-	changedConfig := false
+// bridgeAllocations tracks addresses reserved by AllocateAddress, keyed by "<network name>/
+// <family>", with a *sync.Map of reserved address strings as the value. This is the local seam a
+// DB-backed reservation table would hook into for cluster-wide allocator state; for now
+// reservations are only tracked on the cluster member performing the allocation.
+var bridgeAllocations sync.Map
 
-	// Now populate "auto" values where needed.
-	if config["ipv4.address"] == "auto" {
-		subnet, err := randomSubnetV4()
-		if err != nil {
-			return err
+// nextIP returns the IP address immediately following ip.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
 		}
+	}
 
-		config["ipv4.address"] = subnet
-		changedConfig = true
+	return next
+}
+
+// AllocateAddress picks the next free address within "ipv4.allocation.ranges" (family 4) or
+// "ipv6.allocation.ranges" (family 6), skipping any address already reserved by a previous call
+// on this cluster member, and reserves it for the caller. This is the allocator a NIC would call
+// on start to assign a static "ipv4.address"/"ipv6.address" out of the pool set aside for
+// Incus-managed addresses, distinct from the DHCP and OVN pools. Call ReleaseAddress once the
+// address is no longer needed.
+func (n *bridge) AllocateAddress(family uint) (net.IP, error) {
+	keyPrefix := "ipv4"
+	if family == 6 {
+		keyPrefix = "ipv6"
 	}
 
-	if config["ipv6.address"] == "auto" {
-		subnet, err := randomSubnetV6()
-		if err != nil {
-			return err
+	allocKey := keyPrefix + ".allocation.ranges"
+
+	rangesConfig := n.config[allocKey]
+	if rangesConfig == "" {
+		return nil, fmt.Errorf("%q is not set", allocKey)
+	}
+
+	ranges, err := parseIPRanges(rangesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing %s: %w", allocKey, err)
+	}
+
+	v, _ := bridgeAllocations.LoadOrStore(fmt.Sprintf("%s/%d", n.name, family), &sync.Map{})
+	reserved := v.(*sync.Map)
+
+	for _, ipRange := range ranges {
+		startStr, endStr, found := strings.Cut(ipRange.String(), "-")
+		if !found {
+			startStr = ipRange.String()
+			endStr = startStr
 		}
 
-		config["ipv6.address"] = subnet
-		changedConfig = true
+		start := net.ParseIP(startStr)
+		end := net.ParseIP(endStr)
+		if start == nil || end == nil {
+			continue
+		}
+
+		for ip := start; bytes.Compare(ip.To16(), end.To16()) <= 0; ip = nextIP(ip) {
+			_, alreadyReserved := reserved.LoadOrStore(ip.String(), true)
+			if !alreadyReserved {
+				return ip, nil
+			}
+		}
 	}
 
-	// Re-validate config if changed.
-	if changedConfig && n.state != nil {
-		return n.Validate(config)
+	return nil, fmt.Errorf("No free address available in %s", allocKey)
+}
+
+// ReleaseAddress releases an address previously reserved by AllocateAddress, making it available
+// for allocation again.
+func (n *bridge) ReleaseAddress(family uint, address net.IP) {
+	v, ok := bridgeAllocations.Load(fmt.Sprintf("%s/%d", n.name, family))
+	if !ok {
+		return
 	}
 
-	return nil
+	v.(*sync.Map).Delete(address.String())
+}
+
+// firewallReloadWatchers tracks the running reload-watch loop cancel function for each network,
+// keyed by network name, so it can be torn down on Stop.
+var firewallReloadWatchers sync.Map
+
+// firewallReloadWatchInterval is how often the reload watcher re-runs the network's setup.
+// Nothing subscribes to firewalld's D-Bus "Reloaded" signal or an nftables netlink monitor, and
+// no check is made for whether the rules are still present; the watcher is purely periodic.
+const firewallReloadWatchInterval = 30 * time.Second
+
+// setupFirewallReloadWatcher starts a background loop that re-runs this network's setup every
+// firewallReloadWatchInterval, so rules wiped out by an external actor (a firewalld restart,
+// "nft flush ruleset", etc) are restored within one interval.
+func (n *bridge) setupFirewallReloadWatcher() {
+	n.teardownFirewallReloadWatcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firewallReloadWatchers.Store(n.name, cancel)
+
+	go n.firewallReloadWatchLoop(ctx)
+}
+
+// teardownFirewallReloadWatcher stops this network's reload watcher, if running.
+func (n *bridge) teardownFirewallReloadWatcher() {
+	cancel, ok := firewallReloadWatchers.LoadAndDelete(n.name)
+	if ok {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// firewallReloadWatchLoop calls n.setup on every tick, re-applying NetworkSetup, the address set
+// and ACL rules, and the address forwards whether or not an external flush or service restart has
+// removed them.
+func (n *bridge) firewallReloadWatchLoop(ctx context.Context) {
+	t := time.NewTicker(firewallReloadWatchInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			err := n.setup(n.config)
+			if err != nil {
+				n.logger.Warn("Failed to reconcile firewall rules", logger.Ctx{"err": err})
+			}
+		}
+	}
 }
 
 // ValidateName validates network name.
@@ -202,6 +1484,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Bridge driver: `native` or `openvswitch`
 		"bridge.driver": validate.Optional(validate.IsOneOf("native", "openvswitch")),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=bridge.dhcp.backend)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: `dnsmasq`
+		//  shortdesc: DHCP/DNS server implementation to use for the bridge: `dnsmasq` (external process) or `native` (in-process, no DNS service)
+		"bridge.dhcp.backend": validate.Optional(validate.IsOneOf("dnsmasq", "native")),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=bridge.external_interfaces)
 		//
 		// ---
@@ -217,9 +1508,18 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  type: string
 		//  condition: -
 		//  default: -
-		//  shortdesc: MAC address for the bridge
+		//  shortdesc: MAC address for the bridge; auto-generated and persisted on first start if unset
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=bridge.hwaddr.mode)
+		//
+		// ---
+		//  type: string
+		//  condition: `bridge.hwaddr` unset
+		//  default: `derived`
+		//  shortdesc: How to pick the bridge MAC address when `bridge.hwaddr` isn't set: `derived` (stable per-node, derived from the network ID and server name), `random` (stable per-node random MAC), or `static` (requires `bridge.hwaddr`)
+		"bridge.hwaddr.mode": validate.Optional(validate.IsOneOf("random", "derived", "static")),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=bridge.mtu)
 		//
 		// ---
@@ -259,8 +1559,8 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  type: bool
 		//  condition: IPv4 address
 		//  default: `false`(initial value on creation if `ipv4.address` is set to `auto`: `true`)
-		//  shortdesc: Whether to NAT
-		"ipv4.nat": validate.Optional(validate.IsBool),
+		//  shortdesc: Whether to NAT (`true`/`false`, or `insert`/`append` to also pick the rule placement)
+		"ipv4.nat": validate.Optional(validateNAT),
 
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.nat.order)
 		//
@@ -280,6 +1580,33 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: The source address used for outbound traffic from the bridge
 		"ipv4.nat.address": validate.Optional(validate.IsNetworkAddressV4),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.nat.mode)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 address
+		//  default: `masquerade`
+		//  shortdesc: Source-selection policy for NATed traffic leaving the bridge: `masquerade` (or `snat` with `ipv4.nat.address` set) for a single source address, or `snat-pool` to round-robin over `ipv4.nat.pool`
+		"ipv4.nat.mode": validate.Optional(validate.IsOneOf("masquerade", "snat", "snat-pool")),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.nat.pool)
+		//
+		// ---
+		//  type: string
+		//  condition: `ipv4.nat.mode=snat-pool`
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv4 ranges to round-robin as the SNAT source address (FIRST-LAST format)
+		"ipv4.nat.pool": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.nat.none_for)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 address
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv4 CIDR subnets to exempt from NAT (an `ACCEPT` rule is added ahead of the SNAT rule for these destinations), useful for routing to other internal networks without NAT while still NATing everything else
+		"ipv4.nat.none_for": validate.Optional(validate.IsListOf(validate.IsNetworkV4)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.dhcp)
 		//
 		// ---
@@ -325,6 +1652,22 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Static routes to provide via DHCP option 121, as a comma-separated list of alternating subnets (CIDR) and gateway addresses (same syntax as dnsmasq)
 		"ipv4.dhcp.routes": validate.Optional(validate.IsDHCPRouteList),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.dhcp.vendor-info)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 DHCP, bridge.dhcp.backend=native
+		//  default: -
+		//  shortdesc: Hex-encoded payload to serve as DHCP option 43 (vendor-specific information); ignored by the `dnsmasq` backend
+		"ipv4.dhcp.vendor-info": validate.Optional(func(value string) error {
+			_, err := hex.DecodeString(value)
+			if err != nil {
+				return fmt.Errorf("Invalid hex-encoded vendor data: %w", err)
+			}
+
+			return nil
+		}),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.routes)
 		//
 		// ---
@@ -334,6 +1677,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Comma-separated list of additional IPv4 CIDR subnets to route to the bridge
 		"ipv4.routes": validate.Optional(validate.IsListOf(validate.IsNetworkV4)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.routes.dynamic)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv4 address
+		//  default: -
+		//  shortdesc: BGP peer to import additional IPv4 routes from, as `asn=<ASN>,address=<IP>[,password=<secret>][,holdtime=<seconds>][,filter=<CIDR>]`
+		"ipv4.routes.dynamic": validate.Optional(validateDynamicRoutePeer),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.routing)
 		//
 		// ---
@@ -343,6 +1695,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Whether to route traffic in and out of the bridge
 		"ipv4.routing": validate.Optional(validate.IsBool),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.routing.table)
+		//
+		// ---
+		//  type: integer
+		//  condition: IPv4 address
+		//  default: -
+		//  shortdesc: Routing table ID to steer the bridge's egress IPv4 traffic into, for use with `routing.policy`
+		"ipv4.routing.table": validate.Optional(validate.IsUint32),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.ovn.ranges)
 		//
 		// ---
@@ -352,21 +1713,39 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Comma-separated list of IPv4 ranges to use for child OVN network routers (FIRST-LAST format)
 		"ipv4.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv4.allocation.ranges)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv4 ranges to reserve exclusively for Incus-managed static address allocation (such as NIC `ipv4.address`), distinct from `ipv4.dhcp.ranges` and `ipv4.ovn.ranges` (FIRST-LAST format)
+		"ipv4.allocation.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV4)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.address)
 		//
 		// ---
 		//  type: string
 		//  condition: standard mode
 		//  default: - (initial value on creation: `auto`)
-		//  shortdesc: IPv6 address for the bridge (use `none` to turn off IPv6 or `auto` to generate a new random unused subnet) (CIDR)
+		//  shortdesc: IPv6 address for the bridge (use `none` to turn off IPv6, `auto` to generate a new random unused subnet, or `dhcp6-pd` to request a prefix from `ipv6.prefix.upstream`) (CIDR)
 		"ipv6.address": validate.Optional(func(value string) error {
-			if validate.IsOneOf("none", "auto")(value) == nil {
+			if validate.IsOneOf("none", "auto", "dhcp6-pd")(value) == nil {
 				return nil
 			}
 
 			return validate.Or(validate.IsNetworkAddressCIDRV6, validate.IsNetworkV6)(value)
 		}),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.prefix.upstream)
+		//
+		// ---
+		//  type: string
+		//  condition: `ipv6.address` set to `dhcp6-pd`
+		//  default: -
+		//  shortdesc: Upstream interface to run the DHCPv6-PD client against
+		"ipv6.prefix.upstream": validate.Optional(validate.IsInterfaceName),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.firewall)
 		//
 		// ---
@@ -382,8 +1761,8 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  type: bool
 		//  condition: IPv6 address
 		//  default: `false` (initial value on creation if `ipv6.address` is set to `auto`: `true`)
-		//  shortdesc: Whether to NAT
-		"ipv6.nat": validate.Optional(validate.IsBool),
+		//  shortdesc: Whether to NAT (`true`/`false`, or `insert`/`append` to also pick the rule placement)
+		"ipv6.nat": validate.Optional(validateNAT),
 
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.nat.order)
 		//
@@ -403,6 +1782,33 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: The source address used for outbound traffic from the bridge
 		"ipv6.nat.address": validate.Optional(validate.IsNetworkAddressV6),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.nat.mode)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: `masquerade`
+		//  shortdesc: Source-selection policy for NATed traffic leaving the bridge: `masquerade` (or `snat` with `ipv6.nat.address` set) for a single source address, or `snat-pool` to round-robin over `ipv6.nat.pool`
+		"ipv6.nat.mode": validate.Optional(validate.IsOneOf("masquerade", "snat", "snat-pool")),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.nat.pool)
+		//
+		// ---
+		//  type: string
+		//  condition: `ipv6.nat.mode=snat-pool`
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv6 ranges to round-robin as the SNAT source address (FIRST-LAST format)
+		"ipv6.nat.pool": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.nat.none_for)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv6 CIDR subnets to exempt from NAT (an `ACCEPT` rule is added ahead of the SNAT rule for these destinations), useful for routing to other internal networks without NAT while still NATing everything else
+		"ipv6.nat.none_for": validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.dhcp)
 		//
 		// ---
@@ -439,6 +1845,60 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Comma-separated list of IPv6 ranges to use for DHCP (FIRST-LAST format)
 		"ipv6.dhcp.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.dhcp.routes)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 stateful DHCP
+		//  default: -
+		//  shortdesc: Static routes to provide via DHCPv6, as a comma-separated list of alternating subnets (CIDR) and gateway addresses (same syntax as `ipv4.dhcp.routes`)
+		"ipv6.dhcp.routes": validate.Optional(validate.IsDHCPv6RouteList),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ra.default_gateway)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Non-link-local IPv6 default gateway on segments bridged to an external L2 with an upstream router; when set, router advertisements carry a non-zero router lifetime (1800 seconds), but the advertised default router is always the bridge's own link-local address and this address itself is not advertised; must be inside `ipv6.address`'s subnet unless `ipv6.routing` is `false`, and requires a `/64` subnet when SLAAC is in use
+		"ipv6.ra.default_gateway": validate.Optional(validate.IsNetworkAddressV6),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ra.mtu)
+		//
+		// ---
+		//  type: integer
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: MTU to advertise to clients via router advertisements
+		"ipv6.ra.mtu": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ra.dns)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv6 DNS server addresses to advertise via router advertisements (RDNSS), overriding `dns.nameservers` for SLAAC clients
+		"ipv6.ra.dns": validate.Optional(validate.IsListOf(validate.IsNetworkAddressV6)),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ra.prefix_lifetime)
+		//
+		// ---
+		//  type: integer
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Valid lifetime (in seconds) to advertise for the on-link prefix via router advertisements
+		"ipv6.ra.prefix_lifetime": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ra.preferred_lifetime)
+		//
+		// ---
+		//  type: integer
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Preferred lifetime (in seconds) to advertise for the on-link prefix via router advertisements
+		"ipv6.ra.preferred_lifetime": validate.Optional(validate.IsUint32),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.routes)
 		//
 		// ---
@@ -448,6 +1908,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Comma-separated list of additional IPv6 CIDR subnets to route to the bridge
 		"ipv6.routes": validate.Optional(validate.IsListOf(validate.IsNetworkV6)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.routes.dynamic)
+		//
+		// ---
+		//  type: string
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: BGP peer to import additional IPv6 routes from, as `asn=<ASN>,address=<IP>[,password=<secret>][,holdtime=<seconds>][,filter=<CIDR>]`
+		"ipv6.routes.dynamic": validate.Optional(validateDynamicRoutePeer),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.routing)
 		//
 		// ---
@@ -457,6 +1926,24 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Whether to route traffic in and out of the bridge
 		"ipv6.routing": validate.Optional(validate.IsBool),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.routing.table)
+		//
+		// ---
+		//  type: integer
+		//  condition: IPv6 address
+		//  default: -
+		//  shortdesc: Routing table ID to steer the bridge's egress IPv6 traffic into, for use with `routing.policy`
+		"ipv6.routing.table": validate.Optional(validate.IsUint32),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=routing.policy)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: -
+		//  shortdesc: Semicolon-separated list of additional policy routing rules, as `src=<CIDR>,dst=<CIDR>,fwmark=<mark>,table=<ID>` (`table` required, at least one of `src`/`dst`/`fwmark` required)
+		"routing.policy": validate.Optional(validateRoutingPolicy),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.ovn.ranges)
 		//
 		// ---
@@ -466,6 +1953,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Comma-separated list of IPv6 ranges to use for child OVN network routers (FIRST-LAST format)
 		"ipv6.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
 
+		// gendoc:generate(entity=network_bridge, group=common, key=ipv6.allocation.ranges)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: -
+		//  shortdesc: Comma-separated list of IPv6 ranges to reserve exclusively for Incus-managed static address allocation (such as NIC `ipv6.address`), distinct from `ipv6.dhcp.ranges` and `ipv6.ovn.ranges` (FIRST-LAST format)
+		"ipv6.allocation.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+
 		// gendoc:generate(entity=network_bridge, group=common, key=dns.nameservers)
 		//
 		// ---
@@ -581,6 +2077,51 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  default: `false`
 		//  shortdesc: Whether to log egress traffic that doesn't match any ACL rule
 		"security.acls.default.egress.logged": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=security.icc)
+		//
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: `true`
+		//  shortdesc: Whether to allow inter-instance communication between instances connected to this bridge (traffic to the bridge host address and upstream gateway is still allowed). Ignored if `security.acls` is set, as ACLs provide more granular control
+		"security.icc": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=bridge.icc)
+		//
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: `true`
+		//  shortdesc: Whether to allow inter-instance (east-west) communication across this bridge, even when `security.acls` is configured. When set to `false`, forwarded traffic whose input and output interface are both this bridge is dropped by default, except traffic matching an explicit ACL allow rule or a configured network forward
+		"bridge.icc": validate.Optional(validate.IsBool),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ports.publish)
+		//
+		// ---
+		//  type: string
+		//  condition: -
+		//  default: -
+		//  shortdesc: Comma-separated list of `protocol:listen_address:listen_ports->target_address:target_ports` port publish entries DNATed (or userland-proxied, see `ports.publish.mode`) to a target address reachable on this bridge
+		"ports.publish": validate.Optional(validatePublishedPorts),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=ports.publish.mode)
+		//
+		// ---
+		//  type: string
+		//  condition: `ports.publish`
+		//  default: `dnat`
+		//  shortdesc: Whether `ports.publish` entries are programmed as firewall DNAT/SNAT rules (`dnat`) or relayed by a userland accept-and-forward proxy (`userland`), for cases where DNAT hairpinning is undesirable
+		"ports.publish.mode": validate.Optional(validate.IsOneOf("dnat", "userland")),
+
+		// gendoc:generate(entity=network_bridge, group=common, key=network.userland_proxy)
+		//
+		// ---
+		//  type: bool
+		//  condition: -
+		//  default: `false`
+		//  shortdesc: Whether network address forwards default to a userland accept-and-forward proxy instead of a firewall DNAT rule. Can be overridden per-forward via the forward's own `userland_proxy` config key. Useful on hosts where DNAT hairpinning isn't available, or to expose a forward on `127.0.0.1`
+		"network.userland_proxy": validate.Optional(validate.IsBool),
 	}
 
 	// Add dynamic validation rules.
@@ -608,59 +2149,59 @@ func (n *bridge) Validate(config map[string]string) error {
 				//  type: string
 				//  condition: standard mode
 				//  default: -
-				//  shortdesc: Tunneling protocol: `vxlan` or `gre`
-				rules[k] = validate.Optional(validate.IsOneOf("gre", "vxlan"))
+				//  shortdesc: Tunneling protocol: `vxlan`, `gre`, or `geneve`
+				rules[k] = validate.Optional(validate.IsOneOf("gre", "vxlan", "geneve"))
 			case "local":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.local)
 				//
 				// ---
 				//  type: string
-				//  condition: `gre` or `vxlan`
+				//  condition: `gre`, `vxlan`, or `geneve`
 				//  default: -
-				//  shortdesc: Local address for the tunnel (not necessary for multicast `vxlan`)
+				//  shortdesc: Local address for the tunnel (not necessary for multicast `vxlan`/`geneve`)
 				rules[k] = validate.Optional(validate.IsNetworkAddress)
 			case "remote":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.remote)
 				//
 				// ---
 				//  type: string
-				//  condition: `gre` or `vxlan`
+				//  condition: `gre`, `vxlan`, or `geneve`
 				//  default: -
-				//  shortdesc: Remote address for the tunnel (not necessary for multicast `vxlan`)
+				//  shortdesc: Remote address for the tunnel (not necessary for multicast `vxlan`/`geneve`)
 				rules[k] = validate.Optional(validate.IsNetworkAddress)
 			case "port":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.port)
 				//
 				// ---
 				//  type: integer
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `geneve`
 				//  default: `0`
-				//  shortdesc: Specific port to use for the `vxlan` tunnel
+				//  shortdesc: Specific port to use for the `vxlan`/`geneve` tunnel (defaults to `6081` for `geneve`)
 				rules[k] = networkValidPort
 			case "group":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.group)
 				//
 				// ---
 				//  type: string
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `geneve`
 				//  default: `239.0.0.1`
-				//  shortdesc: Multicast address for `vxlan` (used if local and remote aren't set)
+				//  shortdesc: Multicast address for `vxlan`/`geneve` (used if local and remote aren't set)
 				rules[k] = validate.Optional(validate.IsNetworkAddress)
 			case "id":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.id)
 				//
 				// ---
 				//  type: integer
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `geneve`
 				//  default: `0`
-				//  shortdesc: Specific tunnel ID to use for the `vxlan` tunnel
+				//  shortdesc: Specific tunnel ID (VNI) to use for the `vxlan`/`geneve` tunnel
 				rules[k] = validate.Optional(validate.IsInt64)
 			case "interface":
 				// gendoc:generate(entity=network_bridge, group=common, key=tunnel.NAME.interface)
 				//
 				// ---
 				//  type: string
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `geneve`
 				//  default: -
 				//  shortdesc: Specific host interface to use for the tunnel
 				rules[k] = validate.IsInterfaceName
@@ -669,7 +2210,7 @@ func (n *bridge) Validate(config map[string]string) error {
 				//
 				// ---
 				//  type: integer
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `geneve`
 				//  default: `1`
 				//  shortdesc: Specific TTL to use for multicast routing topologies
 				rules[k] = validate.Optional(validate.IsUint8)
@@ -836,6 +2377,75 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check IPv4/IPv6 static allocation ranges don't overlap with the DHCP or OVN ranges carved
+	// out of the same address, so a dynamically leased or OVN-routed address can never collide
+	// with one handed out by AllocateAddress.
+	err = n.checkAllocationRangesNoOverlap(config, 4)
+	if err != nil {
+		return err
+	}
+
+	err = n.checkAllocationRangesNoOverlap(config, 6)
+	if err != nil {
+		return err
+	}
+
+	// Check DHCPv6-PD prefix delegation settings.
+	if config["ipv6.address"] == "dhcp6-pd" && config["ipv6.prefix.upstream"] == "" {
+		return errors.New(`"ipv6.prefix.upstream" must be set when "ipv6.address" is "dhcp6-pd"`)
+	}
+
+	// Check bridge.hwaddr.mode is only set to "static" alongside an explicit bridge.hwaddr.
+	if config["bridge.hwaddr.mode"] == "static" && config["bridge.hwaddr"] == "" {
+		return errors.New(`"bridge.hwaddr" must be set when "bridge.hwaddr.mode" is "static"`)
+	}
+
+	// Check ipv4.routing.table/ipv6.routing.table are only used alongside an actual address.
+	if config["ipv4.routing.table"] != "" && (config["ipv4.address"] == "" || config["ipv4.address"] == "none") {
+		return errors.New(`"ipv4.routing.table" requires "ipv4.address" to be set`)
+	}
+
+	if config["ipv6.routing.table"] != "" && (config["ipv6.address"] == "" || config["ipv6.address"] == "none") {
+		return errors.New(`"ipv6.routing.table" requires "ipv6.address" to be set`)
+	}
+
+	// Check ipv6.ra.default_gateway is inside the ipv6.address subnet (unless routing is
+	// disabled, in which case the gateway may legitimately sit on an upstream-only segment), and
+	// that the subnet is a /64 if SLAAC clients are going to learn it.
+	if config["ipv6.ra.default_gateway"] != "" {
+		if util.IsNoneOrEmpty(config["ipv6.address"]) {
+			return errors.New(`"ipv6.ra.default_gateway" requires "ipv6.address" to be set`)
+		}
+
+		_, subnet, err := net.ParseCIDR(config["ipv6.address"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing ipv6.address: %w", err)
+		}
+
+		gateway := net.ParseIP(config["ipv6.ra.default_gateway"])
+
+		if util.IsTrueOrEmpty(config["ipv6.routing"]) && !subnet.Contains(gateway) {
+			return errors.New(`"ipv6.ra.default_gateway" must be inside the "ipv6.address" subnet unless "ipv6.routing" is disabled`)
+		}
+
+		if !util.IsTrue(config["ipv6.dhcp.stateful"]) {
+			ones, _ := subnet.Mask.Size()
+			if ones != 64 {
+				return errors.New(`"ipv6.ra.default_gateway" requires a /64 "ipv6.address" subnet when SLAAC is in use`)
+			}
+		}
+	}
+
+	// Check "ipv4.nat.pool"/"ipv6.nat.pool" is set when the corresponding "nat.mode" is
+	// "snat-pool".
+	if config["ipv4.nat.mode"] == "snat-pool" && config["ipv4.nat.pool"] == "" {
+		return errors.New(`"ipv4.nat.pool" must be set when "ipv4.nat.mode" is "snat-pool"`)
+	}
+
+	if config["ipv6.nat.mode"] == "snat-pool" && config["ipv6.nat.pool"] == "" {
+		return errors.New(`"ipv6.nat.pool" must be set when "ipv6.nat.mode" is "snat-pool"`)
+	}
+
 	// Check Security ACLs are supported and exist.
 	if config["security.acls"] != "" {
 		err = acl.Exists(n.state, n.Project(), util.SplitNTrimSpace(config["security.acls"], ",", -1, true)...)
@@ -942,7 +2552,12 @@ func (n *bridge) Start() error {
 
 	reverter.Add(func() { n.setUnavailable() })
 
-	err := n.setup(nil)
+	err := n.ensurePersistentHwaddr()
+	if err != nil {
+		return err
+	}
+
+	err = n.setup(nil)
 	if err != nil {
 		return err
 	}
@@ -952,6 +2567,9 @@ func (n *bridge) Start() error {
 	// Ensure network is marked as available now its started.
 	n.setAvailable()
 
+	// Watch for the host firewall service being reloaded/flushed out from under us.
+	n.setupFirewallReloadWatcher()
+
 	return nil
 }
 
@@ -977,6 +2595,17 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 	var err error
 
+	// If using DHCPv6-PD, resolve the delegated prefix into a concrete ipv6.address before
+	// the rest of setup (dnsmasq, RA, routes) runs, and arrange for it to be kept up to date.
+	if n.config["ipv6.address"] == "dhcp6-pd" {
+		err = n.setupDHCPv6PD()
+		if err != nil {
+			return fmt.Errorf("Failed configuring DHCPv6-PD: %w", err)
+		}
+	} else {
+		stopDHCPv6PDClient(n.name)
+	}
+
 	// Build up the bridge interface's settings.
 	bridge := ip.Bridge{
 		Link: ip.Link{
@@ -1006,6 +2635,13 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		if err != nil {
 			return fmt.Errorf("Failed parsing MAC address %q: %w", n.config["bridge.hwaddr"], err)
 		}
+	} else if n.config["bridge.hwaddr.mode"] == "derived" {
+		// Deterministically derive a locally-administered MAC from the network ID and the node
+		// name, so that each cluster member gets a stable (across reboots and re-elections of
+		// seedNodeID) MAC without risking a cluster-wide MAC conflict.
+		bridge.Address = derivedBridgeMAC(n.ID(), n.state.ServerName)
+
+		n.logger.Debug("Derived MAC generated", logger.Ctx{"hwAddr": bridge.Address.String()})
 	} else {
 		// If no cluster wide static MAC address set, then generate one.
 		var seedNodeID int64
@@ -1065,12 +2701,13 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		} else {
 			// Add and configure the interface in one operation to reduce the number of executions and
 			// to avoid systemd-udevd from applying the default MACAddressPolicy=persistent policy.
-			err := bridge.Add()
+			// Delegated to the platform bridgeDriver (netlink on Linux, ifconfig on FreeBSD).
+			err := n.bridgeDriver().create(n.name, bridge.MTU, bridge.Address)
 			if err != nil {
 				return err
 			}
 
-			reverter.Add(func() { _ = bridge.Delete() })
+			reverter.Add(func() { _ = n.bridgeDriver().delete(n.name) })
 		}
 	} else {
 		// If bridge already exists then re-apply settings. If we just created a bridge then we don't
@@ -1273,6 +2910,18 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 	if n.config["security.acls"] != "" {
 		fwOpts.ACL = true
+	} else if util.IsFalse(n.config["security.icc"]) {
+		// Block inter-instance communication on this bridge. Only applies when ACLs aren't in
+		// use, since ACLs already provide more granular control over instance-to-instance traffic.
+		fwOpts.ICCDisabled = true
+	}
+
+	if util.IsFalse(n.config["bridge.icc"]) {
+		// Unlike "security.icc", this isolation rule is installed regardless of whether ACLs
+		// are configured, so operators using ACLs can still opt into a default-deny east-west
+		// posture and punch holes for specific flows with an explicit ACL allow rule or a
+		// configured network forward.
+		fwOpts.IsolateInternal = true
 	}
 
 	// Snapshot container specific IPv4 routes (added with boot proto) before removing IPv4 addresses.
@@ -1441,20 +3090,33 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 
 		// Configure NAT.
-		if util.IsTrue(n.config["ipv4.nat"]) {
+		if natEnabled(n.config["ipv4.nat"]) {
 			// If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
 			var srcIP net.IP
-			if n.config["ipv4.nat.address"] != "" {
+			var pool []net.IP
+			if n.config["ipv4.nat.mode"] == "snat-pool" {
+				pool, err = parseNATPool(n.config["ipv4.nat.pool"])
+				if err != nil {
+					return err
+				}
+			} else if n.config["ipv4.nat.address"] != "" {
 				srcIP = net.ParseIP(n.config["ipv4.nat.address"])
 			}
 
-			fwOpts.SNATV4 = &firewallDrivers.SNATOpts{
-				SNATAddress: srcIP,
-				Subnet:      subnet,
+			var excludes []*net.IPNet
+			if n.config["ipv4.nat.none_for"] != "" {
+				excludes, err = parseNATExcludeDestinations(n.config["ipv4.nat.none_for"])
+				if err != nil {
+					return err
+				}
 			}
 
-			if n.config["ipv4.nat.order"] == "after" {
-				fwOpts.SNATV4.Append = true
+			fwOpts.SNATV4 = &firewallDrivers.SNATOpts{
+				SNATAddress:         srcIP,
+				Pool:                pool,
+				ExcludeDestinations: excludes,
+				Subnet:              subnet,
+				Append:              natAppend(n.config["ipv4.nat"], n.config["ipv4.nat.order"]),
 			}
 		}
 
@@ -1480,6 +3142,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Import additional routes dynamically from a BGP peer.
+		err = n.setupDynamicRoutes("ipv4.routes.dynamic", 4, ip.FamilyV4)
+		if err != nil {
+			return err
+		}
+
 		// Restore container specific IPv4 routes to interface.
 		n.applyBootRoutesV4(ctRoutes)
 	}
@@ -1560,6 +3228,48 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 		// Update the dnsmasq config.
 		dnsmasqCmd = append(dnsmasqCmd, []string{fmt.Sprintf("--listen-address=%s", ipAddress.String()), "--enable-ra"}...)
+
+		// NOTE: "ipv6.dhcp.routes" only reaches stateful DHCPv6 clients (handled below). No RFC 4191
+		// Route Information or RFC 8781 PREF64 options are added to Router Advertisements, as the
+		// dnsmasq this daemon manages doesn't expose a way to set them, so SLAAC-only clients that
+		// never perform DHCPv6 don't receive these routes.
+
+		// Build the "--ra-param" tuning the RA MTU option and, if "ipv6.ra.default_gateway" is
+		// set, the router lifetime. NOTE: dnsmasq always advertises its own link-local address
+		// as the RA default router and has no option to substitute an arbitrary next-hop, so the
+		// most "ipv6.ra.default_gateway" can do here is keep a non-zero router lifetime
+		// advertised, making the bridge itself eligible as a default router on segments bridged
+		// to an external L2 with an upstream router at this address.
+		var raParams []string
+		if n.config["ipv6.ra.mtu"] != "" {
+			raParams = append(raParams, fmt.Sprintf("mtu:%s", n.config["ipv6.ra.mtu"]))
+		}
+
+		if n.config["ipv6.ra.default_gateway"] != "" {
+			raParams = append(raParams, "0", "1800")
+		}
+
+		if len(raParams) > 0 {
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--ra-param=%s,%s", n.name, strings.Join(raParams, ",")))
+		}
+
+		// Build the on-link prefix valid/preferred lifetime suffix shared by the "ra-only" and
+		// "ra-stateless" dhcp-range lines below.
+		prefixLifetimes := ""
+		if n.config["ipv6.ra.prefix_lifetime"] != "" || n.config["ipv6.ra.preferred_lifetime"] != "" {
+			validLifetime := n.config["ipv6.ra.prefix_lifetime"]
+			if validLifetime == "" {
+				validLifetime = "infinite"
+			}
+
+			preferredLifetime := n.config["ipv6.ra.preferred_lifetime"]
+			if preferredLifetime == "" {
+				preferredLifetime = "infinite"
+			}
+
+			prefixLifetimes = fmt.Sprintf(",%s,%s", validLifetime, preferredLifetime)
+		}
+
 		if n.DHCPv6Subnet() != nil {
 			if n.hasIPv6Firewall() {
 				fwOpts.FeaturesV6.ICMPDHCPDNSAccess = true
@@ -1584,14 +3294,27 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				} else {
 					dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("%s,%s,%d,%s", dhcpalloc.GetIP(subnet, 2), dhcpalloc.GetIP(subnet, -1), subnetSize, expiry)}...)
 				}
+
+				// Push static routes to stateful DHCPv6 clients. DHCPv6 has no standardised
+				// equivalent of the IPv4 "classless static route" option (121), so a vendor-specific
+				// option code is used here. SLAAC-only clients don't receive these routes (see the
+				// NOTE above).
+				if n.config["ipv6.dhcp.routes"] != "" {
+					dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-option-force=option6:242,%s", strings.ReplaceAll(n.config["ipv6.dhcp.routes"], " ", "")))
+				}
 			} else {
-				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-stateless,ra-names", n.name)}...)
+				dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-stateless,ra-names%s", n.name, prefixLifetimes)}...)
 			}
 		} else {
-			dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-only", n.name)}...)
+			dnsmasqCmd = append(dnsmasqCmd, []string{"--dhcp-range", fmt.Sprintf("::,constructor:%s,ra-only%s", n.name, prefixLifetimes)}...)
 		}
 
-		if n.config["dns.nameservers"] != "" {
+		if n.config["ipv6.ra.dns"] != "" {
+			// "ipv6.ra.dns" overrides "dns.nameservers" for the RDNSS option advertised via
+			// router advertisements (and, since dnsmasq has no separate RA/DHCPv6 DNS options,
+			// for stateful DHCPv6 clients too).
+			dnsmasqCmd = append(dnsmasqCmd, fmt.Sprintf("--dhcp-option-force=option6:dns-server,[%s]", strings.ReplaceAll(n.config["ipv6.ra.dns"], " ", "")))
+		} else if n.config["dns.nameservers"] != "" {
 			if len(dnsIPv6) == 0 {
 				dnsmasqCmd = append(dnsmasqCmd, "--dhcp-option-force=option6:dns-server")
 			} else {
@@ -1657,20 +3380,33 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 
 		// Configure NAT.
-		if util.IsTrue(n.config["ipv6.nat"]) {
+		if natEnabled(n.config["ipv6.nat"]) {
 			// If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
 			var srcIP net.IP
-			if n.config["ipv6.nat.address"] != "" {
+			var pool []net.IP
+			if n.config["ipv6.nat.mode"] == "snat-pool" {
+				pool, err = parseNATPool(n.config["ipv6.nat.pool"])
+				if err != nil {
+					return err
+				}
+			} else if n.config["ipv6.nat.address"] != "" {
 				srcIP = net.ParseIP(n.config["ipv6.nat.address"])
 			}
 
-			fwOpts.SNATV6 = &firewallDrivers.SNATOpts{
-				SNATAddress: srcIP,
-				Subnet:      subnet,
+			var excludes []*net.IPNet
+			if n.config["ipv6.nat.none_for"] != "" {
+				excludes, err = parseNATExcludeDestinations(n.config["ipv6.nat.none_for"])
+				if err != nil {
+					return err
+				}
 			}
 
-			if n.config["ipv6.nat.order"] == "after" {
-				fwOpts.SNATV6.Append = true
+			fwOpts.SNATV6 = &firewallDrivers.SNATOpts{
+				SNATAddress:         srcIP,
+				Pool:                pool,
+				ExcludeDestinations: excludes,
+				Subnet:              subnet,
+				Append:              natAppend(n.config["ipv6.nat"], n.config["ipv6.nat.order"]),
 			}
 		}
 
@@ -1696,6 +3432,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Import additional routes dynamically from a BGP peer.
+		err = n.setupDynamicRoutes("ipv6.routes.dynamic", 6, ip.FamilyV6)
+		if err != nil {
+			return err
+		}
+
 		// Restore container specific IPv6 routes to interface.
 		n.applyBootRoutesV6(ctRoutes)
 	}
@@ -1712,28 +3454,93 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		tunRemote := net.ParseIP(getConfig("remote"))
 		tunName := fmt.Sprintf("%s-%s", n.name, tunnel)
 
-		// Configure the tunnel.
-		if tunProtocol == "gre" {
-			// Skip partial configs.
-			if tunLocal == nil || tunRemote == nil {
-				continue
+		// Configure the tunnel.
+		if tunProtocol == "gre" {
+			// Skip partial configs.
+			if tunLocal == nil || tunRemote == nil {
+				continue
+			}
+
+			gretap := &ip.Gretap{
+				Link:   ip.Link{Name: tunName},
+				Local:  tunLocal,
+				Remote: tunRemote,
+			}
+
+			err := gretap.Add()
+			if err != nil {
+				return err
+			}
+		} else if tunProtocol == "vxlan" {
+			tunGroup := net.ParseIP(getConfig("group"))
+			tunInterface := getConfig("interface")
+
+			vxlan := &ip.Vxlan{
+				Link:  ip.Link{Name: tunName},
+				Local: tunLocal,
+			}
+
+			if tunRemote != nil {
+				// Skip partial configs.
+				if tunLocal == nil {
+					continue
+				}
+
+				vxlan.Remote = tunRemote
+			} else {
+				if tunGroup == nil {
+					tunGroup = net.IPv4(239, 0, 0, 1) // 239.0.0.1
+				}
+
+				devName := tunInterface
+				if devName == "" {
+					_, devName, err = DefaultGatewaySubnetV4()
+					if err != nil {
+						return err
+					}
+				}
+
+				vxlan.Group = tunGroup
+				vxlan.DevName = devName
+			}
+
+			tunPort := getConfig("port")
+			if tunPort != "" {
+				vxlan.DstPort, err = strconv.Atoi(tunPort)
+				if err != nil {
+					return err
+				}
+			}
+
+			tunID := getConfig("id")
+			if tunID == "" {
+				vxlan.VxlanID = 1
+			} else {
+				vxlan.VxlanID, err = strconv.Atoi(tunID)
+				if err != nil {
+					return err
+				}
 			}
 
-			gretap := &ip.Gretap{
-				Link:   ip.Link{Name: tunName},
-				Local:  tunLocal,
-				Remote: tunRemote,
+			tunTTL := getConfig("ttl")
+			if tunTTL == "" {
+				vxlan.TTL = 1
+			} else {
+				vxlan.TTL, err = strconv.Atoi(tunTTL)
+				if err != nil {
+					return err
+				}
 			}
 
-			err := gretap.Add()
+			err := vxlan.Add()
 			if err != nil {
 				return err
 			}
-		} else if tunProtocol == "vxlan" {
+		} else if tunProtocol == "geneve" {
 			tunGroup := net.ParseIP(getConfig("group"))
 			tunInterface := getConfig("interface")
 
-			vxlan := &ip.Vxlan{
+			geneve := &ip.Geneve{
 				Link:  ip.Link{Name: tunName},
 				Local: tunLocal,
 			}
@@ -1744,7 +3551,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 					continue
 				}
 
-				vxlan.Remote = tunRemote
+				geneve.Remote = tunRemote
 			} else {
 				if tunGroup == nil {
 					tunGroup = net.IPv4(239, 0, 0, 1) // 239.0.0.1
@@ -1758,13 +3565,15 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 					}
 				}
 
-				vxlan.Group = tunGroup
-				vxlan.DevName = devName
+				geneve.Group = tunGroup
+				geneve.DevName = devName
 			}
 
 			tunPort := getConfig("port")
-			if tunPort != "" {
-				vxlan.DstPort, err = strconv.Atoi(tunPort)
+			if tunPort == "" {
+				geneve.DstPort = 6081
+			} else {
+				geneve.DstPort, err = strconv.Atoi(tunPort)
 				if err != nil {
 					return err
 				}
@@ -1772,25 +3581,29 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 			tunID := getConfig("id")
 			if tunID == "" {
-				vxlan.VxlanID = 1
+				geneve.VNI = 1
 			} else {
-				vxlan.VxlanID, err = strconv.Atoi(tunID)
+				geneve.VNI, err = strconv.Atoi(tunID)
 				if err != nil {
 					return err
 				}
+
+				if geneve.VNI < 0 || geneve.VNI > 1<<24-1 {
+					return fmt.Errorf("Invalid geneve ID %q (must be between 0 and %d)", tunID, 1<<24-1)
+				}
 			}
 
 			tunTTL := getConfig("ttl")
 			if tunTTL == "" {
-				vxlan.TTL = 1
+				geneve.TTL = 1
 			} else {
-				vxlan.TTL, err = strconv.Atoi(tunTTL)
+				geneve.TTL, err = strconv.Atoi(tunTTL)
 				if err != nil {
 					return err
 				}
 			}
 
-			err := vxlan.Add()
+			err := geneve.Add()
 			if err != nil {
 				return err
 			}
@@ -1827,12 +3640,6 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
-	// Kill any existing dnsmasq daemon for this network.
-	err = dnsmasq.Kill(n.name, false)
-	if err != nil {
-		return err
-	}
-
 	// Configure dnsmasq.
 	if n.UsesDNSMasq() {
 		// Setup the dnsmasq domain.
@@ -1884,61 +3691,96 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
-		// Create subprocess object dnsmasq.
-		dnsmasqLogPath := internalUtil.LogPath(fmt.Sprintf("dnsmasq.%s.log", n.name))
-		p, err := subprocess.NewProcess(command, dnsmasqCmd, "", dnsmasqLogPath)
-		if err != nil {
-			return fmt.Errorf("Failed to create subprocess: %s", err)
+		// If dnsmasq's argv hasn't changed since it was last launched, ask the running instance
+		// to reload its hosts/leases/config files in place rather than killing and respawning
+		// it. This preserves in-flight DHCP leases and the DNS cache across trivial changes such
+		// as a new static host entry or an edited "raw.dnsmasq", and only falls back to a full
+		// restart when something that actually changes the command line (e.g. a new
+		// "--listen-address" or "--dhcp-range") requires it.
+		argvPath := internalUtil.VarPath("networks", n.name, "dnsmasq.argv")
+		newArgv := strings.Join(dnsmasqCmd, "\x00")
+
+		reloaded := false
+		oldArgv, err := os.ReadFile(argvPath)
+		if err == nil && string(oldArgv) == newArgv {
+			err = dnsmasq.Reload(n.name)
+			reloaded = err == nil
 		}
 
-		// Apply AppArmor confinement.
-		if n.config["raw.dnsmasq"] == "" {
-			p.SetApparmor(apparmor.DnsmasqProfileName(n))
+		if !reloaded {
+			// Kill any existing dnsmasq daemon for this network before respawning it.
+			err = dnsmasq.Kill(n.name, false)
+			if err != nil {
+				return err
+			}
 
-			err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(n.state.DB.Cluster, n.project, warningtype.AppArmorDisabledDueToRawDnsmasq, dbCluster.TypeNetwork, int(n.id))
+			// Create subprocess object dnsmasq.
+			dnsmasqLogPath := internalUtil.LogPath(fmt.Sprintf("dnsmasq.%s.log", n.name))
+			p, err := subprocess.NewProcess(command, dnsmasqCmd, "", dnsmasqLogPath)
 			if err != nil {
-				n.logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+				return fmt.Errorf("Failed to create subprocess: %s", err)
 			}
-		} else {
-			n.logger.Warn("Skipping AppArmor for dnsmasq due to raw.dnsmasq being set", logger.Ctx{"name": n.name})
 
-			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				return tx.UpsertWarningLocalNode(ctx, n.project, dbCluster.TypeNetwork, int(n.id), warningtype.AppArmorDisabledDueToRawDnsmasq, "")
-			})
+			// Apply AppArmor confinement.
+			if n.config["raw.dnsmasq"] == "" {
+				p.SetApparmor(apparmor.DnsmasqProfileName(n))
+
+				err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(n.state.DB.Cluster, n.project, warningtype.AppArmorDisabledDueToRawDnsmasq, dbCluster.TypeNetwork, int(n.id))
+				if err != nil {
+					n.logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+				}
+			} else {
+				n.logger.Warn("Skipping AppArmor for dnsmasq due to raw.dnsmasq being set", logger.Ctx{"name": n.name})
+
+				err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+					return tx.UpsertWarningLocalNode(ctx, n.project, dbCluster.TypeNetwork, int(n.id), warningtype.AppArmorDisabledDueToRawDnsmasq, "")
+				})
+				if err != nil {
+					n.logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+				}
+			}
+
+			// Start dnsmasq.
+			err = p.Start(context.Background())
 			if err != nil {
-				n.logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+				return fmt.Errorf("Failed to run: %s %s: %w", command, strings.Join(dnsmasqCmd, " "), err)
 			}
-		}
 
-		// Start dnsmasq.
-		err = p.Start(context.Background())
-		if err != nil {
-			return fmt.Errorf("Failed to run: %s %s: %w", command, strings.Join(dnsmasqCmd, " "), err)
-		}
+			// Check dnsmasq started OK.
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Millisecond*time.Duration(500)))
+			_, err = p.Wait(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				stderr, _ := os.ReadFile(dnsmasqLogPath)
+				cancel()
 
-		// Check dnsmasq started OK.
-		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Millisecond*time.Duration(500)))
-		_, err = p.Wait(ctx)
-		if !errors.Is(err, context.DeadlineExceeded) {
-			stderr, _ := os.ReadFile(dnsmasqLogPath)
-			cancel()
+				return fmt.Errorf("The DNS and DHCP service exited prematurely: %w (%q)", err, strings.TrimSpace(string(stderr)))
+			}
 
-			return fmt.Errorf("The DNS and DHCP service exited prematurely: %w (%q)", err, strings.TrimSpace(string(stderr)))
-		}
+			cancel()
 
-		cancel()
+			err = p.Save(internalUtil.VarPath("networks", n.name, "dnsmasq.pid"))
+			if err != nil {
+				// Kill Process if started, but could not save the file.
+				err2 := p.Stop()
+				if err2 != nil {
+					return fmt.Errorf("Could not kill subprocess while handling saving error: %s: %s", err, err2)
+				}
 
-		err = p.Save(internalUtil.VarPath("networks", n.name, "dnsmasq.pid"))
-		if err != nil {
-			// Kill Process if started, but could not save the file.
-			err2 := p.Stop()
-			if err2 != nil {
-				return fmt.Errorf("Could not kill subprocess while handling saving error: %s: %s", err, err2)
+				return fmt.Errorf("Failed to save subprocess details: %s", err)
 			}
 
-			return fmt.Errorf("Failed to save subprocess details: %s", err)
+			err = os.WriteFile(argvPath, []byte(newArgv), 0o644)
+			if err != nil {
+				return fmt.Errorf("Failed to save dnsmasq argv: %w", err)
+			}
 		}
 	} else {
+		// Kill any existing dnsmasq daemon for this network.
+		err = dnsmasq.Kill(n.name, false)
+		if err != nil {
+			return err
+		}
+
 		// Clean up old dnsmasq config if exists and we are not starting dnsmasq.
 		leasesPath := internalUtil.VarPath("networks", n.name, "dnsmasq.leases")
 		if util.PathExists(leasesPath) {
@@ -1956,6 +3798,28 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				return fmt.Errorf("Failed to remove old dnsmasq pid file %q: %w", pidPath, err)
 			}
 		}
+
+		// Clean up old dnsmasq argv file.
+		argvPath := internalUtil.VarPath("networks", n.name, "dnsmasq.argv")
+		if util.PathExists(argvPath) {
+			err := os.Remove(argvPath)
+			if err != nil {
+				return fmt.Errorf("Failed to remove old dnsmasq argv file %q: %w", argvPath, err)
+			}
+		}
+	}
+
+	// Serve DHCP natively instead of via dnsmasq.
+	if n.config["bridge.dhcp.backend"] == "native" {
+		err = n.startNativeDHCP()
+		if err != nil {
+			return err
+		}
+	} else {
+		err = n.stopNativeDHCP()
+		if err != nil {
+			n.logger.Warn("Failed stopping native DHCP server", logger.Ctx{"err": err})
+		}
 	}
 
 	// Setup firewall.
@@ -2003,12 +3867,33 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Re-apply any "ports.publish" entries using the userland proxy fallback. DNAT mode entries
+	// are handled above as part of forwardSetupFirewall.
+	n.teardownPublishedPortProxies()
+
+	if n.config["ports.publish"] != "" && n.config["ports.publish.mode"] == "userland" {
+		publishedPorts, err := parsePublishedPorts(n.config["ports.publish"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing %q: %w", "ports.publish", err)
+		}
+
+		n.setupPublishedPortProxies(publishedPorts)
+	}
+
 	// Setup BGP.
 	err = n.bgpSetup(oldConfig)
 	if err != nil {
 		return err
 	}
 
+	// Setup egress policy routing.
+	n.teardownEgressRoutingPolicy()
+
+	err = n.setupEgressRoutingPolicy()
+	if err != nil {
+		return err
+	}
+
 	reverter.Success()
 
 	return nil
@@ -2022,6 +3907,25 @@ func (n *bridge) Stop() error {
 		return nil
 	}
 
+	// Stop any running DHCPv6-PD client for this network.
+	stopDHCPv6PDClient(n.name)
+
+	// Stop any running dynamic route BGP sessions for this network.
+	n.stopDynamicRouteClient(4)
+	n.stopDynamicRouteClient(6)
+
+	// Tear down egress policy routing.
+	n.teardownEgressRoutingPolicy()
+
+	// Stop any running userland "ports.publish" proxies for this network.
+	n.teardownPublishedPortProxies()
+
+	// Stop any running userland address forward proxies for this network.
+	n.teardownForwardProxies()
+
+	// Stop watching for host firewall service reloads/flushes.
+	n.teardownFirewallReloadWatcher()
+
 	// Clear BGP.
 	err := n.bgpClear(n.config)
 	if err != nil {
@@ -2045,8 +3949,7 @@ func (n *bridge) Stop() error {
 			return err
 		}
 	} else {
-		bridgeLink := &ip.Link{Name: n.name}
-		err := bridgeLink.Delete()
+		err := n.bridgeDriver().delete(n.name)
 		if err != nil {
 			return err
 		}
@@ -2077,6 +3980,12 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Stop the native DHCP server for this network, if running.
+	err = n.stopNativeDHCP()
+	if err != nil {
+		return err
+	}
+
 	// Unload apparmor profiles.
 	err = apparmor.NetworkUnload(n.state.OS, n)
 	if err != nil {
@@ -2341,19 +4250,37 @@ func (n *bridge) forwardConvertToFirewallForwards(listenAddress net.IP, defaultT
 	}
 
 	for _, portMap := range portMaps {
-		vips = append(vips, firewallDrivers.AddressForward{
-			ListenAddress: listenAddress,
-			Protocol:      portMap.protocol,
-			TargetAddress: portMap.target.address,
-			ListenPorts:   portMap.listenPorts,
-			TargetPorts:   portMap.target.ports,
-			SNAT:          portMap.snat,
-		})
+		// portMap.protocol may list more than one protocol (e.g. "tcp+udp"), so that a single
+		// forward port entry can bind the same listen/target ports on several protocols at once.
+		// Each one becomes its own firewallDrivers.AddressForward, since that's the granularity
+		// the firewall drivers operate at.
+		for _, protocol := range expandForwardProtocols(portMap.protocol) {
+			vips = append(vips, firewallDrivers.AddressForward{
+				ListenAddress: listenAddress,
+				Protocol:      protocol,
+				TargetAddress: portMap.target.address,
+				ListenPorts:   portMap.listenPorts,
+				TargetPorts:   portMap.target.ports,
+				SNAT:          portMap.snat,
+			})
+		}
 	}
 
 	return vips
 }
 
+// expandForwardProtocols splits a forward port entry's "proto" value into the individual
+// protocols it binds. Most entries name a single protocol ("tcp", "udp" or "sctp"), but
+// "tcp+udp" (and similar "+"-joined combinations) binds the same listen/target ports on each of
+// the named protocols.
+func expandForwardProtocols(proto string) []string {
+	if proto == "" {
+		return []string{""}
+	}
+
+	return strings.Split(proto, "+")
+}
+
 // bridgeProjectNetworks takes a map of all networks in all projects and returns a filtered map of bridge networks.
 func (n *bridge) bridgeProjectNetworks(projectNetworks map[string]map[int64]api.Network) map[string][]*api.Network {
 	bridgeProjectNetworks := make(map[string][]*api.Network)
@@ -2776,92 +4703,12 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return err
 	}
 
-	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
-	if n.config["bridge.driver"] != "openvswitch" {
-		brNetfilterEnabled := false
-		for _, ipVersion := range []uint{4, 6} {
-			if BridgeNetfilterEnabled(ipVersion) == nil {
-				brNetfilterEnabled = true
-				break
-			}
-		}
-
-		// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on each NIC's bridge
-		// port in case any of the forwards target the NIC and the instance attempts to connect to the
-		// forward's listener. Without hairpin mode on the target of the forward will not be able to
-		// connect to the listener.
-		if brNetfilterEnabled {
-			var listenAddresses map[int64]string
-
-			err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				networkID := n.ID()
-				dbRecords, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
-					NetworkID: &networkID,
-				})
-				if err != nil {
-					return err
-				}
-
-				listenAddresses = make(map[int64]string)
-				for _, dbRecord := range dbRecords {
-					if !dbRecord.NodeID.Valid || (dbRecord.NodeID.Int64 == tx.GetNodeID()) {
-						listenAddresses[dbRecord.ID] = dbRecord.ListenAddress
-					}
-				}
-
-				return err
-			})
-			if err != nil {
-				return fmt.Errorf("Failed loading network forwards: %w", err)
-			}
-
-			// If we are the first forward on this bridge, enable hairpin mode on active NIC ports.
-			if len(listenAddresses) <= 1 {
-				filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
-
-				err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-					return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
-						// Get the instance's effective network project name.
-						instNetworkProject := project.NetworkProjectFromRecord(&p)
-
-						if instNetworkProject != api.ProjectDefaultName {
-							return nil // Managed bridge networks can only exist in default project.
-						}
-
-						devices := db.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
-
-						// Iterate through each of the instance's devices, looking for bridged NICs
-						// that are linked to this network.
-						for devName, devConfig := range devices {
-							if devConfig["type"] != "nic" {
-								continue
-							}
-
-							// Check whether the NIC device references our network..
-							if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
-								continue
-							}
-
-							hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
-							if InterfaceExists(hostName) {
-								link := &ip.Link{Name: hostName}
-								err = link.BridgeLinkSetHairpin(true)
-								if err != nil {
-									return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
-								}
-
-								n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
-							}
-						}
-
-						return nil
-					}, filter)
-				})
-				if err != nil {
-					return err
-				}
-			}
-		}
+	// Check if hairpin/reflection handling needs to be enabled so that a forward's target can
+	// connect back to the forward's own listen address. How this is done is platform-specific
+	// (see refreshBridgeHairpin).
+	err = n.refreshBridgeHairpin()
+	if err != nil {
+		return err
 	}
 
 	// Refresh exported BGP prefixes on local member.
@@ -3153,6 +5000,7 @@ func (n *bridge) forwardSetupFirewall() error {
 	}
 
 	var fwForwards []firewallDrivers.AddressForward
+	var proxyForwards []firewallDrivers.AddressForward
 	ipVersions := make(map[uint]struct{})
 
 	for _, forward := range forwards {
@@ -3174,7 +5022,16 @@ func (n *bridge) forwardSetupFirewall() error {
 			return fmt.Errorf("Failed validating firewall address forward for listen address %q: %w", forward.ListenAddress, err)
 		}
 
-		fwForwards = append(fwForwards, n.forwardConvertToFirewallForwards(listenAddressNet.IP, net.ParseIP(forward.Config["target_address"]), portMaps)...)
+		converted := n.forwardConvertToFirewallForwards(listenAddressNet.IP, net.ParseIP(forward.Config["target_address"]), portMaps)
+
+		// Forwards opted into the userland proxy skip the firewall DNAT rule entirely; the
+		// proxy is started separately below, once NetworkApplyForwards has been called for the
+		// remaining forwards.
+		if n.forwardUsesUserlandProxy(forward) {
+			proxyForwards = append(proxyForwards, converted...)
+		} else {
+			fwForwards = append(fwForwards, converted...)
+		}
 	}
 
 	if len(forwards) > 0 {
@@ -3203,11 +5060,47 @@ func (n *bridge) forwardSetupFirewall() error {
 		}
 	}
 
-	err = n.state.Firewall.NetworkApplyForwards(n.name, fwForwards)
-	if err != nil {
-		return fmt.Errorf("Failed applying firewall address forwards: %w", err)
+	// Add any "ports.publish" entries using DNAT mode (the default) to the same firewall forward
+	// set. Entries using "userland" mode are handled separately by setupPublishedPortProxies.
+	if n.config["ports.publish"] != "" && n.config["ports.publish.mode"] != "userland" {
+		publishedPorts, err := parsePublishedPorts(n.config["ports.publish"])
+		if err != nil {
+			return fmt.Errorf("Failed parsing %q: %w", "ports.publish", err)
+		}
+
+		fwForwards = append(fwForwards, publishedPortsToFirewallForwards(publishedPorts)...)
+	}
+
+	// If an out-of-process network backend plugin is configured and reachable, it fully owns
+	// realizing the forward set; otherwise Incus programs it itself, either as OpenFlow rules
+	// (OVS bridges) or through the host's iptables/nftables firewall driver (native bridges).
+	if plugin := n.dialForwardBackendPlugin(); plugin != nil {
+		defer func() { _ = plugin.Close() }()
+
+		apiForwards := make([]api.NetworkForward, 0, len(forwards))
+		for _, forward := range forwards {
+			apiForwards = append(apiForwards, *forward)
+		}
+
+		err = plugin.Sync(apiForwards)
+		if err != nil {
+			return fmt.Errorf("Failed syncing address forwards to network backend %q: %w", n.config["network.backend"], err)
+		}
+	} else if n.config["bridge.driver"] == "openvswitch" {
+		err = n.ovsForwardSetupFirewall(fwForwards)
+		if err != nil {
+			return fmt.Errorf("Failed applying OVS address forwards: %w", err)
+		}
+	} else {
+		err = n.state.Firewall.NetworkApplyForwards(n.name, fwForwards)
+		if err != nil {
+			return fmt.Errorf("Failed applying firewall address forwards: %w", err)
+		}
 	}
 
+	// Start userland proxies for any forwards that opted out of the firewall DNAT rule.
+	n.setupForwardProxies(proxyForwards)
+
 	return nil
 }
 
@@ -3220,8 +5113,36 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 
 	// Get all static leases.
 	if clientType == request.ClientTypeNormal {
-		// If requested project matches network's project then include gateway and downstream uplink IPs.
-		if projectName == n.project {
+		// Resolve the effective project that projectName's requests against this network are
+		// scoped to (its own project if it has features.networks enabled, or its parent project
+		// otherwise), so that a default-project uplink network's gateway/uplink leases are still
+		// visible to projects that only ever see it through features.networks being disabled.
+		// Skip the lookup entirely for the common cases where it can't change the answer.
+		effectiveProject := n.project
+		if projectName != "" && projectName != n.project {
+			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+				if err != nil {
+					return err
+				}
+
+				apiProject, err := dbProject.ToAPI(ctx, tx.Tx())
+				if err != nil {
+					return err
+				}
+
+				effectiveProject = project.NetworkProjectFromRecord(apiProject)
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Include gateway and downstream uplink IPs whenever the requesting project's effective
+		// network project is this network's project, or for an all-projects listing.
+		if projectName == "" || effectiveProject == n.project {
 			// Add our own gateway IPs.
 			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
 				ip, _, _ := net.ParseCIDR(addr)
@@ -3326,6 +5247,29 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 	}
 
 	// Get dynamic leases.
+	if n.config["bridge.dhcp.backend"] == "native" {
+		nativeLeases, err := dhcpd.Leases(n.name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range nativeLeases {
+			leaseType := "dynamic"
+			if l.Static {
+				leaseType = "static"
+			}
+
+			leases = append(leases, api.NetworkLease{
+				Hostname: l.Hostname,
+				Address:  l.Address.String(),
+				Hwaddr:   l.Hwaddr.String(),
+				Type:     leaseType,
+			})
+		}
+
+		return leases, nil
+	}
+
 	leaseFile := internalUtil.VarPath("networks", n.name, "dnsmasq.leases")
 	if !util.PathExists(leaseFile) {
 		return leases, nil
@@ -3336,6 +5280,11 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 		return nil, err
 	}
 
+	// Lazily populated from the bridge's IPv6 neighbour cache the first time a DHCPv6 lease needs
+	// its MAC resolved, and reused below to synthesise leases for SLAAC-only clients that never
+	// show up in dnsmasq.leases at all.
+	var neighbourMACs map[string]net.HardwareAddr
+
 	for _, lease := range strings.Split(string(content), "\n") {
 		fields := strings.Fields(lease)
 		if len(fields) >= 5 {
@@ -3360,10 +5309,19 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 				continue
 			}
 
-			// DHCPv6 leases can't be tracked down to a MAC so clear the field.
-			// This means that instance project filtering will not work on IPv6 leases.
+			// dnsmasq's own lease records can't tie a DHCPv6 address back to a MAC, so fall back to
+			// resolving it through the bridge's IPv6 neighbour cache (populated by ND) before giving
+			// up on project filtering for this lease.
 			if strings.Contains(fields[2], ":") {
-				macStr = ""
+				if neighbourMACs == nil {
+					neighbourMACs, _ = GetNeighbourV6Hwaddr(n.name) // Best effort; nil map is fine below.
+				}
+
+				if hwAddr, ok := neighbourMACs[fields[2]]; ok {
+					macStr = hwAddr.String()
+				} else {
+					macStr = ""
+				}
 			}
 
 			// Skip leases that don't match any of the instance MACs from the project (only when we
@@ -3384,6 +5342,42 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 		}
 	}
 
+	// Synthesise dynamic leases for SLAAC-only clients: dnsmasq never writes a lease record for an
+	// address it didn't hand out itself, so a project's instance that only has a router-advertised
+	// address would otherwise never show up here at all.
+	if clientType == request.ClientTypeNormal && len(projectMacs) > 0 {
+		if neighbourMACs == nil {
+			neighbourMACs, _ = GetNeighbourV6Hwaddr(n.name)
+		}
+
+		for addr, hwAddr := range neighbourMACs {
+			macStr := hwAddr.String()
+			if !slices.Contains(projectMacs, macStr) {
+				continue
+			}
+
+			found := false
+			for _, entry := range leases {
+				if entry.Hwaddr == macStr && entry.Address == addr {
+					found = true
+					break
+				}
+			}
+
+			if found {
+				continue
+			}
+
+			leases = append(leases, api.NetworkLease{
+				Hostname: "",
+				Address:  addr,
+				Hwaddr:   macStr,
+				Type:     "dynamic",
+				Location: n.state.ServerName,
+			})
+		}
+	}
+
 	// Collect leases from other servers.
 	if clientType == request.ClientTypeNormal {
 		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
@@ -3416,6 +5410,13 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
+	// The native DHCP backend replaces dnsmasq entirely rather than running alongside it; it
+	// has no DNS service of its own, so choosing it trades away instance DNS records and
+	// mDNS-style name resolution for not needing dnsmasq installed at all.
+	if n.config["bridge.dhcp.backend"] == "native" {
+		return false
+	}
+
 	// Skip dnsmasq when no connectivity is configured.
 	if util.IsNoneOrEmpty(n.config["ipv4.address"]) && util.IsNoneOrEmpty(n.config["ipv6.address"]) {
 		return false
@@ -3457,6 +5458,7 @@ func (n *bridge) deleteChildren() error {
 	kinds := []string{
 		"vxlan",
 		"gretap",
+		"geneve",
 		"dummy",
 	}
 
@@ -0,0 +1,236 @@
+package network
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/dnsmasq/dhcpalloc"
+	"github.com/lxc/incus/v6/internal/server/network/dhcpd"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// startNativeDHCP (re)starts this network's native DHCPv4/DHCPv6 server per its current config,
+// replacing dnsmasq for networks configured with bridge.dhcp.backend=native. It is safe to call
+// repeatedly, e.g. from setup() on every config change, since dhcpd.Start always stops whatever
+// was previously running for this network first.
+func (n *bridge) startNativeDHCP() error {
+	cfg := dhcpd.Config{Interface: n.name}
+
+	if n.hasDHCPv4() && !util.IsNoneOrEmpty(n.config["ipv4.address"]) {
+		ipv4Cfg, err := n.nativeDHCPv4Config()
+		if err != nil {
+			return fmt.Errorf("Failed building native IPv4 DHCP config: %w", err)
+		}
+
+		cfg.IPv4 = ipv4Cfg
+	}
+
+	if n.hasDHCPv6() && util.IsTrue(n.config["ipv6.dhcp.stateful"]) && !util.IsNoneOrEmpty(n.config["ipv6.address"]) {
+		ipv6Cfg, err := n.nativeDHCPv6Config()
+		if err != nil {
+			return fmt.Errorf("Failed building native IPv6 DHCP config: %w", err)
+		}
+
+		cfg.IPv6 = ipv6Cfg
+	}
+
+	_, err := dhcpd.Start(n.name, cfg)
+	if err != nil {
+		return fmt.Errorf("Failed starting native DHCP server: %w", err)
+	}
+
+	return nil
+}
+
+// nativeDHCPv4Config translates this network's IPv4 config into a dhcpd.IPv4Config.
+func (n *bridge) nativeDHCPv4Config() (*dhcpd.IPv4Config, error) {
+	subnet := n.DHCPv4Subnet()
+
+	static, err := n.nativeDHCPStaticReservations(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := n.nativeDHCPRanges(subnet, n.config["ipv4.dhcp.ranges"])
+	if err != nil {
+		return nil, err
+	}
+
+	gateway := net.ParseIP(n.config["ipv4.dhcp.gateway"])
+	if gateway == nil && subnet != nil {
+		gateway = subnet.IP
+	}
+
+	var routes []dhcpd.Route
+
+	if n.config["ipv4.dhcp.routes"] != "" {
+		routes, err = parseNativeDHCPRoutes(n.config["ipv4.dhcp.routes"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var vendorInfo []byte
+
+	if n.config["ipv4.dhcp.vendor-info"] != "" {
+		vendorInfo, err = hex.DecodeString(n.config["ipv4.dhcp.vendor-info"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dhcpd.IPv4Config{
+		Subnet:       subnet,
+		Ranges:       ranges,
+		Static:       static,
+		Gateway:      gateway,
+		DNS:          nativeDHCPDNSServers(n.config["dns.nameservers"], false),
+		DomainSearch: util.SplitNTrimSpace(n.config["dns.search"], ",", -1, false),
+		Routes:       routes,
+		VendorInfo:   vendorInfo,
+	}, nil
+}
+
+// nativeDHCPv6Config translates this network's IPv6 config into a dhcpd.IPv6Config.
+func (n *bridge) nativeDHCPv6Config() (*dhcpd.IPv6Config, error) {
+	subnet := n.DHCPv6Subnet()
+
+	static, err := n.nativeDHCPStaticReservations(true)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := n.nativeDHCPRanges(subnet, n.config["ipv6.dhcp.ranges"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &dhcpd.IPv6Config{
+		Subnet: subnet,
+		Ranges: ranges,
+		Static: static,
+		DNS:    nativeDHCPDNSServers(n.config["dns.nameservers"], true),
+	}, nil
+}
+
+// nativeDHCPRanges parses a ipv4.dhcp.ranges/ipv6.dhcp.ranges config value into dhcpd.IPRanges,
+// falling back to the whole of subnet (minus its network/gateway address) when unset, the same
+// default the dnsmasq backend applies via dhcpalloc.GetIP.
+func (n *bridge) nativeDHCPRanges(subnet *net.IPNet, rangesConfig string) ([]dhcpd.IPRange, error) {
+	if rangesConfig == "" {
+		if subnet == nil {
+			return nil, nil
+		}
+
+		start := dhcpalloc.GetIP(subnet, 2)
+		end := dhcpalloc.GetIP(subnet, -2)
+
+		return []dhcpd.IPRange{{Start: start, End: end}}, nil
+	}
+
+	parsed, err := parseIPRanges(rangesConfig, subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]dhcpd.IPRange, 0, len(parsed))
+	for _, r := range parsed {
+		ranges = append(ranges, dhcpd.IPRange{Start: r.Start, End: r.End})
+	}
+
+	return ranges, nil
+}
+
+// nativeDHCPStaticReservations collects every MAC -> address reservation implied by instance NIC
+// devices attached to this network, across every project, mirroring what UpdateDNSMasqStatic does
+// for the dnsmasq backend's dhcp-hostsfile.
+func (n *bridge) nativeDHCPStaticReservations(isV6 bool) (map[string]net.IP, error) {
+	reservations := make(map[string]net.IP)
+
+	addrKey := "ipv4.address"
+	if isV6 {
+		addrKey = "ipv6.address"
+	}
+
+	err := UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		if nicConfig["hwaddr"] == "" {
+			nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		hwAddr, err := net.ParseMAC(nicConfig["hwaddr"])
+		if err != nil || hwAddr == nil {
+			return nil
+		}
+
+		addr := net.ParseIP(nicConfig[addrKey])
+		if addr == nil {
+			return nil
+		}
+
+		reservations[hwAddr.String()] = addr
+
+		return nil
+	}, dbCluster.InstanceFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// parseNativeDHCPRoutes parses an ipv4.dhcp.routes/ipv6.dhcp.routes value (a comma-separated list
+// of alternating subnet/gateway pairs, already validated by validate.IsDHCPRouteList) into
+// dhcpd.Routes.
+func parseNativeDHCPRoutes(value string) ([]dhcpd.Route, error) {
+	fields := util.SplitNTrimSpace(value, ",", -1, false)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("Invalid DHCP route list %q", value)
+	}
+
+	routes := make([]dhcpd.Route, 0, len(fields)/2)
+
+	for i := 0; i < len(fields); i += 2 {
+		_, dest, err := net.ParseCIDR(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid route destination %q: %w", fields[i], err)
+		}
+
+		gateway := net.ParseIP(fields[i+1])
+		if gateway == nil {
+			return nil, fmt.Errorf("Invalid route gateway %q", fields[i+1])
+		}
+
+		routes = append(routes, dhcpd.Route{Destination: dest, Gateway: gateway})
+	}
+
+	return routes, nil
+}
+
+// nativeDHCPDNSServers parses a dns.nameservers config value, returning only the addresses
+// matching the requested family.
+func nativeDHCPDNSServers(value string, isV6 bool) []net.IP {
+	var out []net.IP
+
+	for _, s := range util.SplitNTrimSpace(value, ",", -1, false) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			continue
+		}
+
+		if (ip.To4() != nil) == isV6 {
+			continue
+		}
+
+		out = append(out, ip)
+	}
+
+	return out
+}
+
+// stopNativeDHCP stops this network's native DHCP server, if one is running in this process.
+func (n *bridge) stopNativeDHCP() error {
+	return dhcpd.Stop(n.name)
+}
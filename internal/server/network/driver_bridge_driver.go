@@ -0,0 +1,39 @@
+package network
+
+import (
+	"net"
+
+	"github.com/lxc/incus/v6/internal/server/state"
+)
+
+// bridgeDriver abstracts the OS-specific primitives needed to create and manage a bridge
+// network's kernel interface, independently of the platform's bridging and NAT stack. This lets
+// the logic shared between platforms (config handling, forward conflict detection in
+// getExternalSubnetInUse, etc.) stay the same while "create"/"delete"/"addPort"/"removePort" and
+// hairpin handling are implemented per OS: natively via netlink on Linux, and via `ifconfig`
+// and `pf` on FreeBSD.
+type bridgeDriver interface {
+	// create brings the named bridge interface into existence with the given MTU and MAC
+	// address, or reconfigures it in place if it already exists.
+	create(name string, mtu uint32, hwaddr net.HardwareAddr) error
+
+	// delete removes the bridge interface.
+	delete(name string) error
+
+	// addPort attaches an existing interface to the bridge as a port.
+	addPort(s *state.State, bridgeName string, portName string) error
+
+	// removePort detaches a port interface from the bridge.
+	removePort(s *state.State, bridgeName string, portName string) error
+
+	// setHairpin enables or disables hairpin (reflection) handling for ref, so that a forward's
+	// target can connect back to the forward's own listen address. On Linux ref is a bridge
+	// port's interface name and hairpin is a per-port bridge attribute; on FreeBSD ref is a
+	// forward's listen address and hairpin is implemented as a pf reflection rule.
+	setHairpin(ref string, enabled bool) error
+}
+
+// newBridgeDriver returns the bridgeDriver implementation for the current platform.
+func newBridgeDriver() bridgeDriver {
+	return platformBridgeDriver()
+}
@@ -0,0 +1,83 @@
+//go:build freebsd
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/lxc/incus/v6/internal/server/state"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// platformBridgeDriver returns the FreeBSD bridgeDriver, which manages the interface via
+// `ifconfig bridgeN create`/`addm`/`deletem` and hairpin handling via a pf reflection rule.
+func platformBridgeDriver() bridgeDriver {
+	return freebsdBridgeDriver{}
+}
+
+// freebsdBridgeDriver is the FreeBSD bridgeDriver implementation, built on if_bridge(4) and pf(4).
+type freebsdBridgeDriver struct{}
+
+// create brings the bridge interface into existence (if needed) and applies its MTU and MAC.
+func (freebsdBridgeDriver) create(name string, mtu uint32, hwaddr net.HardwareAddr) error {
+	if !InterfaceExists(name) {
+		_, err := subprocess.RunCommand("ifconfig", name, "create", "name", name)
+		if err != nil {
+			return fmt.Errorf("Failed creating bridge interface %q: %w", name, err)
+		}
+	}
+
+	if hwaddr != nil {
+		_, err := subprocess.RunCommand("ifconfig", name, "link", hwaddr.String())
+		if err != nil {
+			return fmt.Errorf("Failed setting MAC address on bridge interface %q: %w", name, err)
+		}
+	}
+
+	_, err := subprocess.RunCommand("ifconfig", name, "mtu", strconv.FormatUint(uint64(mtu), 10))
+	if err != nil {
+		return fmt.Errorf("Failed setting MTU on bridge interface %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// delete destroys the bridge interface.
+func (freebsdBridgeDriver) delete(name string) error {
+	_, err := subprocess.RunCommand("ifconfig", name, "destroy")
+	if err != nil {
+		return fmt.Errorf("Failed destroying bridge interface %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// addPort attaches a member interface to the bridge.
+func (freebsdBridgeDriver) addPort(_ *state.State, bridgeName string, portName string) error {
+	_, err := subprocess.RunCommand("ifconfig", bridgeName, "addm", portName, "up")
+	if err != nil {
+		return fmt.Errorf("Failed adding %q to bridge %q: %w", portName, bridgeName, err)
+	}
+
+	return nil
+}
+
+// removePort detaches a member interface from the bridge.
+func (freebsdBridgeDriver) removePort(_ *state.State, bridgeName string, portName string) error {
+	_, err := subprocess.RunCommand("ifconfig", bridgeName, "deletem", portName)
+	if err != nil {
+		return fmt.Errorf("Failed removing %q from bridge %q: %w", portName, bridgeName, err)
+	}
+
+	return nil
+}
+
+// setHairpin enables or disables the pf reflection rule that lets a forward's target connect back
+// to the forward's own listen address (ref). Linux achieves this per bridge port; FreeBSD has no
+// equivalent if_bridge(4) flag, so it's done with a "reflect" rdr rule scoped to the listen
+// address instead, managed via pfAnchorSetReflect.
+func (freebsdBridgeDriver) setHairpin(ref string, enabled bool) error {
+	return pfAnchorSetReflect(ref, enabled)
+}
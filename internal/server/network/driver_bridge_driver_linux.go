@@ -0,0 +1,70 @@
+package network
+
+import (
+	"net"
+
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/internal/server/state"
+)
+
+// platformBridgeDriver returns the Linux bridgeDriver, which manages the interface via netlink
+// (through the "ip" package) and hairpin mode via the kernel's per-port bridge attribute.
+func platformBridgeDriver() bridgeDriver {
+	return linuxBridgeDriver{}
+}
+
+// linuxBridgeDriver is the Linux bridgeDriver implementation.
+type linuxBridgeDriver struct{}
+
+// create adds the bridge interface if it doesn't exist yet, or reconfigures its MTU/MAC in place.
+func (linuxBridgeDriver) create(name string, mtu uint32, hwaddr net.HardwareAddr) error {
+	br := &ip.Bridge{
+		Link: ip.Link{
+			Name: name,
+			MTU:  mtu,
+		},
+		Address: hwaddr,
+	}
+
+	if !InterfaceExists(name) {
+		return br.Add()
+	}
+
+	err := br.SetMTU(mtu)
+	if err != nil {
+		return err
+	}
+
+	if hwaddr != nil {
+		err = br.SetAddress(hwaddr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// delete removes the bridge interface.
+func (linuxBridgeDriver) delete(name string) error {
+	link := &ip.Link{Name: name}
+
+	return link.Delete()
+}
+
+// addPort attaches an existing interface to the bridge as a port.
+func (linuxBridgeDriver) addPort(s *state.State, bridgeName string, portName string) error {
+	return AttachInterface(s, bridgeName, portName)
+}
+
+// removePort detaches a port interface from the bridge.
+func (linuxBridgeDriver) removePort(s *state.State, bridgeName string, portName string) error {
+	return DetachInterface(s, bridgeName, portName)
+}
+
+// setHairpin enables or disables hairpin mode on a bridge port.
+func (linuxBridgeDriver) setHairpin(ref string, enabled bool) error {
+	link := &ip.Link{Name: ref}
+
+	return link.BridgeLinkSetHairpin(enabled)
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	apb "github.com/osrg/gobgp/v3/api"
+	gobgp "github.com/osrg/gobgp/v3/pkg/server"
+)
+
+// runDynamicRouteSession establishes a single BGP session to spec and streams path
+// announcements/withdrawals to onPath until either the peer session fails or ctx is cancelled.
+// It blocks for the lifetime of the session, so callers run it in its own goroutine.
+func runDynamicRouteSession(ctx context.Context, spec *dynamicRoutePeerSpec, onPath func(prefix *net.IPNet, withdrawn bool)) error {
+	s := gobgp.NewBgpServer()
+	go s.Serve()
+	defer s.StopBgp(ctx, &apb.StopBgpRequest{})
+
+	err := s.StartBgp(ctx, &apb.StartBgpRequest{
+		Global: &apb.Global{
+			Asn:        spec.asn,
+			RouterId:   spec.address,
+			ListenPort: -1, // Only connect out to the peer, don't listen for incoming sessions.
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed starting BGP instance for peer %q: %w", spec.address, err)
+	}
+
+	peer := &apb.Peer{
+		Conf: &apb.PeerConf{
+			NeighborAddress: spec.address,
+			PeerAsn:         spec.asn,
+		},
+		Timers: &apb.Timers{
+			Config: &apb.TimersConfig{
+				HoldTime: uint64(spec.holdTime.Seconds()),
+			},
+		},
+	}
+
+	if spec.password != "" {
+		peer.Conf.AuthPassword = spec.password
+	}
+
+	err = s.AddPeer(ctx, &apb.AddPeerRequest{Peer: peer})
+	if err != nil {
+		return fmt.Errorf("Failed adding BGP peer %q: %w", spec.address, err)
+	}
+
+	errCh := make(chan error, 1)
+
+	err = s.WatchEvent(ctx, &apb.WatchEventRequest{Table: &apb.WatchEventRequest_Table{Filters: []*apb.WatchEventRequest_Table_Filter{{Type: apb.WatchEventRequest_Table_Filter_BEST}}}}, func(r *apb.WatchEventResponse) {
+		table := r.GetTable()
+		if table == nil {
+			return
+		}
+
+		for _, path := range table.Paths {
+			prefix, err := pathPrefix(path)
+			if err != nil {
+				continue
+			}
+
+			onPath(prefix, path.IsWithdraw)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("Failed watching BGP peer %q: %w", spec.address, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// pathPrefix extracts the advertised/withdrawn IP prefix from a gobgp path update.
+func pathPrefix(path *apb.Path) (*net.IPNet, error) {
+	nlri := &apb.IPAddressPrefix{}
+
+	err := path.Nlri.UnmarshalTo(nlri)
+	if err != nil {
+		return nil, fmt.Errorf("Unsupported BGP NLRI: %w", err)
+	}
+
+	ip := net.ParseIP(nlri.Prefix)
+	if ip == nil {
+		return nil, fmt.Errorf("Invalid BGP prefix %q", nlri.Prefix)
+	}
+
+	bits := 32
+	if ip.To4() == nil {
+		bits = 128
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(nlri.PrefixLen), bits)}, nil
+}
@@ -0,0 +1,121 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// forwardBackendProtocolVersion is the JSON-RPC schema version advertised to, and required from,
+// an out-of-process ForwardBackend plugin, so that a plugin binary built against an incompatible
+// schema is rejected at handshake time rather than silently programming the wrong dataplane.
+const forwardBackendProtocolVersion = 1
+
+// forwardBackendSocketDir is where out-of-process ForwardBackend plugins register themselves, one
+// Unix socket per backend name.
+const forwardBackendSocketDir = "/var/lib/incus/network-backends"
+
+// ForwardBackend lets an external dataplane implementation own how a managed bridge network's
+// address forwards and hairpin/reflection handling are realized (e.g. a netavark- or OVN-style
+// plugin), while Incus remains the source of truth for the api.NetworkForward records themselves
+// and for getExternalSubnetInUse's conflict checking, both of which only ever consult the
+// database and so need no backend involvement at all.
+type ForwardBackend interface {
+	// Sync reconciles the backend's dataplane state with the full, current set of forwards
+	// defined for the network, rather than being driven by incremental create/update/delete
+	// events, so that a plugin can diff against what it last programmed and also recover cleanly
+	// from having missed a notification (e.g. across an incusd restart).
+	Sync(forwards []api.NetworkForward) error
+
+	// InstanceAttach is called once an instance NIC using this network has come up, identified
+	// by its device name and host-side interface name, so a plugin managing its own dataplane
+	// (rather than Incus's native bridge) can wire the host-side veth in.
+	InstanceAttach(devName string, hostName string) error
+
+	// InstanceDetach is the converse of InstanceAttach, called as the NIC is being removed.
+	InstanceDetach(devName string, hostName string) error
+
+	// Close releases any resources (e.g. an open socket connection) held by the backend.
+	Close() error
+}
+
+// forwardBackend returns the ForwardBackend to use for this network: the out-of-process plugin
+// named by "network.backend", if set and reachable, or the in-tree implementation built on top of
+// today's firewallDrivers/OVS forward handling otherwise. A configured but unreachable plugin
+// falls back to the in-tree implementation rather than failing the whole sync, since losing an
+// operator-supplied dataplane shouldn't also take down Incus's own bookkeeping.
+func (n *bridge) forwardBackend() ForwardBackend {
+	if plugin := n.dialForwardBackendPlugin(); plugin != nil {
+		return plugin
+	}
+
+	return &builtinForwardBackend{n: n}
+}
+
+// dialForwardBackendPlugin connects to the out-of-process plugin named by "network.backend", if
+// that key is set, returning nil (and logging a warning) if it's unset or unreachable.
+func (n *bridge) dialForwardBackendPlugin() ForwardBackend {
+	name := n.config["network.backend"]
+	if name == "" {
+		return nil
+	}
+
+	backend, err := dialForwardBackendPlugin(name)
+	if err != nil {
+		n.logger.Warn("Network backend plugin unreachable, falling back to built-in handling", logger.Ctx{"backend": name, "err": err})
+
+		return nil
+	}
+
+	return backend
+}
+
+// builtinForwardBackend is the reference ForwardBackend implementation, wrapping the existing
+// firewallDrivers (native Linux bridges) and OpenFlow (OVS bridges) forward handling.
+type builtinForwardBackend struct {
+	n *bridge
+}
+
+// Sync applies forwards via the network's existing firewall/OpenFlow handling.
+func (b *builtinForwardBackend) Sync(forwards []api.NetworkForward) error {
+	return b.n.forwardSetupFirewall()
+}
+
+// InstanceAttach is a no-op for the built-in backend: the instance NIC device code already
+// attaches the host-side veth to the native or OVS bridge directly.
+func (b *builtinForwardBackend) InstanceAttach(devName string, hostName string) error {
+	return nil
+}
+
+// InstanceDetach is a no-op for the built-in backend, for the same reason as InstanceAttach.
+func (b *builtinForwardBackend) InstanceDetach(devName string, hostName string) error {
+	return nil
+}
+
+// Close is a no-op for the built-in backend: it holds no external resources.
+func (b *builtinForwardBackend) Close() error {
+	return nil
+}
+
+// forwardBackendRequest is a single JSON-RPC-style request sent to an out-of-process
+// ForwardBackend plugin over its Unix socket, one JSON object per line.
+type forwardBackendRequest struct {
+	Version  int                  `json:"version"`
+	Method   string               `json:"method"`
+	Forwards []api.NetworkForward `json:"forwards,omitempty"`
+	DevName  string               `json:"dev_name,omitempty"`
+	HostName string               `json:"host_name,omitempty"`
+}
+
+// forwardBackendResponse is a plugin's reply to a forwardBackendRequest.
+type forwardBackendResponse struct {
+	Version int    `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+// forwardBackendSocketPath returns the Unix socket path a ForwardBackend plugin named name is
+// expected to listen on.
+func forwardBackendSocketPath(name string) string {
+	return fmt.Sprintf("%s/%s.sock", forwardBackendSocketDir, name)
+}
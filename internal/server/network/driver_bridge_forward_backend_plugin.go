@@ -0,0 +1,114 @@
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// forwardBackendPlugin is a ForwardBackend backed by a single persistent connection to an
+// out-of-process plugin's Unix socket (see forwardBackendSocketPath), speaking one
+// forwardBackendRequest/forwardBackendResponse pair per line. Requests are serialized with a mutex
+// since a plugin socket only ever has one incusd connection at a time and there is no need for the
+// complexity of matching concurrent requests to responses.
+type forwardBackendPlugin struct {
+	name string
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+// dialForwardBackendPlugin connects to the plugin registered under name and performs the version
+// handshake, returning an error if the socket doesn't exist, isn't listening, or advertises an
+// incompatible protocol version.
+func dialForwardBackendPlugin(name string) (*forwardBackendPlugin, error) {
+	conn, err := net.Dial("unix", forwardBackendSocketPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to network backend plugin %q: %w", name, err)
+	}
+
+	plugin := &forwardBackendPlugin{name: name, conn: conn}
+
+	resp, err := plugin.call(forwardBackendRequest{Version: forwardBackendProtocolVersion, Method: "Hello"})
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("Failed handshake with network backend plugin %q: %w", name, err)
+	}
+
+	if resp.Version != forwardBackendProtocolVersion {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("Network backend plugin %q speaks protocol version %d, expected %d", name, resp.Version, forwardBackendProtocolVersion)
+	}
+
+	return plugin, nil
+}
+
+// call sends req to the plugin and decodes its response, returning an error if the plugin itself
+// reported one.
+func (p *forwardBackendPlugin) call(req forwardBackendRequest) (forwardBackendResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req.Version = forwardBackendProtocolVersion
+
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return forwardBackendResponse{}, err
+	}
+
+	encoded = append(encoded, '\n')
+
+	_, err = p.conn.Write(encoded)
+	if err != nil {
+		return forwardBackendResponse{}, fmt.Errorf("Failed writing to network backend plugin %q: %w", p.name, err)
+	}
+
+	line, err := bufio.NewReader(p.conn).ReadBytes('\n')
+	if err != nil {
+		return forwardBackendResponse{}, fmt.Errorf("Failed reading from network backend plugin %q: %w", p.name, err)
+	}
+
+	var resp forwardBackendResponse
+
+	err = json.Unmarshal(line, &resp)
+	if err != nil {
+		return forwardBackendResponse{}, fmt.Errorf("Failed decoding response from network backend plugin %q: %w", p.name, err)
+	}
+
+	if resp.Error != "" {
+		return forwardBackendResponse{}, fmt.Errorf("Network backend plugin %q: %s", p.name, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// Sync sends the full set of forwards to the plugin for reconciliation.
+func (p *forwardBackendPlugin) Sync(forwards []api.NetworkForward) error {
+	_, err := p.call(forwardBackendRequest{Method: "Sync", Forwards: forwards})
+
+	return err
+}
+
+// InstanceAttach notifies the plugin that an instance NIC has come up.
+func (p *forwardBackendPlugin) InstanceAttach(devName string, hostName string) error {
+	_, err := p.call(forwardBackendRequest{Method: "InstanceAttach", DevName: devName, HostName: hostName})
+
+	return err
+}
+
+// InstanceDetach notifies the plugin that an instance NIC is being removed.
+func (p *forwardBackendPlugin) InstanceDetach(devName string, hostName string) error {
+	_, err := p.call(forwardBackendRequest{Method: "InstanceDetach", DevName: devName, HostName: hostName})
+
+	return err
+}
+
+// Close ends the connection to the plugin.
+func (p *forwardBackendPlugin) Close() error {
+	return p.conn.Close()
+}
@@ -0,0 +1,50 @@
+//go:build freebsd
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+)
+
+// refreshBridgeHairpin makes sure every listen address of this bridge's forwards has a pf
+// reflection rule, so that a forward's target can connect back to the forward's own listen
+// address. if_bridge(4) has no per-port hairpin attribute equivalent to Linux's, so this is done
+// with one "reflect" rdr rule per listen address instead of per NIC bridge port.
+func (n *bridge) refreshBridgeHairpin() error {
+	var listenAddresses map[int64]string
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		listenAddresses = make(map[int64]string)
+		for _, dbRecord := range dbRecords {
+			if !dbRecord.NodeID.Valid || (dbRecord.NodeID.Int64 == tx.GetNodeID()) {
+				listenAddresses[dbRecord.ID] = dbRecord.ListenAddress
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network forwards: %w", err)
+	}
+
+	for _, listenAddress := range listenAddresses {
+		err = pfAnchorSetReflect(listenAddress, true)
+		if err != nil {
+			return fmt.Errorf("Failed enabling pf reflection for listen address %q: %w", listenAddress, err)
+		}
+	}
+
+	return nil
+}
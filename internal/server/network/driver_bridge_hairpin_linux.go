@@ -0,0 +1,109 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lxc/incus/v6/internal/server/db"
+	dbCluster "github.com/lxc/incus/v6/internal/server/db/cluster"
+	"github.com/lxc/incus/v6/internal/server/ip"
+	"github.com/lxc/incus/v6/internal/server/project"
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// refreshBridgeHairpin checks if hairpin mode needs to be enabled on active NIC bridge ports.
+func (n *bridge) refreshBridgeHairpin() error {
+	if n.config["bridge.driver"] == "openvswitch" {
+		// OVS bridges get their own per-forward reflection flow installed alongside the rest of
+		// their OpenFlow forward rules (see ovsForwardInstallReflect), so there's no separate
+		// per-port hairpin step to run here.
+		return nil
+	}
+
+	brNetfilterEnabled := false
+	for _, ipVersion := range []uint{4, 6} {
+		if BridgeNetfilterEnabled(ipVersion) == nil {
+			brNetfilterEnabled = true
+			break
+		}
+	}
+
+	// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on each NIC's bridge
+	// port in case any of the forwards target the NIC and the instance attempts to connect to the
+	// forward's listener. Without hairpin mode on the target of the forward will not be able to
+	// connect to the listener.
+	if !brNetfilterEnabled {
+		return nil
+	}
+
+	var listenAddresses map[int64]string
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID := n.ID()
+		dbRecords, err := dbCluster.GetNetworkForwards(ctx, tx.Tx(), dbCluster.NetworkForwardFilter{
+			NetworkID: &networkID,
+		})
+		if err != nil {
+			return err
+		}
+
+		listenAddresses = make(map[int64]string)
+		for _, dbRecord := range dbRecords {
+			if !dbRecord.NodeID.Valid || (dbRecord.NodeID.Int64 == tx.GetNodeID()) {
+				listenAddresses[dbRecord.ID] = dbRecord.ListenAddress
+			}
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network forwards: %w", err)
+	}
+
+	// If we are not the first forward on this bridge, hairpin mode is already enabled.
+	if len(listenAddresses) > 1 {
+		return nil
+	}
+
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			// Get the instance's effective network project name.
+			instNetworkProject := project.NetworkProjectFromRecord(&p)
+
+			if instNetworkProject != api.ProjectDefaultName {
+				return nil // Managed bridge networks can only exist in default project.
+			}
+
+			devices := db.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+
+			// Iterate through each of the instance's devices, looking for bridged NICs
+			// that are linked to this network.
+			for devName, devConfig := range devices {
+				if devConfig["type"] != "nic" {
+					continue
+				}
+
+				// Check whether the NIC device references our network..
+				if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
+					continue
+				}
+
+				hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
+				if InterfaceExists(hostName) {
+					link := &ip.Link{Name: hostName}
+					err := link.BridgeLinkSetHairpin(true)
+					if err != nil {
+						return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
+					}
+
+					n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/logger"
+)
+
+// LeaseEventType identifies what changed about a lease in a LeaseEvent.
+type LeaseEventType string
+
+const (
+	// LeaseEventAdd indicates a lease that wasn't previously known appeared.
+	LeaseEventAdd LeaseEventType = "add"
+
+	// LeaseEventUpdate indicates a previously known lease's record changed.
+	LeaseEventUpdate LeaseEventType = "update"
+
+	// LeaseEventRemove indicates a previously known lease expired or was released.
+	LeaseEventRemove LeaseEventType = "remove"
+)
+
+// LeaseEvent describes a single add/update/remove change to one of a bridge network's leases. This
+// is the in-tree equivalent of the api.NetworkLeaseEvent type a REST events endpoint would
+// serialize, which isn't implemented here since this tree has no REST/websocket layer to hang it
+// off of.
+type LeaseEvent struct {
+	Type  LeaseEventType
+	Lease api.NetworkLease
+}
+
+// leaseSubscribePollInterval is how often Subscribe diffs the lease store for changes. Neither
+// LeaseStore implementation pushes change notifications of its own yet, so this stays poll-based
+// even now that the native backend exists; a future improvement could have dhcpd push events
+// directly as it hands out leases instead.
+const leaseSubscribePollInterval = 2 * time.Second
+
+// Subscribe streams add/update/remove events for this (local node's) leases by periodically
+// diffing newLeaseStore(n.name, ...).List() snapshots, since neither LeaseStore implementation has
+// change notification of its own. The returned channel is closed when ctx is cancelled. Unlike
+// Leases, this does not fan events out across the cluster; a caller wanting cluster-wide events
+// needs to subscribe on every member.
+func (n *bridge) Subscribe(ctx context.Context) (<-chan LeaseEvent, error) {
+	store := newLeaseStore(n.name, n.config["bridge.dhcp.backend"])
+
+	previous, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading initial lease state: %w", err)
+	}
+
+	events := make(chan LeaseEvent)
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(leaseSubscribePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current, err := store.List()
+				if err != nil {
+					n.logger.Warn("Failed polling lease state", logger.Ctx{"err": err})
+
+					continue
+				}
+
+				diffLeases(previous, current, events, ctx)
+
+				previous = current
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// diffLeases compares two lease snapshots keyed by (hwaddr, address) and sends the resulting
+// add/update/remove events, returning early if ctx is cancelled mid-send.
+func diffLeases(previous []LeaseRecord, current []LeaseRecord, events chan<- LeaseEvent, ctx context.Context) {
+	key := func(r LeaseRecord) string {
+		return r.Hwaddr.String() + "|" + r.Address
+	}
+
+	previousByKey := make(map[string]LeaseRecord, len(previous))
+	for _, r := range previous {
+		previousByKey[key(r)] = r
+	}
+
+	currentByKey := make(map[string]LeaseRecord, len(current))
+	for _, r := range current {
+		currentByKey[key(r)] = r
+	}
+
+	send := func(evt LeaseEvent) bool {
+		select {
+		case events <- evt:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for k, r := range currentByKey {
+		old, ok := previousByKey[k]
+		lease := api.NetworkLease{Hostname: r.Hostname, Address: r.Address, Hwaddr: r.Hwaddr.String(), Type: "dynamic"}
+
+		if !ok {
+			if !send(LeaseEvent{Type: LeaseEventAdd, Lease: lease}) {
+				return
+			}
+		} else if old.Hostname != r.Hostname {
+			if !send(LeaseEvent{Type: LeaseEventUpdate, Lease: lease}) {
+				return
+			}
+		}
+	}
+
+	for k, r := range previousByKey {
+		if _, ok := currentByKey[k]; !ok {
+			lease := api.NetworkLease{Hostname: r.Hostname, Address: r.Address, Hwaddr: r.Hwaddr.String(), Type: "dynamic"}
+
+			if !send(LeaseEvent{Type: LeaseEventRemove, Lease: lease}) {
+				return
+			}
+		}
+	}
+}
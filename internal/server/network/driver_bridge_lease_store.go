@@ -0,0 +1,110 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/lxc/incus/v6/internal/server/network/dhcpd"
+	internalUtil "github.com/lxc/incus/v6/internal/util"
+	"github.com/lxc/incus/v6/shared/util"
+)
+
+// LeaseRecord is a single DHCP/SLAAC lease, independent of which backend produced it.
+type LeaseRecord struct {
+	Hostname string
+	Hwaddr   net.HardwareAddr
+	Address  string
+	Static   bool
+}
+
+// LeaseStore abstracts over where a bridge network's leases come from, so that Leases() doesn't
+// need to know whether they were parsed from a dnsmasq.leases file or tracked natively by the
+// in-process dhcpd server. See dnsmasqLeaseStore and nativeLeaseStore.
+type LeaseStore interface {
+	// List returns every lease currently known to the backend.
+	List() ([]LeaseRecord, error)
+}
+
+// newLeaseStore returns the LeaseStore for network name according to its bridge.dhcp.backend
+// setting.
+func newLeaseStore(name string, backend string) LeaseStore {
+	if backend == "native" {
+		return &nativeLeaseStore{name: name}
+	}
+
+	return &dnsmasqLeaseStore{name: name}
+}
+
+// nativeLeaseStore reads leases from the native dhcpd backend's persisted leases.json.
+type nativeLeaseStore struct {
+	name string
+}
+
+// List returns dhcpd's current lease snapshot for this network, translated to LeaseRecord.
+func (s *nativeLeaseStore) List() ([]LeaseRecord, error) {
+	leases, err := dhcpd.Leases(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LeaseRecord, 0, len(leases))
+
+	for _, l := range leases {
+		records = append(records, LeaseRecord{
+			Hostname: l.Hostname,
+			Hwaddr:   l.Hwaddr,
+			Address:  l.Address.String(),
+			Static:   l.Static,
+		})
+	}
+
+	return records, nil
+}
+
+// dnsmasqLeaseStore reads leases from dnsmasq's own `--dhcp-leasefile`, the format Leases()
+// already parses inline today.
+type dnsmasqLeaseStore struct {
+	name string
+}
+
+// List parses the network's dnsmasq.leases file, returning an empty list if dnsmasq hasn't
+// written one yet (e.g. before the first client request).
+func (s *dnsmasqLeaseStore) List() ([]LeaseRecord, error) {
+	leaseFile := internalUtil.VarPath("networks", s.name, "dnsmasq.leases")
+	if !util.PathExists(leaseFile) {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(leaseFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading %q: %w", leaseFile, err)
+	}
+
+	records := make([]LeaseRecord, 0)
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		mac := GetMACSlice(fields[1])
+		macStr := strings.Join(mac, ":")
+
+		if len(macStr) < 17 && fields[4] != "" {
+			macStr = fields[4][len(fields[4])-17:]
+		}
+
+		hwAddr, _ := net.ParseMAC(macStr)
+
+		records = append(records, LeaseRecord{
+			Hostname: fields[3],
+			Hwaddr:   hwAddr,
+			Address:  fields[2],
+		})
+	}
+
+	return records, nil
+}
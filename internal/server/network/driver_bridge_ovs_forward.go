@@ -0,0 +1,151 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
+	"github.com/lxc/incus/v6/shared/logger"
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// ovsForwardCookieBase namespaces the OpenFlow cookies incusd installs for network address
+// forwards on an "openvswitch" bridge, so that `ovs-ofctl`'s own flows (and any flows installed
+// by other integrations sharing the bridge) are never touched by a re-sync. The low 32 bits carry
+// the forward's database ID.
+const ovsForwardCookieBase = uint64(0x696e637500000000)
+
+// ovsForwardCookieMask matches any cookie in the incus forward namespace, regardless of which
+// forward ID the low bits encode.
+const ovsForwardCookieMask = uint64(0xffffffff00000000)
+
+// ovsForwardReflectCookie is the (single, fixed) cookie used for the reflection flow that lets a
+// forward's target connect back to the forward's own listen address from another port on the
+// same bridge, replacing the br_netfilter-based hairpin mode used on native Linux bridges.
+const ovsForwardReflectCookieBase = uint64(0x696e63757a000000)
+
+// ovsForwardSetupFirewall (re)installs the OpenFlow rules implementing this OVS bridge's network
+// address forwards, and is the "openvswitch" counterpart to forwardSetupFirewall's iptables/
+// nftables path via n.state.Firewall. It is idempotent: on every call it first clears out any
+// previously installed incus forward flows (identified by ovsForwardCookieMask) and then installs
+// one fresh flow set per current forward, so ForwardCreate/Update/Delete and a cold start (via
+// forwardSetupFirewall) all converge on the same state.
+func (n *bridge) ovsForwardSetupFirewall(forwards []firewallDrivers.AddressForward) error {
+	err := ovsOfctl(n.name, "--strict", "del-flows", n.name, ovsForwardCookieMatch(ovsForwardCookieBase, ovsForwardCookieMask))
+	if err != nil {
+		return fmt.Errorf("Failed clearing existing OVS forward flows: %w", err)
+	}
+
+	err = ovsOfctl(n.name, "--strict", "del-flows", n.name, ovsForwardCookieMatch(ovsForwardReflectCookieBase, ovsForwardCookieMask))
+	if err != nil {
+		return fmt.Errorf("Failed clearing existing OVS reflection flows: %w", err)
+	}
+
+	for i, fwd := range forwards {
+		err = n.ovsForwardInstall(uint32(i), fwd)
+		if err != nil {
+			return fmt.Errorf("Failed installing OVS forward flow for %q: %w", fwd.ListenAddress.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// ovsForwardInstall installs the OpenFlow rules for a single converted forward: a DNAT-equivalent
+// flow rewriting the destination address/port and outputting to the target's bridge port, a
+// symmetric conntrack-based SNAT-back flow when the forward requests it, and a same-bridge
+// reflection flow so a client on this bridge can reach the forward's listen address too.
+func (n *bridge) ovsForwardInstall(idx uint32, fwd firewallDrivers.AddressForward) error {
+	cookie := ovsForwardCookieBase | uint64(idx)
+
+	matches := []string{fmt.Sprintf("nw_dst=%s", fwd.ListenAddress.String())}
+	actions := []string{fmt.Sprintf("mod_nw_dst:%s", fwd.TargetAddress.String())}
+
+	if fwd.Protocol != "" {
+		for i, listenPort := range fwd.ListenPorts {
+			targetPort := fwd.TargetPorts[i]
+
+			portMatches := append(append([]string{}, matches...), fmt.Sprintf("%s,tp_dst=%d", fwd.Protocol, listenPort))
+			portActions := append(append([]string{}, actions...), fmt.Sprintf("mod_tp_dst:%d", targetPort), "NORMAL")
+
+			if fwd.SNAT {
+				portActions = append([]string{"ct(commit,nat(dst=" + fwd.TargetAddress.String() + "))"}, portActions...)
+			}
+
+			err := ovsAddFlow(n.name, cookie, portMatches, portActions)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		// Address-only forward: translate every packet to the target address regardless of port.
+		if fwd.SNAT {
+			actions = append([]string{"ct(commit,nat(dst=" + fwd.TargetAddress.String() + "))"}, actions...)
+		}
+
+		actions = append(actions, "NORMAL")
+
+		err := ovsAddFlow(n.name, cookie, matches, actions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.ovsForwardInstallReflect(fwd)
+}
+
+// ovsForwardInstallReflect installs the reflection flow letting another port on the same OVS
+// bridge reach fwd.ListenAddress, translating traffic the same way ovsForwardInstall does for
+// traffic arriving from outside the bridge. This is the OVS/OpenFlow equivalent of enabling
+// hairpin mode on a Linux native bridge's NIC ports.
+func (n *bridge) ovsForwardInstallReflect(fwd firewallDrivers.AddressForward) error {
+	cookie := ovsForwardReflectCookieBase | uint64(hashListenAddress(fwd.ListenAddress))
+
+	matches := []string{fmt.Sprintf("nw_dst=%s", fwd.ListenAddress.String()), "in_port=LOCAL"}
+	actions := []string{fmt.Sprintf("mod_nw_dst:%s", fwd.TargetAddress.String()), "mod_nw_src:" + fwd.ListenAddress.String(), "NORMAL"}
+
+	return ovsAddFlow(n.name, cookie, matches, actions)
+}
+
+// hashListenAddress derives a small, stable value from a listen address for use in a flow cookie,
+// so that repeated installs of the same reflection flow replace rather than duplicate it.
+func hashListenAddress(addr net.IP) uint32 {
+	b := addr.To4()
+	if b == nil {
+		b = addr.To16()
+	}
+
+	var h uint32
+	for _, c := range b {
+		h = h*31 + uint32(c)
+	}
+
+	return h
+}
+
+// ovsForwardCookieMatch renders an `ovs-ofctl` cookie=value/mask match expression.
+func ovsForwardCookieMatch(cookie uint64, mask uint64) string {
+	return fmt.Sprintf("cookie=0x%x/0x%x", cookie, mask)
+}
+
+// ovsAddFlow installs a single OpenFlow rule tagged with cookie, matching match and running
+// actions, via `ovs-ofctl add-flow`.
+func ovsAddFlow(bridgeName string, cookie uint64, match []string, actions []string) error {
+	flow := fmt.Sprintf("cookie=0x%x,priority=32768,%s,actions=%s", cookie, strings.Join(match, ","), strings.Join(actions, ","))
+
+	return ovsOfctl(bridgeName, "add-flow", bridgeName, flow)
+}
+
+// ovsOfctl runs `ovs-ofctl` against the given bridge, logging the full command on failure to aid
+// debugging flow syntax issues.
+func ovsOfctl(bridgeName string, args ...string) error {
+	_, err := subprocess.RunCommand("ovs-ofctl", args...)
+	if err != nil {
+		logger.Warn("ovs-ofctl command failed", logger.Ctx{"bridge": bridgeName, "args": args, "err": err})
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+//go:build freebsd
+
+package network
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	firewallDrivers "github.com/lxc/incus/v6/internal/server/firewall/drivers"
+)
+
+// pfAnchorName returns the pf anchor used for a given bridge network's address forwards. Incus
+// manages its own rdr-anchor/nat-anchor rather than editing pf.conf directly, the same way it
+// manages its own iptables/nftables chains on Linux.
+func pfAnchorName(bridgeName string) string {
+	return fmt.Sprintf("incus/%s", bridgeName)
+}
+
+// pfApplyForwards (re)loads the rdr-anchor and nat-anchor rules for a bridge network's address
+// forwards (see forwardConvertToFirewallForwards for how ports.publish/network forwards are
+// turned into the shared firewallDrivers.AddressForward format this consumes). This is the pf
+// counterpart of the iptables/nftables NetworkApplyForwards implementation.
+func pfApplyForwards(bridgeName string, forwards []firewallDrivers.AddressForward) error {
+	anchor := pfAnchorName(bridgeName)
+
+	var rdr strings.Builder
+	var nat strings.Builder
+
+	for _, fwd := range forwards {
+		proto := fwd.Protocol
+		if proto == "" {
+			// Address-only forwards (no port map) apply to both TCP and UDP.
+			for _, p := range []string{"tcp", "udp"} {
+				fmt.Fprintf(&rdr, "rdr pass proto %s from any to %s -> %s\n", p, fwd.ListenAddress, fwd.TargetAddress)
+			}
+		} else {
+			listenPorts := pfPortList(fwd.ListenPorts)
+			targetPorts := pfPortList(fwd.TargetPorts)
+
+			fmt.Fprintf(&rdr, "rdr pass proto %s from any to %s port %s -> %s port %s\n", proto, fwd.ListenAddress, listenPorts, fwd.TargetAddress, targetPorts)
+		}
+
+		if fwd.SNAT {
+			fmt.Fprintf(&nat, "nat pass from %s to any -> (%s)\n", fwd.TargetAddress, fwd.ListenAddress)
+		}
+	}
+
+	err := pfLoadAnchor(fmt.Sprintf("%s/rdr", anchor), rdr.String())
+	if err != nil {
+		return fmt.Errorf("Failed loading pf rdr-anchor rules for %q: %w", bridgeName, err)
+	}
+
+	err = pfLoadAnchor(fmt.Sprintf("%s/nat", anchor), nat.String())
+	if err != nil {
+		return fmt.Errorf("Failed loading pf nat-anchor rules for %q: %w", bridgeName, err)
+	}
+
+	return nil
+}
+
+// pfClearForwards removes all rdr-anchor/nat-anchor rules for a bridge network.
+func pfClearForwards(bridgeName string) error {
+	anchor := pfAnchorName(bridgeName)
+
+	err := pfLoadAnchor(fmt.Sprintf("%s/rdr", anchor), "")
+	if err != nil {
+		return err
+	}
+
+	return pfLoadAnchor(fmt.Sprintf("%s/nat", anchor), "")
+}
+
+// pfAnchorSetReflect enables or disables the reflection rule that allows a forward's target to
+// connect back to listenAddress via the bridge, the FreeBSD equivalent of Linux's per-port
+// hairpin mode.
+func pfAnchorSetReflect(listenAddress string, enabled bool) error {
+	anchor := fmt.Sprintf("incus/reflect/%s", strings.ReplaceAll(listenAddress, ":", "_"))
+
+	if !enabled {
+		return pfLoadAnchor(anchor, "")
+	}
+
+	rule := fmt.Sprintf("rdr pass on lo0 proto { tcp udp } from any to %s -> %s reflect\n", listenAddress, listenAddress)
+
+	return pfLoadAnchor(anchor, rule)
+}
+
+// pfLoadAnchor replaces the rules loaded into the named pf anchor, creating it if needed and
+// flushing it if rules is empty. incusd owns this anchor exclusively, so it always fully replaces
+// (rather than appends to) its contents.
+func pfLoadAnchor(anchor string, rules string) error {
+	cmd := exec.Command("pfctl", "-a", anchor, "-f", "-")
+	cmd.Stdin = strings.NewReader(rules)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Failed loading pf anchor %q: %w (%s)", anchor, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// pfPortList renders a list of ports/port ranges as a pf port-list literal (e.g. "{ 80 443 }").
+func pfPortList(ports []uint64) string {
+	parts := make([]string, 0, len(ports))
+	for _, port := range ports {
+		parts = append(parts, fmt.Sprintf("%d", port))
+	}
+
+	return fmt.Sprintf("{ %s }", strings.Join(parts, " "))
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// neighbourLineRegex matches a single `ip -6 neigh show dev <iface>` line, e.g.
+// "2001:db8::1 lladdr 00:11:22:33:44:55 REACHABLE". The state is captured so FAILED/INCOMPLETE
+// entries (no usable link-layer address yet) can be skipped.
+var neighbourLineRegex = regexp.MustCompile(`^([0-9a-fA-F:.]+)\s+lladdr\s+([0-9a-fA-F:]+)\s+(\S+)`)
+
+// GetNeighbourV6Hwaddr returns a map of IPv6 address (string form) to link-layer address for every
+// usable entry in interfaceName's IPv6 neighbour cache, by parsing `ip -6 neigh show dev
+// <interfaceName>`. Entries in the FAILED or INCOMPLETE state are omitted since they carry no
+// resolved hardware address.
+func GetNeighbourV6Hwaddr(interfaceName string) (map[string]net.HardwareAddr, error) {
+	output, err := subprocess.RunCommand("ip", "-6", "neigh", "show", "dev", interfaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[string]net.HardwareAddr)
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := neighbourLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		state := strings.ToUpper(matches[3])
+		if state == "FAILED" || state == "INCOMPLETE" {
+			continue
+		}
+
+		hwAddr, err := net.ParseMAC(matches[2])
+		if err != nil {
+			continue
+		}
+
+		addresses[matches[1]] = hwAddr
+	}
+
+	return addresses, nil
+}
+
+// GetNeighbourV6Addresses returns every IPv6 address in interfaceName's neighbour cache resolved
+// to hwaddr, the inverse lookup of GetNeighbourV6Hwaddr for callers that already know the MAC
+// they're interested in (e.g. filtering to a project's instance MACs).
+func GetNeighbourV6Addresses(interfaceName string, hwaddr net.HardwareAddr) ([]string, error) {
+	all, err := GetNeighbourV6Hwaddr(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0)
+	for addr, mac := range all {
+		if mac.String() == hwaddr.String() {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses, nil
+}
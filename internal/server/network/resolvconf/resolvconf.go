@@ -0,0 +1,211 @@
+// Package resolvconf manages a container's resolv.conf the way Docker libnetwork's resolvconf
+// package does: parse whatever is already on disk into its nameservers, search domains, options
+// and free-form lines, merge in what DHCP (or the admin) wants changed, and write the result back
+// atomically without clobbering lines the user added by hand.
+package resolvconf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// userLineSentinel marks a line that AddUserLine preserved and Write will always keep, so
+// hand-added admin lines survive being regenerated alongside DHCP-pushed values. Any comment line
+// in the original file that isn't marked this way is treated as previously auto-generated content
+// and is dropped the next time Write runs.
+const userLineSentinel = "# incus:user"
+
+// maxNameservers is the most nameserver lines Write will ever emit. glibc's resolver only looks at
+// the first three regardless, so capping here avoids silently shipping a file the resolver can't
+// fully use.
+const maxNameservers = 3
+
+var nameserverRegex = regexp.MustCompile(`^\s*nameserver\s+(\S+)`)
+var searchRegex = regexp.MustCompile(`^\s*search\s+(.+)$`)
+var optionsRegex = regexp.MustCompile(`^\s*options\s+(.+)$`)
+
+// File is a parsed resolv.conf.
+type File struct {
+	Nameservers []string
+	Search      []string
+	Options     []string
+
+	extra []string // Lines to preserve verbatim, in their original relative order.
+}
+
+// Parse reads and parses the resolv.conf at path, returning an empty File if it doesn't exist yet
+// (e.g. the first time a container's network comes up).
+func Parse(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &File{}, nil
+		}
+
+		return nil, fmt.Errorf("Failed reading %q: %w", path, err)
+	}
+
+	file := &File{}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		switch {
+		case nameserverRegex.MatchString(line):
+			file.Nameservers = append(file.Nameservers, nameserverRegex.FindStringSubmatch(line)[1])
+		case searchRegex.MatchString(line):
+			file.Search = strings.Fields(searchRegex.FindStringSubmatch(line)[1])
+		case optionsRegex.MatchString(line):
+			file.Options = strings.Fields(optionsRegex.FindStringSubmatch(line)[1])
+		case strings.HasPrefix(strings.TrimSpace(line), userLineSentinel):
+			file.extra = append(file.extra, line)
+		}
+	}
+
+	return file, nil
+}
+
+// AddUserLine appends a free-form line to file, marked so it survives being merged with
+// DHCP-pushed nameservers/search domains on the next Write.
+func (file *File) AddUserLine(line string) {
+	file.extra = append(file.extra, userLineSentinel+" "+line)
+}
+
+// Update replaces file's nameservers and search domains, filtering out link-local IPv6
+// nameservers unless ipv6Enabled (there's no point handing a container a resolver address that
+// only works if IPv6 is actually usable in its netns) and capping the result to maxNameservers.
+func (file *File) Update(nameservers []string, search []string, ipv6Enabled bool) {
+	if !ipv6Enabled {
+		nameservers = filterLinkLocalV6(nameservers)
+	}
+
+	if len(nameservers) > maxNameservers {
+		nameservers = nameservers[:maxNameservers]
+	}
+
+	file.Nameservers = nameservers
+	file.Search = search
+}
+
+// filterLinkLocalV6 drops any link-local IPv6 address from nameservers.
+func filterLinkLocalV6(nameservers []string) []string {
+	filtered := make([]string, 0, len(nameservers))
+
+	for _, nameserver := range nameservers {
+		ip := net.ParseIP(nameserver)
+		if ip != nil && ip.IsLinkLocalUnicast() {
+			continue
+		}
+
+		filtered = append(filtered, nameserver)
+	}
+
+	return filtered
+}
+
+// render returns file's full resolv.conf contents: a generated-file notice, the
+// nameserver/search/options directives, then every preserved user line.
+func (file *File) render() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Generated by Incus, do not edit directly; add your own lines with a \"" + userLineSentinel + "\" line instead.\n")
+
+	for _, nameserver := range file.Nameservers {
+		fmt.Fprintf(&buf, "nameserver %s\n", nameserver)
+	}
+
+	if len(file.Search) > 0 {
+		fmt.Fprintf(&buf, "search %s\n", strings.Join(file.Search, " "))
+	}
+
+	if len(file.Options) > 0 {
+		fmt.Fprintf(&buf, "options %s\n", strings.Join(file.Options, " "))
+	}
+
+	for _, line := range file.extra {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes()
+}
+
+// hashPath returns where Write records the MD5 of the last contents it wrote to path.
+func hashPath(stateDir string) string {
+	return filepath.Join(stateDir, "resolv.conf.hash")
+}
+
+// Write atomically writes file's contents to path. stateDir records the MD5 of what gets written
+// so that a later Write can tell whether something other than this package touched path since
+// (most likely the user, by hand) and, if so, refuses to overwrite it unless force is true.
+func (file *File) Write(path string, stateDir string, force bool) error {
+	if !force {
+		err := checkUnmodified(path, stateDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	content := file.render()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".resolv.conf.")
+	if err != nil {
+		return fmt.Errorf("Failed creating temporary file for %q: %w", path, err)
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("Failed writing %q: %w", tmp.Name(), err)
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("Failed writing %q: %w", tmp.Name(), err)
+	}
+
+	err = os.Chmod(tmp.Name(), 0o644)
+	if err != nil {
+		return fmt.Errorf("Failed setting permissions on %q: %w", tmp.Name(), err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("Failed renaming %q to %q: %w", tmp.Name(), path, err)
+	}
+
+	err = os.WriteFile(hashPath(stateDir), []byte(fmt.Sprintf("%x", md5.Sum(content))), 0o600)
+	if err != nil {
+		return fmt.Errorf("Failed recording %q: %w", hashPath(stateDir), err)
+	}
+
+	return nil
+}
+
+// checkUnmodified returns an error if path already exists, a hash was previously recorded under
+// stateDir, and the two don't match (i.e. path was edited since the last Write).
+func checkUnmodified(path string, stateDir string) error {
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		// Nothing to conflict with yet.
+		return nil
+	}
+
+	lastHash, err := os.ReadFile(hashPath(stateDir))
+	if err != nil {
+		// Nothing recorded yet, so there's no prior write of ours to conflict with.
+		return nil
+	}
+
+	if fmt.Sprintf("%x", md5.Sum(onDisk)) != strings.TrimSpace(string(lastHash)) {
+		return fmt.Errorf("%q was modified since it was last written by Incus; use --force to overwrite", path)
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
 	"github.com/lxc/incus/v6/internal/instancewriter"
 	"github.com/lxc/incus/v6/internal/server/backup"
@@ -21,9 +22,11 @@ import (
 
 // TransferManager represents a transfer manager.
 type TransferManager struct {
-	s3URL     *url.URL
-	accessKey string
-	secretKey string
+	s3URL          *url.URL
+	accessKey      string
+	secretKey      string
+	sseCustomerKey string
+	sseServerSide  bool
 }
 
 // NewTransferManager instantiates a new TransferManager struct.
@@ -35,6 +38,41 @@ func NewTransferManager(s3URL *url.URL, accessKey string, secretKey string) Tran
 	}
 }
 
+// WithSSECustomerKey returns a copy of the transfer manager that encrypts uploads and
+// decrypts downloads using SSE-C with the given 32-byte customer-provided key.
+func (t TransferManager) WithSSECustomerKey(key string) TransferManager {
+	t.sseCustomerKey = key
+	t.sseServerSide = false
+	return t
+}
+
+// WithSSES3 returns a copy of the transfer manager that requests SSE-S3 (server-managed
+// key) encryption for uploads.
+func (t TransferManager) WithSSES3() TransferManager {
+	t.sseCustomerKey = ""
+	t.sseServerSide = true
+	return t
+}
+
+// serverSideEncryption returns the encrypt.ServerSide to use for object operations, or nil
+// if neither SSE-C nor SSE-S3 was requested.
+func (t TransferManager) serverSideEncryption() (encrypt.ServerSide, error) {
+	if t.sseCustomerKey != "" {
+		sse, err := encrypt.NewSSEC([]byte(t.sseCustomerKey))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid SSE-C customer key: %w", err)
+		}
+
+		return sse, nil
+	}
+
+	if t.sseServerSide {
+		return encrypt.NewSSE(), nil
+	}
+
+	return nil, nil
+}
+
 // DownloadAllFiles downloads all files from a bucket and writes them to a tar writer.
 func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instancewriter.InstanceTarWriter) error {
 	logger.Debugf("Downloading all files from bucket %s", bucketName)
@@ -48,6 +86,11 @@ func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instance
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
 
+	sse, err := t.serverSideEncryption()
+	if err != nil {
+		return err
+	}
+
 	objectCh := minioClient.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
 		Recursive: true,
 	})
@@ -58,7 +101,7 @@ func (t TransferManager) DownloadAllFiles(bucketName string, tarWriter *instance
 			return objectInfo.Err
 		}
 
-		object, err := minioClient.GetObject(ctx, bucketName, objectInfo.Key, minio.GetObjectOptions{})
+		object, err := minioClient.GetObject(ctx, bucketName, objectInfo.Key, minio.GetObjectOptions{ServerSideEncryption: sse})
 		if err != nil {
 			logger.Errorf("Failed to get object: %v", err)
 			return err
@@ -124,6 +167,11 @@ func (t TransferManager) UploadAllFiles(bucketName string, srcData io.ReadSeeker
 
 	defer cancelFunc()
 
+	sse, err := t.serverSideEncryption()
+	if err != nil {
+		return err
+	}
+
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -138,7 +186,7 @@ func (t TransferManager) UploadAllFiles(bucketName string, srcData io.ReadSeeker
 		// Skip directories because they are part of the key of an actual file
 		fileName := hdr.Name[len("backup/bucket/"):]
 
-		_, err = minioClient.PutObject(ctx, bucketName, fileName, tr, -1, minio.PutObjectOptions{})
+		_, err = minioClient.PutObject(ctx, bucketName, fileName, tr, -1, minio.PutObjectOptions{ServerSideEncryption: sse})
 		if err != nil {
 			return err
 		}
@@ -147,6 +195,33 @@ func (t TransferManager) UploadAllFiles(bucketName string, srcData io.ReadSeeker
 	return nil
 }
 
+// UploadArchiveStream uploads a single object to the bucket directly from r, without
+// requiring r to be seekable or the object to be materialized on local disk first.
+func (t TransferManager) UploadArchiveStream(bucketName string, objectName string, r io.Reader) error {
+	logger.Debugf("Streaming object %s to bucket %s", objectName, bucketName)
+	logger.Debugf("Endpoint: %s", t.getEndpoint())
+
+	minioClient, err := t.getMinioClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	sse, err := t.serverSideEncryption()
+	if err != nil {
+		return err
+	}
+
+	_, err = minioClient.PutObject(ctx, bucketName, objectName, r, -1, minio.PutObjectOptions{ServerSideEncryption: sse})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (t TransferManager) getMinioClient() (*minio.Client, error) {
 	bucketLookup := minio.BucketLookupPath
 	creds := credentials.NewStaticV4(t.accessKey, t.secretKey, "")
@@ -0,0 +1,572 @@
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+// k8sNetworkPolicy is the minimal subset of networking.k8s.io/v1 NetworkPolicy this package
+// understands, enough to round-trip the ingress/egress rules of a cluster-wide (podSelector-free)
+// policy. Fields and constructs outside this subset are rejected rather than silently dropped.
+type k8sNetworkPolicy struct {
+	APIVersion string               `yaml:"apiVersion"`
+	Kind       string               `yaml:"kind"`
+	Metadata   k8sObjectMeta        `yaml:"metadata"`
+	Spec       k8sNetworkPolicySpec `yaml:"spec"`
+}
+
+type k8sObjectMeta struct {
+	Name string `yaml:"name"`
+}
+
+type k8sNetworkPolicySpec struct {
+	PodSelector map[interface{}]interface{} `yaml:"podSelector"`
+	PolicyTypes []string                    `yaml:"policyTypes,omitempty"`
+	Ingress     []k8sNetworkPolicyRule      `yaml:"ingress,omitempty"`
+	Egress      []k8sNetworkPolicyRule      `yaml:"egress,omitempty"`
+}
+
+type k8sNetworkPolicyRule struct {
+	Ports []k8sNetworkPolicyPort `yaml:"ports,omitempty"`
+	From  []k8sNetworkPolicyPeer `yaml:"from,omitempty"`
+	To    []k8sNetworkPolicyPeer `yaml:"to,omitempty"`
+}
+
+type k8sNetworkPolicyPeer struct {
+	IPBlock           *k8sIPBlock                 `yaml:"ipBlock,omitempty"`
+	PodSelector       map[interface{}]interface{} `yaml:"podSelector,omitempty"`
+	NamespaceSelector map[interface{}]interface{} `yaml:"namespaceSelector,omitempty"`
+}
+
+type k8sIPBlock struct {
+	CIDR   string   `yaml:"cidr"`
+	Except []string `yaml:"except,omitempty"`
+}
+
+type k8sNetworkPolicyPort struct {
+	Protocol *string     `yaml:"protocol,omitempty"`
+	Port     interface{} `yaml:"port,omitempty"`
+	EndPort  *int        `yaml:"endPort,omitempty"`
+}
+
+// FromK8sNetworkPolicy parses a Kubernetes NetworkPolicy manifest and returns the equivalent ACL
+// ingress/egress rules. labelMapping resolves namespaceSelector peers (keyed by their sorted
+// "key=value[,key=value...]" matchLabels) to a source/destination CIDR; pass nil if the manifest
+// has none. Constructs with no ACL equivalent (a scoping podSelector, podSelector peers,
+// namespaceSelector without a mapping entry, ipBlock.except) are reported as errors rather than
+// approximated.
+func FromK8sNetworkPolicy(data []byte, labelMapping map[string]string) (ingress []api.NetworkACLRule, egress []api.NetworkACLRule, err error) {
+	var policy k8sNetworkPolicy
+
+	err = yaml.UnmarshalStrict(data, &policy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid NetworkPolicy manifest: %w", err)
+	}
+
+	if len(policy.Spec.PodSelector) > 0 {
+		return nil, nil, errors.New("Network policies scoped by a non-empty podSelector are not supported, only cluster-wide (podSelector-free) policies can be imported")
+	}
+
+	ingress, err = k8sRulesToACL(policy.Spec.Ingress, true, labelMapping)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Ingress: %w", err)
+	}
+
+	egress, err = k8sRulesToACL(policy.Spec.Egress, false, labelMapping)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Egress: %w", err)
+	}
+
+	return ingress, egress, nil
+}
+
+// k8sRulesToACL converts one direction's worth of NetworkPolicy rules into ACL rules, crossing
+// every peer in the rule with every port entry (a NetworkPolicy rule matches if any peer and any
+// port match, which is exactly what a cross product of single-peer/single-port ACL rules express).
+func k8sRulesToACL(rules []k8sNetworkPolicyRule, isIngress bool, labelMapping map[string]string) ([]api.NetworkACLRule, error) {
+	var out []api.NetworkACLRule
+
+	for _, rule := range rules {
+		peers := rule.From
+		if !isIngress {
+			peers = rule.To
+		}
+
+		if len(peers) == 0 {
+			peers = []k8sNetworkPolicyPeer{{}} // No peers means "all sources/destinations".
+		}
+
+		ports, err := k8sPortsToACL(rule.Ports)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, peer := range peers {
+			addr, err := k8sPeerToACLAddress(peer, labelMapping)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, port := range ports {
+				aclRule := api.NetworkACLRule{
+					Action:   "allow",
+					State:    "enabled",
+					Protocol: port.protocol,
+				}
+
+				if isIngress {
+					aclRule.Source = addr
+					aclRule.DestinationPort = port.port
+				} else {
+					aclRule.Destination = addr
+					aclRule.DestinationPort = port.port
+				}
+
+				out = append(out, aclRule)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// k8sPeerToACLAddress resolves a single NetworkPolicy peer to the ACL source/destination string,
+// or "" if the peer matches everything.
+func k8sPeerToACLAddress(peer k8sNetworkPolicyPeer, labelMapping map[string]string) (string, error) {
+	if peer.IPBlock != nil {
+		if len(peer.IPBlock.Except) > 0 {
+			return "", fmt.Errorf("IpBlock.except is not supported (cidr %q)", peer.IPBlock.CIDR)
+		}
+
+		return peer.IPBlock.CIDR, nil
+	}
+
+	if peer.PodSelector != nil {
+		return "", errors.New("PodSelector peers are not supported; only ipBlock and namespaceSelector (with --label-mapping) peers can be imported")
+	}
+
+	if peer.NamespaceSelector != nil {
+		key, err := k8sSelectorKey(peer.NamespaceSelector)
+		if err != nil {
+			return "", err
+		}
+
+		addr, ok := labelMapping[key]
+		if !ok {
+			return "", fmt.Errorf("NamespaceSelector %q has no entry in --label-mapping", key)
+		}
+
+		return addr, nil
+	}
+
+	return "", nil
+}
+
+// k8sSelectorKey turns a label selector's matchLabels into the deterministic "k=v,k=v" string used
+// to look it up in --label-mapping. matchExpressions has no equivalent and is rejected.
+func k8sSelectorKey(selector map[interface{}]interface{}) (string, error) {
+	if _, ok := selector["matchExpressions"]; ok {
+		return "", errors.New("NamespaceSelector.matchExpressions is not supported, only matchLabels")
+	}
+
+	matchLabelsRaw, ok := selector["matchLabels"]
+	if !ok {
+		return "", errors.New("NamespaceSelector without matchLabels is not supported")
+	}
+
+	matchLabels, ok := matchLabelsRaw.(map[interface{}]interface{})
+	if !ok {
+		return "", errors.New("NamespaceSelector.matchLabels is malformed")
+	}
+
+	pairs := make([]string, 0, len(matchLabels))
+	for k, v := range matchLabels {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", k, v))
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ","), nil
+}
+
+type k8sACLPort struct {
+	protocol string
+	port     string
+}
+
+// k8sPortsToACL converts NetworkPolicy port entries into ACL protocol/port pairs. An empty list
+// means "all ports", expressed as a single unrestricted entry.
+func k8sPortsToACL(ports []k8sNetworkPolicyPort) ([]k8sACLPort, error) {
+	if len(ports) == 0 {
+		return []k8sACLPort{{}}, nil
+	}
+
+	out := make([]k8sACLPort, 0, len(ports))
+	for _, p := range ports {
+		protocol := "tcp"
+		if p.Protocol != nil {
+			protocol = strings.ToLower(*p.Protocol)
+		}
+
+		port := ""
+		if p.Port != nil {
+			port = fmt.Sprintf("%v", p.Port)
+		}
+
+		if p.EndPort != nil {
+			if port == "" {
+				return nil, errors.New("EndPort without port is not supported")
+			}
+
+			port = fmt.Sprintf("%s-%d", port, *p.EndPort)
+		}
+
+		out = append(out, k8sACLPort{protocol: protocol, port: port})
+	}
+
+	return out, nil
+}
+
+// ToK8sNetworkPolicy renders a cluster-wide NetworkPolicy manifest equivalent to the given ACL
+// ingress/egress rules. Only "allow" rules have a NetworkPolicy equivalent (NetworkPolicy has no
+// concept of an explicit deny); a non-allow rule fails the conversion rather than being dropped.
+func ToK8sNetworkPolicy(name string, ingress []api.NetworkACLRule, egress []api.NetworkACLRule) ([]byte, error) {
+	policy := k8sNetworkPolicy{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		Metadata:   k8sObjectMeta{Name: name},
+		Spec: k8sNetworkPolicySpec{
+			PodSelector: map[interface{}]interface{}{},
+		},
+	}
+
+	if len(ingress) > 0 {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, "Ingress")
+
+		rules, err := aclToK8sRules(ingress, true)
+		if err != nil {
+			return nil, fmt.Errorf("Ingress: %w", err)
+		}
+
+		policy.Spec.Ingress = rules
+	}
+
+	if len(egress) > 0 {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, "Egress")
+
+		rules, err := aclToK8sRules(egress, false)
+		if err != nil {
+			return nil, fmt.Errorf("Egress: %w", err)
+		}
+
+		policy.Spec.Egress = rules
+	}
+
+	return yaml.Marshal(&policy)
+}
+
+// aclToK8sRules converts ACL rules into one NetworkPolicy rule per ACL rule, the inverse of
+// k8sRulesToACL's cross product.
+func aclToK8sRules(rules []api.NetworkACLRule, isIngress bool) ([]k8sNetworkPolicyRule, error) {
+	out := make([]k8sNetworkPolicyRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.Action != "allow" {
+			return nil, fmt.Errorf("Rule with action %q has no NetworkPolicy equivalent, only \"allow\" rules can be exported", rule.Action)
+		}
+
+		addr := rule.Source
+		if !isIngress {
+			addr = rule.Destination
+		}
+
+		var peer k8sNetworkPolicyPeer
+		if addr != "" {
+			peer = k8sNetworkPolicyPeer{IPBlock: &k8sIPBlock{CIDR: addr}}
+		}
+
+		policyRule := k8sNetworkPolicyRule{}
+		if isIngress {
+			policyRule.From = []k8sNetworkPolicyPeer{peer}
+		} else {
+			policyRule.To = []k8sNetworkPolicyPeer{peer}
+		}
+
+		if rule.Protocol != "" || rule.DestinationPort != "" {
+			port := k8sNetworkPolicyPort{}
+			if rule.Protocol != "" {
+				protocol := strings.ToUpper(rule.Protocol)
+				port.Protocol = &protocol
+			}
+
+			if rule.DestinationPort != "" {
+				port.Port = rule.DestinationPort
+			}
+
+			policyRule.Ports = []k8sNetworkPolicyPort{port}
+		}
+
+		out = append(out, policyRule)
+	}
+
+	return out, nil
+}
+
+// LoadLabelMapping parses a --label-mapping file: a flat YAML or JSON map of
+// "key=value[,key=value...]" selector strings (matching a namespaceSelector's sorted matchLabels,
+// see k8sSelectorKey) to the ACL source/destination CIDR they resolve to.
+func LoadLabelMapping(data []byte) (map[string]string, error) {
+	mapping := make(map[string]string)
+
+	err := yaml.UnmarshalStrict(data, &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid label mapping file: %w", err)
+	}
+
+	return mapping, nil
+}
+
+// nftVerdict maps an ACL rule action to its nftables verdict statement.
+func nftVerdict(action string) (string, error) {
+	switch action {
+	case "allow":
+		return "accept", nil
+	case "reject":
+		return "reject", nil
+	case "drop":
+		return "drop", nil
+	default:
+		return "", fmt.Errorf("Unknown action %q", action)
+	}
+}
+
+// nftAction is the inverse of nftVerdict, used when parsing nftables back into ACL rules.
+func nftAction(verdict string) (string, error) {
+	switch verdict {
+	case "accept":
+		return "allow", nil
+	case "reject":
+		return "reject", nil
+	case "drop":
+		return "drop", nil
+	default:
+		return "", fmt.Errorf("Unknown verdict %q", verdict)
+	}
+}
+
+// ToNFTables renders ingress/egress ACL rules (plus their default actions) as a flat script of
+// `nft` statements creating an "inet incus" table and one chain per direction, named
+// "acl_<name>_in"/"acl_<name>_out". An empty default action leaves that chain's fall-through
+// behaviour unstated.
+func ToNFTables(name string, ingress []api.NetworkACLRule, egress []api.NetworkACLRule, defaultActionIngress string, defaultActionEgress string) (string, error) {
+	inChain := fmt.Sprintf("acl_%s_in", name)
+	outChain := fmt.Sprintf("acl_%s_out", name)
+
+	var b strings.Builder
+
+	b.WriteString("add table inet incus\n")
+	fmt.Fprintf(&b, "add chain inet incus %s\n", inChain)
+	fmt.Fprintf(&b, "add chain inet incus %s\n", outChain)
+
+	err := writeNFTRules(&b, inChain, ingress, defaultActionIngress)
+	if err != nil {
+		return "", fmt.Errorf("Ingress: %w", err)
+	}
+
+	err = writeNFTRules(&b, outChain, egress, defaultActionEgress)
+	if err != nil {
+		return "", fmt.Errorf("Egress: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// nftAddressFamily returns the nftables match keyword ("ip" or "ip6") for addr, which may be a
+// bare address or a CIDR. Anything that doesn't parse as an IP is treated as IPv4, matching the
+// behaviour ToNFTables has always had for addresses it can't make sense of.
+func nftAddressFamily(addr string) string {
+	host, _, _ := strings.Cut(addr, "/")
+
+	ip, err := netip.ParseAddr(host)
+	if err == nil && ip.Is6() && !ip.Is4In6() {
+		return "ip6"
+	}
+
+	return "ip"
+}
+
+func writeNFTRules(b *strings.Builder, chain string, rules []api.NetworkACLRule, defaultAction string) error {
+	for _, rule := range rules {
+		verdict, err := nftVerdict(rule.Action)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b, "add rule inet incus %s", chain)
+
+		if rule.Source != "" {
+			fmt.Fprintf(b, " %s saddr %s", nftAddressFamily(rule.Source), rule.Source)
+		}
+
+		if rule.Destination != "" {
+			fmt.Fprintf(b, " %s daddr %s", nftAddressFamily(rule.Destination), rule.Destination)
+		}
+
+		if rule.Protocol != "" {
+			fmt.Fprintf(b, " %s", strings.ToLower(rule.Protocol))
+
+			if rule.SourcePort != "" {
+				fmt.Fprintf(b, " sport %s", rule.SourcePort)
+			}
+
+			if rule.DestinationPort != "" {
+				fmt.Fprintf(b, " dport %s", rule.DestinationPort)
+			}
+		}
+
+		fmt.Fprintf(b, " %s\n", verdict)
+	}
+
+	if defaultAction != "" {
+		verdict, err := nftVerdict(defaultAction)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b, "add rule inet incus %s %s\n", chain, verdict)
+	}
+
+	return nil
+}
+
+// FromNFTables parses a script produced by ToNFTables back into ACL rules. It only understands
+// the statement shapes ToNFTables emits (an optional "ip"/"ip6" "saddr"/"daddr", an optional
+// protocol with "sport"/"dport", and a trailing verdict); anything else is reported rather than
+// guessed at.
+// A rule with no match conditions sets that chain's default action instead of becoming a rule.
+func FromNFTables(data string) (ingress []api.NetworkACLRule, egress []api.NetworkACLRule, defaultActionIngress string, defaultActionEgress string, err error) {
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] != "add" {
+			return nil, nil, "", "", fmt.Errorf("Unsupported nftables statement: %q", line)
+		}
+
+		if fields[1] == "table" || fields[1] == "chain" {
+			continue
+		}
+
+		if fields[1] != "rule" || len(fields) < 5 || fields[2] != "inet" || fields[3] != "incus" {
+			return nil, nil, "", "", fmt.Errorf("Unsupported nftables statement: %q", line)
+		}
+
+		chain := fields[4]
+
+		var isIngress bool
+		switch {
+		case strings.HasSuffix(chain, "_in"):
+			isIngress = true
+		case strings.HasSuffix(chain, "_out"):
+			isIngress = false
+		default:
+			return nil, nil, "", "", fmt.Errorf("Chain %q is not named acl_<name>_in/acl_<name>_out, can't tell its direction", chain)
+		}
+
+		rule, isDefault, err := parseNFTRule(fields[5:], line)
+		if err != nil {
+			return nil, nil, "", "", err
+		}
+
+		if isDefault {
+			if isIngress {
+				defaultActionIngress = rule.Action
+			} else {
+				defaultActionEgress = rule.Action
+			}
+
+			continue
+		}
+
+		if isIngress {
+			ingress = append(ingress, rule)
+		} else {
+			egress = append(egress, rule)
+		}
+	}
+
+	return ingress, egress, defaultActionIngress, defaultActionEgress, nil
+}
+
+// parseNFTRule parses the condition/verdict tokens that follow "add rule inet incus <chain>".
+// isDefault reports whether the rule had no match conditions at all (just a bare verdict).
+func parseNFTRule(fields []string, line string) (rule api.NetworkACLRule, isDefault bool, err error) {
+	rule.State = "enabled"
+
+	i := 0
+	for i < len(fields) {
+		tok := fields[i]
+
+		switch tok {
+		case "ip", "ip6":
+			if i+2 >= len(fields) {
+				return rule, false, fmt.Errorf("Truncated %q match in: %q", tok, line)
+			}
+
+			switch fields[i+1] {
+			case "saddr":
+				rule.Source = fields[i+2]
+			case "daddr":
+				rule.Destination = fields[i+2]
+			default:
+				return rule, false, fmt.Errorf("Unsupported %q %q match in: %q", tok, fields[i+1], line)
+			}
+
+			i += 3
+		case "tcp", "udp", "icmp":
+			rule.Protocol = tok
+			i++
+		case "sport":
+			if i+1 >= len(fields) {
+				return rule, false, fmt.Errorf("Truncated \"sport\" match in: %q", line)
+			}
+
+			rule.SourcePort = fields[i+1]
+			i += 2
+		case "dport":
+			if i+1 >= len(fields) {
+				return rule, false, fmt.Errorf("Truncated \"dport\" match in: %q", line)
+			}
+
+			rule.DestinationPort = fields[i+1]
+			i += 2
+		case "accept", "drop", "reject":
+			rule.Action, err = nftAction(tok)
+			if err != nil {
+				return rule, false, err
+			}
+
+			i++
+		default:
+			return rule, false, fmt.Errorf("Unsupported token %q in: %q", tok, line)
+		}
+	}
+
+	if rule.Action == "" {
+		return rule, false, fmt.Errorf("Rule has no verdict (accept/drop/reject): %q", line)
+	}
+
+	isDefault = rule.Source == "" && rule.Destination == "" && rule.Protocol == ""
+
+	return rule, isDefault, nil
+}
@@ -0,0 +1,234 @@
+package acl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestFromK8sNetworkPolicy(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-web
+spec:
+  podSelector: {}
+  ingress:
+    - from:
+        - ipBlock:
+            cidr: 10.0.0.0/24
+      ports:
+        - protocol: TCP
+          port: 443
+  egress:
+    - to:
+        - namespaceSelector:
+            matchLabels:
+              role: db
+      ports:
+        - port: 5432
+`
+
+	ingress, egress, err := FromK8sNetworkPolicy([]byte(manifest), map[string]string{"role=db": "10.0.1.0/24"})
+	if err != nil {
+		t.Fatalf("FromK8sNetworkPolicy returned unexpected error: %v", err)
+	}
+
+	if len(ingress) != 1 {
+		t.Fatalf("len(ingress) = %d, want 1", len(ingress))
+	}
+
+	if ingress[0].Source != "10.0.0.0/24" || ingress[0].Protocol != "tcp" || ingress[0].DestinationPort != "443" {
+		t.Errorf("ingress[0] = %+v, unexpected", ingress[0])
+	}
+
+	if len(egress) != 1 {
+		t.Fatalf("len(egress) = %d, want 1", len(egress))
+	}
+
+	if egress[0].Destination != "10.0.1.0/24" || egress[0].DestinationPort != "5432" {
+		t.Errorf("egress[0] = %+v, unexpected", egress[0])
+	}
+}
+
+func TestFromK8sNetworkPolicyRejectsUnsupportedConstructs(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name: "scoped podSelector",
+			manifest: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: scoped
+spec:
+  podSelector:
+    matchLabels:
+      app: web
+`,
+		},
+		{
+			name: "ipBlock.except",
+			manifest: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: excepted
+spec:
+  podSelector: {}
+  ingress:
+    - from:
+        - ipBlock:
+            cidr: 10.0.0.0/16
+            except:
+              - 10.0.1.0/24
+`,
+		},
+		{
+			name: "podSelector peer",
+			manifest: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: pod-peer
+spec:
+  podSelector: {}
+  ingress:
+    - from:
+        - podSelector: {}
+`,
+		},
+		{
+			name: "namespaceSelector without mapping entry",
+			manifest: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: unmapped
+spec:
+  podSelector: {}
+  ingress:
+    - from:
+        - namespaceSelector:
+            matchLabels:
+              role: unmapped
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := FromK8sNetworkPolicy([]byte(tt.manifest), nil)
+			if err == nil {
+				t.Fatalf("expected an error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestToK8sNetworkPolicyRejectsNonAllowRules(t *testing.T) {
+	_, err := ToK8sNetworkPolicy("name", []api.NetworkACLRule{{Action: "drop"}}, nil)
+	if err == nil {
+		t.Fatal("expected an error converting a non-allow rule to NetworkPolicy")
+	}
+}
+
+func TestLoadLabelMapping(t *testing.T) {
+	mapping, err := LoadLabelMapping([]byte("role=db: 10.0.1.0/24\nrole=web: 10.0.2.0/24\n"))
+	if err != nil {
+		t.Fatalf("LoadLabelMapping returned unexpected error: %v", err)
+	}
+
+	if mapping["role=db"] != "10.0.1.0/24" {
+		t.Errorf("mapping[role=db] = %q, want 10.0.1.0/24", mapping["role=db"])
+	}
+}
+
+func TestLoadLabelMappingInvalid(t *testing.T) {
+	_, err := LoadLabelMapping([]byte("not: [valid"))
+	if err == nil {
+		t.Fatal("expected an error for malformed label mapping data")
+	}
+}
+
+func TestToNFTablesAddressFamily(t *testing.T) {
+	ingress := []api.NetworkACLRule{
+		{Action: "allow", State: "enabled", Source: "10.0.0.0/24"},
+		{Action: "drop", State: "enabled", Source: "2001:db8::/32"},
+	}
+
+	script, err := ToNFTables("test", ingress, nil, "", "")
+	if err != nil {
+		t.Fatalf("ToNFTables returned unexpected error: %v", err)
+	}
+
+	if !strings.Contains(script, "ip saddr 10.0.0.0/24") {
+		t.Errorf("expected an IPv4 rule to use \"ip saddr\", got:\n%s", script)
+	}
+
+	if !strings.Contains(script, "ip6 saddr 2001:db8::/32") {
+		t.Errorf("expected an IPv6 rule to use \"ip6 saddr\", got:\n%s", script)
+	}
+}
+
+func TestNFTablesRoundTrip(t *testing.T) {
+	ingress := []api.NetworkACLRule{
+		{Action: "allow", State: "enabled", Source: "10.0.0.0/24", Protocol: "tcp", DestinationPort: "443"},
+		{Action: "drop", State: "enabled", Source: "2001:db8::/32"},
+	}
+	egress := []api.NetworkACLRule{
+		{Action: "reject", State: "enabled", Destination: "10.0.1.5"},
+	}
+
+	script, err := ToNFTables("test", ingress, egress, "drop", "allow")
+	if err != nil {
+		t.Fatalf("ToNFTables returned unexpected error: %v", err)
+	}
+
+	gotIngress, gotEgress, defaultIngress, defaultEgress, err := FromNFTables(script)
+	if err != nil {
+		t.Fatalf("FromNFTables returned unexpected error: %v", err)
+	}
+
+	if len(gotIngress) != len(ingress) || len(gotEgress) != len(egress) {
+		t.Fatalf("round-trip rule counts = (%d, %d), want (%d, %d)", len(gotIngress), len(gotEgress), len(ingress), len(egress))
+	}
+
+	if gotIngress[1].Source != "2001:db8::/32" {
+		t.Errorf("round-tripped ingress[1].Source = %q, want 2001:db8::/32", gotIngress[1].Source)
+	}
+
+	if defaultIngress != "drop" || defaultEgress != "allow" {
+		t.Errorf("default actions = (%q, %q), want (drop, allow)", defaultIngress, defaultEgress)
+	}
+}
+
+func TestFromNFTablesRejectsUnsupportedStatement(t *testing.T) {
+	_, _, _, _, err := FromNFTables("flush ruleset\n")
+	if err == nil {
+		t.Fatal("expected an error for an unsupported nftables statement")
+	}
+}
+
+func TestNftAddressFamily(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "10.0.0.1", want: "ip"},
+		{addr: "10.0.0.0/24", want: "ip"},
+		{addr: "2001:db8::1", want: "ip6"},
+		{addr: "2001:db8::/32", want: "ip6"},
+	}
+
+	for _, tt := range tests {
+		got := nftAddressFamily(tt.addr)
+		if got != tt.want {
+			t.Errorf("nftAddressFamily(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package acl implements offline evaluation of network ACL rule lists, shared by the
+// `incus network acl test` command and (potentially) server-side policy tooling, so that both
+// agree on exactly how a rule list is matched against a flow.
+package acl
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/api"
+	"github.com/lxc/incus/v6/shared/network/netmatch"
+)
+
+// Flow describes a single packet/connection to evaluate against a rule list.
+type Flow struct {
+	Protocol        string `json:"protocol,omitempty"`
+	Source          string `json:"source,omitempty"`
+	SourcePort      string `json:"source_port,omitempty"`
+	Destination     string `json:"destination,omitempty"`
+	DestinationPort string `json:"destination_port,omitempty"`
+	ICMPType        string `json:"icmp_type,omitempty"`
+	ICMPCode        string `json:"icmp_code,omitempty"`
+	State           string `json:"state,omitempty"`
+}
+
+// TraceStep records why a single rule was or wasn't chosen, in rule-list order, for --trace output.
+type TraceStep struct {
+	Index   int                `json:"index"`
+	Rule    api.NetworkACLRule `json:"rule"`
+	Matched bool               `json:"matched"`
+	Reason  string             `json:"reason,omitempty"`
+}
+
+// Verdict is the result of evaluating a Flow against a rule list.
+type Verdict struct {
+	Action string
+	Index  int // Index of the matched rule, or -1 if the implicit default action applied.
+	Rule   *api.NetworkACLRule
+	Trace  []TraceStep
+}
+
+// Evaluate walks rules in order and returns the first one that matches flow, falling back to
+// defaultAction if none do. groups resolves the named address sets any rule's source, destination,
+// source_port or destination_port references as "@<name>" to their member list; pass nil if no
+// rule references one. When trace is true, Verdict.Trace records every rule considered and why it
+// was rejected (or accepted).
+func Evaluate(rules []api.NetworkACLRule, flow Flow, defaultAction string, trace bool, groups map[string][]string) Verdict {
+	for i, rule := range rules {
+		reason := matchReason(&rule, flow, groups)
+
+		if reason == "" {
+			if trace {
+				return appendTraceAndReturn(rules[:i+1], flow, Verdict{Action: rule.Action, Index: i, Rule: &rule}, trace, groups)
+			}
+
+			return Verdict{Action: rule.Action, Index: i, Rule: &rule}
+		}
+	}
+
+	if trace {
+		return appendTraceAndReturn(rules, flow, Verdict{Action: defaultAction, Index: -1}, trace, groups)
+	}
+
+	return Verdict{Action: defaultAction, Index: -1}
+}
+
+// appendTraceAndReturn re-derives the per-rule trace reasons for every rule up to and including the
+// matched one (or all of them, if nothing matched) and attaches it to verdict.
+func appendTraceAndReturn(rules []api.NetworkACLRule, flow Flow, verdict Verdict, trace bool, groups map[string][]string) Verdict {
+	verdict.Trace = make([]TraceStep, 0, len(rules))
+
+	for i, rule := range rules {
+		reason := matchReason(&rule, flow, groups)
+		verdict.Trace = append(verdict.Trace, TraceStep{
+			Index:   i,
+			Rule:    rule,
+			Matched: reason == "",
+			Reason:  reason,
+		})
+	}
+
+	return verdict
+}
+
+// matchReason returns "" if rule matches flow, or a human-readable reason it didn't.
+func matchReason(rule *api.NetworkACLRule, flow Flow, groups map[string][]string) string {
+	if rule.State == "disabled" {
+		return "rule is disabled"
+	}
+
+	if rule.Protocol != "" && !strings.EqualFold(rule.Protocol, flow.Protocol) {
+		return fmt.Sprintf("protocol %q does not match %q", flow.Protocol, rule.Protocol)
+	}
+
+	if !matchSubjectList(rule.Source, flow.Source, groups) {
+		return fmt.Sprintf("source %q does not match %q", flow.Source, rule.Source)
+	}
+
+	if !matchSubjectList(rule.Destination, flow.Destination, groups) {
+		return fmt.Sprintf("destination %q does not match %q", flow.Destination, rule.Destination)
+	}
+
+	if !matchPortList(rule.SourcePort, flow.SourcePort, groups) {
+		return fmt.Sprintf("source port %q does not match %q", flow.SourcePort, rule.SourcePort)
+	}
+
+	if !matchPortList(rule.DestinationPort, flow.DestinationPort, groups) {
+		return fmt.Sprintf("destination port %q does not match %q", flow.DestinationPort, rule.DestinationPort)
+	}
+
+	if rule.ICMPType != "" && rule.ICMPType != flow.ICMPType {
+		return fmt.Sprintf("ICMP type %q does not match %q", flow.ICMPType, rule.ICMPType)
+	}
+
+	if rule.ICMPCode != "" && rule.ICMPCode != flow.ICMPCode {
+		return fmt.Sprintf("ICMP code %q does not match %q", flow.ICMPCode, rule.ICMPCode)
+	}
+
+	return ""
+}
+
+// matchSubjectList reports whether value satisfies a rule's comma-separated source/destination
+// field, which may name a netmatch pattern (wildcard, CIDR, single IP or range) or a "@<name>"
+// network address set resolved against groups. If value doesn't parse as an IP address (e.g. it
+// names something groups-only, like an instance NIC), each subject falls back to a literal string
+// comparison, since that's as much as an offline evaluator without network lookups can do. An
+// empty rule field matches any value.
+func matchSubjectList(ruleField string, value string, groups map[string][]string) bool {
+	if ruleField == "" {
+		return true
+	}
+
+	ip, ipErr := netip.ParseAddr(value)
+
+	for _, subject := range strings.Split(ruleField, ",") {
+		subject = strings.TrimSpace(subject)
+
+		if name, ok := strings.CutPrefix(subject, "@"); ok {
+			for _, member := range groups[name] {
+				if matchSubject(strings.TrimSpace(member), value, ip, ipErr) {
+					return true
+				}
+			}
+
+			continue
+		}
+
+		if matchSubject(subject, value, ip, ipErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSubject reports whether a single subject (one entry of a source/destination field, already
+// stripped of any "@group" prefix) matches value. It prefers netmatch so CIDRs and ranges are
+// matched as actual address sets, falling back to a literal string comparison for subjects
+// netmatch doesn't recognize (e.g. a group member that isn't a plain address) or when value itself
+// isn't a parseable IP.
+func matchSubject(subject string, value string, ip netip.Addr, ipErr error) bool {
+	if ipErr == nil {
+		matched, err := netmatch.Match(subject, ip)
+		if err == nil {
+			return matched
+		}
+	}
+
+	return subject == value
+}
+
+// matchPortList reports whether port satisfies a rule's comma-separated port/port-range field,
+// where an entry may also be a "@<name>" network address set resolved against groups. An empty
+// rule field matches any port.
+func matchPortList(ruleField string, port string, groups map[string][]string) bool {
+	if ruleField == "" {
+		return true
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range strings.Split(ruleField, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if name, ok := strings.CutPrefix(entry, "@"); ok {
+			if matchPortList(strings.Join(groups[name], ","), port, nil) {
+				return true
+			}
+
+			continue
+		}
+
+		start, end, ok := strings.Cut(entry, "-")
+		if !ok {
+			if entry == port {
+				return true
+			}
+
+			continue
+		}
+
+		startNum, err := strconv.Atoi(start)
+		if err != nil {
+			continue
+		}
+
+		endNum, err := strconv.Atoi(end)
+		if err != nil {
+			continue
+		}
+
+		if portNum >= startNum && portNum <= endNum {
+			return true
+		}
+	}
+
+	return false
+}
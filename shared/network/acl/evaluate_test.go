@@ -0,0 +1,146 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/lxc/incus/v6/shared/api"
+)
+
+func TestEvaluate(t *testing.T) {
+	rules := []api.NetworkACLRule{
+		{State: "disabled", Action: "allow", Source: "10.0.0.0/24"},
+		{State: "enabled", Action: "drop", Protocol: "tcp", Destination: "10.0.0.5", DestinationPort: "22"},
+		{State: "enabled", Action: "allow", Source: "10.0.0.0/24"},
+	}
+
+	tests := []struct {
+		name       string
+		flow       Flow
+		wantAction string
+		wantIndex  int
+	}{
+		{
+			name:       "disabled rule is skipped",
+			flow:       Flow{Source: "10.0.0.1"},
+			wantAction: "allow",
+			wantIndex:  2,
+		},
+		{
+			name:       "matches a specific rule before falling through",
+			flow:       Flow{Protocol: "tcp", Source: "10.0.0.1", Destination: "10.0.0.5", DestinationPort: "22"},
+			wantAction: "drop",
+			wantIndex:  1,
+		},
+		{
+			name:       "falls back to the default action when nothing matches",
+			flow:       Flow{Source: "192.168.1.1"},
+			wantAction: "reject",
+			wantIndex:  -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := Evaluate(rules, tt.flow, "reject", false, nil)
+
+			if verdict.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", verdict.Action, tt.wantAction)
+			}
+
+			if verdict.Index != tt.wantIndex {
+				t.Errorf("Index = %d, want %d", verdict.Index, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestEvaluateTrace(t *testing.T) {
+	rules := []api.NetworkACLRule{
+		{State: "enabled", Action: "drop", Source: "10.0.0.5"},
+		{State: "enabled", Action: "allow", Source: "10.0.0.0/24"},
+	}
+
+	verdict := Evaluate(rules, Flow{Source: "10.0.0.1"}, "reject", true, nil)
+
+	if verdict.Action != "allow" || verdict.Index != 1 {
+		t.Fatalf("Verdict = %+v, want action=allow index=1", verdict)
+	}
+
+	if len(verdict.Trace) != 2 {
+		t.Fatalf("len(Trace) = %d, want 2", len(verdict.Trace))
+	}
+
+	if verdict.Trace[0].Matched {
+		t.Error("expected the first rule not to match")
+	}
+
+	if verdict.Trace[0].Reason == "" {
+		t.Error("expected a reason for the first rule's non-match")
+	}
+
+	if !verdict.Trace[1].Matched {
+		t.Error("expected the second rule to match")
+	}
+}
+
+func TestMatchSubjectList(t *testing.T) {
+	groups := map[string][]string{
+		"web": {"10.0.0.5", "10.0.0.6"},
+	}
+
+	tests := []struct {
+		name      string
+		ruleField string
+		value     string
+		want      bool
+	}{
+		{name: "empty field matches anything", ruleField: "", value: "10.0.0.1", want: true},
+		{name: "cidr match", ruleField: "10.0.0.0/24", value: "10.0.0.42", want: true},
+		{name: "cidr non-match", ruleField: "10.0.0.0/24", value: "10.0.1.42", want: false},
+		{name: "comma separated alternatives", ruleField: "10.0.0.1,10.0.0.2", value: "10.0.0.2", want: true},
+		{name: "group member match", ruleField: "@web", value: "10.0.0.5", want: true},
+		{name: "group non-member", ruleField: "@web", value: "10.0.0.9", want: false},
+		{name: "literal fallback for non-IP value", ruleField: "eth0", value: "eth0", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchSubjectList(tt.ruleField, tt.value, groups)
+			if got != tt.want {
+				t.Errorf("matchSubjectList(%q, %q) = %v, want %v", tt.ruleField, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPortList(t *testing.T) {
+	groups := map[string][]string{
+		"web-ports": {"80", "443"},
+	}
+
+	tests := []struct {
+		name      string
+		ruleField string
+		port      string
+		want      bool
+	}{
+		{name: "empty field matches any port", ruleField: "", port: "22", want: true},
+		{name: "exact match", ruleField: "22", port: "22", want: true},
+		{name: "exact non-match", ruleField: "22", port: "23", want: false},
+		{name: "range match", ruleField: "8000-9000", port: "8080", want: true},
+		{name: "range non-match", ruleField: "8000-9000", port: "9001", want: false},
+		{name: "comma separated alternatives", ruleField: "22,80,443", port: "443", want: true},
+		{name: "group member match", ruleField: "@web-ports", port: "80", want: true},
+		{name: "group non-member", ruleField: "@web-ports", port: "22", want: false},
+		{name: "non-numeric port never matches", ruleField: "22", port: "not-a-port", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchPortList(tt.ruleField, tt.port, groups)
+			if got != tt.want {
+				t.Errorf("matchPortList(%q, %q) = %v, want %v", tt.ruleField, tt.port, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package netmatch matches an IP address against an ACL-style pattern: a wildcard (`*`, `*4`,
+// `*6`), a single IP, a CIDR network, or a "start-end" range. It gives the network ACL and
+// forward/proxy code one expressive syntax instead of today's split between separate "address",
+// "network" and "range" fields, each checked for syntax by validate.IsNetworkPattern but never
+// actually matched against a real address.
+package netmatch
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Matcher reports whether an address satisfies a precompiled pattern. Compile a pattern once and
+// reuse the Matcher on a hot path (e.g. per-packet firewall/OVN rule evaluation) instead of
+// re-parsing it on every call.
+type Matcher interface {
+	Match(ip netip.Addr) bool
+}
+
+// wildcardMatcher matches any address, optionally restricted to one IP family.
+type wildcardMatcher struct {
+	v4only bool
+	v6only bool
+}
+
+func (m wildcardMatcher) Match(ip netip.Addr) bool {
+	ip = unmap(ip)
+
+	if m.v4only {
+		return ip.Is4()
+	}
+
+	if m.v6only {
+		return ip.Is6()
+	}
+
+	return true
+}
+
+// cidrMatcher matches any address contained in a network.
+type cidrMatcher netip.Prefix
+
+func (m cidrMatcher) Match(ip netip.Addr) bool {
+	return netip.Prefix(m).Contains(unmap(ip))
+}
+
+// addrMatcher matches exactly one address.
+type addrMatcher netip.Addr
+
+func (m addrMatcher) Match(ip netip.Addr) bool {
+	return netip.Addr(m) == unmap(ip)
+}
+
+// rangeMatcher matches any address between two bounds (inclusive).
+type rangeMatcher struct {
+	start netip.Addr
+	end   netip.Addr
+}
+
+func (m rangeMatcher) Match(ip netip.Addr) bool {
+	ip = unmap(ip)
+
+	return ip.Compare(m.start) >= 0 && ip.Compare(m.end) <= 0
+}
+
+// unmap treats an IPv4-mapped IPv6 address (::ffff:a.b.c.d) as its plain IPv4 form, so a pattern
+// written as an IPv4 CIDR or range still matches a connection that arrived over a dual-stack v6
+// socket.
+func unmap(ip netip.Addr) netip.Addr {
+	if ip.Is4In6() {
+		return ip.Unmap()
+	}
+
+	return ip
+}
+
+// Compile parses pattern (in the same syntax validate.IsNetworkPattern accepts: `*`, `*4`, `*6`,
+// a CIDR, a single IP, or a "start-end" range) into a reusable Matcher.
+func Compile(pattern string) (Matcher, error) {
+	switch pattern {
+	case "*":
+		return wildcardMatcher{}, nil
+	case "*4":
+		return wildcardMatcher{v4only: true}, nil
+	case "*6":
+		return wildcardMatcher{v6only: true}, nil
+	}
+
+	if prefix, err := netip.ParsePrefix(pattern); err == nil {
+		return cidrMatcher(prefix), nil
+	}
+
+	if start, end, ok := strings.Cut(pattern, "-"); ok {
+		startIP, err := netip.ParseAddr(strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid range start %q: %w", start, err)
+		}
+
+		endIP, err := netip.ParseAddr(strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid range end %q: %w", end, err)
+		}
+
+		startIP, endIP = unmap(startIP), unmap(endIP)
+
+		if startIP.Is4() != endIP.Is4() {
+			return nil, fmt.Errorf("Range start and end %q are not in the same address family", pattern)
+		}
+
+		if startIP.Compare(endIP) > 0 {
+			return nil, fmt.Errorf("Range start must be before or equal to end %q", pattern)
+		}
+
+		return rangeMatcher{start: startIP, end: endIP}, nil
+	}
+
+	ip, err := netip.ParseAddr(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%q isn't a wildcard, IP address, CIDR or range", pattern)
+	}
+
+	return addrMatcher(unmap(ip)), nil
+}
+
+// Match reports whether ip satisfies pattern. It compiles pattern on every call; a caller matching
+// the same pattern repeatedly (e.g. one ACL rule against a stream of packets) should call Compile
+// once and reuse the Matcher instead.
+func Match(pattern string, ip netip.Addr) (bool, error) {
+	matcher, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return matcher.Match(ip), nil
+}
@@ -0,0 +1,73 @@
+package netmatch
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		addr    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "wildcard matches v4", pattern: "*", addr: "10.0.0.1", want: true},
+		{name: "wildcard matches v6", pattern: "*", addr: "fd00::1", want: true},
+		{name: "v4 wildcard matches v4", pattern: "*4", addr: "10.0.0.1", want: true},
+		{name: "v4 wildcard rejects v6", pattern: "*4", addr: "fd00::1", want: false},
+		{name: "v6 wildcard matches v6", pattern: "*6", addr: "fd00::1", want: true},
+		{name: "v6 wildcard rejects v4", pattern: "*6", addr: "10.0.0.1", want: false},
+		{name: "v4 wildcard matches v4-mapped v6", pattern: "*4", addr: "::ffff:10.0.0.1", want: true},
+		{name: "cidr contains address", pattern: "10.0.0.0/24", addr: "10.0.0.42", want: true},
+		{name: "cidr excludes address", pattern: "10.0.0.0/24", addr: "10.0.1.42", want: false},
+		{name: "single address matches itself", pattern: "10.0.0.5", addr: "10.0.0.5", want: true},
+		{name: "single address rejects other", pattern: "10.0.0.5", addr: "10.0.0.6", want: false},
+		{name: "range matches inside bounds", pattern: "10.0.0.5-10.0.0.10", addr: "10.0.0.7", want: true},
+		{name: "range matches lower bound", pattern: "10.0.0.5-10.0.0.10", addr: "10.0.0.5", want: true},
+		{name: "range matches upper bound", pattern: "10.0.0.5-10.0.0.10", addr: "10.0.0.10", want: true},
+		{name: "range excludes outside bounds", pattern: "10.0.0.5-10.0.0.10", addr: "10.0.0.11", want: false},
+		{name: "mixed family range is rejected", pattern: "10.0.0.5-fd00::1", wantErr: true},
+		{name: "reversed range is rejected", pattern: "10.0.0.10-10.0.0.5", wantErr: true},
+		{name: "garbage pattern is rejected", pattern: "not-a-pattern", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var addr netip.Addr
+
+			if tt.addr != "" {
+				addr = netip.MustParseAddr(tt.addr)
+			}
+
+			got, err := Match(tt.pattern, addr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Match(%q) = nil error, want an error", tt.pattern)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Match(%q) returned unexpected error: %v", tt.pattern, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchUnmapsIPv4MappedAddresses(t *testing.T) {
+	matcher, err := Compile("10.0.0.0/24")
+	if err != nil {
+		t.Fatalf("Compile returned unexpected error: %v", err)
+	}
+
+	if !matcher.Match(netip.MustParseAddr("::ffff:10.0.0.42")) {
+		t.Error("expected IPv4 CIDR matcher to match a v4-mapped v6 address")
+	}
+}
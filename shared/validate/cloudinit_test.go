@@ -0,0 +1,190 @@
+package validate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseCloudInitUserData(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantFormat CloudInitFormat
+		wantErr    bool
+	}{
+		{
+			name:       "cloud-config",
+			value:      "#cloud-config\npackages:\n  - curl\n",
+			wantFormat: CloudInitFormatCloudConfig,
+		},
+		{
+			name:    "cloud-config with invalid yaml body",
+			value:   "#cloud-config\npackages: [curl\n",
+			wantErr: true,
+		},
+		{
+			name:       "bare cloud-config header",
+			value:      "#cloud-config",
+			wantFormat: CloudInitFormatCloudConfig,
+		},
+		{
+			name:       "boothook",
+			value:      "#cloud-boothook\necho hello\n",
+			wantFormat: CloudInitFormatBoothook,
+		},
+		{
+			name:       "shell script",
+			value:      "#!/bin/sh\necho hello\n",
+			wantFormat: CloudInitFormatShellScript,
+		},
+		{
+			name:       "include list",
+			value:      "#include\nhttp://example.com/a.txt\nhttp://example.com/b.txt\n",
+			wantFormat: CloudInitFormatInclude,
+		},
+		{
+			name:    "include list with invalid url",
+			value:   "#include\nnot-a-url\n",
+			wantErr: true,
+		},
+		{
+			name:       "opaque content falls through rather than being rejected",
+			value:      "something cloud-init doesn't recognize",
+			wantFormat: CloudInitFormatOpaque,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := ParseCloudInitUserData(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCloudInitUserData(%q) = nil error, want an error", tt.value)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseCloudInitUserData(%q) returned unexpected error: %v", tt.value, err)
+			}
+
+			if payload.Format != tt.wantFormat {
+				t.Errorf("ParseCloudInitUserData(%q).Format = %q, want %q", tt.value, payload.Format, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestParseCloudInitUserDataIncludes(t *testing.T) {
+	value := "#include\nhttp://example.com/a.txt\n# a comment\n\nhttp://example.com/b.txt\n"
+
+	payload, err := ParseCloudInitUserData(value)
+	if err != nil {
+		t.Fatalf("ParseCloudInitUserData returned unexpected error: %v", err)
+	}
+
+	want := []string{"http://example.com/a.txt", "http://example.com/b.txt"}
+	if len(payload.Includes) != len(want) {
+		t.Fatalf("Includes = %v, want %v", payload.Includes, want)
+	}
+
+	for i, url := range want {
+		if payload.Includes[i] != url {
+			t.Errorf("Includes[%d] = %q, want %q", i, payload.Includes[i], url)
+		}
+	}
+}
+
+func TestParseCloudInitUserDataJinja(t *testing.T) {
+	payload, err := ParseCloudInitUserData("## template: jinja\n#cloud-config\npackages:\n  - curl\n")
+	if err != nil {
+		t.Fatalf("ParseCloudInitUserData returned unexpected error: %v", err)
+	}
+
+	if !payload.Jinja {
+		t.Error("expected Jinja to be true for a jinja-templated payload")
+	}
+
+	if payload.Format != CloudInitFormatCloudConfig {
+		t.Errorf("Format = %q, want %q", payload.Format, CloudInitFormatCloudConfig)
+	}
+}
+
+func TestParseCloudInitUserDataGzipped(t *testing.T) {
+	var buf bytes.Buffer
+
+	gzw := gzip.NewWriter(&buf)
+
+	_, err := gzw.Write([]byte("#cloud-config\npackages:\n  - curl\n"))
+	if err != nil {
+		t.Fatalf("failed writing gzip payload: %v", err)
+	}
+
+	err = gzw.Close()
+	if err != nil {
+		t.Fatalf("failed closing gzip writer: %v", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	payload, err := ParseCloudInitUserData(encoded)
+	if err != nil {
+		t.Fatalf("ParseCloudInitUserData returned unexpected error: %v", err)
+	}
+
+	if !payload.Gzipped {
+		t.Error("expected Gzipped to be true for a gzip+base64 payload")
+	}
+
+	if payload.Format != CloudInitFormatCloudConfig {
+		t.Errorf("Format = %q, want %q", payload.Format, CloudInitFormatCloudConfig)
+	}
+}
+
+func TestParseCloudInitUserDataMultipart(t *testing.T) {
+	value := "Content-Type: multipart/mixed; boundary=\"BOUNDARY\"\n" +
+		"MIME-Version: 1.0\n\n" +
+		"--BOUNDARY\n" +
+		"Content-Type: text/cloud-config\n\n" +
+		"packages:\n  - curl\n" +
+		"--BOUNDARY\n" +
+		"Content-Type: text/x-shellscript\n\n" +
+		"#!/bin/sh\necho hi\n" +
+		"--BOUNDARY--\n"
+
+	payload, err := ParseCloudInitUserData(value)
+	if err != nil {
+		t.Fatalf("ParseCloudInitUserData returned unexpected error: %v", err)
+	}
+
+	if payload.Format != CloudInitFormatMultipart {
+		t.Fatalf("Format = %q, want %q", payload.Format, CloudInitFormatMultipart)
+	}
+
+	if len(payload.Parts) != 2 {
+		t.Fatalf("len(Parts) = %d, want 2", len(payload.Parts))
+	}
+
+	if payload.Parts[0].Format != CloudInitFormatCloudConfig {
+		t.Errorf("Parts[0].Format = %q, want %q", payload.Parts[0].Format, CloudInitFormatCloudConfig)
+	}
+
+	if payload.Parts[1].Format != CloudInitFormatShellScript {
+		t.Errorf("Parts[1].Format = %q, want %q", payload.Parts[1].Format, CloudInitFormatShellScript)
+	}
+}
+
+func TestIsCloudInitUserData(t *testing.T) {
+	err := IsCloudInitUserData("#cloud-config\npackages:\n  - curl\n")
+	if err != nil {
+		t.Errorf("expected a valid cloud-config payload to pass, got: %v", err)
+	}
+
+	err = IsCloudInitUserData("#cloud-config\npackages: [curl\n")
+	if err == nil {
+		t.Error("expected malformed YAML in a cloud-config payload to fail")
+	}
+}
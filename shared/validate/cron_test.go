@@ -0,0 +1,118 @@
+package validate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2026, time.July, 27, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{
+			name: "single trigger",
+			expr: "0 0 * * *",
+			want: time.Date(2026, time.July, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "merges across comma-separated triggers",
+			expr: "0 12 * * *, 0 6 * * *",
+			want: time.Date(2026, time.July, 27, 6, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CronNext(tt.expr, from, nil)
+			if err != nil {
+				t.Fatalf("CronNext returned unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("CronNext(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextInvalidExpression(t *testing.T) {
+	_, err := CronNext("not a cron expr", time.Now(), nil)
+	if err == nil {
+		t.Fatal("expected an error for an invalid cron expression")
+	}
+}
+
+func TestCronSeries(t *testing.T) {
+	from := time.Date(2026, time.July, 27, 0, 0, 0, 0, time.UTC)
+
+	series, err := CronSeries("0 0 * * *", from, 3)
+	if err != nil {
+		t.Fatalf("CronSeries returned unexpected error: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2026, time.July, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.July, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.July, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	if len(series) != len(want) {
+		t.Fatalf("CronSeries returned %d entries, want %d", len(series), len(want))
+	}
+
+	for i, tick := range series {
+		if !tick.Equal(want[i]) {
+			t.Errorf("CronSeries()[%d] = %v, want %v", i, tick, want[i])
+		}
+	}
+}
+
+func TestCronMinInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want time.Duration
+	}{
+		{name: "hourly", expr: "0 * * * *", want: time.Hour},
+		{name: "every five minutes", expr: "*/5 * * * *", want: 5 * time.Minute},
+		{
+			// The gap between the two irregular monthly triggers isn't uniform (Jan has 31
+			// days, so 1st->15th is 14 days but 15th->1st is 17 days); the smallest of those
+			// gaps must win regardless of which one a naive single-sample check happens to see.
+			name: "irregular monthly triggers",
+			expr: "0 0 1 * *, 0 0 15 * *",
+			want: 14 * 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CronMinInterval(tt.expr)
+			if err != nil {
+				t.Fatalf("CronMinInterval returned unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("CronMinInterval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCronWithMinInterval(t *testing.T) {
+	validator := IsCronWithMinInterval(time.Hour, nil)
+
+	err := validator("0 * * * *")
+	if err != nil {
+		t.Errorf("expected hourly schedule to satisfy a 1 hour minimum, got: %v", err)
+	}
+
+	err = validator("*/5 * * * *")
+	if err == nil {
+		t.Error("expected a 5 minute schedule to fail a 1 hour minimum interval check")
+	}
+}
@@ -0,0 +1,117 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable machine-readable identifier for a validation failure, so callers (API
+// handlers, the CLI, the web UI) can distinguish failure kinds without string-matching the
+// rendered message.
+type ErrorCode string
+
+// Error codes returned by the validators in this package. New validators should reuse one of
+// these where it fits rather than minting a new code for every function.
+const (
+	ErrRequired          ErrorCode = "required"
+	ErrInvalidFormat     ErrorCode = "invalid_format"
+	ErrInvalidValue      ErrorCode = "invalid_value"
+	ErrNotOneOf          ErrorCode = "not_one_of"
+	ErrOutOfRange        ErrorCode = "out_of_range"
+	ErrInvalidCIDR       ErrorCode = "invalid_cidr"
+	ErrInvalidIP         ErrorCode = "invalid_ip"
+	ErrInvalidMAC        ErrorCode = "invalid_mac"
+	ErrDNSNotResolved    ErrorCode = "dns_not_resolved"
+	ErrCronParse         ErrorCode = "cron_parse"
+	ErrCompressorMissing ErrorCode = "compressor_missing"
+	ErrInvalidYAML       ErrorCode = "invalid_yaml"
+	ErrInvalidURL        ErrorCode = "invalid_url"
+	ErrTooLong           ErrorCode = "too_long"
+	ErrDurationTooShort  ErrorCode = "duration_too_short"
+)
+
+// Error is the structured error type returned by the validators in this package. It carries a
+// stable Code alongside the offending Value and any Params a catalog-based translation needs to
+// re-render the message (e.g. Min/Max for ErrOutOfRange, Allowed for ErrNotOneOf), so a caller can
+// react to or re-render the failure without parsing English text out of Error().
+type Error struct {
+	Code   ErrorCode
+	Value  string
+	Field  string
+	Params map[string]any
+
+	msg string // English fallback, used verbatim unless a Translator is installed.
+}
+
+// Error implements the error interface, rendering the Translator's message if one is installed,
+// falling back to the English message the validator constructed it with otherwise.
+func (e *Error) Error() string {
+	msg := e.msg
+
+	if translator != nil {
+		translated, ok := translator(e.Code, e.Params)
+		if ok {
+			msg = translated
+		}
+	}
+
+	if e.Field != "" {
+		return fmt.Sprintf("%s: %s", e.Field, msg)
+	}
+
+	return msg
+}
+
+// withField returns a copy of e scoped to field, composing so that a validator combined into a
+// larger struct (e.g. a device's config map) can report exactly which key failed.
+func (e *Error) withField(field string) *Error {
+	clone := *e
+	if clone.Field != "" {
+		clone.Field = field + "." + clone.Field
+	} else {
+		clone.Field = field
+	}
+
+	return &clone
+}
+
+// newError builds a validate.Error carrying code, the offending value, its params and the English
+// fallback message, matching the historic fmt.Errorf/errors.New text so existing callers that
+// display Error() verbatim see no change.
+func newError(code ErrorCode, value string, params map[string]any, msg string) *Error {
+	return &Error{Code: code, Value: value, Params: params, msg: msg}
+}
+
+// Translator renders a catalog message for code given its params, returning ok=false to fall back
+// to the validator's baked-in English message. Install one with SetTranslator to localize every
+// message produced by this package without touching individual validators.
+type Translator func(code ErrorCode, params map[string]any) (message string, ok bool)
+
+var translator Translator
+
+// SetTranslator installs the catalog used to render validate.Error messages, or clears it back to
+// the built-in English fallback if t is nil.
+func SetTranslator(t Translator) {
+	translator = t
+}
+
+// Field wraps validator so that any validate.Error it returns is scoped to path, for use when a
+// validator is applied to one field of a larger structure (e.g. one key of a device's config map).
+// Errors that aren't a *validate.Error (from a validator this package doesn't own) are wrapped
+// as-is with just the field set.
+func Field(path string, validator func(value string) error) func(value string) error {
+	return func(value string) error {
+		err := validator(value)
+		if err == nil {
+			return nil
+		}
+
+		var verr *Error
+
+		if errors.As(err, &verr) {
+			return verr.withField(path)
+		}
+
+		return &Error{Field: path, msg: err.Error()}
+	}
+}
@@ -2,14 +2,22 @@ package validate
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net"
+	"net/mail"
+	"net/netip"
 	"net/url"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -48,7 +56,7 @@ func Or(validators ...func(value string) error) func(value string) error {
 			}
 		}
 
-		return fmt.Errorf("%q isn't a valid value", value)
+		return newError(ErrInvalidValue, value, nil, fmt.Sprintf("%q isn't a valid value", value))
 	}
 }
 
@@ -72,7 +80,7 @@ func Optional(validators ...func(value string) error) func(value string) error {
 func IsInt64(value string) error {
 	_, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return fmt.Errorf("Invalid value for an integer %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for an integer %q", value))
 	}
 
 	return nil
@@ -82,7 +90,7 @@ func IsInt64(value string) error {
 func IsUint8(value string) error {
 	_, err := strconv.ParseUint(value, 10, 8)
 	if err != nil {
-		return fmt.Errorf("Invalid value for an integer %q. Must be between 0 and 255", value)
+		return newError(ErrOutOfRange, value, map[string]any{"min": 0, "max": 255}, fmt.Sprintf("Invalid value for an integer %q. Must be between 0 and 255", value))
 	}
 
 	return nil
@@ -92,7 +100,7 @@ func IsUint8(value string) error {
 func IsUint32(value string) error {
 	_, err := strconv.ParseUint(value, 10, 32)
 	if err != nil {
-		return fmt.Errorf("Invalid value for uint32 %q: %w", value, err)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for uint32 %q: %s", value, err))
 	}
 
 	return nil
@@ -101,12 +109,12 @@ func IsUint32(value string) error {
 // IsWWN validates whether the string can be converted to a uint64 WWN.
 func IsWWN(value string) error {
 	if !strings.HasPrefix(value, "0x") {
-		return fmt.Errorf("Invalid value for a WWN %q: Missing expected 0x prefix", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for a WWN %q: Missing expected 0x prefix", value))
 	}
 
 	_, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
 	if err != nil {
-		return fmt.Errorf("Invalid value for a WWN %q: %w", value, err)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for a WWN %q: %s", value, err))
 	}
 
 	return nil
@@ -123,11 +131,11 @@ func IsInRange(minValue int64, maxValue int64) func(value string) error {
 	return func(value string) error {
 		valueInt, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("Invalid value for an integer %q", value)
+			return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for an integer %q", value))
 		}
 
 		if valueInt < minValue || valueInt > maxValue {
-			return fmt.Errorf("Value isn't within valid range. Must be between %d and %d", minValue, maxValue)
+			return newError(ErrOutOfRange, value, map[string]any{"min": minValue, "max": maxValue}, fmt.Sprintf("Value isn't within valid range. Must be between %d and %d", minValue, maxValue))
 		}
 
 		return nil
@@ -138,11 +146,11 @@ func IsInRange(minValue int64, maxValue int64) func(value string) error {
 func IsPriority(value string) error {
 	valueInt, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return fmt.Errorf("Invalid value for an integer %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for an integer %q", value))
 	}
 
 	if valueInt < 0 || valueInt > 10 {
-		return fmt.Errorf("Invalid value for a limit %q. Must be between 0 and 10", value)
+		return newError(ErrOutOfRange, value, map[string]any{"min": 0, "max": 10}, fmt.Sprintf("Invalid value for a limit %q. Must be between 0 and 10", value))
 	}
 
 	return nil
@@ -151,7 +159,7 @@ func IsPriority(value string) error {
 // IsBool validates if string can be understood as a bool.
 func IsBool(value string) error {
 	if !slices.Contains([]string{"true", "false", "yes", "no", "1", "0", "on", "off"}, strings.ToLower(value)) {
-		return fmt.Errorf("Invalid value for a boolean %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid value for a boolean %q", value))
 	}
 
 	return nil
@@ -161,7 +169,7 @@ func IsBool(value string) error {
 func IsOneOf(valid ...string) func(value string) error {
 	return func(value string) error {
 		if !slices.Contains(valid, value) {
-			return fmt.Errorf("Invalid value %q (not one of %s)", value, valid)
+			return newError(ErrNotOneOf, value, map[string]any{"allowed": valid}, fmt.Sprintf("Invalid value %q (not one of %s)", value, valid))
 		}
 
 		return nil
@@ -173,15 +181,17 @@ func IsAny(_ string) error {
 	return nil
 }
 
-// IsListOf returns a validator for a comma separated list of values.
+// IsListOf returns a validator for a comma separated list of values. A failure from validator is
+// decorated with the index of the offending item (e.g. "item 2: ...") via Field, so the structured
+// error composes through the list the same way it would through a single field.
 func IsListOf(validator func(value string) error) func(value string) error {
 	return func(value string) error {
-		for _, v := range strings.Split(value, ",") {
+		for i, v := range strings.Split(value, ",") {
 			v = strings.TrimSpace(v)
 
-			err := validator(v)
+			err := Field(fmt.Sprintf("item %d", i), validator)(v)
 			if err != nil {
-				return fmt.Errorf("Item %q: %w", v, err)
+				return err
 			}
 		}
 
@@ -192,7 +202,7 @@ func IsListOf(validator func(value string) error) func(value string) error {
 // IsNotEmpty requires a non-empty string.
 func IsNotEmpty(value string) error {
 	if value == "" {
-		return errors.New("Required value")
+		return newError(ErrRequired, value, nil, "Required value")
 	}
 
 	return nil
@@ -212,7 +222,7 @@ func IsSize(value string) error {
 func IsDeviceID(value string) error {
 	match, _ := regexp.MatchString(`^[0-9a-f]{4}$`, value)
 	if !match {
-		return errors.New("Invalid value, must be four lower case hex characters")
+		return newError(ErrInvalidFormat, value, nil, "Invalid value, must be four lower case hex characters")
 	}
 
 	return nil
@@ -222,17 +232,17 @@ func IsDeviceID(value string) error {
 func IsInterfaceName(value string) error {
 	// Validate the length.
 	if len(value) < 2 {
-		return errors.New("Network interface is too short (minimum 2 characters)")
+		return newError(ErrOutOfRange, value, map[string]any{"min": 2}, "Network interface is too short (minimum 2 characters)")
 	}
 
 	if len(value) > 15 {
-		return errors.New("Network interface is too long (maximum 15 characters)")
+		return newError(ErrOutOfRange, value, map[string]any{"max": 15}, "Network interface is too long (maximum 15 characters)")
 	}
 
 	// Validate the character set.
 	match, _ := regexp.MatchString(`^[-_a-zA-Z0-9.]+$`, value)
 	if !match {
-		return errors.New("Network interface contains invalid characters")
+		return newError(ErrInvalidFormat, value, nil, "Network interface contains invalid characters")
 	}
 
 	return nil
@@ -251,7 +261,7 @@ func IsNetworkName(value string) error {
 	}
 
 	if strings.Contains(value, ":") {
-		return fmt.Errorf("Cannot contain %q", ":")
+		return newError(ErrInvalidFormat, value, map[string]any{"char": ":"}, fmt.Sprintf("Cannot contain %q", ":"))
 	}
 
 	return nil
@@ -263,7 +273,7 @@ func IsNetworkMAC(value string) error {
 
 	// Check is valid Ethernet MAC length and delimiter.
 	if err != nil || len(value) != 17 || strings.ContainsAny(value, "-.") {
-		return errors.New("Invalid MAC address, must be 6 bytes of hex separated by colons")
+		return newError(ErrInvalidMAC, value, nil, "Invalid MAC address, must be 6 bytes of hex separated by colons")
 	}
 
 	return nil
@@ -273,7 +283,7 @@ func IsNetworkMAC(value string) error {
 func IsNetworkAddress(value string) error {
 	ip := net.ParseIP(value)
 	if ip == nil {
-		return fmt.Errorf("Not an IP address %q", value)
+		return newError(ErrInvalidIP, value, nil, fmt.Sprintf("Not an IP address %q", value))
 	}
 
 	return nil
@@ -283,11 +293,11 @@ func IsNetworkAddress(value string) error {
 func IsNetwork(value string) error {
 	ip, subnet, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	if ip.String() != subnet.IP.String() {
-		return fmt.Errorf("Not an IP network address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IP network address %q", value))
 	}
 
 	return nil
@@ -297,7 +307,7 @@ func IsNetwork(value string) error {
 func IsNetworkAddressCIDR(value string) error {
 	_, _, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	return nil
@@ -307,25 +317,25 @@ func IsNetworkAddressCIDR(value string) error {
 func IsNetworkRange(value string) error {
 	ips := strings.SplitN(value, "-", 2)
 	if len(ips) != 2 {
-		return errors.New("IP range must contain start and end IP addresses")
+		return newError(ErrInvalidFormat, value, nil, "IP range must contain start and end IP addresses")
 	}
 
 	startIP := net.ParseIP(ips[0])
 	if startIP == nil {
-		return fmt.Errorf("Start not an IP address %q", ips[0])
+		return newError(ErrInvalidIP, ips[0], nil, fmt.Sprintf("Start not an IP address %q", ips[0]))
 	}
 
 	endIP := net.ParseIP(ips[1])
 	if endIP == nil {
-		return fmt.Errorf("End not an IP address %q", ips[1])
+		return newError(ErrInvalidIP, ips[1], nil, fmt.Sprintf("End not an IP address %q", ips[1]))
 	}
 
 	if (startIP.To4() != nil) != (endIP.To4() != nil) {
-		return errors.New("Start and end IP addresses are not in same family")
+		return newError(ErrInvalidValue, value, nil, "Start and end IP addresses are not in same family")
 	}
 
 	if bytes.Compare(startIP, endIP) > 0 {
-		return errors.New("Start IP address must be before or equal to end IP address")
+		return newError(ErrInvalidValue, value, nil, "Start IP address must be before or equal to end IP address")
 	}
 
 	return nil
@@ -335,15 +345,15 @@ func IsNetworkRange(value string) error {
 func IsNetworkV4(value string) error {
 	ip, subnet, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	if ip.To4() == nil {
-		return fmt.Errorf("Not an IPv4 network %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv4 network %q", value))
 	}
 
 	if ip.String() != subnet.IP.String() {
-		return fmt.Errorf("Not an IPv4 network address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv4 network address %q", value))
 	}
 
 	return nil
@@ -353,7 +363,7 @@ func IsNetworkV4(value string) error {
 func IsNetworkAddressV4(value string) error {
 	ip := net.ParseIP(value)
 	if ip == nil || ip.To4() == nil {
-		return fmt.Errorf("Not an IPv4 address %q", value)
+		return newError(ErrInvalidIP, value, nil, fmt.Sprintf("Not an IPv4 address %q", value))
 	}
 
 	return nil
@@ -363,15 +373,15 @@ func IsNetworkAddressV4(value string) error {
 func IsNetworkAddressCIDRV4(value string) error {
 	ip, subnet, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	if ip.To4() == nil {
-		return fmt.Errorf("Not an IPv4 address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv4 address %q", value))
 	}
 
 	if ip.String() == subnet.IP.String() {
-		return fmt.Errorf("Not a usable IPv4 address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not a usable IPv4 address %q", value))
 	}
 
 	return nil
@@ -381,7 +391,7 @@ func IsNetworkAddressCIDRV4(value string) error {
 func IsNetworkRangeV4(value string) error {
 	ips := strings.SplitN(value, "-", 2)
 	if len(ips) != 2 {
-		return errors.New("IP range must contain start and end IP addresses")
+		return newError(ErrInvalidFormat, value, nil, "IP range must contain start and end IP addresses")
 	}
 
 	for _, ip := range ips {
@@ -398,15 +408,15 @@ func IsNetworkRangeV4(value string) error {
 func IsNetworkV6(value string) error {
 	ip, subnet, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	if ip == nil || ip.To4() != nil {
-		return fmt.Errorf("Not an IPv6 network %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv6 network %q", value))
 	}
 
 	if ip.String() != subnet.IP.String() {
-		return fmt.Errorf("Not an IPv6 network address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv6 network address %q", value))
 	}
 
 	return nil
@@ -416,7 +426,7 @@ func IsNetworkV6(value string) error {
 func IsNetworkAddressV6(value string) error {
 	ip := net.ParseIP(value)
 	if ip == nil || ip.To4() != nil {
-		return fmt.Errorf("Not an IPv6 address %q", value)
+		return newError(ErrInvalidIP, value, nil, fmt.Sprintf("Not an IPv6 address %q", value))
 	}
 
 	return nil
@@ -426,15 +436,15 @@ func IsNetworkAddressV6(value string) error {
 func IsNetworkAddressCIDRV6(value string) error {
 	ip, subnet, err := net.ParseCIDR(value)
 	if err != nil {
-		return err
+		return newError(ErrInvalidCIDR, value, nil, err.Error())
 	}
 
 	if ip.To4() != nil {
-		return fmt.Errorf("Not an IPv6 address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not an IPv6 address %q", value))
 	}
 
 	if ip.String() == subnet.IP.String() {
-		return fmt.Errorf("Not a usable IPv6 address %q", value)
+		return newError(ErrInvalidCIDR, value, nil, fmt.Sprintf("Not a usable IPv6 address %q", value))
 	}
 
 	return nil
@@ -444,7 +454,7 @@ func IsNetworkAddressCIDRV6(value string) error {
 func IsNetworkRangeV6(value string) error {
 	ips := strings.SplitN(value, "-", 2)
 	if len(ips) != 2 {
-		return errors.New("IP range must contain start and end IP addresses")
+		return newError(ErrInvalidFormat, value, nil, "IP range must contain start and end IP addresses")
 	}
 
 	for _, ip := range ips {
@@ -461,27 +471,52 @@ func IsNetworkRangeV6(value string) error {
 func IsNetworkVLAN(value string) error {
 	vlanID, err := strconv.Atoi(value)
 	if err != nil {
-		return fmt.Errorf("Invalid VLAN ID %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid VLAN ID %q", value))
 	}
 
 	if vlanID < 0 || vlanID > 4094 {
-		return fmt.Errorf("Out of VLAN ID range (0-4094) %q", value)
+		return newError(ErrOutOfRange, value, map[string]any{"min": 0, "max": 4094}, fmt.Sprintf("Out of VLAN ID range (0-4094) %q", value))
 	}
 
 	return nil
 }
 
+// IsNetworkPattern validates value as an ACL-style address pattern: `*` (wildcard, both
+// families), `*4` or `*6` (wildcard restricted to one family), a single IP address, a CIDR
+// network, or an IP range in the format "start-end". It accepts exactly the syntax
+// github.com/lxc/incus/v6/shared/network/netmatch knows how to match, so a validator and the
+// matcher it feeds never drift apart.
+func IsNetworkPattern(value string) error {
+	if value == "*" || value == "*4" || value == "*6" {
+		return nil
+	}
+
+	if IsNetworkAddress(value) == nil {
+		return nil
+	}
+
+	if IsNetworkAddressCIDR(value) == nil {
+		return nil
+	}
+
+	if IsNetworkRange(value) == nil {
+		return nil
+	}
+
+	return newError(ErrInvalidValue, value, nil, fmt.Sprintf("%q isn't a wildcard, IP address, CIDR or range", value))
+}
+
 // IsNetworkMTU validates MTU number >= 1280 and <= 16384.
 // Anything below 68 and the kernel doesn't allow IPv4, anything below 1280 and the kernel doesn't allow IPv6.
 // So require an IPv6-compatible MTU as the low value and cap at the max ethernet jumbo frame size.
 func IsNetworkMTU(value string) error {
 	mtu, err := strconv.ParseUint(value, 10, 32)
 	if err != nil {
-		return fmt.Errorf("Invalid MTU %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid MTU %q", value))
 	}
 
 	if mtu < 1280 || mtu > 16384 {
-		return fmt.Errorf("Out of MTU range (1280-16384) %q", value)
+		return newError(ErrOutOfRange, value, map[string]any{"min": 1280, "max": 16384}, fmt.Sprintf("Out of MTU range (1280-16384) %q", value))
 	}
 
 	return nil
@@ -491,11 +526,11 @@ func IsNetworkMTU(value string) error {
 func IsNetworkPort(value string) error {
 	port, err := strconv.ParseUint(value, 10, 32)
 	if err != nil {
-		return fmt.Errorf("Invalid port number %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid port number %q", value))
 	}
 
 	if port > 65535 {
-		return fmt.Errorf("Out of port number range (0-65535) %q", value)
+		return newError(ErrOutOfRange, value, map[string]any{"min": 0, "max": 65535}, fmt.Sprintf("Out of port number range (0-65535) %q", value))
 	}
 
 	return nil
@@ -506,22 +541,22 @@ func IsNetworkPortRange(value string) error {
 	ports := strings.SplitN(value, "-", 2)
 	portsLen := len(ports)
 	if portsLen != 1 && portsLen != 2 {
-		return errors.New("Port range must contain either a single port or start and end port numbers")
+		return newError(ErrInvalidFormat, value, nil, "Port range must contain either a single port or start and end port numbers")
 	}
 
 	startPort, err := strconv.ParseUint(ports[0], 10, 32)
 	if err != nil {
-		return fmt.Errorf("Invalid port number %q", value)
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid port number %q", value))
 	}
 
 	if portsLen == 2 {
 		endPort, err := strconv.ParseUint(ports[1], 10, 32)
 		if err != nil {
-			return fmt.Errorf("Invalid end port number %q", value)
+			return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid end port number %q", value))
 		}
 
 		if startPort >= endPort {
-			return fmt.Errorf("Start port %d must be lower than end port %d", startPort, endPort)
+			return newError(ErrInvalidValue, value, map[string]any{"start": startPort, "end": endPort}, fmt.Sprintf("Start port %d must be lower than end port %d", startPort, endPort))
 		}
 	}
 
@@ -546,7 +581,31 @@ func IsDHCPRouteList(value string) error {
 	}
 
 	if len(parts)%2 != 0 { // uneven number of parts means the gateway of the last route is missing
-		return fmt.Errorf("missing gateway for route %v", parts[len(parts)-1])
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("missing gateway for route %v", parts[len(parts)-1]))
+	}
+
+	return nil
+}
+
+// IsDHCPv6RouteList validates a comma-separated list of alternating CIDR networks and IPv6 addresses.
+func IsDHCPv6RouteList(value string) error {
+	parts := strings.Split(value, ",")
+	for i, s := range parts {
+		// routes are pairs of subnet and gateway
+		var err error
+		if i%2 == 0 { // subnet part
+			err = IsNetworkV6(s)
+		} else { // gateway part
+			err = IsNetworkAddressV6(s)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(parts)%2 != 0 { // uneven number of parts means the gateway of the last route is missing
+		return newError(ErrInvalidFormat, value, nil, fmt.Sprintf("missing gateway for route %v", parts[len(parts)-1]))
 	}
 
 	return nil
@@ -556,7 +615,7 @@ func IsDHCPRouteList(value string) error {
 func IsURLSegmentSafe(value string) error {
 	for _, char := range []string{"/", "?", "&", "+"} {
 		if strings.Contains(value, char) {
-			return fmt.Errorf("Cannot contain %q", char)
+			return newError(ErrInvalidFormat, value, map[string]any{"char": char}, fmt.Sprintf("Cannot contain %q", char))
 		}
 	}
 
@@ -567,7 +626,7 @@ func IsURLSegmentSafe(value string) error {
 func IsUUID(value string) error {
 	_, err := uuid.Parse(value)
 	if err != nil {
-		return errors.New("Invalid UUID")
+		return newError(ErrInvalidFormat, value, nil, "Invalid UUID")
 	}
 
 	return nil
@@ -577,31 +636,161 @@ func IsUUID(value string) error {
 func IsPCIAddress(value string) error {
 	match, _ := regexp.MatchString(`^(?:[0-9a-fA-F]{4}:)?[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-9a-fA-F]$`, value)
 	if !match {
-		return errors.New("Invalid PCI address")
+		return newError(ErrInvalidFormat, value, nil, "Invalid PCI address")
 	}
 
 	return nil
 }
 
-// IsCompressionAlgorithm validates whether a value is a valid compression algorithm and is available on the system.
-func IsCompressionAlgorithm(value string) error {
-	if value == "none" {
+// CompressionAlgorithmInfo describes one entry of the CompressionAlgorithms registry, so callers
+// that need to act on a compression algorithm (the image import code picking a decompressor, the
+// docs generator rendering allowed values) don't have to duplicate this mapping themselves.
+type CompressionAlgorithmInfo struct {
+	// Binary is the executable used to compress with this algorithm.
+	Binary string
+
+	// DecompressBinary is the executable used to decompress data produced by Binary.
+	DecompressBinary string
+
+	// MIMEType is the MIME type data compressed with this algorithm is served as.
+	MIMEType string
+
+	// Magic is the leading file magic bytes identifying data compressed with this algorithm, or
+	// nil if the algorithm has none (e.g. "none" itself).
+	Magic []byte
+
+	// AllowedFlags matches a single flag token this algorithm's Binary may be invoked with. A nil
+	// AllowedFlags means the algorithm doesn't accept any flags.
+	AllowedFlags *regexp.Regexp
+}
+
+// CompressionAlgorithms is the registry of compression algorithms IsCompressionAlgorithmName and
+// CompressionAlgorithmAvailable validate against.
+var CompressionAlgorithms = map[string]CompressionAlgorithmInfo{
+	"none": {},
+	"gzip": {
+		Binary:           "gzip",
+		DecompressBinary: "gunzip",
+		MIMEType:         "application/gzip",
+		Magic:            []byte{0x1f, 0x8b},
+		AllowedFlags:     regexp.MustCompile(`^-[1-9]$`),
+	},
+	"bzip2": {
+		Binary:           "bzip2",
+		DecompressBinary: "bunzip2",
+		MIMEType:         "application/x-bzip2",
+		Magic:            []byte("BZh"),
+		AllowedFlags:     regexp.MustCompile(`^-[1-9]$`),
+	},
+	"xz": {
+		Binary:           "xz",
+		DecompressBinary: "unxz",
+		MIMEType:         "application/x-xz",
+		Magic:            []byte{0xfd, '7', 'z', 'X', 'Z', 0x00},
+		AllowedFlags:     regexp.MustCompile(`^-(?:[0-9]|-extreme)$`),
+	},
+	"lzma": {
+		Binary:           "lzma",
+		DecompressBinary: "unlzma",
+		MIMEType:         "application/x-lzma",
+		Magic:            []byte{0x5d, 0x00, 0x00},
+	},
+	"zstd": {
+		Binary:           "zstd",
+		DecompressBinary: "zstd",
+		MIMEType:         "application/zstd",
+		Magic:            []byte{0x28, 0xb5, 0x2f, 0xfd},
+		AllowedFlags:     regexp.MustCompile(`^-(?:1?[0-9]|2[0-2]|-long(?:=[0-9]+)?)$`),
+	},
+	"lz4": {
+		Binary:           "lz4",
+		DecompressBinary: "lz4",
+		MIMEType:         "application/x-lz4",
+		Magic:            []byte{0x04, 0x22, 0x4d, 0x18},
+		AllowedFlags:     regexp.MustCompile(`^-(?:[1-9]|-fast(?:=[0-9]+)?)$`),
+	},
+	"squashfs": {
+		Binary:           "tar2sqfs",
+		DecompressBinary: "sqfs2tar",
+		MIMEType:         "application/vnd.squashfs",
+		Magic:            []byte{'h', 's', 'q', 's'},
+	},
+}
+
+// compressionAlgorithmNames returns the sorted registry keys, for use in "not one of" error params.
+func compressionAlgorithmNames() []string {
+	names := make([]string, 0, len(CompressionAlgorithms))
+	for name := range CompressionAlgorithms {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// IsCompressionAlgorithmName validates value as a compression algorithm name, optionally followed
+// by flags, purely syntactically against the CompressionAlgorithms registry. Unlike
+// IsCompressionAlgorithm it never shells out, so a config can be validated on a controller node
+// that will actually run it on a worker with a different set of binaries installed, and unit tests
+// don't need those tools present.
+func IsCompressionAlgorithmName(value string) error {
+	fields, err := shellquote.Split(value)
+	if err != nil {
+		return newError(ErrInvalidFormat, value, nil, err.Error())
+	}
+
+	if len(fields) == 0 {
+		return newError(ErrInvalidFormat, value, nil, "Empty compression algorithm")
+	}
+
+	name := fields[0]
+
+	info, ok := CompressionAlgorithms[name]
+	if !ok {
+		return newError(ErrNotOneOf, value, map[string]any{"allowed": compressionAlgorithmNames()}, fmt.Sprintf("Unknown compression algorithm %q", name))
+	}
+
+	for _, flag := range fields[1:] {
+		if info.AllowedFlags == nil || !info.AllowedFlags.MatchString(flag) {
+			return newError(ErrInvalidValue, value, map[string]any{"flag": flag}, fmt.Sprintf("Flag %q isn't allowed for compression algorithm %q", flag, name))
+		}
+	}
+
+	return nil
+}
+
+// CompressionAlgorithmAvailable checks that the binary for compression algorithm name is
+// available, using lookup (typically exec.LookPath) to probe for it. It's kept separate from the
+// syntactic check so the probe can run against a different PATH/root than the one doing
+// validation, and so it can be stubbed out in tests.
+func CompressionAlgorithmAvailable(name string, lookup func(string) (string, error)) error {
+	info, ok := CompressionAlgorithms[name]
+	if !ok || info.Binary == "" {
 		return nil
 	}
 
-	// Going to look up tar2sqfs executable binary
-	if value == "squashfs" {
-		value = "tar2sqfs"
+	_, err := lookup(info.Binary)
+	if err != nil {
+		return newError(ErrCompressorMissing, name, map[string]any{"command": info.Binary}, err.Error())
 	}
 
-	// Parse the command.
-	fields, err := shellquote.Split(value)
+	return nil
+}
+
+// IsCompressionAlgorithm validates whether a value is a valid compression algorithm and is available on the system.
+func IsCompressionAlgorithm(value string) error {
+	err := IsCompressionAlgorithmName(value)
 	if err != nil {
 		return err
 	}
 
-	_, err = exec.LookPath(fields[0])
-	return err
+	fields, err := shellquote.Split(value)
+	if err != nil {
+		return newError(ErrInvalidFormat, value, nil, err.Error())
+	}
+
+	return CompressionAlgorithmAvailable(fields[0], exec.LookPath)
 }
 
 // IsArchitecture validates whether the value is a valid architecture name.
@@ -622,7 +811,7 @@ func IsCron(aliases []string) func(value string) error {
 				return nil
 			}
 
-			return fmt.Errorf("Error parsing cron expr: %s", value)
+			return newError(ErrCronParse, value, nil, fmt.Sprintf("Error parsing cron expr: %s", value))
 		}
 
 		// Can be comma+space separated (just commas are valid cron pattern).
@@ -639,51 +828,329 @@ func IsCron(aliases []string) func(value string) error {
 	}
 }
 
+// cronTriggers splits a cron value into its comma+space separated triggers, lower-cased the same
+// way IsCron does (a bare "," is a valid cron field separator, so only ", " splits triggers apart).
+func cronTriggers(expr string) []string {
+	return strings.Split(strings.ToLower(expr), ", ")
+}
+
+// CronNext returns the earliest time at or after from that expr fires, across all of its
+// comma+space separated triggers.
+func CronNext(expr string, from time.Time, tz *time.Location) (time.Time, error) {
+	if tz != nil {
+		from = from.In(tz)
+	}
+
+	var next time.Time
+
+	for _, trigger := range cronTriggers(expr) {
+		tick, err := gronx.NextTickAfter(trigger, from, true)
+		if err != nil {
+			return time.Time{}, newError(ErrCronParse, trigger, nil, fmt.Sprintf("Error parsing cron expr: %s", trigger))
+		}
+
+		if next.IsZero() || tick.Before(next) {
+			next = tick
+		}
+	}
+
+	return next, nil
+}
+
+// CronPrev returns the latest time at or before from that expr fired, across all of its
+// comma+space separated triggers.
+func CronPrev(expr string, from time.Time, tz *time.Location) (time.Time, error) {
+	if tz != nil {
+		from = from.In(tz)
+	}
+
+	var prev time.Time
+
+	for _, trigger := range cronTriggers(expr) {
+		tick, err := gronx.PrevTickBefore(trigger, from, true)
+		if err != nil {
+			return time.Time{}, newError(ErrCronParse, trigger, nil, fmt.Sprintf("Error parsing cron expr: %s", trigger))
+		}
+
+		if prev.IsZero() || tick.After(prev) {
+			prev = tick
+		}
+	}
+
+	return prev, nil
+}
+
+// CronSeries returns the next n times (in order, starting at or after from) that expr fires,
+// merging across all of its comma+space separated triggers.
+func CronSeries(expr string, from time.Time, n int) ([]time.Time, error) {
+	series := make([]time.Time, 0, n)
+
+	next := from
+
+	for range n {
+		tick, err := CronNext(expr, next, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		series = append(series, tick)
+		next = tick.Add(time.Second)
+	}
+
+	return series, nil
+}
+
+// cronMinIntervalSamples bounds how many consecutive fires CronMinInterval walks. It needs to be
+// large enough to observe every distinct gap a schedule can produce, including irregular ones like
+// "0 0 1 * *, 0 0 15 * *" or a day-of-week list such as "mon,wed" — a couple of fires isn't enough,
+// since the gap between just the first two depends on when validation happens to run.
+const cronMinIntervalSamples = 1000
+
+// CronMinInterval walks cronMinIntervalSamples consecutive fires of expr and returns the smallest
+// gap between any two of them. Operators use this to forbid schedules (e.g. user-supplied
+// snapshot/backup cron strings) that would fire more often than an administrator-configured
+// minimum interval.
+func CronMinInterval(expr string) (time.Duration, error) {
+	series, err := CronSeries(expr, time.Now(), cronMinIntervalSamples)
+	if err != nil {
+		return 0, err
+	}
+
+	minGap := series[1].Sub(series[0])
+
+	for i := 1; i < len(series)-1; i++ {
+		gap := series[i+1].Sub(series[i])
+		if gap < minGap {
+			minGap = gap
+		}
+	}
+
+	return minGap, nil
+}
+
+// IsCronWithMinInterval returns a validator like IsCron, additionally rejecting any expression
+// whose minimum interval between fires is shorter than min.
+func IsCronWithMinInterval(minInterval time.Duration, aliases []string) func(value string) error {
+	isCron := IsCron(aliases)
+
+	return func(value string) error {
+		err := isCron(value)
+		if err != nil {
+			return err
+		}
+
+		interval, err := CronMinInterval(value)
+		if err != nil {
+			return err
+		}
+
+		if interval < minInterval {
+			return newError(ErrDurationTooShort, value, map[string]any{"min": minInterval.String()}, fmt.Sprintf("Schedule %q fires more often than the minimum interval of %s", value, minInterval))
+		}
+
+		return nil
+	}
+}
+
 // IsListenAddress returns a validator for a listen address.
+//
+// Deprecated: this only validates syntax and throws away the resolved address. Callers that go on
+// to actually bind the address, enforce a single IP family, or reject link-local/multicast targets
+// should use ResolveListenAddress instead.
 func IsListenAddress(allowDNS bool, allowWildcard bool, requirePort bool) func(value string) error {
+	resolve := ResolveListenAddress(ResolveOptions{
+		AllowDNS:      allowDNS,
+		AllowWildcard: allowWildcard,
+		RequirePort:   requirePort,
+	})
+
 	return func(value string) error {
+		_, err := resolve(value)
+		return err
+	}
+}
+
+// AddressFamily restricts which IP family ResolveListenAddress will return.
+type AddressFamily int
+
+const (
+	// AddressFamilyAny accepts both IPv4 and IPv6 addresses.
+	AddressFamilyAny AddressFamily = iota
+
+	// AddressFamilyV4 restricts resolution to IPv4 addresses.
+	AddressFamilyV4
+
+	// AddressFamilyV6 restricts resolution to IPv6 addresses.
+	AddressFamilyV6
+)
+
+// Resolver is the subset of net's host resolution used by ResolveListenAddress, so tests and the
+// cluster code can inject a fake resolver rather than depending on the real DNS.
+type Resolver interface {
+	LookupHost(host string) ([]string, error)
+}
+
+// netResolver implements Resolver on top of net.LookupHost.
+type netResolver struct{}
+
+func (netResolver) LookupHost(host string) ([]string, error) {
+	return net.LookupHost(host)
+}
+
+// ResolveOptions configures ResolveListenAddress.
+type ResolveOptions struct {
+	// AllowDNS allows value's host part to be a DNS name requiring a lookup, rather than only a
+	// literal IP or wildcard.
+	AllowDNS bool
+
+	// AllowWildcard allows value's host part to be a wildcard address ("", "::", "[::]" or "0.0.0.0").
+	AllowWildcard bool
+
+	// RequirePort requires value to include a port.
+	RequirePort bool
+
+	// Families restricts which IP family is returned. Defaults to AddressFamilyAny.
+	Families AddressFamily
+
+	// ExcludeLoopback rejects loopback addresses (127.0.0.0/8, ::1).
+	ExcludeLoopback bool
+
+	// ExcludeLinkLocal rejects link-local addresses (169.254.0.0/16, fe80::/10).
+	ExcludeLinkLocal bool
+
+	// ExcludeMulticast rejects multicast addresses.
+	ExcludeMulticast bool
+
+	// Resolver performs the DNS lookup for AllowDNS. Defaults to net.LookupHost.
+	Resolver Resolver
+}
+
+// ResolveListenAddress validates value the same way IsListenAddress does, but rather than
+// discarding the answer it returns the concrete addresses value resolves to, so a caller doesn't
+// have to repeat the resolution to actually bind. Unlike IsListenAddress it can also enforce a
+// single IP family and reject loopback/link-local/multicast targets via opts.
+func ResolveListenAddress(opts ResolveOptions) func(value string) ([]netip.AddrPort, error) {
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = netResolver{}
+	}
+
+	return func(value string) ([]netip.AddrPort, error) {
 		// Validate address format and port.
-		host, _, err := net.SplitHostPort(value)
+		var port uint16
+
+		host, portStr, err := net.SplitHostPort(value)
 		if err != nil {
-			if requirePort {
-				return errors.New("A port is required as part of the address")
+			if opts.RequirePort {
+				return nil, newError(ErrInvalidFormat, value, nil, "A port is required as part of the address")
 			}
 
 			host = value
+		} else {
+			// Best-effort only; IsListenAddress never validated the port itself and callers that
+			// pass a non-numeric port (e.g. a service name) expect that to keep working.
+			p, err := strconv.ParseUint(portStr, 10, 16)
+			if err == nil {
+				port = uint16(p)
+			}
 		}
 
 		// Validate wildcard.
 		if slices.Contains([]string{"", "::", "[::]", "0.0.0.0"}, host) {
-			if !allowWildcard {
-				return errors.New("Wildcard addresses aren't allowed")
+			if !opts.AllowWildcard {
+				return nil, newError(ErrInvalidValue, value, nil, "Wildcard addresses aren't allowed")
 			}
 
-			return nil
+			var addrs []netip.AddrPort
+			if opts.Families != AddressFamilyV6 {
+				addrs = append(addrs, netip.AddrPortFrom(netip.IPv4Unspecified(), port))
+			}
+
+			if opts.Families != AddressFamilyV4 {
+				addrs = append(addrs, netip.AddrPortFrom(netip.IPv6Unspecified(), port))
+			}
+
+			return addrs, nil
 		}
 
-		// Validate DNS.
-		ip := net.ParseIP(strings.Trim(host, "[]"))
-		if ip != nil {
-			return nil
+		// Validate literal IP.
+		ip, err := netip.ParseAddr(strings.Trim(host, "[]"))
+		if err == nil {
+			addr, err := restrictFamily(ip, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			return []netip.AddrPort{netip.AddrPortFrom(addr, port)}, nil
 		}
 
-		if !allowDNS {
-			return errors.New("DNS names not allowed in address")
+		// Validate DNS.
+		if !opts.AllowDNS {
+			return nil, newError(ErrInvalidValue, value, nil, "DNS names not allowed in address")
 		}
 
-		_, err = net.LookupHost(host)
+		hosts, err := resolver.LookupHost(host)
 		if err != nil {
-			return fmt.Errorf("Couldn't resolve %q", host)
+			return nil, newError(ErrDNSNotResolved, host, nil, fmt.Sprintf("Couldn't resolve %q", host))
 		}
 
-		return nil
+		var addrs []netip.AddrPort
+		for _, h := range hosts {
+			resolved, err := netip.ParseAddr(h)
+			if err != nil {
+				continue
+			}
+
+			addr, err := restrictFamily(resolved, opts)
+			if err != nil {
+				continue
+			}
+
+			addrs = append(addrs, netip.AddrPortFrom(addr, port))
+		}
+
+		if len(addrs) == 0 {
+			return nil, newError(ErrDNSNotResolved, host, nil, fmt.Sprintf("%q didn't resolve to any allowed address", host))
+		}
+
+		return addrs, nil
+	}
+}
+
+// restrictFamily applies opts' family and scope restrictions to a resolved address, unmapping an
+// IPv4-in-IPv6 address first so it's treated as v4 for matching.
+func restrictFamily(ip netip.Addr, opts ResolveOptions) (netip.Addr, error) {
+	if ip.Is4In6() {
+		ip = ip.Unmap()
+	}
+
+	if opts.Families == AddressFamilyV4 && !ip.Is4() {
+		return netip.Addr{}, newError(ErrInvalidValue, ip.String(), nil, "Only IPv4 addresses are allowed")
 	}
+
+	if opts.Families == AddressFamilyV6 && ip.Is4() {
+		return netip.Addr{}, newError(ErrInvalidValue, ip.String(), nil, "Only IPv6 addresses are allowed")
+	}
+
+	if opts.ExcludeLoopback && ip.IsLoopback() {
+		return netip.Addr{}, newError(ErrInvalidValue, ip.String(), nil, "Loopback addresses aren't allowed")
+	}
+
+	if opts.ExcludeLinkLocal && ip.IsLinkLocalUnicast() {
+		return netip.Addr{}, newError(ErrInvalidValue, ip.String(), nil, "Link-local addresses aren't allowed")
+	}
+
+	if opts.ExcludeMulticast && ip.IsMulticast() {
+		return netip.Addr{}, newError(ErrInvalidValue, ip.String(), nil, "Multicast addresses aren't allowed")
+	}
+
+	return ip, nil
 }
 
 // IsAbsFilePath checks if value is an absolute file path.
 func IsAbsFilePath(value string) error {
 	if !filepath.IsAbs(value) {
-		return errors.New("Must be absolute file path")
+		return newError(ErrInvalidFormat, value, nil, "Must be absolute file path")
 	}
 
 	return nil
@@ -732,22 +1199,22 @@ func ParseNetworkVLANRange(vlan string) (int, int, error) {
 func IsHostname(name string) error {
 	// Validate length
 	if len(name) < 1 || len(name) > 63 {
-		return errors.New("Name must be 1-63 characters long")
+		return newError(ErrOutOfRange, name, map[string]any{"min": 1, "max": 63}, "Name must be 1-63 characters long")
 	}
 
 	// Validate first character
 	if strings.HasPrefix(name, "-") {
-		return errors.New(`Name must not start with "-" character`)
+		return newError(ErrInvalidFormat, name, nil, `Name must not start with "-" character`)
 	}
 
 	// Validate last character
 	if strings.HasSuffix(name, "-") {
-		return errors.New(`Name must not end with "-" character`)
+		return newError(ErrInvalidFormat, name, nil, `Name must not end with "-" character`)
 	}
 
 	_, err := strconv.ParseUint(name, 10, 64)
 	if err == nil {
-		return errors.New("Name cannot be a number")
+		return newError(ErrInvalidFormat, name, nil, "Name cannot be a number")
 	}
 
 	match, err := regexp.MatchString(`^[\-a-zA-Z0-9]+$`, name)
@@ -756,7 +1223,7 @@ func IsHostname(name string) error {
 	}
 
 	if !match {
-		return errors.New("Name can only contain alphanumeric and hyphen characters")
+		return newError(ErrInvalidFormat, name, nil, "Name can only contain alphanumeric and hyphen characters")
 	}
 
 	return nil
@@ -766,11 +1233,11 @@ func IsHostname(name string) error {
 // forward slash, hyphen, colon, underscore and full stop characters.
 func IsDeviceName(name string) error {
 	if len(name) < 1 || len(name) > 63 {
-		return errors.New("Name must be 1-63 characters long")
+		return newError(ErrOutOfRange, name, map[string]any{"min": 1, "max": 63}, "Name must be 1-63 characters long")
 	}
 
 	if string(name[0]) == "." {
-		return errors.New(`Name must not start with "." character`)
+		return newError(ErrInvalidFormat, name, nil, `Name must not start with "." character`)
 	}
 
 	match, err := regexp.MatchString(`^[\/\.\-:_a-zA-Z0-9]+$`, name)
@@ -779,7 +1246,7 @@ func IsDeviceName(name string) error {
 	}
 
 	if !match {
-		return errors.New("Name can only contain alphanumeric, forward slash, hyphen, colon, underscore and full stop characters")
+		return newError(ErrInvalidFormat, name, nil, "Name can only contain alphanumeric, forward slash, hyphen, colon, underscore and full stop characters")
 	}
 
 	return nil
@@ -788,32 +1255,251 @@ func IsDeviceName(name string) error {
 // IsRequestURL checks value is a valid HTTP/HTTPS request URL.
 func IsRequestURL(value string) error {
 	if value == "" {
-		return errors.New("Empty URL")
+		return newError(ErrRequired, value, nil, "Empty URL")
 	}
 
 	_, err := url.ParseRequestURI(value)
 	if err != nil {
-		return fmt.Errorf("Invalid URL: %w", err)
+		return newError(ErrInvalidURL, value, nil, fmt.Sprintf("Invalid URL: %s", err))
 	}
 
 	return nil
 }
 
-// IsCloudInitUserData checks value is valid cloud-init user data.
-func IsCloudInitUserData(value string) error {
-	if value == "#cloud-config" || strings.HasPrefix(value, "#cloud-config\n") {
-		lines := strings.SplitN(value, "\n", 2)
+// CloudInitFormat identifies which of the cloud-init user-data formats a CloudInitPayload (or one
+// of its Parts) was recognized as.
+type CloudInitFormat string
+
+// Cloud-init user-data formats recognized by ParseCloudInitUserData, named after the
+// content-type/header cloud-init itself uses to tell them apart.
+const (
+	CloudInitFormatCloudConfig CloudInitFormat = "text/cloud-config"
+	CloudInitFormatShellScript CloudInitFormat = "text/x-shellscript"
+	CloudInitFormatBoothook    CloudInitFormat = "text/cloud-boothook"
+	CloudInitFormatInclude     CloudInitFormat = "text/x-include-url"
+	CloudInitFormatMultipart   CloudInitFormat = "multipart/mixed"
+	CloudInitFormatOpaque      CloudInitFormat = "text/plain"
+)
 
-		// If value only contains the cloud-config header, it is valid.
-		if len(lines) == 1 {
-			return nil
+// CloudInitPart is one part of a multipart/mixed cloud-init payload.
+type CloudInitPart struct {
+	ContentType string
+	Format      CloudInitFormat
+	Data        string
+}
+
+// CloudInitPayload is the parsed structure of a cloud-init user-data value, so callers that need
+// to know what cloud-init will actually do with it (the docs generator, the web UI's preview)
+// don't have to re-implement format sniffing themselves.
+type CloudInitPayload struct {
+	Format CloudInitFormat
+
+	// Gzipped is true if value was a gzip-compressed, base64-encoded payload; Format and the
+	// remaining fields describe the decompressed content.
+	Gzipped bool
+
+	// Jinja is true if value carried a "## template: jinja" header; Format and the remaining
+	// fields describe the content that follows the header.
+	Jinja bool
+
+	// Includes holds the URLs of a text/x-include(-once) payload.
+	Includes []string
+
+	// Parts holds the sub-payloads of a multipart/mixed payload.
+	Parts []CloudInitPart
+}
+
+// jinjaHeader is the first line of a jinja-templated cloud-init payload, per cloud-init's own
+// "## template: jinja" convention.
+const jinjaHeader = "## template: jinja"
+
+// ParseCloudInitUserData parses value as cloud-init user-data, identifying it as one of the
+// formats cloud-init itself understands (a #cloud-config YAML document, a "#!" shebang script, a
+// #cloud-boothook script, a #include/#include-once URL list, a gzip+base64 compressed payload, a
+// "## template: jinja" templated payload, or a MIME multipart archive combining several of the
+// above), transparently unwrapping gzip and jinja headers before classifying the content beneath
+// them. Anything that doesn't match a known format is reported as opaque rather than rejected,
+// since cloud-init itself accepts user-data formats this package doesn't need to understand.
+func ParseCloudInitUserData(value string) (*CloudInitPayload, error) {
+	if decoded, ok := decodeGzippedBase64(value); ok {
+		inner, err := ParseCloudInitUserData(decoded)
+		if err != nil {
+			return nil, err
 		}
 
-		return IsYAML(lines[1])
+		inner.Gzipped = true
+
+		return inner, nil
 	}
 
-	// Since there are various other user-data formats besides cloud-config, consider those valid.
-	return nil
+	if rest, ok := strings.CutPrefix(value, jinjaHeader); ok {
+		rest = strings.TrimPrefix(rest, "\n")
+
+		inner, err := ParseCloudInitUserData(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		inner.Jinja = true
+
+		return inner, nil
+	}
+
+	switch {
+	case value == "#cloud-config" || strings.HasPrefix(value, "#cloud-config\n"):
+		return parseCloudConfig(value)
+	case value == "#cloud-boothook" || strings.HasPrefix(value, "#cloud-boothook\n"):
+		return &CloudInitPayload{Format: CloudInitFormatBoothook}, nil
+	case strings.HasPrefix(value, "#!"):
+		return &CloudInitPayload{Format: CloudInitFormatShellScript}, nil
+	case strings.HasPrefix(value, "#include"):
+		includes, err := parseCloudInitIncludes(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &CloudInitPayload{Format: CloudInitFormatInclude, Includes: includes}, nil
+	case isMIMEMultipart(value):
+		return parseCloudInitMultipart(value)
+	}
+
+	return &CloudInitPayload{Format: CloudInitFormatOpaque}, nil
+}
+
+// decodeGzippedBase64 reports whether value is a base64-encoded gzip stream, returning its
+// decompressed content if so.
+func decodeGzippedBase64(value string) (string, bool) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(raw) < 2 || raw[0] != 0x1f || raw[1] != 0x8b {
+		return "", false
+	}
+
+	gzr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", false
+	}
+
+	defer func() { _ = gzr.Close() }()
+
+	decompressed, err := io.ReadAll(gzr)
+	if err != nil {
+		return "", false
+	}
+
+	return string(decompressed), true
+}
+
+// parseCloudConfig validates the YAML body of a #cloud-config payload.
+func parseCloudConfig(value string) (*CloudInitPayload, error) {
+	lines := strings.SplitN(value, "\n", 2)
+
+	// If value only contains the cloud-config header, it is valid.
+	if len(lines) == 1 {
+		return &CloudInitPayload{Format: CloudInitFormatCloudConfig}, nil
+	}
+
+	err := IsYAML(lines[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudInitPayload{Format: CloudInitFormatCloudConfig}, nil
+}
+
+// parseCloudInitIncludes validates the body of a #include/#include-once payload, which is one URL
+// per non-empty, non-comment line.
+func parseCloudInitIncludes(value string) ([]string, error) {
+	var includes []string
+
+	for _, line := range strings.Split(value, "\n")[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		err := IsRequestURL(line)
+		if err != nil {
+			return nil, err
+		}
+
+		includes = append(includes, line)
+	}
+
+	return includes, nil
+}
+
+// isMIMEMultipart reports whether value looks like a raw MIME message carrying a
+// multipart/mixed (or related) cloud-init archive, per cloud-init's own "Content-Type:
+// multipart/" / "MIME-Version: 1.0" header convention.
+func isMIMEMultipart(value string) bool {
+	header, _, _ := strings.Cut(value, "\n\n")
+
+	return strings.Contains(strings.ToLower(header), "mime-version:") ||
+		strings.Contains(strings.ToLower(header), "content-type: multipart/")
+}
+
+// parseCloudInitMultipart parses a MIME multipart cloud-init archive, validating each part
+// according to its own Content-Type.
+func parseCloudInitMultipart(value string) (*CloudInitPayload, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(value))
+	if err != nil {
+		return nil, newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid MIME cloud-init payload: %s", err))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, newError(ErrInvalidFormat, value, nil, "Cloud-init MIME payload isn't multipart")
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	var parts []CloudInitPart
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid MIME cloud-init part: %s", err))
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, newError(ErrInvalidFormat, value, nil, fmt.Sprintf("Invalid MIME cloud-init part: %s", err))
+		}
+
+		contentType := part.Header.Get("Content-Type")
+
+		format := CloudInitFormat(contentType)
+		switch format {
+		case CloudInitFormatCloudConfig, CloudInitFormatShellScript, CloudInitFormatBoothook:
+			// Recognized formats; validated below.
+		case "text/jinja2":
+			// Handled via the jinja header rather than a distinct format once unwrapped.
+		default:
+			format = CloudInitFormatOpaque
+		}
+
+		if format == CloudInitFormatCloudConfig {
+			err := IsYAML(string(data))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		parts = append(parts, CloudInitPart{ContentType: contentType, Format: format, Data: string(data)})
+	}
+
+	return &CloudInitPayload{Format: CloudInitFormatMultipart, Parts: parts}, nil
+}
+
+// IsCloudInitUserData checks value is valid cloud-init user data.
+func IsCloudInitUserData(value string) error {
+	_, err := ParseCloudInitUserData(value)
+
+	return err
 }
 
 // IsYAML checks value is valid YAML.
@@ -822,7 +1508,7 @@ func IsYAML(value string) error {
 
 	err := yaml.Unmarshal([]byte(value), &out)
 	if err != nil {
-		return err
+		return newError(ErrInvalidYAML, value, nil, err.Error())
 	}
 
 	return nil
@@ -833,14 +1519,14 @@ func IsValidCPUSet(value string) error {
 	// Validate the CPU set syntax.
 	match, _ := regexp.MatchString(`^(?:[0-9]+(?:[,-][0-9]+)?)(?:,[0-9]+(?:[,-][0-9]+)*)?$`, value)
 	if !match {
-		return errors.New("Invalid CPU limit syntax")
+		return newError(ErrInvalidFormat, value, nil, "Invalid CPU limit syntax")
 	}
 
 	// Validate single values.
 	cpu, err := strconv.ParseInt(value, 10, 64)
 	if err == nil {
 		if cpu < 1 {
-			return fmt.Errorf("Invalid cpuset value: %s", value)
+			return newError(ErrInvalidValue, value, nil, fmt.Sprintf("Invalid cpuset value: %s", value))
 		}
 
 		return nil
@@ -855,17 +1541,17 @@ func IsValidCPUSet(value string) error {
 			// Range
 			fields := strings.SplitN(chunk, "-", 2)
 			if len(fields) != 2 {
-				return fmt.Errorf("Invalid cpuset value: %s", value)
+				return newError(ErrInvalidValue, value, nil, fmt.Sprintf("Invalid cpuset value: %s", value))
 			}
 
 			low, err := strconv.ParseInt(fields[0], 10, 64)
 			if err != nil {
-				return fmt.Errorf("Invalid cpuset value: %s", value)
+				return newError(ErrInvalidValue, value, nil, fmt.Sprintf("Invalid cpuset value: %s", value))
 			}
 
 			high, err := strconv.ParseInt(fields[1], 10, 64)
 			if err != nil {
-				return fmt.Errorf("Invalid cpuset value: %s", value)
+				return newError(ErrInvalidValue, value, nil, fmt.Sprintf("Invalid cpuset value: %s", value))
 			}
 
 			for i := low; i <= high; i++ {
@@ -875,7 +1561,7 @@ func IsValidCPUSet(value string) error {
 			// Simple entry
 			nr, err := strconv.ParseInt(chunk, 10, 64)
 			if err != nil {
-				return fmt.Errorf("Invalid cpuset value: %s", value)
+				return newError(ErrInvalidValue, value, nil, fmt.Sprintf("Invalid cpuset value: %s", value))
 			}
 
 			cpus[nr]++
@@ -885,7 +1571,7 @@ func IsValidCPUSet(value string) error {
 	for i := range cpus {
 		// The CPU was specified more than once, e.g. 1-3,3.
 		if cpus[i] > 1 {
-			return errors.New("Cannot define CPU multiple times")
+			return newError(ErrInvalidValue, value, nil, "Cannot define CPU multiple times")
 		}
 	}
 
@@ -896,7 +1582,7 @@ func IsValidCPUSet(value string) error {
 func IsShorterThan(length int) func(value string) error {
 	return func(value string) error {
 		if len(value) > length {
-			return fmt.Errorf("Value is too long. Must be within %d characters", length)
+			return newError(ErrTooLong, value, map[string]any{"max": length}, fmt.Sprintf("Value is too long. Must be within %d characters", length))
 		}
 
 		return nil
@@ -908,11 +1594,11 @@ func IsMinimumDuration(minimum time.Duration) func(value string) error {
 	return func(value string) error {
 		duration, err := time.ParseDuration(value)
 		if err != nil {
-			return errors.New("Invalid duration")
+			return newError(ErrInvalidFormat, value, nil, "Invalid duration")
 		}
 
 		if duration < minimum {
-			return fmt.Errorf("Duration must be greater than %s", minimum)
+			return newError(ErrDurationTooShort, value, map[string]any{"min": minimum.String()}, fmt.Sprintf("Duration must be greater than %s", minimum))
 		}
 
 		return nil